@@ -0,0 +1,413 @@
+package agentconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/secret"
+	"github.com/rs/zerolog/log"
+)
+
+// Interval between config file modification checks
+const watchInterval = 2 * time.Second
+
+// Config - Go 侧配置，修改后无需重启 Agent 即可在下一次运行生效
+type Config struct {
+	Resell     ResellConfig     `json:"resell"`
+	StuckCheck StuckCheckConfig `json:"stuck_check"`
+	// Diagnostics - 只在 Agent 启动时读取，修改后需要重启
+	Diagnostics DiagnosticsConfig `json:"diagnostics"`
+	// DataDir - 持久化数据目录，为空时使用 ./data；只在 Agent 启动时读取
+	DataDir    string           `json:"data_dir"`
+	PriceWatch []PriceWatchRule `json:"price_watch"`
+	Schedule   ScheduleConfig   `json:"schedule"`
+	// Wanted - 武器获取规划的目标清单
+	Wanted []WantedWeapon `json:"wanted"`
+	Focus  FocusConfig    `json:"focus"`
+	// Chains - 按名称定义的任务串联流程，由 ChainRunAction 执行
+	Chains map[string][]ChainStep `json:"chains"`
+	// ResourcePack - 叠加在客户端资源之上的资源包，对应 resource_packs 下的目录名，为空表示不使用
+	ResourcePack string `json:"resource_pack"`
+	// Seed - 随机行为使用的种子，0 表示每次运行随机生成；复现问题时填入日志或崩溃报告中的 seed
+	Seed int64 `json:"seed"`
+	// CrashReport - 只在 Agent 启动时读取
+	CrashReport CrashReportConfig `json:"crash_report"`
+	Foreground  ForegroundConfig  `json:"foreground"`
+	// Instances - 由 dispatch 工具轮流或同时运行任务的多个游戏实例
+	Instances []InstanceConfig `json:"instances"`
+	Log       LogConfig        `json:"log"`
+	// PriceCompare - 多个商店出售同一物品时按汇率比较，推荐更便宜的来源
+	PriceCompare PriceCompareConfig `json:"price_compare"`
+	// Webhooks - 任务结束时推送结果的地址，推送失败会排队重试
+	Webhooks []WebhookConfig `json:"webhooks"`
+	SafeMode SafeModeConfig  `json:"safe_mode"`
+	// Takeover - 只在 Agent 启动时读取，修改后需要重启
+	Takeover TakeoverConfig `json:"takeover"`
+	OCR      OCRConfig      `json:"ocr"`
+	// Sync - 只在 Agent 启动时读取，修改后需要重启
+	Sync SyncConfig `json:"sync"`
+}
+
+// SyncConfig - 定时把数据目录同步到 WebDAV 或 S3 兼容存储，多台电脑共享历史、宏与资源替换，默认关闭
+type SyncConfig struct {
+	// Kind - webdav 或 s3，为空不同步
+	Kind string `json:"kind"`
+	// URL - WebDAV 目录地址，或 S3 的 endpoint（如 https://s3.amazonaws.com），可以是 encrypt-secret 生成的加密值
+	URL string `json:"url"`
+	// Username, Password - WebDAV 的账号，Password 可以是加密值
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Bucket, Region, AccessKey, SecretKey - S3 的存储桶与密钥，SecretKey 可以是加密值；Region 为空时使用 us-east-1
+	Bucket    string `json:"bucket"`
+	Region    string `json:"region"`
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+	// Prefix - 远端存放数据的目录，为空使用 maaend
+	Prefix string `json:"prefix"`
+	// IntervalMinutes - 同步间隔，0 表示 30 分钟；Agent 启动时先同步一次
+	IntervalMinutes int `json:"interval_minutes"`
+	// Include - 同步的数据目录子路径，为空时同步各模块的历史、宏与资源替换，不同步队列、失败计数等本机状态
+	Include []string `json:"include"`
+}
+
+// OCRConfig - Go 侧 OCR 失败时的回退链，按节点 attach.ocr_category 声明的 ROI 类别选择
+type OCRConfig struct {
+	// AccurateModel - 高精度 OCR 模型，相对资源 model/ocr 的目录名，为空时跳过 accurate
+	AccurateModel string `json:"accurate_model"`
+	// Chains - ROI 类别 -> 回退链，没有声明类别或类别未配置的节点只用节点自身的 OCR
+	Chains map[string]OCRChain `json:"chains"`
+}
+
+// OCRChain - 前一种方式没有结果时才尝试下一种，用速度换准确度
+type OCRChain struct {
+	// Stages - 依次尝试的方式：default（节点自身的 OCR）、accurate（换用高精度模型）、digits（数字模板匹配）
+	Stages []string `json:"stages"`
+	// DigitTemplates - digits 使用的内置资源目录，其中放 0.png ~ 9.png
+	DigitTemplates string `json:"digit_templates"`
+}
+
+// TakeoverConfig - 扫描中途暂停，让用户手动处理游戏画面后从中断的格子继续，默认关闭
+type TakeoverConfig struct {
+	// Addr - 暂停/恢复接口的监听地址，如 127.0.0.1:6061，为空不开启；接口为 POST /pause、POST /resume、GET /status
+	// 非本机地址（包括 :6061 这种监听所有网卡的写法）必须配置 Token，否则不开启
+	Addr string `json:"addr"`
+	// Token - 接口的访问令牌，请求需带 Authorization: Bearer <token>，可以是加密值；为空时不校验
+	Token string `json:"token"`
+	// Hotkey - 切换暂停与恢复的全局热键，支持 F1-F12、Pause、ScrollLock，仅 Windows，为空不开启
+	Hotkey string `json:"hotkey"`
+}
+
+// SafeModeConfig - 任务连续失败后，下一次运行改为安全模式：不购买、显示全部提示、记录每一步的截图并生成诊断包
+type SafeModeConfig struct {
+	// AfterFailures - 同一任务入口连续失败多少次后进入安全模式，0 表示不启用；成功运行一次即清零
+	AfterFailures int `json:"after_failures"`
+}
+
+// WebhookConfig - 以 POST JSON 推送任务结果（与 TaskResult 节点输出的结果相同）
+type WebhookConfig struct {
+	// Name - 排队中的推送按名称对应配置，改名后未送达的推送会被放弃；必须非空且不重复，否则该 webhook 被忽略
+	Name string `json:"name"`
+	// URL - 可以是 encrypt-secret 生成的加密值
+	URL string `json:"url"`
+	// Tasks - 只推送这些任务的结果，为空时推送全部
+	Tasks []string `json:"tasks"`
+	// MaxAttempts - 最多投递次数（含首次），0 表示 12 次；失败后等待 30 秒起、每次翻倍、最长 30 分钟
+	MaxAttempts int `json:"max_attempts"`
+}
+
+// PriceCompareConfig - 不同商店的价格按汇率折算为统一价值后比较
+type PriceCompareConfig struct {
+	// Rates - 商店名 -> 该商店一单位货币折合的价值，如 {"信用商店": 1, "倒卖": 0.05}；只比较两边都设置了汇率的商店
+	Rates map[string]float64 `json:"rates"`
+	// MaxAgeHours - 早于该小时数看到的价格不参与比较，0 表示一周
+	MaxAgeHours int `json:"max_age_hours"`
+}
+
+// LogConfig - 日志输出方式
+type LogConfig struct {
+	// Bilingual - 每条日志额外输出 msg_key 与中文日志的英文翻译 msg_en，方便不同语言的开发者排查
+	Bilingual bool `json:"bilingual"`
+}
+
+// InstanceConfig - 一个模拟器实例或游戏窗口，Adb 与 Window 二选一
+// 每个实例在 data/instances/<name> 下有独立的数据目录，倒卖历史、识别统计等互不影响
+type InstanceConfig struct {
+	Name string `json:"name"`
+	// Adb - 模拟器的 adb 地址，如 127.0.0.1:16384
+	Adb string `json:"adb"`
+	// AdbPath - adb 可执行文件，为空时使用自动发现的 adb
+	AdbPath string `json:"adb_path"`
+	// Window - Win32 窗口标题需要包含的文字
+	Window string `json:"window"`
+	// Screencap - Win32 截图方式，如 FramePool、PrintWindow，为空使用 FramePool
+	Screencap string `json:"screencap"`
+	// Resources - 依次加载的资源目录，为空时使用 resource（adb 实例额外加载 resource_adb），B 服账号可加上 resource_bilibili
+	Resources []string `json:"resources"`
+}
+
+// ForegroundConfig - 任务开始前把游戏窗口切到前台，并确认连接的是预期的窗口或模拟器实例，默认关闭
+type ForegroundConfig struct {
+	Enabled bool `json:"enabled"`
+	// WindowTitle - Win32 窗口标题需要包含的文字，为空不检查
+	WindowTitle string `json:"window_title"`
+	// Instance - 控制器标识（模拟器为 adb 地址，如 127.0.0.1:16384）需要包含的文字，为空不检查
+	Instance string `json:"instance"`
+	// StopOnMismatch - 窗口或实例不符时停止任务，否则只警告
+	StopOnMismatch bool `json:"stop_on_mismatch"`
+}
+
+// CrashReportConfig - 崩溃报告总会写入 debug/crash，上传需要用户主动开启
+type CrashReportConfig struct {
+	Upload bool `json:"upload"`
+	// Endpoint - 接收报告的地址（POST JSON），可以是 encrypt-secret 生成的加密值
+	Endpoint string `json:"endpoint"`
+}
+
+// ChainStep - 串联流程中的一步，Run 为 pipeline 入口节点
+type ChainStep struct {
+	Run string `json:"run"`
+	// If - 可选，满足条件才执行该步
+	If *ChainCondition `json:"if"`
+	// Override - 可选，执行时附带的 pipeline override，用于替代界面上的任务选项
+	Override map[string]any `json:"override"`
+	// StopOnFailure - 该步失败时结束整个流程
+	StopOnFailure bool `json:"stop_on_failure"`
+}
+
+// ChainCondition - 基于本次流程中任务结果（taskresult）的条件，各字段同时满足才成立
+type ChainCondition struct {
+	// Result - 结果中的任务名，如 Resell、EssenceFilter
+	Result string `json:"result"`
+	// Status - success / skipped / failed，为空不限
+	Status string `json:"status"`
+	// Decision - 结果中任一决定的 action，如 buy、recommend，为空不限
+	Decision string `json:"decision"`
+	// Not - 取反
+	Not bool `json:"not"`
+}
+
+// FocusConfig - 界面提示信息的详细程度
+type FocusConfig struct {
+	// Verbosity - quiet 只显示最终结果与建议，normal 额外显示阶段进度，verbose 显示逐项细节
+	Verbosity string `json:"verbosity"`
+}
+
+// ResellConfig - 倒卖相关配置
+type ResellConfig struct {
+	// ScanDelay - 每一步识别前等待画面静止的时间（毫秒）
+	ScanDelay int `json:"scan_delay"`
+	// FriendPriceDelay - 等待好友价格加载的时间（毫秒）
+	FriendPriceDelay int `json:"friend_price_delay"`
+	// StepTimeout - 等待详情页、返回按钮等界面出现的最长时间（毫秒）
+	StepTimeout int `json:"step_timeout"`
+}
+
+// StuckCheckConfig - 卡死检测相关配置
+type StuckCheckConfig struct {
+	// Threshold - 画面连续无变化多少次点击、滑动、按键或输入后停止任务，DoNothing、等待与自定义动作不计入
+	Threshold int `json:"threshold"`
+}
+
+// DiagnosticsConfig - 内存、协程泄漏诊断，默认关闭
+type DiagnosticsConfig struct {
+	Enabled bool `json:"enabled"`
+	// PprofAddr - pprof 监听地址，为空则不开启 pprof
+	PprofAddr string `json:"pprof_addr"`
+	// IntervalSec - 自检采样间隔（秒）
+	IntervalSec int `json:"interval_sec"`
+}
+
+// PriceWatchRule - 商店扫描中观察到满足条件的物品时立即提醒，与是否购买无关
+// MaxPrice、MinProfit 为 0 表示不限制，两者都设置时需同时满足
+type PriceWatchRule struct {
+	Item      string `json:"item"`
+	MaxPrice  int    `json:"max_price"`
+	MinProfit int    `json:"min_profit"`
+}
+
+// ScheduleConfig - 刷新时间推算与运行建议
+type ScheduleConfig struct {
+	// DailyResetHour - 商店每日重置的本地整点
+	DailyResetHour int `json:"daily_reset_hour"`
+	// DelayMinutes - 刷新后多久运行任务
+	DelayMinutes int `json:"delay_minutes"`
+	// MergeMinutes - 间隔在此范围内的两次刷新合并为一次运行
+	MergeMinutes int `json:"merge_minutes"`
+	// Maintenance - 维护时段，期间不运行任务，计划中的运行顺延到时段结束
+	Maintenance []MaintenanceWindow `json:"maintenance"`
+	// Cooldown - 任务完成后一段时间内拒绝再次运行，防止计划或误点重复排队
+	Cooldown CooldownConfig `json:"cooldown"`
+}
+
+// CooldownConfig - Minutes 以任务入口节点为键，如 {"ResellVersionGate": 30}；默认为空，未列出或为 0 的任务不限制
+// 需要立即重跑时在任务选项中打开「忽略冷却」，只对本次运行生效
+type CooldownConfig struct {
+	Minutes map[string]int `json:"minutes"`
+}
+
+// MaintenanceWindow - 一个维护时段
+// Start/End 为本地时间 "HH:MM"，End 不晚于 Start 时视为跨天；Weekdays 为空表示每天（0 为周日）
+// From/To 为 "2006-01-02 15:04" 格式的一次性时段，设置后忽略 Start/End/Weekdays
+type MaintenanceWindow struct {
+	Name     string `json:"name"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Weekdays []int  `json:"weekdays"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+// WantedWeapon - 希望持有的武器，Weapon 可以是中文名或 internal_id
+// Count 为 0 时按 1 处理，Priority 越大越靠前
+type WantedWeapon struct {
+	Weapon   string `json:"weapon"`
+	Count    int    `json:"count"`
+	Priority int    `json:"priority"`
+}
+
+// Default returns the built-in configuration
+func Default() Config {
+	return Config{
+		Resell: ResellConfig{
+			ScanDelay:        200,
+			FriendPriceDelay: 600,
+			StepTimeout:      3000,
+		},
+		StuckCheck: StuckCheckConfig{
+			Threshold: 30,
+		},
+		Schedule: ScheduleConfig{
+			DailyResetHour: 4,
+			DelayMinutes:   10,
+			MergeMinutes:   30,
+		},
+		Diagnostics: DiagnosticsConfig{
+			PprofAddr:   "127.0.0.1:6060",
+			IntervalSec: 60,
+		},
+		Focus: FocusConfig{
+			Verbosity: "normal",
+		},
+		SafeMode: SafeModeConfig{
+			AfterFailures: 3,
+		},
+		OCR: OCRConfig{
+			Chains: map[string]OCRChain{
+				"price": {
+					Stages:         []string{"default", "accurate", "digits"},
+					DigitTemplates: "resell/digits",
+				},
+			},
+		},
+	}
+}
+
+var current atomic.Value // Config
+
+func init() {
+	current.Store(Default())
+}
+
+// Get returns the latest loaded configuration
+func Get() Config {
+	return current.Load().(Config)
+}
+
+// Set installs cfg as the current configuration, for commands that load the config once instead of watching it
+func Set(cfg Config) {
+	current.Store(cfg)
+}
+
+// Load reads the config file on top of defaults; a missing file yields defaults
+func Load(path string) (Config, error) {
+	cfg := Default()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Default(), err
+	}
+	cfg.Webhooks = uniqueWebhooks(cfg.Webhooks)
+	return cfg, nil
+}
+
+// uniqueWebhooks drops webhooks without a name or sharing one with an earlier entry,
+// since queued deliveries find their webhook by name
+func uniqueWebhooks(hooks []WebhookConfig) []WebhookConfig {
+	seen := map[string]bool{}
+	kept := hooks[:0:0]
+	for i, hook := range hooks {
+		if hook.Name == "" || seen[hook.Name] {
+			log.Warn().Int("index", i).Str("name", hook.Name).Msg("Webhook name is empty or duplicated, webhook ignored")
+			continue
+		}
+		seen[hook.Name] = true
+		kept = append(kept, hook)
+	}
+	return kept
+}
+
+// Masked returns a copy for logging with addresses, accounts and keys hidden by secret.Mask
+func (c Config) Masked() Config {
+	c.Sync.URL = secret.Mask(c.Sync.URL)
+	c.Sync.Username = secret.Mask(c.Sync.Username)
+	c.Sync.Password = secret.Mask(c.Sync.Password)
+	c.Sync.AccessKey = secret.Mask(c.Sync.AccessKey)
+	c.Sync.SecretKey = secret.Mask(c.Sync.SecretKey)
+	c.CrashReport.Endpoint = secret.Mask(c.CrashReport.Endpoint)
+	c.Takeover.Token = secret.Mask(c.Takeover.Token)
+	hooks := make([]WebhookConfig, len(c.Webhooks))
+	for i, hook := range c.Webhooks {
+		hook.URL = secret.Mask(hook.URL)
+		hooks[i] = hook
+	}
+	c.Webhooks = hooks
+	return c
+}
+
+// Watch loads the config file and reloads it whenever it changes
+func Watch(path string) {
+	if cfg, err := Load(path); err != nil {
+		log.Warn().Err(err).Str("path", path).Msg("Failed to load agent config, using defaults")
+	} else {
+		current.Store(cfg)
+		log.Info().Str("path", path).Interface("config", cfg.Masked()).Msg("Agent config loaded")
+	}
+
+	go func() {
+		lastMod := modTime(path)
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			mod := modTime(path)
+			if mod.Equal(lastMod) {
+				continue
+			}
+			lastMod = mod
+
+			cfg, err := Load(path)
+			if err != nil {
+				log.Warn().Err(err).Str("path", path).Msg("Failed to reload agent config, keeping previous one")
+				continue
+			}
+			current.Store(cfg)
+			log.Info().Str("path", path).Interface("config", cfg.Masked()).Msg("Agent config reloaded")
+			fmt.Println("Go 侧配置已重新加载，将在下一次运行生效")
+		}
+	}()
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
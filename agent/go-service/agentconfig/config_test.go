@@ -0,0 +1,22 @@
+package agentconfig
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestUniqueWebhooks(t *testing.T) {
+	hooks := []WebhookConfig{
+		{Name: "a", URL: "https://a"},
+		{Name: "", URL: "https://unnamed"},
+		{Name: "b", URL: "https://b"},
+		{Name: "a", URL: "https://a2"},
+	}
+	var got []string
+	for _, hook := range uniqueWebhooks(hooks) {
+		got = append(got, hook.URL)
+	}
+	if want := []string{"https://a", "https://b"}; !slices.Equal(got, want) {
+		t.Errorf("uniqueWebhooks kept %v, want %v", got, want)
+	}
+}
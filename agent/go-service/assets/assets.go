@@ -0,0 +1,162 @@
+// Package assets holds the template images, reference screenshots and icon databases used by
+// Go-side recognizers. Files are embedded at build time; a file with the same path under the
+// data directory's assets folder replaces the embedded one, so templates can be patched after
+// a UI change without rebuilding.
+package assets
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+//go:embed files
+var embedded embed.FS
+
+// embeddedRoot - 内置文件在 embedded 中的根目录
+const embeddedRoot = "files"
+
+// templatePrefix - 注册到 MaaFramework 的模板名前缀，避免与资源目录中的模板重名
+const templatePrefix = "GoAssets/"
+
+// Source - 资源的来源
+type Source string
+
+const (
+	SourceDisk     Source = "disk"
+	SourceEmbedded Source = "embedded"
+)
+
+// overridePath returns where a user override of name would be
+func overridePath(name string) string {
+	return datadir.Path("assets", filepath.FromSlash(name))
+}
+
+// ReadFile returns the override of name from the data directory if present, otherwise the embedded file
+func ReadFile(name string) ([]byte, Source, error) {
+	data, err := os.ReadFile(overridePath(name))
+	if err == nil {
+		return data, SourceDisk, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, "", err
+	}
+	data, err = embedded.ReadFile(path.Join(embeddedRoot, name))
+	if err != nil {
+		return nil, "", fmt.Errorf("asset %s: %w", name, err)
+	}
+	return data, SourceEmbedded, nil
+}
+
+type cachedImage struct {
+	img     image.Image
+	source  Source
+	modTime time.Time
+}
+
+var (
+	mu     sync.Mutex
+	images = make(map[string]cachedImage)
+)
+
+// Image decodes the PNG or JPEG asset name. Decoded images are cached; an override on disk is
+// read again once its modification time changes, and adding or removing it takes effect at once
+func Image(name string) (image.Image, Source, error) {
+	var modTime time.Time
+	source := SourceEmbedded
+	if info, err := os.Stat(overridePath(name)); err == nil {
+		modTime, source = info.ModTime(), SourceDisk
+	}
+
+	mu.Lock()
+	if c, ok := images[name]; ok && c.source == source && c.modTime.Equal(modTime) {
+		mu.Unlock()
+		return c.img, c.source, nil
+	}
+	mu.Unlock()
+
+	data, source, err := ReadFile(name)
+	if err != nil {
+		return nil, "", err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("decode asset %s: %w", name, err)
+	}
+
+	mu.Lock()
+	images[name] = cachedImage{img: img, source: source, modTime: modTime}
+	mu.Unlock()
+	return img, source, nil
+}
+
+// TemplateName returns the template name under which Register makes name available
+func TemplateName(name string) string {
+	return templatePrefix + name
+}
+
+// Register loads the image asset name and registers it with MaaFramework for the current
+// task, returning the name to use as "template" in a node or override
+func Register(ctx *maa.Context, name string) (string, error) {
+	img, _, err := Image(name)
+	if err != nil {
+		return "", err
+	}
+	template := TemplateName(name)
+	if err := ctx.OverrideImage(template, img); err != nil {
+		return "", fmt.Errorf("register asset %s: %w", name, err)
+	}
+	return template, nil
+}
+
+// Entry - 一个可用的资源及其来源
+type Entry struct {
+	Name   string
+	Source Source
+}
+
+// List returns every embedded asset plus the overrides found on disk, sorted by name
+func List() ([]Entry, error) {
+	sources := map[string]Source{}
+	err := fs.WalkDir(embedded, embeddedRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		sources[p[len(embeddedRoot)+1:]] = SourceEmbedded
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	root := datadir.Path("assets")
+	filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if rel, err := filepath.Rel(root, p); err == nil {
+			sources[filepath.ToSlash(rel)] = SourceDisk
+		}
+		return nil
+	})
+
+	entries := make([]Entry, 0, len(sources))
+	for name, source := range sources {
+		entries = append(entries, Entry{Name: name, Source: source})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/assets"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+)
+
+// runAssets - go-service assets [-export name]
+// 列出内置资源及其来源（embedded 或数据目录中的替换文件）；-export 把内置文件复制到数据目录，修改后即替代内置文件
+func runAssets(args []string) error {
+	fs := flag.NewFlagSet("assets", flag.ContinueOnError)
+	export := fs.String("export", "", "copy the embedded asset with this name into the data dir for editing")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := agentconfig.Load(filepath.Join(getCwd(), "config", "go-service.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v, using default data dir\n", err)
+	}
+	if err := datadir.Init(cfg.DataDir); err != nil {
+		return err
+	}
+
+	if *export != "" {
+		data, source, err := assets.ReadFile(*export)
+		if err != nil {
+			return err
+		}
+		dst := datadir.Path("assets", filepath.FromSlash(*export))
+		if source == assets.SourceDisk {
+			return fmt.Errorf("%s already overridden at %s", *export, dst)
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dst, data, 0644); err != nil {
+			return err
+		}
+		fmt.Printf("exported %s to %s\n", *export, dst)
+		return nil
+	}
+
+	entries, err := assets.List()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		fmt.Printf("%-9s %s\n", e.Source, e.Name)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/backup"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+)
+
+// backupSources - 打包的内容：Go 侧配置与数据目录
+func backupSources() []backup.Source {
+	configPath := filepath.Join(getCwd(), "config", "go-service.json")
+	// 配置损坏时按默认值处理，恢复正是为了修复这种情况
+	cfg, err := agentconfig.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %s: %v, using default data dir\n", configPath, err)
+	}
+	dataDir := datadir.Root()
+	if cfg.DataDir != "" {
+		dataDir = cfg.DataDir
+	}
+	return []backup.Source{
+		{Name: "config/go-service.json", Path: configPath},
+		{Name: "data", Path: dataDir},
+	}
+}
+
+// runBackup - go-service backup [-out maaend-backup.zip]
+func runBackup(args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	out := fs.String("out", "maaend-backup-"+time.Now().Format("20060102-150405")+".zip", "archive to write")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	manifest, err := backup.Export(*out, Version, backupSources())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d files written to %s\n", len(manifest.Entries), *out)
+	return nil
+}
+
+// runRestore - go-service restore -in maaend-backup.zip
+// 现有的配置和数据目录会先改名为 *.bak-<时间> 保留
+func runRestore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	in := fs.String("in", "", "archive written by backup")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" {
+		fs.Usage()
+		return fmt.Errorf("-in is required")
+	}
+	manifest, err := backup.Import(*in, backupSources())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("restored %d files from %s (agent %s, %s)\n", len(manifest.Entries), *in,
+		manifest.AgentVersion, manifest.CreatedAt.Format("2006-01-02 15:04"))
+	return nil
+}
@@ -0,0 +1,208 @@
+package backup
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// manifestName - 归档内的说明文件
+const manifestName = "manifest.json"
+
+// formatVersion - 归档格式版本，恢复时拒绝更新的格式
+const formatVersion = 1
+
+// Source - 一个需要打包的文件或目录，Name 为其在归档中的位置
+type Source struct {
+	Name string
+	Path string
+}
+
+// Manifest - 归档的基本信息
+type Manifest struct {
+	Format       int       `json:"format"`
+	AgentVersion string    `json:"agent_version"`
+	CreatedAt    time.Time `json:"created_at"`
+	Entries      []string  `json:"entries"`
+}
+
+// Export writes every existing source into a zip archive at path; missing sources are skipped
+func Export(path, agentVersion string, sources []Source) (Manifest, error) {
+	manifest := Manifest{Format: formatVersion, AgentVersion: agentVersion, CreatedAt: time.Now()}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return manifest, err
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+
+	for _, src := range sources {
+		info, err := os.Stat(src.Path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return manifest, err
+		}
+		if !info.IsDir() {
+			if err := addFile(zw, src.Path, src.Name); err != nil {
+				return manifest, err
+			}
+			manifest.Entries = append(manifest.Entries, src.Name)
+			continue
+		}
+		err = filepath.WalkDir(src.Path, func(p string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(src.Path, p)
+			if err != nil {
+				return err
+			}
+			name := src.Name + "/" + filepath.ToSlash(rel)
+			manifest.Entries = append(manifest.Entries, name)
+			return addFile(zw, p, name)
+		})
+		if err != nil {
+			return manifest, err
+		}
+	}
+
+	w, err := zw.Create(manifestName)
+	if err != nil {
+		return manifest, err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(manifest); err != nil {
+		return manifest, err
+	}
+	return manifest, zw.Close()
+}
+
+func addFile(zw *zip.Writer, path, name string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, in)
+	return err
+}
+
+// Import restores an archive written by Export. Each source whose Name appears in the archive is
+// first moved aside to <path>.bak-<time>, so a bad archive never destroys the current state.
+func Import(path string, sources []Source) (Manifest, error) {
+	var manifest Manifest
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return manifest, err
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.Name != manifestName {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return manifest, err
+		}
+		err = json.NewDecoder(rc).Decode(&manifest)
+		rc.Close()
+		if err != nil {
+			return manifest, fmt.Errorf("read manifest: %w", err)
+		}
+	}
+	if manifest.Format == 0 {
+		return manifest, fmt.Errorf("%s is not a backup archive", path)
+	}
+	if manifest.Format > formatVersion {
+		return manifest, fmt.Errorf("backup format %d is newer than supported %d", manifest.Format, formatVersion)
+	}
+
+	suffix := ".bak-" + time.Now().Format("20060102-150405")
+	for _, src := range sources {
+		if !contains(zr.File, src.Name) {
+			continue
+		}
+		if _, err := os.Stat(src.Path); err == nil {
+			if err := os.Rename(src.Path, src.Path+suffix); err != nil {
+				return manifest, fmt.Errorf("move aside %s: %w", src.Path, err)
+			}
+		}
+	}
+
+	for _, f := range zr.File {
+		if f.Name == manifestName || strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+		dst, ok := destination(f.Name, sources)
+		if !ok {
+			continue
+		}
+		if err := extract(f, dst); err != nil {
+			return manifest, err
+		}
+	}
+	return manifest, nil
+}
+
+// contains - 归档中是否有属于该来源的条目
+func contains(files []*zip.File, name string) bool {
+	for _, f := range files {
+		if f.Name == name || strings.HasPrefix(f.Name, name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// destination - 把归档条目映射回本地路径，拒绝跳出来源目录的条目
+func destination(entry string, sources []Source) (string, bool) {
+	for _, src := range sources {
+		if entry == src.Name {
+			return src.Path, true
+		}
+		rel, ok := strings.CutPrefix(entry, src.Name+"/")
+		if !ok {
+			continue
+		}
+		dst := filepath.Join(src.Path, filepath.FromSlash(rel))
+		if inside, err := filepath.Rel(src.Path, dst); err != nil || strings.HasPrefix(inside, "..") {
+			return "", false
+		}
+		return dst, true
+	}
+	return "", false
+}
+
+func extract(f *zip.File, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
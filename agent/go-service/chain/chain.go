@@ -0,0 +1,113 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/taskresult"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// ChainRunAction - 按 go-service.json 中 chains 的定义依次执行任务
+// custom_action_param: {"chain": "evening"}
+type ChainRunAction struct{}
+
+func (a *ChainRunAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	var params struct {
+		Chain string `json:"chain"`
+	}
+	if err := json.Unmarshal([]byte(arg.CustomActionParam), &params); err != nil {
+		log.Error().Err(err).Msg("Failed to parse ChainRunAction param")
+		return false
+	}
+
+	steps, ok := agentconfig.Get().Chains[params.Chain]
+	if !ok || len(steps) == 0 {
+		log.Error().Str("chain", params.Chain).Msg("Chain not defined in config")
+		showMessage(ctx, fmt.Sprintf("⚠️ 未找到串联流程「%s」，请在 go-service.json 的 chains 中定义", params.Chain))
+		return false
+	}
+
+	start := time.Now()
+	var summary []string
+	for i, step := range steps {
+		if ctx.GetTasker().Stopping() {
+			return false
+		}
+		if step.If != nil && !satisfied(*step.If, start) {
+			log.Info().Str("chain", params.Chain).Int("step", i+1).Str("run", step.Run).Msg("Chain step condition not met, skipped")
+			summary = append(summary, fmt.Sprintf("⏭️ %s", step.Run))
+			continue
+		}
+
+		log.Info().Str("chain", params.Chain).Int("step", i+1).Str("run", step.Run).Msg("Chain step started")
+		if err := run(ctx, step); err != nil {
+			log.Warn().Err(err).Str("chain", params.Chain).Int("step", i+1).Str("run", step.Run).Msg("Chain step failed")
+			summary = append(summary, fmt.Sprintf("❌ %s", step.Run))
+			if step.StopOnFailure {
+				break
+			}
+			continue
+		}
+		summary = append(summary, fmt.Sprintf("✅ %s", step.Run))
+	}
+
+	showMessage(ctx, fmt.Sprintf("🔗 %s\n%s", params.Chain, strings.Join(summary, " → ")))
+	return true
+}
+
+func run(ctx *maa.Context, step agentconfig.ChainStep) error {
+	var detail *maa.TaskDetail
+	var err error
+	if step.Override != nil {
+		detail, err = ctx.RunTask(step.Run, step.Override)
+	} else {
+		detail, err = ctx.RunTask(step.Run)
+	}
+	if err != nil {
+		return err
+	}
+	if detail == nil || !detail.Status.Success() {
+		return fmt.Errorf("%s did not complete", step.Run)
+	}
+	return nil
+}
+
+// satisfied - 只认本次流程开始之后产生的结果，之前的结果视为不存在
+func satisfied(cond agentconfig.ChainCondition, since time.Time) bool {
+	result, ok := taskresult.Last(cond.Result)
+	met := ok && !result.FinishedAt.Before(since)
+	if met && cond.Status != "" {
+		met = string(result.Status) == cond.Status
+	}
+	if met && cond.Decision != "" {
+		found := false
+		for _, d := range result.Decisions {
+			if d.Action == cond.Decision {
+				found = true
+				break
+			}
+		}
+		met = found
+	}
+	if cond.Not {
+		return !met
+	}
+	return met
+}
+
+func showMessage(ctx *maa.Context, text string) {
+	ctx.RunTask("Chain_TaskShowMessage", map[string]interface{}{
+		"Chain_TaskShowMessage": map[string]interface{}{
+			"recognition": "DirectHit",
+			"action":      "DoNothing",
+			"focus": map[string]interface{}{
+				"Node.Action.Starting": text,
+			},
+		},
+	})
+}
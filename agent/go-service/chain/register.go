@@ -0,0 +1,19 @@
+package chain
+
+import (
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+var (
+	_ maa.CustomActionRunner = &ChainRunAction{}
+)
+
+// Components returns the custom components of chain package
+func Components() []registry.Component {
+	return []registry.Component{
+		registry.Action("ChainRunAction", &ChainRunAction{}, "按 go-service.json 中 chains 的定义依次执行任务",
+			registry.P("chain", "string", "chains 中的任务链名"),
+		),
+	}
+}
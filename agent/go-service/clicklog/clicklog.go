@@ -0,0 +1,170 @@
+package clicklog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// Number of recent clicks kept in the stats file
+	recentLimit = 200
+	// Clicks this close to the screen border (as a share of its size) count as the edge region,
+	// which is where window borders offsetting coordinates show up first
+	edgeRatio = 0.02
+	// Minimum clicks in a region before its failure rate is reported
+	minClicks = 5
+	// Failure rate at which a region is warned about when the task ends
+	warnRate = 0.5
+)
+
+// Click is one issued click and whether the flow moved on afterwards
+type Click struct {
+	Time   time.Time `json:"time"`
+	Node   string    `json:"node"`
+	X      int       `json:"x"`
+	Y      int       `json:"y"`
+	Region string    `json:"region"`
+	// OK is false when the same node ran again (a retry) or the task ended in failure
+	OK bool `json:"ok"`
+}
+
+// RegionStat is the cumulative click and failure count of one screen region
+type RegionStat struct {
+	Region   string `json:"region"`
+	Clicks   int    `json:"clicks"`
+	Failures int    `json:"failures"`
+}
+
+// Rate returns the failure rate of the region
+func (s RegionStat) Rate() float64 {
+	if s.Clicks == 0 {
+		return 0
+	}
+	return float64(s.Failures) / float64(s.Clicks)
+}
+
+type store struct {
+	Regions map[string]*RegionStat `json:"regions"`
+	Recent  []Click                `json:"recent"`
+}
+
+var (
+	mu     sync.Mutex
+	loaded *store
+)
+
+// statsPath holds the per-region totals and the most recent clicks
+func statsPath() string {
+	return datadir.Path("clicklog", "click_stats.json")
+}
+
+// load must be called with mu held
+func load() *store {
+	if loaded != nil {
+		return loaded
+	}
+	loaded = &store{Regions: map[string]*RegionStat{}}
+	data, err := os.ReadFile(statsPath())
+	if err != nil {
+		return loaded
+	}
+	if err := json.Unmarshal(data, loaded); err != nil {
+		log.Warn().Err(err).Msg("Failed to parse click stats, starting over")
+		loaded = &store{Regions: map[string]*RegionStat{}}
+	}
+	if loaded.Regions == nil {
+		loaded.Regions = map[string]*RegionStat{}
+	}
+	return loaded
+}
+
+// Region names the part of a width x height screen that (x, y) falls in
+func Region(x, y, width, height int) string {
+	if width <= 0 || height <= 0 {
+		return "unknown"
+	}
+	ex, ey := int(float64(width)*edgeRatio), int(float64(height)*edgeRatio)
+	if x <= ex || y <= ey || x >= width-ex || y >= height-ey {
+		return "edge"
+	}
+	rows := [3]string{"top", "middle", "bottom"}
+	cols := [3]string{"left", "center", "right"}
+	return rows[min(y*3/height, 2)] + "-" + cols[min(x*3/width, 2)]
+}
+
+// Record adds one click outcome to the statistics
+func Record(c Click) {
+	mu.Lock()
+	defer mu.Unlock()
+	s := load()
+	stat, ok := s.Regions[c.Region]
+	if !ok {
+		stat = &RegionStat{Region: c.Region}
+		s.Regions[c.Region] = stat
+	}
+	stat.Clicks++
+	if !c.OK {
+		stat.Failures++
+	}
+	s.Recent = append(s.Recent, c)
+	if n := len(s.Recent); n > recentLimit {
+		s.Recent = append([]Click(nil), s.Recent[n-recentLimit:]...)
+	}
+}
+
+// Report returns the cumulative statistics of every region, highest failure rate first
+func Report() []RegionStat {
+	mu.Lock()
+	defer mu.Unlock()
+	var report []RegionStat
+	for _, stat := range load().Regions {
+		report = append(report, *stat)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Rate() != report[j].Rate() {
+			return report[i].Rate() > report[j].Rate()
+		}
+		return report[i].Region < report[j].Region
+	})
+	return report
+}
+
+// Save writes the statistics to the data directory
+func Save() {
+	mu.Lock()
+	data, err := json.MarshalIndent(load(), "", "  ")
+	mu.Unlock()
+	if err != nil {
+		return
+	}
+	path := statsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Warn().Err(err).Msg("Failed to create click stats dir")
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Warn().Err(err).Msg("Failed to save click stats")
+	}
+}
+
+// warnDeadZones logs regions whose clicks often have no effect
+func warnDeadZones() {
+	for _, stat := range Report() {
+		if stat.Clicks < minClicks || stat.Rate() < warnRate {
+			continue
+		}
+		log.Warn().
+			Str("region", stat.Region).
+			Int("clicks", stat.Clicks).
+			Int("failures", stat.Failures).
+			Float64("rate", stat.Rate()).
+			Msg("Clicks in this screen region often have no effect, check window borders or emulator scaling")
+	}
+}
@@ -0,0 +1,15 @@
+package clicklog
+
+import "github.com/MaaXYZ/maa-framework-go/v4"
+
+var (
+	_ maa.TaskerEventSink  = &ClickLogger{}
+	_ maa.ContextEventSink = &ClickLogger{}
+)
+
+// Register registers the click logger as tasker and context sink
+func Register() {
+	logger := &ClickLogger{}
+	maa.AgentServerAddTaskerSink(logger)
+	maa.AgentServerAddContextSink(logger)
+}
@@ -0,0 +1,94 @@
+package clicklog
+
+import (
+	"sync"
+	"time"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+// ClickLogger records pipeline Click actions and whether the flow moved on afterwards.
+// Clicks issued directly by Go code through the controller are not seen here.
+type ClickLogger struct {
+	mu      sync.Mutex
+	pending *Click
+}
+
+// OnTaskerTask resolves the last click when the task ends and saves the statistics
+func (l *ClickLogger) OnTaskerTask(tasker *maa.Tasker, event maa.EventStatus, detail maa.TaskerTaskDetail) {
+	if event == maa.EventStatusStarting {
+		l.mu.Lock()
+		l.pending = nil
+		l.mu.Unlock()
+		return
+	}
+	l.resolve(event == maa.EventStatusSucceeded)
+	Save()
+	warnDeadZones()
+}
+
+// OnNodeAction remembers Click actions until the next node shows whether they worked
+func (l *ClickLogger) OnNodeAction(ctx *maa.Context, event maa.EventStatus, detail maa.NodeActionDetail) {
+	if event == maa.EventStatusStarting {
+		return
+	}
+	tasker := ctx.GetTasker()
+	node, err := tasker.GetLatestNode(detail.Name)
+	if err != nil || node == nil || node.Action == nil || node.Action.Action != "Click" {
+		return
+	}
+	box := node.Action.Box
+	x, y := box.X()+box.Width()/2, box.Y()+box.Height()/2
+	width, height := 0, 0
+	if img, err := tasker.GetController().CacheImage(); err == nil && img != nil {
+		width, height = img.Bounds().Dx(), img.Bounds().Dy()
+	}
+
+	click := Click{Time: time.Now(), Node: detail.Name, X: x, Y: y, Region: Region(x, y, width, height)}
+	if event == maa.EventStatusFailed || !node.Action.Success {
+		Record(click)
+		return
+	}
+	l.resolve(true)
+	l.mu.Lock()
+	l.pending = &click
+	l.mu.Unlock()
+}
+
+// OnNodePipelineNode resolves the pending click: running the same node again means it is retrying
+func (l *ClickLogger) OnNodePipelineNode(ctx *maa.Context, event maa.EventStatus, detail maa.NodePipelineNodeDetail) {
+	if event != maa.EventStatusStarting {
+		return
+	}
+	l.mu.Lock()
+	p := l.pending
+	l.mu.Unlock()
+	if p == nil {
+		return
+	}
+	l.resolve(detail.Name != p.Node)
+}
+
+func (l *ClickLogger) resolve(ok bool) {
+	l.mu.Lock()
+	p := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+	if p == nil {
+		return
+	}
+	p.OK = ok
+	Record(*p)
+}
+
+func (l *ClickLogger) OnNodeRecognitionNode(ctx *maa.Context, event maa.EventStatus, detail maa.NodeRecognitionNodeDetail) {
+}
+
+func (l *ClickLogger) OnNodeActionNode(ctx *maa.Context, event maa.EventStatus, detail maa.NodeActionNodeDetail) {
+}
+
+func (l *ClickLogger) OnNodeNextList(ctx *maa.Context, event maa.EventStatus, detail maa.NodeNextListDetail) {
+}
+
+func (l *ClickLogger) OnNodeRecognition(ctx *maa.Context, event maa.EventStatus, detail maa.NodeRecognitionDetail) {
+}
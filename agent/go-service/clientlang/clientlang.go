@@ -0,0 +1,152 @@
+package clientlang
+
+import (
+	"encoding/json"
+	"image"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/overridesnap"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// profilesNode - 在该节点的 attach 中定义取样 OCR 节点与各语言的关键词和覆盖
+const profilesNode = "ClientLanguageProfiles"
+
+// Language - 一种客户端语言的识别配置
+type Language struct {
+	// Name - 语言代码，与 locales 文件名一致，如 zh_cn、en_us
+	Name string `json:"name"`
+	// Keywords - 只会出现在该语言界面上的文字，取样文本包含任一关键词即命中
+	Keywords []string `json:"keywords"`
+	// Override - 命中该语言时应用的 pipeline 覆盖，例如替换 OCR 的 expected 关键词表
+	Override map[string]any `json:"override"`
+}
+
+type profiles struct {
+	// Probes - 依次尝试的 OCR 节点，roi 指向各界面上位置固定的文字
+	Probes    []string   `json:"probes"`
+	Languages []Language `json:"languages"`
+}
+
+var (
+	mu      sync.Mutex
+	current string // 最近一次识别到的语言，空表示尚未识别
+)
+
+// Current returns the client language detected so far, empty when it is not known yet
+func Current() string {
+	mu.Lock()
+	defer mu.Unlock()
+	return current
+}
+
+// Apply OCRs the probe labels on the current screen, detects the client language and applies its
+// override. A screen without any probe text keeps the language detected earlier, since the client
+// language does not change within a session. Call it at the start of a module's Init action.
+func Apply(ctx *maa.Context) string {
+	p := loadProfiles(ctx)
+	if len(p.Languages) == 0 || len(p.Probes) == 0 {
+		return Current()
+	}
+
+	var nodes []string
+	seen := map[string]bool{}
+	for _, lang := range p.Languages {
+		for node := range lang.Override {
+			if !seen[node] {
+				seen[node] = true
+				nodes = append(nodes, node)
+			}
+		}
+	}
+	sort.Strings(nodes)
+	overridesnap.Reset(ctx, "ClientLanguage", nodes...)
+
+	name := detect(ctx, p)
+	if name == "" {
+		name = Current()
+		if name == "" {
+			log.Info().Msg("Client language not detected, using default keywords")
+			return ""
+		}
+		log.Debug().Str("language", name).Msg("No probe text on screen, keeping detected client language")
+	}
+
+	for _, lang := range p.Languages {
+		if lang.Name == name && len(lang.Override) > 0 {
+			if err := overridesnap.Apply(ctx, "ClientLanguage", lang.Override); err != nil {
+				log.Error().Err(err).Str("language", name).Msg("Failed to apply client language override")
+			}
+			break
+		}
+	}
+
+	mu.Lock()
+	changed := current != name
+	current = name
+	mu.Unlock()
+	if changed {
+		log.Info().Str("language", name).Msg("Client language detected")
+	}
+	return name
+}
+
+// detect - 依次 OCR 取样节点，第一个命中关键词的语言即为结果
+func detect(ctx *maa.Context, p profiles) string {
+	controller := ctx.GetTasker().GetController()
+	controller.PostScreencap().Wait()
+	img, err := controller.CacheImage()
+	if err != nil || img == nil {
+		log.Warn().Err(err).Msg("Failed to capture screen for client language detection")
+		return ""
+	}
+
+	for _, probe := range p.Probes {
+		text := probeText(ctx, img, probe)
+		if text == "" {
+			continue
+		}
+		for _, lang := range p.Languages {
+			for _, keyword := range lang.Keywords {
+				if keyword != "" && strings.Contains(text, keyword) {
+					log.Debug().Str("probe", probe).Str("keyword", keyword).Str("language", lang.Name).Msg("Client language keyword found")
+					return lang.Name
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// probeText - 取样节点识别到的全部文字，按识别顺序拼接
+func probeText(ctx *maa.Context, img image.Image, node string) string {
+	detail, err := ctx.RunRecognition(node, img)
+	if err != nil || detail == nil || detail.Results == nil {
+		return ""
+	}
+	var texts []string
+	for _, c := range detail.Results.All {
+		if ocr, ok := c.AsOCR(); ok && ocr.Text != "" {
+			texts = append(texts, ocr.Text)
+		}
+	}
+	return strings.Join(texts, " ")
+}
+
+func loadProfiles(ctx *maa.Context) profiles {
+	raw, err := ctx.GetNodeJSON(profilesNode)
+	if err != nil || raw == "" {
+		return profiles{}
+	}
+	var node struct {
+		Attach profiles `json:"attach"`
+	}
+	if err := json.Unmarshal([]byte(raw), &node); err != nil {
+		log.Error().Err(err).Str("node", profilesNode).Msg("Failed to parse client language profiles")
+		return profiles{}
+	}
+	return node.Attach
+}
@@ -0,0 +1,404 @@
+// Package cloudsync keeps part of the data directory in sync with WebDAV or S3-compatible
+// storage, so several machines running the agent share histories, macros and asset overrides.
+//
+// Each file is compared with the version seen at the last sync: a side that did not change takes
+// the other side's version, and when both changed, .jsonl histories are merged line by line while
+// other files keep the newer one. Deletions are not synced; a file missing on one side is copied.
+package cloudsync
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultInterval = 30 * time.Minute
+	defaultPrefix   = "maaend"
+	// manifestName - 远端记录每个文件哈希与修改时间的清单
+	manifestName = "manifest.json"
+)
+
+// defaultInclude - 未配置 include 时同步的数据目录子路径
+var defaultInclude = []string{"resell", "creditshopping", "purchase", "essencefilter", "pricewatch", "roistats", "macros", "assets"}
+
+// errNotFound - 远端没有该文件
+var errNotFound = errors.New("not found")
+
+// store - 远端存储，name 为相对 prefix 的 / 分隔路径
+type store interface {
+	get(name string) ([]byte, error)
+	put(name string, data []byte) error
+}
+
+// remoteFile - 清单中的一个文件
+type remoteFile struct {
+	Hash    string    `json:"hash"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// manifest - 远端清单，Files 的键为相对数据目录的 / 分隔路径
+type manifest struct {
+	Files map[string]remoteFile `json:"files"`
+}
+
+// Result - 一次同步的结果
+type Result struct {
+	Uploaded   []string
+	Downloaded []string
+	Merged     []string
+}
+
+// mu - 同一时间只进行一次同步
+var mu sync.Mutex
+
+// statePath - 上次同步时各文件的哈希，用来判断哪一边修改过
+func statePath() string {
+	return datadir.Path("cloudsync", "state.json")
+}
+
+func loadState() map[string]string {
+	state := map[string]string{}
+	data, err := os.ReadFile(statePath())
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		log.Warn().Err(err).Msg("Failed to parse sync state, treating every file as changed")
+		return map[string]string{}
+	}
+	return state
+}
+
+func saveState(state map[string]string) {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return
+	}
+	path := statePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Warn().Err(err).Msg("Failed to create sync data dir")
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Warn().Err(err).Msg("Failed to save sync state")
+	}
+}
+
+func hashOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// localFile - 数据目录中的一个文件
+type localFile struct {
+	data    []byte
+	modTime time.Time
+}
+
+// listLocal reads every file under the included subpaths of the data directory
+func listLocal(include []string) (map[string]localFile, error) {
+	files := map[string]localFile{}
+	root := datadir.Root()
+	for _, sub := range include {
+		base := filepath.Join(root, filepath.FromSlash(sub))
+		err := filepath.WalkDir(base, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			files[filepath.ToSlash(rel)] = localFile{data: data, modTime: info.ModTime()}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// included reports whether the remote file name falls under one of the included subpaths
+func included(name string, include []string) bool {
+	for _, sub := range include {
+		sub = strings.Trim(filepath.ToSlash(sub), "/")
+		if name == sub || strings.HasPrefix(name, sub+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// safeName reports whether a remote file name stays inside the data directory:
+// relative, clean, / separated, without .. and without drive letters
+func safeName(name string) bool {
+	if name == "" || path.IsAbs(name) || strings.ContainsAny(name, `\:`) || filepath.IsAbs(filepath.FromSlash(name)) {
+		return false
+	}
+	if path.Clean(name) != name {
+		return false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// localPath resolves a remote file name under the data directory and refuses anything outside it
+func localPath(name string) (string, error) {
+	if !safeName(name) {
+		return "", fmt.Errorf("unsafe file name %q", name)
+	}
+	root, err := filepath.Abs(datadir.Root())
+	if err != nil {
+		return "", err
+	}
+	p := filepath.Join(root, filepath.FromSlash(name))
+	rel, err := filepath.Rel(root, p)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("file name %q escapes the data directory", name)
+	}
+	return p, nil
+}
+
+// mergeLines - 两边都修改过的 .jsonl：保留本地的行，再按远端的顺序追加本地没有的行
+func mergeLines(local, remote []byte) []byte {
+	seen := map[string]bool{}
+	var out bytes.Buffer
+	for _, data := range [][]byte{local, remote} {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "" || seen[line] {
+				continue
+			}
+			seen[line] = true
+			out.WriteString(line)
+			out.WriteByte('\n')
+		}
+	}
+	return out.Bytes()
+}
+
+func writeLocal(name string, data []byte, modTime time.Time) error {
+	p, err := localPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(p, data, 0644); err != nil {
+		return err
+	}
+	if !modTime.IsZero() {
+		os.Chtimes(p, modTime, modTime)
+	}
+	return nil
+}
+
+// Run syncs the included part of the data directory with the configured storage once
+func Run(cfg agentconfig.SyncConfig) (Result, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var result Result
+	st, err := newStore(cfg)
+	if err != nil {
+		return result, err
+	}
+	include := cfg.Include
+	if len(include) == 0 {
+		include = defaultInclude
+	}
+
+	remote := manifest{Files: map[string]remoteFile{}}
+	switch data, err := st.get(manifestName); {
+	case errors.Is(err, errNotFound):
+	case err != nil:
+		return result, fmt.Errorf("read manifest: %w", err)
+	default:
+		if err := json.Unmarshal(data, &remote); err != nil {
+			return result, fmt.Errorf("parse manifest: %w", err)
+		}
+		if remote.Files == nil {
+			remote.Files = map[string]remoteFile{}
+		}
+	}
+
+	local, err := listLocal(include)
+	if err != nil {
+		return result, err
+	}
+	state := loadState()
+
+	names := map[string]bool{}
+	for name := range local {
+		names[name] = true
+	}
+	for name := range remote.Files {
+		if !safeName(name) {
+			log.Warn().Str("name", name).Msg("Unsafe file name in remote manifest, skipped")
+			continue
+		}
+		if included(name, include) {
+			names[name] = true
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	upload := func(name string, data []byte, modTime time.Time) error {
+		if err := st.put(path.Join("files", name), data); err != nil {
+			return fmt.Errorf("upload %s: %w", name, err)
+		}
+		remote.Files[name] = remoteFile{Hash: hashOf(data), ModTime: modTime}
+		return nil
+	}
+	download := func(name string) ([]byte, error) {
+		data, err := st.get(path.Join("files", name))
+		if err != nil {
+			return nil, fmt.Errorf("download %s: %w", name, err)
+		}
+		return data, nil
+	}
+
+	var syncErr error
+	for _, name := range sorted {
+		l, hasLocal := local[name]
+		r, hasRemote := remote.Files[name]
+		localHash := ""
+		if hasLocal {
+			localHash = hashOf(l.data)
+		}
+		base := state[name]
+
+		var err error
+		switch {
+		case hasLocal && hasRemote && localHash == r.Hash:
+			state[name] = localHash
+		case hasLocal && (!hasRemote || r.Hash == base):
+			// 只有本地修改过，或远端还没有
+			if err = upload(name, l.data, l.modTime); err == nil {
+				state[name] = localHash
+				result.Uploaded = append(result.Uploaded, name)
+			}
+		case hasRemote && (!hasLocal || localHash == base):
+			// 只有远端修改过，或本地还没有
+			var data []byte
+			if data, err = download(name); err == nil {
+				if err = writeLocal(name, data, r.ModTime); err == nil {
+					state[name] = hashOf(data)
+					result.Downloaded = append(result.Downloaded, name)
+				}
+			}
+		case strings.HasSuffix(name, ".jsonl"):
+			var data []byte
+			if data, err = download(name); err == nil {
+				merged := mergeLines(l.data, data)
+				now := time.Now()
+				if err = writeLocal(name, merged, now); err == nil {
+					if err = upload(name, merged, now); err == nil {
+						state[name] = hashOf(merged)
+						result.Merged = append(result.Merged, name)
+					}
+				}
+			}
+		case l.modTime.After(r.ModTime):
+			log.Warn().Str("file", name).Msg("File changed on both sides, keeping the local copy")
+			if err = upload(name, l.data, l.modTime); err == nil {
+				state[name] = localHash
+				result.Uploaded = append(result.Uploaded, name)
+			}
+		default:
+			log.Warn().Str("file", name).Msg("File changed on both sides, keeping the remote copy")
+			var data []byte
+			if data, err = download(name); err == nil {
+				if err = writeLocal(name, data, r.ModTime); err == nil {
+					state[name] = hashOf(data)
+					result.Downloaded = append(result.Downloaded, name)
+				}
+			}
+		}
+		if err != nil {
+			// 单个文件失败不影响其他文件，下次同步再试
+			log.Warn().Err(err).Str("file", name).Msg("Failed to sync file")
+			syncErr = errors.Join(syncErr, err)
+		}
+	}
+
+	data, err := json.MarshalIndent(remote, "", "  ")
+	if err != nil {
+		return result, err
+	}
+	if err := st.put(manifestName, data); err != nil {
+		return result, fmt.Errorf("write manifest: %w", err)
+	}
+	saveState(state)
+	return result, syncErr
+}
+
+// Start syncs once and then on the configured interval in the background; does nothing when sync is off
+func Start(cfg agentconfig.SyncConfig) {
+	if cfg.Kind == "" {
+		return
+	}
+	interval := defaultInterval
+	if cfg.IntervalMinutes > 0 {
+		interval = time.Duration(cfg.IntervalMinutes) * time.Minute
+	}
+	log.Info().Str("kind", cfg.Kind).Dur("interval", interval).Msg("Data sync enabled")
+	go func() {
+		for {
+			result, err := Run(cfg)
+			if err != nil {
+				log.Warn().Err(err).Msg("Data sync failed")
+			} else {
+				log.Info().
+					Strs("uploaded", result.Uploaded).
+					Strs("downloaded", result.Downloaded).
+					Strs("merged", result.Merged).
+					Msg("Data sync finished")
+			}
+			time.Sleep(interval)
+		}
+	}()
+}
@@ -0,0 +1,36 @@
+package cloudsync
+
+import "testing"
+
+func TestSafeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"history/resell.jsonl", true},
+		{"macros/a.json", true},
+		{"history/../../../x", false},
+		{"../x", false},
+		{"history/..", false},
+		{"/etc/passwd", false},
+		{"history/./x", false},
+		{"history//x", false},
+		{`history\..\x`, false},
+		{"C:/x", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := safeName(tt.name); got != tt.want {
+			t.Errorf("safeName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLocalPath(t *testing.T) {
+	if _, err := localPath("resell/history.jsonl"); err != nil {
+		t.Errorf("localPath(resell/history.jsonl) = %v, want nil", err)
+	}
+	if _, err := localPath("resell/../../x"); err == nil {
+		t.Error("localPath accepted a name outside the data directory")
+	}
+}
@@ -0,0 +1,65 @@
+package cloudsync
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/secret"
+)
+
+const requestTimeout = 60 * time.Second
+
+var client = &http.Client{Timeout: requestTimeout}
+
+// newStore creates the storage named by cfg.Kind, decrypting encrypted settings
+func newStore(cfg agentconfig.SyncConfig) (store, error) {
+	endpoint, err := secret.Decrypt(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("sync.url is empty")
+	}
+	prefix := strings.Trim(cfg.Prefix, "/")
+	if prefix == "" {
+		prefix = defaultPrefix
+	}
+
+	switch cfg.Kind {
+	case "webdav":
+		password, err := secret.Decrypt(cfg.Password)
+		if err != nil {
+			return nil, err
+		}
+		return &webdavStore{
+			base:     strings.TrimRight(endpoint, "/") + "/" + prefix,
+			username: cfg.Username,
+			password: password,
+		}, nil
+	case "s3":
+		secretKey, err := secret.Decrypt(cfg.SecretKey)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Bucket == "" {
+			return nil, fmt.Errorf("sync.bucket is empty")
+		}
+		region := cfg.Region
+		if region == "" {
+			region = "us-east-1"
+		}
+		return &s3Store{
+			endpoint:  strings.TrimRight(endpoint, "/"),
+			bucket:    cfg.Bucket,
+			prefix:    prefix,
+			region:    region,
+			accessKey: cfg.AccessKey,
+			secretKey: secretKey,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown sync.kind %q, expected webdav or s3", cfg.Kind)
+	}
+}
@@ -0,0 +1,93 @@
+package cloudsync
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// webdavStore - 文件存放在 base 目录下，上传时按需创建父目录
+type webdavStore struct {
+	base     string
+	username string
+	password string
+}
+
+func (s *webdavStore) do(method, name string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, s.base+"/"+strings.TrimLeft(name, "/"), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if s.username != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+	return client.Do(req)
+}
+
+func (s *webdavStore) get(name string) ([]byte, error) {
+	resp, err := s.do(http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("webdav GET %s: %s", name, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (s *webdavStore) put(name string, data []byte) error {
+	status, err := s.putOnce(name, data)
+	if err != nil {
+		return err
+	}
+	// 父目录不存在时服务器返回 409，逐级创建后重试
+	if status == http.StatusConflict || status == http.StatusNotFound {
+		if err := s.mkdirs(path.Dir(name)); err != nil {
+			return err
+		}
+		if status, err = s.putOnce(name, data); err != nil {
+			return err
+		}
+	}
+	if status/100 != 2 {
+		return fmt.Errorf("webdav PUT %s: %d", name, status)
+	}
+	return nil
+}
+
+func (s *webdavStore) putOnce(name string, data []byte) (int, error) {
+	resp, err := s.do(http.MethodPut, name, data)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// mkdirs creates the base directory and every parent of dir; existing directories answer 405
+func (s *webdavStore) mkdirs(dir string) error {
+	parts := []string{""}
+	if dir != "." && dir != "" {
+		parts = append(parts, strings.Split(dir, "/")...)
+	}
+	current := ""
+	for _, part := range parts {
+		current = path.Join(current, part)
+		resp, err := s.do("MKCOL", current+"/", nil)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusMethodNotAllowed {
+			return fmt.Errorf("webdav MKCOL %s: %s", current, resp.Status)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package configexplain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+// Explanation - 对当前配置的白话说明
+type Explanation struct {
+	// Will - 任务会做的事
+	Will []string
+	// Wont - 任务不会做的事
+	Wont []string
+	// Warnings - 可能是配置错误的组合
+	Warnings []string
+}
+
+// String renders the explanation as a focus message
+func (e Explanation) String(title string) string {
+	var builder strings.Builder
+	builder.WriteString(title)
+	for _, line := range e.Will {
+		builder.WriteString("\n✅ " + line)
+	}
+	for _, line := range e.Wont {
+		builder.WriteString("\n⛔ " + line)
+	}
+	for _, line := range e.Warnings {
+		builder.WriteString("\n⚠️ " + line)
+	}
+	return builder.String()
+}
+
+// Show displays the explanation with a focus message
+func Show(ctx *maa.Context, title string, e Explanation) {
+	ctx.RunTask("ConfigExplain_TaskShowMessage", map[string]interface{}{
+		"ConfigExplain_TaskShowMessage": map[string]interface{}{
+			"recognition": "DirectHit",
+			"action":      "DoNothing",
+			"focus": map[string]interface{}{
+				"Node.Action.Starting": e.String(title),
+			},
+		},
+	})
+}
+
+func node(ctx *maa.Context, name string) (map[string]any, error) {
+	raw, err := ctx.GetNodeJSON(name)
+	if err != nil {
+		return nil, err
+	}
+	if raw == "" {
+		return nil, fmt.Errorf("node %s not found", name)
+	}
+	var data map[string]any
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// ActionParam returns the custom_action_param of a node, with task option overrides applied
+func ActionParam(ctx *maa.Context, name string) (string, error) {
+	data, err := node(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	action, _ := data["action"].(map[string]any)
+	param, _ := action["param"].(map[string]any)
+	switch v := param["custom_action_param"].(type) {
+	case nil:
+		return "{}", nil
+	case string:
+		return v, nil
+	default:
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+}
+
+// Enabled reports whether a node is enabled; missing nodes count as disabled
+func Enabled(ctx *maa.Context, name string) bool {
+	data, err := node(ctx, name)
+	if err != nil {
+		return false
+	}
+	enabled, ok := data["enabled"].(bool)
+	return !ok || enabled
+}
+
+// Attach returns the attach field of a node
+func Attach(ctx *maa.Context, name string) map[string]any {
+	data, err := node(ctx, name)
+	if err != nil {
+		return nil
+	}
+	attach, _ := data["attach"].(map[string]any)
+	return attach
+}
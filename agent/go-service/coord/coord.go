@@ -0,0 +1,58 @@
+package coord
+
+import (
+	"image"
+	"math"
+)
+
+// Baseline - pipeline 与代码中的坐标都以 1280x720 截图为基准
+var Baseline = image.Pt(1280, 720)
+
+// Scale - 实际截图相对基准的缩放比例
+type Scale struct {
+	X, Y float64
+}
+
+// Identity - 截图就是基准分辨率
+var Identity = Scale{X: 1, Y: 1}
+
+// FromSize returns the scale of a screenshot of the given size
+func FromSize(size image.Point) Scale {
+	if size.X <= 0 || size.Y <= 0 {
+		return Identity
+	}
+	return Scale{X: float64(size.X) / float64(Baseline.X), Y: float64(size.Y) / float64(Baseline.Y)}
+}
+
+// FromImage returns the scale of img; a nil image is treated as baseline
+func FromImage(img image.Image) Scale {
+	if img == nil {
+		return Identity
+	}
+	return FromSize(img.Bounds().Size())
+}
+
+// IsIdentity reports whether coordinates need no scaling (within half a pixel at 720p)
+func (s Scale) IsIdentity() bool {
+	return math.Abs(s.X-1) < 0.5/float64(Baseline.X) && math.Abs(s.Y-1) < 0.5/float64(Baseline.Y)
+}
+
+// Xi scales a horizontal baseline coordinate or length
+func (s Scale) Xi(v int) int {
+	return int(math.Round(float64(v) * s.X))
+}
+
+// Yi scales a vertical baseline coordinate or length
+func (s Scale) Yi(v int) int {
+	return int(math.Round(float64(v) * s.Y))
+}
+
+// Point scales a baseline point
+func (s Scale) Point(x, y int) (int, int) {
+	return s.Xi(x), s.Yi(y)
+}
+
+// Rect scales a baseline [x, y, w, h] rectangle
+func (s Scale) Rect(r [4]int) [4]int {
+	return [4]int{s.Xi(r[0]), s.Yi(r[1]), s.Xi(r[2]), s.Yi(r[3])}
+}
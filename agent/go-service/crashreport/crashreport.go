@@ -0,0 +1,171 @@
+package crashreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/envsnap"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/seed"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// pendingFile - 运行时的崩溃输出，正常退出时为空
+	pendingFile = "pending.txt"
+	// traceFile - 最近的节点事件和版本信息，崩溃后用于生成报告
+	traceFile = "trace.json"
+)
+
+// Versions - 写入报告的版本信息
+type Versions struct {
+	Agent     string `json:"agent"`
+	Framework string `json:"framework"`
+	Go        string `json:"go"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+}
+
+// Report - 一次崩溃的报告
+type Report struct {
+	Time     time.Time `json:"time"`
+	Versions Versions  `json:"versions"`
+	// Seed - 崩溃时所在运行的随机种子
+	Seed int64 `json:"seed"`
+	// Environment - 崩溃时所在运行开始时的环境，Agent 还未运行任务时为空
+	Environment *envsnap.Snapshot `json:"environment,omitempty"`
+	// Stack - panic 信息与所有协程的调用栈
+	Stack string  `json:"stack"`
+	Trace []Event `json:"trace"`
+}
+
+var (
+	mu       sync.Mutex
+	dir      string
+	versions Versions
+	pending  *os.File
+)
+
+// Start collects the report of a crash from the previous run, if any, and arranges for the
+// runtime to write fatal panics of this run to the crash directory. Reports are uploaded
+// only when crash_report.upload is enabled.
+func Start(crashDir string, v Versions) {
+	v.Go = runtime.Version()
+	v.OS = runtime.GOOS
+	v.Arch = runtime.GOARCH
+
+	mu.Lock()
+	dir = crashDir
+	versions = v
+	mu.Unlock()
+
+	if err := os.MkdirAll(crashDir, 0755); err != nil {
+		log.Warn().Err(err).Msg("Failed to create crash report dir")
+		return
+	}
+
+	if path, err := collectPending(); err != nil {
+		log.Warn().Err(err).Msg("Failed to collect crash from previous run")
+	} else if path != "" {
+		log.Warn().Str("report", path).Msg("The previous run crashed, report written")
+		if cfg := agentconfig.Get().CrashReport; cfg.Upload {
+			go upload(cfg, path)
+		}
+	}
+
+	f, err := os.Create(filepath.Join(crashDir, pendingFile))
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to open crash output")
+		return
+	}
+	if err := debug.SetCrashOutput(f, debug.CrashOptions{}); err != nil {
+		log.Warn().Err(err).Msg("Failed to set crash output")
+		f.Close()
+		return
+	}
+	mu.Lock()
+	pending = f
+	mu.Unlock()
+	saveTrace()
+}
+
+// Recover writes a report for a panic on the calling goroutine and re-panics; use it as
+// `defer crashreport.Recover()` at the top of main
+func Recover() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	path, err := write(Report{
+		Time:        time.Now(),
+		Versions:    currentVersions(),
+		Seed:        seed.Current(),
+		Environment: environment(),
+		Stack:       fmt.Sprintf("panic: %v\n\n%s", r, debug.Stack()),
+		Trace:       Recent(),
+	})
+	if err == nil {
+		log.Error().Str("report", path).Interface("panic", r).Msg("Agent panicked, crash report written")
+	}
+	panic(r)
+}
+
+// collectPending - 上次运行的崩溃输出非空时生成报告并清空
+func collectPending() (string, error) {
+	stack, err := os.ReadFile(filepath.Join(dir, pendingFile))
+	if err != nil || len(stack) == 0 {
+		return "", nil
+	}
+
+	report := Report{Stack: string(stack)}
+	if info, err := os.Stat(filepath.Join(dir, pendingFile)); err == nil {
+		report.Time = info.ModTime()
+	}
+	// 版本与节点事件来自崩溃的那次运行
+	if data, err := os.ReadFile(filepath.Join(dir, traceFile)); err == nil {
+		var trace struct {
+			Versions    Versions          `json:"versions"`
+			Seed        int64             `json:"seed"`
+			Environment *envsnap.Snapshot `json:"environment"`
+			Events      []Event           `json:"events"`
+		}
+		if json.Unmarshal(data, &trace) == nil {
+			report.Versions = trace.Versions
+			report.Seed = trace.Seed
+			report.Environment = trace.Environment
+			report.Trace = trace.Events
+		}
+	}
+	return write(report)
+}
+
+func write(report Report) (string, error) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "crash-"+report.Time.Format("20060102-150405")+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func environment() *envsnap.Snapshot {
+	if s, ok := envsnap.Current(); ok {
+		return &s
+	}
+	return nil
+}
+
+func currentVersions() Versions {
+	mu.Lock()
+	defer mu.Unlock()
+	return versions
+}
@@ -0,0 +1,15 @@
+package crashreport
+
+import "github.com/MaaXYZ/maa-framework-go/v4"
+
+var (
+	_ maa.TaskerEventSink  = &tracer{}
+	_ maa.ContextEventSink = &tracer{}
+)
+
+// Register registers the tracer that keeps recent task and node events for crash reports
+func Register() {
+	t := &tracer{}
+	maa.AgentServerAddTaskerSink(t)
+	maa.AgentServerAddContextSink(t)
+}
@@ -0,0 +1,111 @@
+package crashreport
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/seed"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+const (
+	// 保留的最近节点事件数
+	traceLimit = 50
+	// 节点事件落盘的最小间隔，崩溃时内存中的记录会丢失
+	traceSaveInterval = time.Second
+)
+
+// Event - 一个节点事件
+type Event struct {
+	Time  time.Time `json:"time"`
+	Kind  string    `json:"kind"`
+	Name  string    `json:"name"`
+	Event string    `json:"event"`
+}
+
+var (
+	events    []Event // 由 mu 保护
+	lastSaved time.Time
+)
+
+// Recent returns the recorded node events, oldest first
+func Recent() []Event {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Event(nil), events...)
+}
+
+func record(kind, name string, status maa.EventStatus) {
+	mu.Lock()
+	events = append(events, Event{Time: time.Now(), Kind: kind, Name: name, Event: statusName(status)})
+	if n := len(events); n > traceLimit {
+		events = append([]Event(nil), events[n-traceLimit:]...)
+	}
+	due := time.Since(lastSaved) >= traceSaveInterval
+	mu.Unlock()
+
+	// 失败事件立即落盘，其余按间隔节流
+	if due || status == maa.EventStatusFailed {
+		saveTrace()
+	}
+}
+
+func statusName(status maa.EventStatus) string {
+	switch status {
+	case maa.EventStatusStarting:
+		return "starting"
+	case maa.EventStatusSucceeded:
+		return "succeeded"
+	case maa.EventStatusFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+func saveTrace() {
+	mu.Lock()
+	if dir == "" {
+		mu.Unlock()
+		return
+	}
+	lastSaved = time.Now()
+	data, err := json.Marshal(map[string]any{
+		"versions":    versions,
+		"seed":        seed.Current(),
+		"environment": environment(),
+		"events":      events,
+	})
+	path := filepath.Join(dir, traceFile)
+	mu.Unlock()
+	if err == nil {
+		os.WriteFile(path, data, 0644)
+	}
+}
+
+// tracer records task and node events for crash reports
+type tracer struct{}
+
+func (t *tracer) OnTaskerTask(tasker *maa.Tasker, event maa.EventStatus, detail maa.TaskerTaskDetail) {
+	record("task", detail.Entry, event)
+}
+
+func (t *tracer) OnNodePipelineNode(ctx *maa.Context, event maa.EventStatus, detail maa.NodePipelineNodeDetail) {
+	record("node", detail.Name, event)
+}
+
+func (t *tracer) OnNodeRecognitionNode(ctx *maa.Context, event maa.EventStatus, detail maa.NodeRecognitionNodeDetail) {
+}
+
+func (t *tracer) OnNodeActionNode(ctx *maa.Context, event maa.EventStatus, detail maa.NodeActionNodeDetail) {
+}
+
+func (t *tracer) OnNodeNextList(ctx *maa.Context, event maa.EventStatus, detail maa.NodeNextListDetail) {
+}
+
+func (t *tracer) OnNodeRecognition(ctx *maa.Context, event maa.EventStatus, detail maa.NodeRecognitionDetail) {
+}
+
+func (t *tracer) OnNodeAction(ctx *maa.Context, event maa.EventStatus, detail maa.NodeActionDetail) {
+}
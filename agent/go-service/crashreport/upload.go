@@ -0,0 +1,50 @@
+package crashreport
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/secret"
+	"github.com/rs/zerolog/log"
+)
+
+const uploadTimeout = 15 * time.Second
+
+// upload - 把报告 POST 到配置的地址，成功后在文件名后追加 .uploaded
+func upload(cfg agentconfig.CrashReportConfig, path string) {
+	if err := post(cfg, path); err != nil {
+		log.Warn().Err(err).Str("report", path).Msg("Failed to upload crash report")
+		return
+	}
+	os.Rename(path, path+".uploaded")
+	log.Info().Str("report", path).Msg("Crash report uploaded")
+}
+
+func post(cfg agentconfig.CrashReportConfig, path string) error {
+	endpoint, err := secret.Decrypt(cfg.Endpoint)
+	if err != nil {
+		return err
+	}
+	if endpoint == "" {
+		return fmt.Errorf("crash_report.endpoint is empty")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: uploadTimeout}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	return nil
+}
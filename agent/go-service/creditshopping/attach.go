@@ -0,0 +1,43 @@
+package creditshopping
+
+// nameOCRSubrec - attach.all_of 缺少商品名 OCR 子识别时补上的默认值，与 Shopping.json 中的写法一致
+func nameOCRSubrec(subName, orderBy string) map[string]interface{} {
+	return map[string]interface{}{
+		"sub_name":    subName,
+		"recognition": "OCR",
+		"roi":         "NotSoldOut",
+		"roi_offset":  []interface{}{0, 170, 0, -160},
+		"expected":    "",
+		"order_by":    orderBy,
+	}
+}
+
+// ensureSubrec - all_of 中没有同名子识别时插入 subrec，返回新的 all_of 与是否插入
+// 有 NotSoldOut 时插在它之后；没有时追加到末尾，并去掉对 NotSoldOut 的 roi 引用，改为全屏识别
+func ensureSubrec(allOf []interface{}, subrec map[string]interface{}) ([]interface{}, bool) {
+	name, _ := subrec["sub_name"].(string)
+	insertIdx := -1
+	for idx, item := range allOf {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch itemMap["sub_name"] {
+		case name:
+			return allOf, false
+		case "NotSoldOut":
+			insertIdx = idx + 1
+		}
+	}
+
+	if insertIdx < 0 {
+		delete(subrec, "roi")
+		delete(subrec, "roi_offset")
+		return append(allOf, subrec), true
+	}
+	newAllOf := make([]interface{}, 0, len(allOf)+1)
+	newAllOf = append(newAllOf, allOf[:insertIdx]...)
+	newAllOf = append(newAllOf, subrec)
+	newAllOf = append(newAllOf, allOf[insertIdx:]...)
+	return newAllOf, true
+}
@@ -0,0 +1,161 @@
+package creditshopping
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/assets"
+	"github.com/rs/zerolog/log"
+)
+
+// catalogAsset - 信用商店物品目录，可在数据目录的 assets 下放同名文件替换
+const catalogAsset = "creditshopping/items.json"
+
+// catalogItem - 目录中的一个商品
+type catalogItem struct {
+	Name string `json:"name"`
+	// Pinyin - 空格分隔的全拼，同时用于全拼与首字母匹配
+	Pinyin  string   `json:"pinyin"`
+	Aliases []string `json:"aliases"`
+	// Group - 同一物品不同等级的共同名称，如 "作战记录"
+	Group string `json:"group"`
+}
+
+// itemCatalog - 解析后的物品目录
+type itemCatalog struct {
+	// Confusables - OCR 或输入法容易混淆的字 -> 正确的字
+	Confusables map[string]string `json:"confusables"`
+	Items       []catalogItem     `json:"items"`
+}
+
+// catalogMatch - 一个用户条目按目录展开的结果，用于日志与配置说明
+type catalogMatch struct {
+	Entry string
+	Names []string
+}
+
+func (m catalogMatch) String() string {
+	return fmt.Sprintf("「%s」→ %s", m.Entry, strings.Join(m.Names, "、"))
+}
+
+// loadCatalog - 读取物品目录，读取失败时返回 nil，条目按原样使用
+func loadCatalog() *itemCatalog {
+	data, _, err := assets.ReadFile(catalogAsset)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to read item catalog, entries used as typed")
+		return nil
+	}
+	var c itemCatalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		log.Warn().Err(err).Msg("Failed to parse item catalog, entries used as typed")
+		return nil
+	}
+	return &c
+}
+
+// normalize - 去掉空白、转小写并把易混淆的字换成正确的字
+func (c *itemCatalog) normalize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		if to, ok := c.Confusables[string(r)]; ok {
+			b.WriteString(to)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// initials - "long men bi" -> "lmb"
+func initials(pinyin string) string {
+	var b strings.Builder
+	for _, syllable := range strings.Fields(pinyin) {
+		b.WriteByte(syllable[0])
+	}
+	return b.String()
+}
+
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup - 按精确名称、别名/等级/拼音、名称子串、拼音前缀的顺序匹配，返回第一个有结果的阶段
+func (c *itemCatalog) lookup(entry string) []string {
+	n := c.normalize(entry)
+	if n == "" {
+		return nil
+	}
+	stages := []func(item catalogItem) bool{
+		func(item catalogItem) bool { return c.normalize(item.Name) == n },
+		func(item catalogItem) bool {
+			pinyin := strings.ReplaceAll(strings.ToLower(item.Pinyin), " ", "")
+			if item.Group != "" && c.normalize(item.Group) == n {
+				return true
+			}
+			if pinyin != "" && (pinyin == n || initials(strings.ToLower(item.Pinyin)) == n) {
+				return true
+			}
+			return slices.ContainsFunc(item.Aliases, func(alias string) bool { return c.normalize(alias) == n })
+		},
+		func(item catalogItem) bool { return strings.Contains(c.normalize(item.Name), n) },
+		func(item catalogItem) bool {
+			if !isASCII(n) || len(n) < 2 || item.Pinyin == "" {
+				return false
+			}
+			pinyin := strings.ToLower(item.Pinyin)
+			return strings.HasPrefix(strings.ReplaceAll(pinyin, " ", ""), n) || strings.HasPrefix(initials(pinyin), n)
+		},
+	}
+	for _, match := range stages {
+		var names []string
+		for _, item := range c.Items {
+			if match(item) {
+				names = append(names, item.Name)
+			}
+		}
+		if len(names) > 0 {
+			return names
+		}
+	}
+	return nil
+}
+
+// resolve - 把用户输入的条目展开为目录中的准确商品名，去重并保持顺序
+// 目录中找不到的条目原样保留，仍可作为正则或子串使用
+func (c *itemCatalog) resolve(entries []string) ([]string, []catalogMatch) {
+	if c == nil {
+		return entries, nil
+	}
+	var resolved []string
+	var matches []catalogMatch
+	add := func(name string) {
+		if !slices.Contains(resolved, name) {
+			resolved = append(resolved, name)
+		}
+	}
+	for _, entry := range entries {
+		names := c.lookup(entry)
+		if len(names) == 0 {
+			add(entry)
+			continue
+		}
+		if len(names) != 1 || names[0] != entry {
+			matches = append(matches, catalogMatch{Entry: entry, Names: names})
+		}
+		for _, name := range names {
+			add(name)
+		}
+	}
+	return resolved, matches
+}
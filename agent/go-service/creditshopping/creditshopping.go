@@ -6,6 +6,9 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/clientlang"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/overridesnap"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/theme"
 	maa "github.com/MaaXYZ/maa-framework-go/v4"
 	"github.com/rs/zerolog/log"
 )
@@ -13,9 +16,23 @@ import (
 type CreditShoppingParseParams struct{}
 
 func (a *CreditShoppingParseParams) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	overridesnap.Reset(ctx, "CreditShopping", "CreditShoppingBuyFirst", "CreditShoppingBuyNormal", "CreditShoppingCheckSpace", recordPurchaseNode)
+	theme.Apply(ctx)
+	clientlang.Apply(ctx)
+	fallback = nil
+	reserveCredit = 0
+	maxPrice = nil
+	decisionPolicy = nil
+
 	var params struct {
-		BuyFirst  string `json:"buy_first"`
-		Blacklist string `json:"blacklist"`
+		BuyFirst      string `json:"buy_first"`
+		Blacklist     string `json:"blacklist"`
+		ReserveCredit int    `json:"reserve_credit"`
+		MaxPrice      string `json:"max_price"`
+		MaxPurchases  int    `json:"max_purchases"`
+		MaxSpend      int    `json:"max_spend"`
+		// DecisionPolicy - 普通购买的条件表达式，为空时不限制
+		DecisionPolicy string `json:"decision_policy"`
 	}
 
 	if err := json.Unmarshal([]byte(arg.CustomActionParam), &params); err != nil {
@@ -23,7 +40,22 @@ func (a *CreditShoppingParseParams) Run(ctx *maa.Context, arg *maa.CustomActionA
 		return false
 	}
 
-	log.Info().Str("buy_first", params.BuyFirst).Str("blacklist", params.Blacklist).Msg("CreditShoppingParseParams input")
+	log.Info().Str("buy_first", params.BuyFirst).Str("blacklist", params.Blacklist).Int("reserve_credit", params.ReserveCredit).Str("max_price", params.MaxPrice).Int("max_purchases", params.MaxPurchases).Int("max_spend", params.MaxSpend).Str("decision_policy", params.DecisionPolicy).Msg("CreditShoppingParseParams input")
+	reserveCredit = max(params.ReserveCredit, 0)
+	var invalidMaxPrice []string
+	maxPrice, invalidMaxPrice = parseMaxPrice(params.MaxPrice)
+	if len(invalidMaxPrice) > 0 {
+		log.Warn().Strs("entries", invalidMaxPrice).Msg("Invalid max_price entries ignored")
+		showMessage(ctx, fmt.Sprintf("⚠️ 价格上限格式应为「商品名:价格」，已忽略：%s", strings.Join(invalidMaxPrice, "、")))
+	}
+	if policy, err := parseDecisionPolicy(params.DecisionPolicy); err != nil {
+		log.Warn().Err(err).Str("decision_policy", params.DecisionPolicy).Msg("Invalid decision_policy ignored")
+		showMessage(ctx, fmt.Sprintf("⚠️ 购买条件「%s」无法解析，已忽略：%v", params.DecisionPolicy, err))
+	} else {
+		decisionPolicy = policy
+	}
+	// 价格上限与购买条件都需要在 Go 侧读取价格
+	needsPrice := len(maxPrice) > 0 || decisionPolicy != nil
 
 	// 1. Process BuyFirst
 	// Convert "A;B" -> ["A", "B"]
@@ -37,21 +69,31 @@ func (a *CreditShoppingParseParams) Run(ctx *maa.Context, arg *maa.CustomActionA
 		}
 	}
 
+	// 按物品目录把简称、拼音、错字和等级总称展开为准确的商品名
+	catalog := loadCatalog()
+	var buyFirstMatches, blacklistMatches []catalogMatch
+	buyFirstExpected, buyFirstMatches = catalog.resolve(buyFirstExpected)
+
+	// Go 侧筛选按子串匹配，OCR expected 原样使用条目
+	buyFirstKeywords := buyFirstExpected
+	if suspicious := suspiciousRegex(buyFirstKeywords); len(suspicious) > 0 {
+		showMessage(ctx, fmt.Sprintf("⚠️ 优先购买中的 %s 可能不是有效的正则表达式，如果没有买到请检查写法", strings.Join(suspicious, "、")))
+	}
+
 	log.Info().Interface("buy_first", buyFirstExpected).Msg("CreditShoppingParseParams buy_first")
 
 	// 2. Process Blacklist
 	// Convert "A;B" -> ["^(?!.*A)(?!.*B).*$"]
+	var blacklistKeywords []string
 	var blacklistExpected []string
 	if params.Blacklist != "" {
-		parts := strings.Split(params.Blacklist, ";")
+		blacklistKeywords, blacklistMatches = catalog.resolve(splitList(params.Blacklist))
 		var sb strings.Builder
 		sb.WriteString("^")
-		for _, part := range parts {
-			if trimmed := strings.TrimSpace(part); trimmed != "" {
-				// Pattern: (?!.*KEYWORD)
-				quoted := regexp.QuoteMeta(trimmed)
-				sb.WriteString(fmt.Sprintf("(?!(?:.*%s))", quoted))
-			}
+		for _, keyword := range blacklistKeywords {
+			// Pattern: (?!.*KEYWORD)
+			quoted := regexp.QuoteMeta(keyword)
+			sb.WriteString(fmt.Sprintf("(?!(?:.*%s))", quoted))
 		}
 		sb.WriteString(".*$")
 		blacklistExpected = append(blacklistExpected, sb.String())
@@ -59,6 +101,15 @@ func (a *CreditShoppingParseParams) Run(ctx *maa.Context, arg *maa.CustomActionA
 
 	log.Info().Interface("blacklist", blacklistExpected).Msg("CreditShoppingParseParams blacklist")
 
+	if matches := append(buyFirstMatches, blacklistMatches...); len(matches) > 0 {
+		lines := make([]string, 0, len(matches))
+		for _, m := range matches {
+			lines = append(lines, m.String())
+		}
+		log.Info().Strs("matches", lines).Msg("Entries resolved by item catalog")
+		showMessage(ctx, fmt.Sprintf("📖 已按物品目录识别：%s", strings.Join(lines, "；")))
+	}
+
 	nodeAttachCache := make(map[string]map[string]interface{})
 	getNodeAttach := func(nodeName string) map[string]interface{} {
 		if attach, ok := nodeAttachCache[nodeName]; ok {
@@ -92,13 +143,14 @@ func (a *CreditShoppingParseParams) Run(ctx *maa.Context, arg *maa.CustomActionA
 	}
 
 	onlyBuyDiscount := false
+	minDiscount := 0
+	regexMode := regexModeAuto
 	var discount2OCROffset []int
 	if attach := getNodeAttach("CreditShoppingBuyNormal"); attach != nil {
-		if v, ok := attach["only_buy_discount"]; ok {
-			if b, ok := v.(bool); ok {
-				onlyBuyDiscount = b
-			}
+		if v, ok := attach["regex_mode"].(string); ok {
+			regexMode = v
 		}
+		onlyBuyDiscount, minDiscount = parseOnlyBuyDiscount(attach["only_buy_discount"])
 		if v, ok := attach["offset"]; ok {
 			if arr, ok := v.([]interface{}); ok && len(arr) == 4 {
 				tmp := make([]int, 4)
@@ -122,7 +174,15 @@ func (a *CreditShoppingParseParams) Run(ctx *maa.Context, arg *maa.CustomActionA
 			}
 		}
 	}
-	log.Info().Bool("only_buy_discount", onlyBuyDiscount).Msg("CreditShoppingParseParams flag")
+	log.Info().Bool("only_buy_discount", onlyBuyDiscount).Int("min_discount", minDiscount).Msg("CreditShoppingParseParams flag")
+
+	// 需要读取价格时黑名单也改为 Go 侧过滤
+	if needsPrice {
+		regexMode = regexModeGo
+	} else if len(blacklistKeywords) > 0 {
+		regexMode = resolveRegexMode(ctx, regexMode)
+		log.Info().Str("regex_mode", regexMode).Msg("CreditShoppingParseParams blacklist mode")
+	}
 
 	// 3. Get all_of from attach, replace expected, and write back to override all_of
 	overrideMap := map[string]interface{}{}
@@ -143,8 +203,15 @@ func (a *CreditShoppingParseParams) Run(ctx *maa.Context, arg *maa.CustomActionA
 		return allOf, true
 	}
 
+	// attach.all_of 缺少商品名 OCR 时补上默认子识别，否则用户填写的关键词不会生效
+	var synthesized []string
+
 	if allOf, ok := getAllOfFromAttach("CreditShoppingBuyFirst"); ok {
 		if len(buyFirstExpected) > 0 {
+			var added bool
+			if allOf, added = ensureSubrec(allOf, nameOCRSubrec("BuyFirstOCR", "Expected")); added {
+				synthesized = append(synthesized, "CreditShoppingBuyFirst.BuyFirstOCR")
+			}
 			for _, item := range allOf {
 				itemMap, ok := item.(map[string]interface{})
 				if !ok {
@@ -165,9 +232,25 @@ func (a *CreditShoppingParseParams) Run(ctx *maa.Context, arg *maa.CustomActionA
 	}
 
 	if allOf, ok := getAllOfFromAttach("CreditShoppingBuyNormal"); ok {
+		if len(blacklistExpected) > 0 || onlyBuyDiscount || needsPrice {
+			var added bool
+			if allOf, added = ensureSubrec(allOf, nameOCRSubrec("BlacklistOCR", "vertical")); added {
+				synthesized = append(synthesized, "CreditShoppingBuyNormal.BlacklistOCR")
+			}
+		}
+
 		// Track position after NotSoldOut for potential discount subrec insertion
 		insertIdx := -1
 
+		var priceOffset any
+		if needsPrice {
+			for _, item := range allOf {
+				if itemMap, ok := item.(map[string]interface{}); ok && itemMap["sub_name"] == "Affordable" {
+					priceOffset = itemMap["roi_offset"]
+				}
+			}
+		}
+
 		for idx, item := range allOf {
 			itemMap, ok := item.(map[string]interface{})
 			if !ok {
@@ -178,8 +261,14 @@ func (a *CreditShoppingParseParams) Run(ctx *maa.Context, arg *maa.CustomActionA
 				insertIdx = idx + 1
 			}
 			if subName == "BlacklistOCR" {
-				if len(blacklistExpected) > 0 {
-					itemMap["expected"] = blacklistExpected
+				if priceOffset != nil {
+					goSideBlacklist(itemMap, blacklistKeywords, priceOffset)
+				} else if len(blacklistExpected) > 0 {
+					if regexMode == regexModeGo {
+						goSideBlacklist(itemMap, blacklistKeywords, nil)
+					} else {
+						itemMap["expected"] = blacklistExpected
+					}
 				}
 				if onlyBuyDiscount {
 					itemMap["roi"] = "IsDiscount"
@@ -195,9 +284,14 @@ func (a *CreditShoppingParseParams) Run(ctx *maa.Context, arg *maa.CustomActionA
 		if onlyBuyDiscount && insertIdx >= 0 {
 			if attach := getNodeAttach("CreditShoppingBuyNormal"); attach != nil {
 				if subrec, ok := attach["only_buy_discount_subrec"].(map[string]interface{}); ok {
-					newAllOf := make([]interface{}, 0, len(allOf)+1)
+					inserted := []interface{}{subrec}
+					// 设置了最低折扣时，紧接着读取角标上的折扣
+					if minDiscount > 0 {
+						inserted = append(inserted, discountSubrec(minDiscount))
+					}
+					newAllOf := make([]interface{}, 0, len(allOf)+len(inserted))
 					newAllOf = append(newAllOf, allOf[:insertIdx]...)
-					newAllOf = append(newAllOf, subrec)
+					newAllOf = append(newAllOf, inserted...)
 					newAllOf = append(newAllOf, allOf[insertIdx:]...)
 					allOf = newAllOf
 				} else {
@@ -212,15 +306,33 @@ func (a *CreditShoppingParseParams) Run(ctx *maa.Context, arg *maa.CustomActionA
 		}
 	}
 
+	// 设置了件数或花费上限时，在记录购买之后插入计数节点
+	if limit := (purchaseLimit{MaxPurchases: params.MaxPurchases, MaxSpend: params.MaxSpend}); limit.enabled() {
+		for node, v := range limit.override() {
+			overrideMap[node] = v
+		}
+	}
+
+	if len(synthesized) > 0 {
+		log.Warn().Strs("synthesized", synthesized).Msg("attach.all_of lacks name OCR sub-recognitions, defaults added")
+		showMessage(ctx, fmt.Sprintf("⚠️ 购买节点缺少商品名识别，已按默认值补上：%s", strings.Join(synthesized, "、")))
+	}
+
 	if len(overrideMap) == 0 {
 		return true
 	}
 
-	log.Info().Interface("override", overrideMap).Msg("CreditShoppingParseParams override")
-
-	if err := ctx.OverridePipeline(overrideMap); err != nil {
+	if err := overridesnap.Apply(ctx, "CreditShopping", overrideMap); err != nil {
 		log.Error().Err(err).Interface("override", overrideMap).Msg("Failed to OverridePipeline")
-		return false
+		plan, planErr := newFallbackPlan(ctx, buyFirstKeywords, blacklistKeywords, onlyBuyDiscount, minDiscount)
+		if planErr != nil {
+			log.Error().Err(planErr).Msg("Failed to prepare Go-side fallback")
+			return false
+		}
+		fallback = plan
+		log.Warn().Msg("CreditShopping falls back to Go-side filtering")
+		showMessage(ctx, "⚠️ 无法修改购买节点，已改用 Go 侧筛选购买")
+		return true
 	}
 
 	return true
@@ -0,0 +1,64 @@
+package creditshopping
+
+import (
+	"encoding/json"
+
+	maa "github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	discountRecognition = "CreditShoppingDiscountRecognition"
+	// discountOCRNode - 折扣角标上的数字，roi 由 Go 侧覆盖为 IsDiscount 的命中框
+	discountOCRNode = "CreditShoppingDiscountOCR"
+)
+
+// parseOnlyBuyDiscount - attach.only_buy_discount 为 true 时只买打折商品，为数字时只买折扣不低于该百分比的商品
+func parseOnlyBuyDiscount(v any) (onlyDiscount bool, minDiscount int) {
+	switch v := v.(type) {
+	case bool:
+		return v, 0
+	case float64:
+		if v > 0 {
+			return true, int(v)
+		}
+	}
+	return false, 0
+}
+
+// discountSubrec - 插在 IsDiscount 之后的子识别，读取角标上的折扣并与 minDiscount 比较
+func discountSubrec(minDiscount int) map[string]interface{} {
+	param, _ := json.Marshal(map[string]any{"min": minDiscount})
+	return map[string]interface{}{
+		"sub_name":                 "DiscountValue",
+		"recognition":              "Custom",
+		"custom_recognition":       discountRecognition,
+		"custom_recognition_param": string(param),
+		"roi":                      "IsDiscount",
+	}
+}
+
+// CreditShoppingDiscountRecognition - OCR roi 内折扣角标上的百分比，不低于 min 时命中
+// custom_recognition_param: {"min": 50}
+type CreditShoppingDiscountRecognition struct{}
+
+func (r *CreditShoppingDiscountRecognition) Run(ctx *maa.Context, arg *maa.CustomRecognitionArg) (*maa.CustomRecognitionResult, bool) {
+	var params struct {
+		Min int `json:"min"`
+	}
+	if err := json.Unmarshal([]byte(arg.CustomRecognitionParam), &params); err != nil {
+		log.Error().Err(err).Msg("Failed to parse CreditShoppingDiscountRecognition param")
+		return nil, false
+	}
+
+	discount, ok := readNumber(ctx, arg.Img, discountOCRNode, arg.Roi)
+	if !ok {
+		log.Info().Msg("Discount badge unreadable, item skipped")
+		return nil, false
+	}
+	if discount < params.Min {
+		log.Info().Int("discount", discount).Int("min", params.Min).Msg("Discount below minimum, item skipped")
+		return nil, false
+	}
+	return &maa.CustomRecognitionResult{Box: arg.Roi, Detail: "discount"}, true
+}
@@ -0,0 +1,187 @@
+package creditshopping
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/configexplain"
+	maa "github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// splitList - "A;B" -> ["A", "B"]
+func splitList(text string) []string {
+	var items []string
+	for _, part := range strings.Split(text, ";") {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+	return items
+}
+
+// creditShoppingConfig - 任务选项最终落到各节点上的配置
+type creditShoppingConfig struct {
+	BuyFirst        []string
+	Blacklist       []string
+	Force           bool // CreditShoppingBuyBlacklist 启用：没有其他可买时也买黑名单商品
+	OnlyBuyDiscount bool
+	MinDiscount     int  // 大于 0 时只买折扣不低于该百分比的商品
+	Reserve         bool // CreditShoppingReserveCredit 启用：信用点不足时停止
+	ReserveCredit   int  // 普通购买后至少保留的信用点
+	MaxPrice        []priceCap
+	InvalidMaxPrice []string
+	Limit           purchaseLimit
+	DecisionPolicy  string
+	// PolicyErr - 购买条件无法解析的原因，此时条件被忽略
+	PolicyErr error
+	// Resolved - 按物品目录展开的条目
+	Resolved []catalogMatch
+}
+
+// explainConfig - 用白话说明信用点购物会买什么、不买什么
+func explainConfig(c creditShoppingConfig) configexplain.Explanation {
+	var e configexplain.Explanation
+
+	if len(c.Resolved) > 0 {
+		lines := make([]string, 0, len(c.Resolved))
+		for _, m := range c.Resolved {
+			lines = append(lines, m.String())
+		}
+		e.Will = append(e.Will, fmt.Sprintf("按物品目录匹配商品名：%s", strings.Join(lines, "；")))
+	}
+	if len(c.BuyFirst) > 0 {
+		e.Will = append(e.Will, fmt.Sprintf("优先购买 %s", strings.Join(c.BuyFirst, "、")))
+	}
+	discounted := "打折"
+	if c.MinDiscount > 0 {
+		discounted = fmt.Sprintf("折扣不低于 %d%%", c.MinDiscount)
+	}
+	switch {
+	case c.OnlyBuyDiscount && len(c.Blacklist) > 0:
+		e.Will = append(e.Will, fmt.Sprintf("之后只购买%s且不含 %s 的商品", discounted, strings.Join(c.Blacklist, "、")))
+	case c.OnlyBuyDiscount:
+		e.Will = append(e.Will, fmt.Sprintf("之后只购买%s的商品", discounted))
+	case len(c.Blacklist) > 0:
+		e.Will = append(e.Will, fmt.Sprintf("之后购买所有买得起且不含 %s 的商品", strings.Join(c.Blacklist, "、")))
+	default:
+		e.Will = append(e.Will, "之后购买所有买得起的商品，直到信用点不足")
+	}
+
+	if c.Force {
+		e.Will = append(e.Will, "信用溢出时会无视黑名单购买")
+		if len(c.Blacklist) == 0 {
+			e.Warnings = append(e.Warnings, "开启了强制购买但黑名单为空，该选项不起作用")
+		}
+	} else if len(c.Blacklist) > 0 {
+		e.Wont = append(e.Wont, "不会购买黑名单中的商品")
+	}
+	if c.OnlyBuyDiscount {
+		if c.MinDiscount > 0 {
+			e.Wont = append(e.Wont, fmt.Sprintf("不会购买未打折或折扣低于 %d%% 的普通商品", c.MinDiscount))
+		} else {
+			e.Wont = append(e.Wont, "不会购买未打折的普通商品")
+		}
+		if len(c.BuyFirst) == 0 {
+			e.Warnings = append(e.Warnings, "只买打折商品且优先购买列表为空，没有折扣时将什么都不买")
+		}
+	}
+	if c.Reserve {
+		e.Wont = append(e.Wont, "信用点低于 300 时停止购买")
+	}
+	if c.ReserveCredit > 0 {
+		e.Wont = append(e.Wont, fmt.Sprintf("普通购买不会让信用点低于 %d，优先购买不受限制", c.ReserveCredit))
+	}
+	if c.Limit.MaxPurchases > 0 {
+		e.Wont = append(e.Wont, fmt.Sprintf("购买 %d 件后停止，优先购买也计入", c.Limit.MaxPurchases))
+	}
+	if c.Limit.MaxSpend > 0 {
+		e.Wont = append(e.Wont, fmt.Sprintf("花费达到 %d 信用点后停止，读不到价格的商品不计入", c.Limit.MaxSpend))
+	}
+	if len(c.MaxPrice) > 0 {
+		caps := make([]string, 0, len(c.MaxPrice))
+		for _, p := range c.MaxPrice {
+			caps = append(caps, p.String())
+		}
+		e.Wont = append(e.Wont, fmt.Sprintf("普通购买不会买价格超过上限的 %s，读不到价格时也跳过", strings.Join(caps, "、")))
+	}
+	if c.PolicyErr != nil {
+		e.Warnings = append(e.Warnings, fmt.Sprintf("购买条件「%s」无法解析，将被忽略：%v", c.DecisionPolicy, c.PolicyErr))
+	} else if c.DecisionPolicy != "" {
+		e.Wont = append(e.Wont, fmt.Sprintf("普通购买只买满足「%s」的商品，读不到价格时跳过", c.DecisionPolicy))
+	}
+	if len(c.InvalidMaxPrice) > 0 {
+		e.Warnings = append(e.Warnings, fmt.Sprintf("价格上限格式应为「商品名:价格」，将忽略 %s", strings.Join(c.InvalidMaxPrice, "、")))
+	}
+
+	for _, item := range c.BuyFirst {
+		if _, err := regexp.Compile(item); err != nil {
+			e.Warnings = append(e.Warnings, fmt.Sprintf("优先购买中的「%s」可能不是有效的正则表达式，将原样用于识别，没有买到时请检查写法", item))
+		}
+	}
+
+	blacklisted := make(map[string]bool, len(c.Blacklist))
+	for _, b := range c.Blacklist {
+		blacklisted[b] = true
+	}
+	for _, item := range c.BuyFirst {
+		for b := range blacklisted {
+			if strings.Contains(item, b) {
+				e.Warnings = append(e.Warnings, fmt.Sprintf("「%s」同时在优先购买和黑名单（%s）中，优先购买不受黑名单限制，仍会买入", item, b))
+				break
+			}
+		}
+	}
+	return e
+}
+
+// CreditShoppingExplainConfigAction - 任务开始前说明当前配置会让信用点购物做什么、不做什么
+type CreditShoppingExplainConfigAction struct{}
+
+func (a *CreditShoppingExplainConfigAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	param, err := configexplain.ActionParam(ctx, "CreditShoppingShopping")
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to read CreditShopping params, skip config explanation")
+		return true
+	}
+	var params struct {
+		BuyFirst      string `json:"buy_first"`
+		Blacklist     string `json:"blacklist"`
+		ReserveCredit int    `json:"reserve_credit"`
+		MaxPrice      string `json:"max_price"`
+		MaxPurchases  int    `json:"max_purchases"`
+		MaxSpend      int    `json:"max_spend"`
+		// DecisionPolicy - 普通购买的条件表达式
+		DecisionPolicy string `json:"decision_policy"`
+	}
+	if err := json.Unmarshal([]byte(param), &params); err != nil {
+		log.Warn().Err(err).Msg("Failed to parse CreditShopping params, skip config explanation")
+		return true
+	}
+
+	catalog := loadCatalog()
+	buyFirst, buyFirstMatches := catalog.resolve(splitList(params.BuyFirst))
+	blacklist, blacklistMatches := catalog.resolve(splitList(params.Blacklist))
+	c := creditShoppingConfig{
+		BuyFirst:      buyFirst,
+		Blacklist:     blacklist,
+		Resolved:      append(buyFirstMatches, blacklistMatches...),
+		Force:         configexplain.Enabled(ctx, "CreditShoppingBuyBlacklist"),
+		Reserve:       configexplain.Enabled(ctx, "CreditShoppingReserveCredit"),
+		ReserveCredit: params.ReserveCredit,
+		Limit:         purchaseLimit{MaxPurchases: params.MaxPurchases, MaxSpend: params.MaxSpend},
+	}
+	c.MaxPrice, c.InvalidMaxPrice = parseMaxPrice(params.MaxPrice)
+	c.DecisionPolicy = strings.TrimSpace(params.DecisionPolicy)
+	_, c.PolicyErr = parseDecisionPolicy(c.DecisionPolicy)
+	c.OnlyBuyDiscount, c.MinDiscount = parseOnlyBuyDiscount(configexplain.Attach(ctx, "CreditShoppingBuyNormal")["only_buy_discount"])
+
+	e := explainConfig(c)
+	for _, w := range e.Warnings {
+		log.Warn().Str("warning", w).Msg("CreditShopping config check")
+	}
+	configexplain.Show(ctx, "📝 本次信用点购物将会：", e)
+	return true
+}
@@ -0,0 +1,57 @@
+package creditshopping
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/xlsx"
+)
+
+// loadHistory - 读取 creditshopping/history.jsonl 中的全部购买，无法解析的行跳过
+func loadHistory() ([]purchaseRecord, error) {
+	f, err := os.Open(filepath.Join(datadir.Path("creditshopping"), "history.jsonl"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []purchaseRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record purchaseRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// ExportSheet - 把信用点购物的购买历史写成一个工作表，末尾为合计公式，返回购买行数
+func ExportSheet(wb *xlsx.Workbook) (int, error) {
+	records, err := loadHistory()
+	if err != nil {
+		return 0, err
+	}
+	sheet := wb.AddSheet("信用点购物")
+	sheet.AddRow("时间", "商品", "价格", "折扣", "来源")
+	for _, r := range records {
+		sheet.AddRow(r.Time.Local().Format("2006-01-02 15:04:05"), r.Name, r.Price, r.Discount, r.Source)
+	}
+	n := sheet.Rows() - 1
+	if n > 0 {
+		last := sheet.Rows()
+		sheet.AddRow()
+		sheet.AddRow("购买件数", nil, xlsx.Formula(fmt.Sprintf("COUNT(C2:C%d)", last)))
+		sheet.AddRow("花费合计", nil, xlsx.Formula(fmt.Sprintf("SUM(C2:C%d)", last)))
+		sheet.AddRow("折扣件数", nil, nil, xlsx.Formula(fmt.Sprintf("COUNTIF(D2:D%d,TRUE)", last)))
+	}
+	return n, nil
+}
@@ -0,0 +1,249 @@
+package creditshopping
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/ocrutil"
+	maa "github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	fallbackRecognition = "CreditShoppingFallbackRecognition"
+	// fallbackSubrecNode - 逐个运行子识别时使用的空节点，内容由 Go 侧按次覆盖
+	fallbackSubrecNode = "CreditShoppingFallbackSubrec"
+)
+
+// fallbackPlan - OverridePipeline 失败时由 Go 侧逐个商品筛选所需的配置
+type fallbackPlan struct {
+	buyFirst     []string
+	blacklist    []string
+	onlyDiscount bool
+	// minDiscount - 大于 0 时只算折扣不低于该百分比的商品为打折
+	minDiscount int
+	// subrecs - CreditShoppingBuyNormal attach 中未被改写的子识别，按 sub_name 索引
+	subrecs map[string]map[string]any
+}
+
+// fallback - 本次购买是否走 Go 侧筛选，CreditShoppingParseParams 每次运行时重新决定
+var fallback *fallbackPlan
+
+// fallbackPicked - Go 侧筛选最近一次选中的商品，供购买汇总记录
+var fallbackPicked fallbackItem
+
+// newFallbackPlan - 重新读取 CreditShoppingBuyNormal 的 attach，ParseParams 中的 attach 已被就地改写
+func newFallbackPlan(ctx *maa.Context, buyFirst, blacklist []string, onlyDiscount bool, minDiscount int) (*fallbackPlan, error) {
+	raw, err := ctx.GetNodeJSON("CreditShoppingBuyNormal")
+	if err != nil {
+		return nil, err
+	}
+	var node struct {
+		Attach struct {
+			AllOf          []map[string]any `json:"all_of"`
+			DiscountSubrec map[string]any   `json:"only_buy_discount_subrec"`
+		} `json:"attach"`
+	}
+	if err := json.Unmarshal([]byte(raw), &node); err != nil {
+		return nil, err
+	}
+
+	p := &fallbackPlan{
+		buyFirst:     buyFirst,
+		blacklist:    blacklist,
+		onlyDiscount: onlyDiscount,
+		minDiscount:  minDiscount,
+		subrecs:      map[string]map[string]any{},
+	}
+	for _, subrec := range append(node.Attach.AllOf, node.Attach.DiscountSubrec) {
+		if name, _ := subrec["sub_name"].(string); name != "" {
+			p.subrecs[name] = subrec
+		}
+	}
+	for _, name := range []string{"CreditIcon", "NotSoldOut", "BlacklistOCR", "Affordable"} {
+		if p.subrecs[name] == nil {
+			return nil, fmt.Errorf("attach.all_of of CreditShoppingBuyNormal has no %s", name)
+		}
+	}
+	return p, nil
+}
+
+// run - 在 base 上叠加子识别的 roi_offset 作为 roi 运行该子识别，base 为空时使用子识别自己的 roi
+func (p *fallbackPlan) run(ctx *maa.Context, img image.Image, name string, base maa.Rect) (*maa.RecognitionDetail, bool) {
+	subrec := p.subrecs[name]
+	if subrec == nil {
+		return nil, false
+	}
+	param := make(map[string]any, len(subrec))
+	for key, value := range subrec {
+		switch key {
+		case "sub_name", "doc", "roi", "roi_offset":
+			continue
+		}
+		param[key] = value
+	}
+	if base == (maa.Rect{}) {
+		param["roi"] = subrec["roi"]
+	} else {
+		param["roi"] = offsetRect(base, subrec["roi_offset"])
+	}
+
+	detail, err := ctx.RunRecognition(fallbackSubrecNode, img, map[string]any{fallbackSubrecNode: param})
+	if err != nil {
+		log.Warn().Err(err).Str("subrec", name).Msg("Fallback sub-recognition failed")
+		return nil, false
+	}
+	if detail == nil || !detail.Hit {
+		return nil, false
+	}
+	return detail, true
+}
+
+// offsetRect - 与 pipeline 的 roi_offset 相同：各分量分别加到 x, y, w, h 上
+func offsetRect(base maa.Rect, offset any) maa.Rect {
+	values, _ := offset.([]any)
+	if len(values) != 4 {
+		return base
+	}
+	var d [4]int
+	for i, v := range values {
+		if n, ok := v.(float64); ok {
+			d[i] = int(n)
+		}
+	}
+	return maa.Rect{base.X() + d[0], base.Y() + d[1], base.Width() + d[2], base.Height() + d[3]}
+}
+
+// fallbackItem - 一件未售罄的商品
+type fallbackItem struct {
+	Name string
+	// NameBox - 商品名的 OCR 框，点击它即可打开购买弹窗
+	NameBox maa.Rect
+	// Card - 商品卡片（NotSoldOut 的命中框）
+	Card     maa.Rect
+	Discount bool
+}
+
+// items - 按纵向顺序列出画面中未售罄的商品
+func (p *fallbackPlan) items(ctx *maa.Context, img image.Image) []fallbackItem {
+	detail, ok := p.run(ctx, img, "CreditIcon", maa.Rect{})
+	if !ok || detail.Results == nil {
+		return nil
+	}
+	var items []fallbackItem
+	for _, result := range detail.Results.Filtered {
+		icon, ok := result.AsTemplateMatch()
+		if !ok {
+			continue
+		}
+		card, ok := p.run(ctx, img, "NotSoldOut", icon.Box)
+		if !ok {
+			continue
+		}
+		nameDetail, err := ctx.RunRecognition(blacklistOCRNode, img, map[string]any{
+			blacklistOCRNode: map[string]any{"roi": offsetRect(card.Box, p.subrecs["BlacklistOCR"]["roi_offset"])},
+		})
+		if err != nil || nameDetail == nil || !nameDetail.Hit {
+			continue
+		}
+		name, ok := ocrutil.Text(nameDetail, 0)
+		if !ok {
+			continue
+		}
+		item := fallbackItem{Name: name.Text, NameBox: name.Box, Card: card.Box}
+		if p.onlyDiscount {
+			badge, ok := p.run(ctx, img, "IsDiscount", card.Box)
+			item.Discount = ok
+			if ok && p.minDiscount > 0 {
+				discount, read := readNumber(ctx, img, discountOCRNode, badge.Box)
+				item.Discount = read && discount >= p.minDiscount
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// affordable - 价格不是红色，价格位于商品名的右上方
+func (p *fallbackPlan) affordable(ctx *maa.Context, img image.Image, item fallbackItem) bool {
+	_, ok := p.run(ctx, img, "Affordable", item.NameBox)
+	return ok
+}
+
+// pick - 与 CreditShoppingBuyFirst / CreditShoppingBuyNormal 的顺序相同：
+// 先按优先购买列表的顺序找，再按画面顺序找不在黑名单中的商品
+func (p *fallbackPlan) pick(ctx *maa.Context, img image.Image) (fallbackItem, bool) {
+	items := p.items(ctx, img)
+	for _, keyword := range p.buyFirst {
+		for _, item := range items {
+			if _, ok := ocrutil.ContainsAny(item.Name, []string{keyword}); ok && p.affordable(ctx, img, item) {
+				return item, true
+			}
+		}
+	}
+	for _, item := range items {
+		if keyword, ok := ocrutil.ContainsAny(item.Name, p.blacklist); ok {
+			log.Info().Str("text", item.Name).Str("keyword", keyword).Msg("Blacklisted item skipped")
+			continue
+		}
+		if p.onlyDiscount && !item.Discount {
+			continue
+		}
+		if !p.affordable(ctx, img, item) {
+			continue
+		}
+		priceBox := offsetRect(item.NameBox, p.subrecs["Affordable"]["roi_offset"])
+		if overPrice(ctx, img, item.Name, priceBox) || policyRejects(ctx, img, item.Name, priceBox) {
+			continue
+		}
+		// 与 CreditShoppingReserveStop 相同，保留信用点、价格上限与购买条件只限制普通购买
+		if reserveBlocks(ctx, img, priceBox) {
+			return fallbackItem{}, false
+		}
+		return item, true
+	}
+	return fallbackItem{}, false
+}
+
+// CreditShoppingFallbackRecognition - Go 侧筛选购买，只在 OverridePipeline 失败后命中
+// custom_recognition_param: {"done": false} 命中要购买的商品；{"done": true} 在没有可买商品时命中，用于结束购买
+type CreditShoppingFallbackRecognition struct{}
+
+func (r *CreditShoppingFallbackRecognition) Run(ctx *maa.Context, arg *maa.CustomRecognitionArg) (*maa.CustomRecognitionResult, bool) {
+	if fallback == nil {
+		return nil, false
+	}
+	var params struct {
+		Done bool `json:"done"`
+	}
+	if arg.CustomRecognitionParam != "" {
+		if err := json.Unmarshal([]byte(arg.CustomRecognitionParam), &params); err != nil {
+			log.Error().Err(err).Msg("Failed to parse CreditShoppingFallbackRecognition param")
+			return nil, false
+		}
+	}
+	if params.Done {
+		return &maa.CustomRecognitionResult{Box: arg.Roi, Detail: "nothing to buy"}, true
+	}
+
+	item, ok := fallback.pick(ctx, arg.Img)
+	if !ok {
+		return nil, false
+	}
+	log.Info().Str("item", item.Name).Msg("Fallback picked item")
+	fallbackPicked = item
+	return &maa.CustomRecognitionResult{Box: item.NameBox, Detail: item.Name}, true
+}
+
+func showMessage(ctx *maa.Context, text string) {
+	ctx.RunTask("CreditShopping_TaskShowMessage", map[string]interface{}{
+		"CreditShopping_TaskShowMessage": map[string]interface{}{
+			"recognition": "DirectHit",
+			"action":      "DoNothing",
+			"focus": map[string]interface{}{
+				"Node.Action.Starting": text,
+			},
+		},
+	})
+}
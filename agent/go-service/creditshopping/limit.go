@@ -0,0 +1,86 @@
+package creditshopping
+
+import (
+	"encoding/json"
+	"fmt"
+
+	maa "github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	limitAction = "CreditShoppingPurchaseLimitAction"
+	// limitNode - 设置了上限时由 CreditShoppingParseParams 注入，接在记录购买之后
+	limitNode = "CreditShoppingPurchaseLimit"
+	// limitReachedNode - 达到上限后关闭获得物品的弹窗并结束购买
+	limitReachedNode   = "CreditShoppingLimitReached"
+	recordPurchaseNode = "CreditShoppingRecordPurchase"
+	claimConfirmNode   = "CreditShoppingClaimConfirm"
+)
+
+// purchaseLimit - 本次任务最多购买的件数与花费的信用点，0 表示不限制
+type purchaseLimit struct {
+	MaxPurchases int `json:"max_purchases"`
+	MaxSpend     int `json:"max_spend"`
+}
+
+func (l purchaseLimit) enabled() bool {
+	return l.MaxPurchases > 0 || l.MaxSpend > 0
+}
+
+// override - 在记录购买之后插入计数节点，未达到上限时照常回到 CreditShoppingClaimConfirm
+func (l purchaseLimit) override() map[string]any {
+	return map[string]any{
+		recordPurchaseNode: map[string]any{
+			"next": []string{limitNode},
+		},
+		limitNode: map[string]any{
+			"doc":                 "统计本次购买的件数与花费，达到上限时改为结束购买",
+			"recognition":         "DirectHit",
+			"action":              "Custom",
+			"custom_action":       limitAction,
+			"custom_action_param": l,
+			"next":                []string{claimConfirmNode},
+		},
+	}
+}
+
+// reached - 按本次汇总的购买记录判断是否达到上限，读不到价格的购买不计入花费
+func (l purchaseLimit) reached(records []purchaseRecord) (count, spent int, ok bool) {
+	count = len(records)
+	for _, p := range records {
+		spent += p.Price
+	}
+	return count, spent, (l.MaxPurchases > 0 && count >= l.MaxPurchases) || (l.MaxSpend > 0 && spent >= l.MaxSpend)
+}
+
+// CreditShoppingPurchaseLimitAction - 每次购买成功后计数，达到件数或花费上限时把后继改为 CreditShoppingLimitReached
+// custom_action_param: {"max_purchases": 3, "max_spend": 500}
+type CreditShoppingPurchaseLimitAction struct{}
+
+func (a *CreditShoppingPurchaseLimitAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	beginSummary(arg)
+	var limit purchaseLimit
+	if err := json.Unmarshal([]byte(arg.CustomActionParam), &limit); err != nil {
+		log.Error().Err(err).Msg("Failed to parse CreditShoppingPurchaseLimitAction param")
+		return false
+	}
+
+	count, spent, reached := limit.reached(purchases)
+	log.Info().Int("count", count).Int("spent", spent).Int("max_purchases", limit.MaxPurchases).Int("max_spend", limit.MaxSpend).Msg("Purchase limit checked")
+	if !reached {
+		return true
+	}
+
+	if err := ctx.OverrideNext(arg.CurrentTaskName, []maa.NodeNextItem{{Name: limitReachedNode}}); err != nil {
+		log.Error().Err(err).Msg("Failed to stop buying at purchase limit")
+		return false
+	}
+	log.Info().Int("count", count).Int("spent", spent).Msg("Purchase limit reached, stop buying")
+	if limit.MaxPurchases > 0 && count >= limit.MaxPurchases {
+		showMessage(ctx, fmt.Sprintf("🛑 已购买 %d 件，达到设定的上限，停止购买", count))
+	} else {
+		showMessage(ctx, fmt.Sprintf("🛑 已花费 %d 信用点，达到设定的预算 %d，停止购买", spent, limit.MaxSpend))
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package creditshopping
+
+import (
+	"fmt"
+	"image"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/ocrutil"
+	maa "github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// priceCap - 名称包含 Keyword 的商品价格不超过 Max 时才购买，Max 为 -1 表示不限制
+type priceCap struct {
+	Keyword string
+	Max     int
+}
+
+// anyPrice - 价格上限写作这些值时不限制价格
+var anyPrice = []string{"任意", "any", "*"}
+
+// maxPrice - 普通购买的价格上限，CreditShoppingParseParams 每次运行时设置
+var maxPrice []priceCap
+
+// parseMaxPrice - "技巧概要:200;龙门币:任意" -> 上限列表，无法解析的条目原样返回
+func parseMaxPrice(text string) (caps []priceCap, invalid []string) {
+	for _, entry := range splitList(text) {
+		keyword, value, ok := strings.Cut(strings.ReplaceAll(entry, "：", ":"), ":")
+		keyword, value = strings.TrimSpace(keyword), strings.TrimSpace(value)
+		if !ok || keyword == "" {
+			invalid = append(invalid, entry)
+			continue
+		}
+		if slices.Contains(anyPrice, strings.ToLower(value)) {
+			caps = append(caps, priceCap{Keyword: keyword, Max: -1})
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			invalid = append(invalid, entry)
+			continue
+		}
+		caps = append(caps, priceCap{Keyword: keyword, Max: n})
+	}
+	return caps, invalid
+}
+
+// capFor - 按填写顺序取第一个名称匹配的上限
+func capFor(name string) (priceCap, bool) {
+	for _, c := range maxPrice {
+		if _, ok := ocrutil.ContainsAny(name, []string{c.Keyword}); ok {
+			return c, true
+		}
+	}
+	return priceCap{}, false
+}
+
+// overPrice - 商品价格超过上限时返回 true；读不到价格时同样跳过，避免高价买入
+func overPrice(ctx *maa.Context, img image.Image, name string, priceBox maa.Rect) bool {
+	c, ok := capFor(name)
+	if !ok || c.Max < 0 {
+		return false
+	}
+	price, ok := readNumber(ctx, img, priceOCRNode, priceBox)
+	if !ok {
+		log.Info().Str("item", name).Int("max_price", c.Max).Msg("Price unreadable, capped item skipped")
+		return true
+	}
+	if price <= c.Max {
+		return false
+	}
+	log.Info().Str("item", name).Int("price", price).Int("max_price", c.Max).Msg("Item above max price skipped")
+	return true
+}
+
+// String - 说明中展示的上限，如 "技巧概要（200）"
+func (c priceCap) String() string {
+	if c.Max < 0 {
+		return fmt.Sprintf("%s（不限）", c.Keyword)
+	}
+	return fmt.Sprintf("%s（%d）", c.Keyword, c.Max)
+}
@@ -0,0 +1,68 @@
+package creditshopping
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/expr"
+	maa "github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// decisionPolicy - 普通购买的自定义条件，CreditShoppingParseParams 每次运行时设置
+var decisionPolicy *expr.Expr
+
+// parseDecisionPolicy - 购买条件，如 "price <= 200 || balance - price >= 1000"
+// 可用变量：price（商品价格）、balance（信用点余额，读不到时引用它的条件视为不成立）、reserve（保留的信用点）
+// 必须是条件（最外层为比较或 && || !），为空时不限制
+func parseDecisionPolicy(text string) (*expr.Expr, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, nil
+	}
+	e, err := expr.Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsCondition() {
+		return nil, fmt.Errorf("需要是条件，如 price <= 200")
+	}
+	if _, err := e.EvalBool(policyVars(100, 1000, true)); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func policyVars(price, balance int, balanceKnown bool) map[string]float64 {
+	vars := map[string]float64{
+		"price":   float64(price),
+		"reserve": float64(reserveCredit),
+	}
+	if balanceKnown {
+		vars["balance"] = float64(balance)
+	}
+	return vars
+}
+
+// policyRejects - 购买条件不成立时返回 true；读不到价格或条件计算失败时同样跳过，避免误买
+func policyRejects(ctx *maa.Context, img image.Image, name string, priceBox maa.Rect) bool {
+	if decisionPolicy == nil {
+		return false
+	}
+	price, ok := readNumber(ctx, img, priceOCRNode, priceBox)
+	if !ok {
+		log.Info().Str("item", name).Str("policy", decisionPolicy.String()).Msg("Price unreadable, item skipped by decision policy")
+		return true
+	}
+	balance, balanceKnown := readNumber(ctx, img, balanceOCRNode, maa.Rect{})
+	allowed, err := decisionPolicy.EvalBool(policyVars(price, balance, balanceKnown))
+	if err != nil {
+		log.Info().Err(err).Str("item", name).Str("policy", decisionPolicy.String()).Msg("Decision policy failed, item skipped")
+		return true
+	}
+	if !allowed {
+		log.Info().Str("item", name).Int("price", price).Str("policy", decisionPolicy.String()).Msg("Item rejected by decision policy")
+	}
+	return !allowed
+}
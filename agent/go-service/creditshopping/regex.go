@@ -0,0 +1,145 @@
+package creditshopping
+
+import (
+	"encoding/json"
+	"regexp"
+	"sync"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/ocrutil"
+	maa "github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// Regex modes for the blacklist, read from attach.regex_mode of CreditShoppingBuyNormal
+const (
+	// regexModeAuto - probe the OCR filter once and pick one of the modes below
+	regexModeAuto = "auto"
+	// regexModeLookahead - pass a negative-lookahead pattern as OCR expected
+	regexModeLookahead = "lookahead"
+	// regexModeGo - replace BlacklistOCR with CreditShoppingBlacklistRecognition
+	regexModeGo = "go"
+)
+
+const (
+	regexProbeNode       = "CreditShoppingRegexProbe"
+	blacklistOCRNode     = "CreditShoppingBlacklistOCR"
+	blacklistRecognition = "CreditShoppingBlacklistRecognition"
+)
+
+var (
+	probeMu sync.Mutex
+	// probed / lookaheadSupported - 只缓存有结论的探测结果；画面没有文字或截图失败时不缓存，下次运行重新探测
+	probed             bool
+	lookaheadSupported bool
+)
+
+// probeLookahead - 对当前画面分别用普通正则和否定前瞻正则做 OCR 过滤
+// 普通正则有结果而前瞻正则没有，说明 OCR 过滤的正则引擎不支持前瞻
+// 画面没有文字时无法判断，本次按不支持处理，改用 Go 侧过滤
+func probeLookahead(ctx *maa.Context) bool {
+	probeMu.Lock()
+	defer probeMu.Unlock()
+	if probed {
+		return lookaheadSupported
+	}
+
+	img, err := ctx.GetTasker().GetController().CacheImage()
+	if err != nil || img == nil {
+		log.Warn().Err(err).Msg("Regex probe: no cached image, falling back to Go-side blacklist")
+		return false
+	}
+
+	hit := func(expected string) bool {
+		detail, err := ctx.RunRecognition(regexProbeNode, img, map[string]any{
+			regexProbeNode: map[string]any{"expected": []string{expected}},
+		})
+		return err == nil && detail != nil && detail.Hit
+	}
+
+	if !hit(".+") {
+		log.Warn().Msg("Regex probe: no text on screen, falling back to Go-side blacklist")
+		return false
+	}
+	lookaheadSupported = hit("^(?!.*@@@@).+$")
+	probed = true
+	log.Info().Bool("lookahead", lookaheadSupported).Msg("Regex probe finished")
+	return lookaheadSupported
+}
+
+// resolveRegexMode - 根据配置和探测结果决定黑名单的过滤方式
+func resolveRegexMode(ctx *maa.Context, configured string) string {
+	switch configured {
+	case regexModeLookahead, regexModeGo:
+		return configured
+	case "", regexModeAuto:
+		if probeLookahead(ctx) {
+			return regexModeLookahead
+		}
+		return regexModeGo
+	default:
+		log.Warn().Str("regex_mode", configured).Msg("Unknown regex_mode, using auto")
+		return resolveRegexMode(ctx, regexModeAuto)
+	}
+}
+
+// suspiciousRegex - 优先购买的条目原样作为 OCR expected 使用，返回 Go 的正则引擎无法编译的条目，只用于提醒
+// OCR 过滤使用框架的正则引擎，它支持前瞻等 Go 不支持的写法，所以这里不改写条目
+func suspiciousRegex(items []string) []string {
+	var suspicious []string
+	for _, item := range items {
+		if _, err := regexp.Compile(item); err != nil {
+			log.Warn().Err(err).Str("entry", item).Msg("buy_first entry may not be a valid regex, used as is")
+			suspicious = append(suspicious, item)
+		}
+	}
+	return suspicious
+}
+
+// goSideBlacklist - 把 BlacklistOCR 子识别改写为 Go 侧过滤的自定义识别，保留 roi 相关字段
+// priceOffset 为 Affordable 的 roi_offset，不为空时同时按价格上限过滤
+func goSideBlacklist(itemMap map[string]interface{}, keywords []string, priceOffset any) {
+	param, _ := json.Marshal(map[string]any{"blacklist": keywords, "price_offset": priceOffset})
+	delete(itemMap, "expected")
+	delete(itemMap, "order_by")
+	itemMap["recognition"] = "Custom"
+	itemMap["custom_recognition"] = blacklistRecognition
+	itemMap["custom_recognition_param"] = string(param)
+}
+
+// CreditShoppingBlacklistRecognition - 在 roi 内 OCR 商品名，名称包含黑名单关键词、价格超过上限或不满足购买条件时不命中
+// custom_recognition_param: {"blacklist": ["A", "B"], "price_offset": [65, -40, -20, 1]}，价格区域为商品名框加上 price_offset
+type CreditShoppingBlacklistRecognition struct{}
+
+func (r *CreditShoppingBlacklistRecognition) Run(ctx *maa.Context, arg *maa.CustomRecognitionArg) (*maa.CustomRecognitionResult, bool) {
+	var params struct {
+		Blacklist   []string `json:"blacklist"`
+		PriceOffset any      `json:"price_offset"`
+	}
+	if err := json.Unmarshal([]byte(arg.CustomRecognitionParam), &params); err != nil {
+		log.Error().Err(err).Msg("Failed to parse CreditShoppingBlacklistRecognition param")
+		return nil, false
+	}
+
+	detail, err := ctx.RunRecognition(blacklistOCRNode, arg.Img, map[string]any{
+		blacklistOCRNode: map[string]any{"roi": arg.Roi},
+	})
+	if err != nil || detail == nil || !detail.Hit {
+		return nil, false
+	}
+
+	ocr, ok := ocrutil.Text(detail, 0)
+	if !ok {
+		return nil, false
+	}
+	if keyword, ok := ocrutil.ContainsAny(ocr.Text, params.Blacklist); ok {
+		log.Info().Str("text", ocr.Text).Str("keyword", keyword).Msg("Blacklisted item skipped")
+		return nil, false
+	}
+	if params.PriceOffset != nil {
+		priceBox := offsetRect(ocr.Box, params.PriceOffset)
+		if overPrice(ctx, arg.Img, ocr.Text, priceBox) || policyRejects(ctx, arg.Img, ocr.Text, priceBox) {
+			return nil, false
+		}
+	}
+	return &maa.CustomRecognitionResult{Box: ocr.Box, Detail: ocr.Text}, true
+}
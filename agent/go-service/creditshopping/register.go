@@ -1,8 +1,51 @@
 package creditshopping
 
-import maa "github.com/MaaXYZ/maa-framework-go/v4"
+import (
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/nodecheck"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+)
 
-// Register registers all custom action components for creditshopping package
+// Components returns the custom components of creditshopping package
+func Components() []registry.Component {
+	P := registry.P
+	return []registry.Component{
+		registry.Action("CreditShoppingParseParams", &CreditShoppingParseParams{}, "解析购物参数，覆盖优先购买、黑名单与上限相关节点",
+			P("buy_first", "string", "优先购买的物品，分号分隔，支持简称与拼音"),
+			P("blacklist", "string", "不购买的物品，分号分隔，支持简称与拼音"),
+			P("reserve_credit", "int", "保留的信用点，购买后低于该值时停止"),
+			P("max_price", "string", "单件价格上限，如 武库配额:200"),
+			P("max_purchases", "int", "最多购买的件数，0 表示不限制"),
+			P("max_spend", "int", "最多花费的信用点，0 表示不限制"),
+			P("decision_policy", "string", "普通购买的条件，如 price <= 200，可用 price、balance、reserve"),
+		),
+		registry.Action("CreditShoppingExplainConfigAction", &CreditShoppingExplainConfigAction{}, "任务开始前说明当前配置会让信用点购物做什么、不做什么"),
+		registry.Action("CreditShoppingPickItemAction", &CreditShoppingPickItemAction{}, "记下要购买的商品后点击它",
+			P("node", "string", "命中该商品的节点"),
+			P("source", "string", "购买原因，如 buy_first"),
+		),
+		registry.Action("CreditShoppingRecordPurchaseAction", &CreditShoppingRecordPurchaseAction{}, "购买成功后计入汇总并追加到购买历史"),
+		registry.Action("CreditShoppingSummaryAction", &CreditShoppingSummaryAction{}, "购买结束时汇总本次买了什么"),
+		registry.Action(limitAction, &CreditShoppingPurchaseLimitAction{}, "达到件数或花费上限时结束购买",
+			P("max_purchases", "int", "最多购买的件数"),
+			P("max_spend", "int", "最多花费的信用点"),
+		),
+		registry.Recognition(blacklistRecognition, &CreditShoppingBlacklistRecognition{}, "名称包含黑名单关键词、价格超过上限或不满足购买条件时不命中",
+			P("blacklist", "array", "黑名单关键词"),
+			P("price_offset", "array", "价格区域相对商品名框的偏移"),
+		),
+		registry.Recognition(fallbackRecognition, &CreditShoppingFallbackRecognition{}, "OverridePipeline 失败后由 Go 侧筛选购买",
+			P("done", "bool", "true 时在没有可买商品时命中，用于结束购买"),
+		),
+		registry.Recognition(reserveRecognition, &CreditShoppingReserveRecognition{}, "购买会让信用点低于保留值时命中",
+			P("node", "string", "价格取该节点命中的框"),
+		),
+		registry.Recognition(discountRecognition, &CreditShoppingDiscountRecognition{}, "折扣角标上的百分比不低于 min 时命中",
+			P("min", "int", "最低折扣百分比"),
+		),
+	}
+}
+
+// Register declares the pipeline nodes creditshopping package references
 func Register() {
-	maa.AgentServerRegisterCustomAction("CreditShoppingParseParams", &CreditShoppingParseParams{})
+	nodecheck.Require("CreditShopping", "CreditShoppingBuyFirst", "CreditShoppingBuyNormal", regexProbeNode, blacklistOCRNode, fallbackSubrecNode, balanceOCRNode, priceOCRNode, discountOCRNode, recordPurchaseNode, limitReachedNode)
 }
@@ -0,0 +1,89 @@
+package creditshopping
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/ocrutil"
+	maa "github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	reserveRecognition = "CreditShoppingReserveRecognition"
+	// balanceOCRNode - 右上角的信用点余额
+	balanceOCRNode = "CreditShoppingBalanceOCR"
+	// priceOCRNode - 商品价格，roi 由 Go 侧按商品位置覆盖
+	priceOCRNode = "CreditShoppingPriceOCR"
+)
+
+// reserveCredit - 购买后至少保留的信用点，0 表示不保留，CreditShoppingParseParams 每次运行时设置
+var reserveCredit int
+
+// readNumber - 运行 OCR 节点并取出其中的数字，roi 为空时使用节点自己的 roi
+func readNumber(ctx *maa.Context, img image.Image, node string, roi maa.Rect) (int, bool) {
+	override := map[string]any{}
+	if roi != (maa.Rect{}) {
+		override = map[string]any{node: map[string]any{"roi": roi}}
+	}
+	detail, err := ctx.RunRecognition(node, img, override)
+	if err != nil || detail == nil || !detail.Hit {
+		return 0, false
+	}
+	part, ok := ocrutil.Text(detail, 0)
+	if !ok {
+		return 0, false
+	}
+	return ocrutil.Number(part.Text)
+}
+
+// reserveBlocks - 余额减去价格会低于保留值时返回 true；读不到余额时不阻止购买，读不到价格时只比较余额
+func reserveBlocks(ctx *maa.Context, img image.Image, priceBox maa.Rect) bool {
+	if reserveCredit <= 0 {
+		return false
+	}
+	balance, ok := readNumber(ctx, img, balanceOCRNode, maa.Rect{})
+	if !ok {
+		log.Warn().Msg("Failed to read credit balance, reserve not checked")
+		return false
+	}
+	price, _ := readNumber(ctx, img, priceOCRNode, priceBox)
+	if balance-price >= reserveCredit {
+		return false
+	}
+
+	log.Info().Int("balance", balance).Int("price", price).Int("reserve", reserveCredit).Msg("Purchase would drop credit below reserve, stop buying")
+	if price > 0 {
+		showMessage(ctx, fmt.Sprintf("💰 信用点 %d，购买 %d 后将低于保留的 %d，停止购买", balance, price, reserveCredit))
+	} else {
+		showMessage(ctx, fmt.Sprintf("💰 信用点 %d 已低于保留的 %d，停止购买", balance, reserveCredit))
+	}
+	return true
+}
+
+// CreditShoppingReserveRecognition - 即将购买的商品会让信用点低于保留值时命中，用于结束购买
+// custom_recognition_param: {"node": "CreditShoppingBuyNormal"}，价格取该节点命中的框（买得起判断所用的价格区域）
+type CreditShoppingReserveRecognition struct{}
+
+func (r *CreditShoppingReserveRecognition) Run(ctx *maa.Context, arg *maa.CustomRecognitionArg) (*maa.CustomRecognitionResult, bool) {
+	if reserveCredit <= 0 {
+		return nil, false
+	}
+	var params struct {
+		Node string `json:"node"`
+	}
+	if err := json.Unmarshal([]byte(arg.CustomRecognitionParam), &params); err != nil || params.Node == "" {
+		log.Error().Err(err).Msg("Failed to parse CreditShoppingReserveRecognition param")
+		return nil, false
+	}
+
+	detail, err := ctx.RunRecognition(params.Node, arg.Img)
+	if err != nil || detail == nil || !detail.Hit {
+		return nil, false
+	}
+	if !reserveBlocks(ctx, arg.Img, detail.Box) {
+		return nil, false
+	}
+	return &maa.CustomRecognitionResult{Box: detail.Box, Detail: "credit reserve reached"}, true
+}
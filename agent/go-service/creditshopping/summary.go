@@ -0,0 +1,227 @@
+package creditshopping
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/ocrutil"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/pricewatch"
+	maa "github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// summaryFocusNode - 购买汇总 JSON 输出到该节点的 focus，供 GUI 展示
+const summaryFocusNode = "CreditShopping_SummaryJSON"
+
+// purchaseRecord - 一次成功的购买，价格读不到时为 0
+type purchaseRecord struct {
+	Time     time.Time `json:"time"`
+	Name     string    `json:"name"`
+	Price    int       `json:"price"`
+	Discount bool      `json:"discount"`
+	// Source - buy_first / normal / blacklist / fallback，对应点击商品的节点
+	Source string `json:"source"`
+}
+
+var (
+	// summaryTask - purchases 所属的任务，任务变化时重新开始汇总
+	summaryTask int64
+	purchases   []purchaseRecord
+	// pending - 已点击、尚未确认购买成功的商品
+	pending *purchaseRecord
+)
+
+// beginSummary - 每个节点都可能是本次任务的第一次调用，按任务 ID 重置汇总
+func beginSummary(arg *maa.CustomActionArg) {
+	if arg.TaskDetail == nil || arg.TaskDetail.ID == summaryTask {
+		return
+	}
+	summaryTask = arg.TaskDetail.ID
+	purchases = nil
+	pending = nil
+	pricewatch.Reset()
+}
+
+// cardOf - 在购买节点的 And 识别结果中找商品名 OCR 与卡片（NotSoldOut）
+func cardOf(detail *maa.RecognitionDetail) (name string, nameBox, card maa.Rect) {
+	if detail == nil {
+		return "", maa.Rect{}, maa.Rect{}
+	}
+	for _, sub := range detail.CombinedResult {
+		if sub == nil || !sub.Hit {
+			continue
+		}
+		if sub.Name == "NotSoldOut" {
+			card = sub.Box
+		}
+		if part, ok := ocrutil.Text(sub, 0); ok && name == "" {
+			name, nameBox = part.Text, part.Box
+		}
+	}
+	return name, nameBox, card
+}
+
+// capture - 点击前记下商品名、价格与是否打折，均按 CreditShoppingBuyNormal attach 中的子识别推算位置
+func capture(ctx *maa.Context, img image.Image, node, source string) purchaseRecord {
+	record := purchaseRecord{Source: source}
+	var nameBox, card maa.Rect
+	if source == "fallback" {
+		record.Name, nameBox, card = fallbackPicked.Name, fallbackPicked.NameBox, fallbackPicked.Card
+	} else if latest, err := ctx.GetTasker().GetLatestNode(node); err == nil && latest != nil {
+		record.Name, nameBox, card = cardOf(latest.Recognition)
+	}
+
+	plan, err := newFallbackPlan(ctx, nil, nil, false, 0)
+	if err != nil || img == nil {
+		return record
+	}
+	if record.Name == "" && card != (maa.Rect{}) {
+		detail, err := ctx.RunRecognition(blacklistOCRNode, img, map[string]any{
+			blacklistOCRNode: map[string]any{"roi": offsetRect(card, plan.subrecs["BlacklistOCR"]["roi_offset"])},
+		})
+		if err == nil {
+			if part, ok := ocrutil.Text(detail, 0); ok {
+				record.Name, nameBox = part.Text, part.Box
+			}
+		}
+	}
+	if nameBox != (maa.Rect{}) {
+		record.Price, _ = readNumber(ctx, img, priceOCRNode, offsetRect(nameBox, plan.subrecs["Affordable"]["roi_offset"]))
+	}
+	if card != (maa.Rect{}) {
+		_, record.Discount = plan.run(ctx, img, "IsDiscount", card)
+	}
+	return record
+}
+
+// CreditShoppingPickItemAction - 记下要购买的商品后点击它
+// custom_action_param: {"node": "CreditShoppingBuyFirst", "source": "buy_first"}，target 为要点击的商品
+type CreditShoppingPickItemAction struct{}
+
+func (a *CreditShoppingPickItemAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	beginSummary(arg)
+	var params struct {
+		Node   string `json:"node"`
+		Source string `json:"source"`
+	}
+	if err := json.Unmarshal([]byte(arg.CustomActionParam), &params); err != nil {
+		log.Error().Err(err).Msg("Failed to parse CreditShoppingPickItemAction param")
+		return false
+	}
+
+	var img image.Image
+	if controller := ctx.GetTasker().GetController(); controller != nil {
+		img, _ = controller.CacheImage()
+	}
+	record := capture(ctx, img, params.Node, params.Source)
+	pending = &record
+	log.Info().Str("item", record.Name).Int("price", record.Price).Bool("discount", record.Discount).Str("source", record.Source).Msg("Item picked")
+	pricewatch.Check(ctx, []pricewatch.Observation{{Item: record.Name, Shop: "信用商店", Price: record.Price}})
+
+	if _, err := ctx.RunActionDirect(maa.NodeActionTypeClick, maa.NodeClickParam{}, arg.Box, nil); err != nil {
+		log.Error().Err(err).Msg("Failed to click item")
+		return false
+	}
+	return true
+}
+
+// CreditShoppingRecordPurchaseAction - 购买成功后把记下的商品计入汇总，并追加到购买历史
+type CreditShoppingRecordPurchaseAction struct{}
+
+func (a *CreditShoppingRecordPurchaseAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	beginSummary(arg)
+	if pending == nil {
+		return true
+	}
+	record := *pending
+	pending = nil
+	record.Time = time.Now()
+	purchases = append(purchases, record)
+	appendHistory(record)
+	log.Info().Str("item", record.Name).Int("price", record.Price).Msg("Purchase recorded")
+	return true
+}
+
+// appendHistory - 追加写入数据目录下的 creditshopping/history.jsonl，便于之后核对
+func appendHistory(record purchaseRecord) {
+	dir := datadir.Path("creditshopping")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Warn().Err(err).Msg("Failed to create creditshopping data dir")
+		return
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	f, err := os.OpenFile(filepath.Join(dir, "history.jsonl"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to record purchase history")
+		return
+	}
+	defer f.Close()
+	f.Write(append(data, '\n'))
+}
+
+// summaryPayload - 输出给 GUI 的购买汇总
+type summaryPayload struct {
+	Type      string           `json:"type"`
+	Purchases []purchaseRecord `json:"purchases"`
+	Count     int              `json:"count"`
+	Spent     int              `json:"spent"`
+}
+
+// CreditShoppingSummaryAction - 购买结束时汇总本次买了什么，输出提示与 JSON
+type CreditShoppingSummaryAction struct{}
+
+func (a *CreditShoppingSummaryAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	beginSummary(arg)
+	payload := summaryPayload{
+		Type:      "credit_shopping_summary",
+		Purchases: append([]purchaseRecord{}, purchases...),
+		Count:     len(purchases),
+	}
+	var lines []string
+	for _, p := range purchases {
+		payload.Spent += p.Price
+		line := p.Name
+		if line == "" {
+			line = "未识别的商品"
+		}
+		if p.Price > 0 {
+			line += fmt.Sprintf(" %d", p.Price)
+		}
+		if p.Discount {
+			line += "（折扣）"
+		}
+		lines = append(lines, line)
+	}
+	log.Info().Int("count", payload.Count).Int("spent", payload.Spent).Msg("CreditShopping summary")
+
+	if len(lines) == 0 {
+		showMessage(ctx, "🛍️ 本次没有购买商品")
+	} else {
+		showMessage(ctx, fmt.Sprintf("🛍️ 本次购买 %d 件，共花费 %d 信用点\n%s", payload.Count, payload.Spent, strings.Join(lines, "\n")))
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to marshal CreditShopping summary")
+		return true
+	}
+	ctx.RunTask(summaryFocusNode, map[string]interface{}{
+		summaryFocusNode: map[string]interface{}{
+			"recognition": "DirectHit",
+			"action":      "DoNothing",
+			"focus": map[string]interface{}{
+				"Node.Action.Succeeded": string(data),
+			},
+		},
+	})
+	return true
+}
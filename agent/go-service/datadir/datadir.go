@@ -0,0 +1,182 @@
+package datadir
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// layoutVersion - 当前的目录布局版本，布局变化时递增并在 migrations 中追加迁移
+const layoutVersion = 1
+
+// layoutFile - 记录布局版本的文件，位于数据目录根部
+const layoutFile = "layout.json"
+
+var (
+	mu   sync.RWMutex
+	root = filepath.Join(".", "data")
+)
+
+// move - 把旧位置的文件或目录移动到数据目录下的 To
+type move struct {
+	From string
+	To   string
+}
+
+// migrations[i] 把布局从版本 i 升级到 i+1
+var migrations = [][]move{
+	// 0 → 1：初始布局，各模块从一开始就写入数据目录，没有需要移动的旧文件
+	nil,
+}
+
+// Root returns the data directory
+func Root() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return root
+}
+
+// Path returns a path under the data directory; callers create parent directories when writing
+func Path(parts ...string) string {
+	return filepath.Join(append([]string{Root()}, parts...)...)
+}
+
+// Isolate moves the data directory into a subdirectory of the current one, so that a process
+// serving one of several game instances keeps its own data. Legacy files are not migrated there.
+func Isolate(parts ...string) error {
+	dir := Path(parts...)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	mu.Lock()
+	root = dir
+	mu.Unlock()
+	return nil
+}
+
+// Init sets the data directory (empty keeps ./data) and migrates files from older layouts.
+// Call it once at startup, before any module reads or writes persisted files.
+func Init(dir string) error {
+	if dir != "" {
+		mu.Lock()
+		root = dir
+		mu.Unlock()
+	}
+	if err := os.MkdirAll(Root(), 0755); err != nil {
+		return err
+	}
+
+	version := readVersion()
+	if version > layoutVersion {
+		return fmt.Errorf("data dir %s has layout version %d, newer than supported %d", Root(), version, layoutVersion)
+	}
+	for ; version < layoutVersion; version++ {
+		for _, m := range migrations[version] {
+			dst := Path(m.To)
+			moved, err := movePath(m.From, dst)
+			if err != nil {
+				return fmt.Errorf("migrate %s: %w", m.From, err)
+			}
+			if moved {
+				log.Info().Str("from", m.From).Str("to", dst).Msg("Migrated persisted data")
+			}
+		}
+		if err := writeVersion(version + 1); err != nil {
+			return err
+		}
+	}
+	log.Info().Str("dir", Root()).Int("layout", layoutVersion).Msg("Data directory ready")
+	return nil
+}
+
+func readVersion() int {
+	data, err := os.ReadFile(Path(layoutFile))
+	if err != nil {
+		return 0
+	}
+	var layout struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return 0
+	}
+	return layout.Version
+}
+
+func writeVersion(version int) error {
+	data, _ := json.Marshal(map[string]int{"version": version})
+	return os.WriteFile(Path(layoutFile), data, 0644)
+}
+
+// movePath - 目录按文件逐个合并，目标已存在的文件保留不覆盖；源不存在时返回 false
+func movePath(src, dst string) (bool, error) {
+	info, err := os.Stat(src)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !info.IsDir() {
+		return moveFile(src, dst)
+	}
+
+	moved := false
+	err = filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		ok, err := moveFile(path, filepath.Join(dst, rel))
+		moved = moved || ok
+		return err
+	})
+	if err == nil {
+		// 只有清空的目录才会被删除
+		os.Remove(src)
+	}
+	return moved, err
+}
+
+func moveFile(src, dst string) (bool, error) {
+	if _, err := os.Stat(dst); err == nil {
+		log.Warn().Str("from", src).Str("to", dst).Msg("Migration target exists, keeping both")
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return false, err
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return true, nil
+	}
+	// 数据目录可能在其他磁盘上，rename 失败时复制后删除
+	if err := copyFile(src, dst); err != nil {
+		return false, err
+	}
+	return true, os.Remove(src)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
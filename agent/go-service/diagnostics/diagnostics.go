@@ -0,0 +1,117 @@
+package diagnostics
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/rs/zerolog/log"
+)
+
+// 连续多少次采样持续增长才视为泄漏，以及各指标的最小增长量
+const (
+	windowSize          = 6
+	goroutineGrowthWarn = 50
+	heapGrowthFactor    = 2
+	handleGrowthWarn    = 100
+)
+
+type sample struct {
+	goroutines int
+	heapAlloc  uint64
+	// handles - 打开的文件句柄数，平台不支持时为 -1
+	handles int
+}
+
+// Start enables pprof and periodic leak self-checks when diagnostics are enabled in config
+func Start(cfg agentconfig.DiagnosticsConfig) {
+	if !cfg.Enabled {
+		return
+	}
+
+	if cfg.PprofAddr != "" {
+		go servePprof(cfg.PprofAddr)
+	}
+
+	interval := time.Duration(cfg.IntervalSec) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	go selfCheck(interval)
+
+	log.Info().Str("pprof", cfg.PprofAddr).Dur("interval", interval).Msg("Diagnostics enabled")
+}
+
+func servePprof(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Error().Err(err).Str("addr", addr).Msg("pprof server stopped")
+	}
+}
+
+func selfCheck(interval time.Duration) {
+	var window []sample
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s := takeSample()
+		log.Debug().
+			Int("goroutines", s.goroutines).
+			Uint64("heap_alloc", s.heapAlloc).
+			Int("handles", s.handles).
+			Msg("Diagnostics sample")
+
+		window = append(window, s)
+		if len(window) > windowSize {
+			window = window[1:]
+		}
+		if len(window) == windowSize {
+			checkLeak(window)
+		}
+	}
+}
+
+func takeSample() sample {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return sample{
+		goroutines: runtime.NumGoroutine(),
+		heapAlloc:  mem.HeapAlloc,
+		handles:    openHandles(),
+	}
+}
+
+// checkLeak - 窗口内某项指标单调增长且增幅超过阈值时告警
+func checkLeak(window []sample) {
+	first, last := window[0], window[len(window)-1]
+
+	if increasing(window, func(s sample) float64 { return float64(s.goroutines) }) &&
+		last.goroutines-first.goroutines >= goroutineGrowthWarn {
+		log.Warn().Int("from", first.goroutines).Int("to", last.goroutines).Msg("Goroutine count keeps growing, possible leak")
+	}
+	if increasing(window, func(s sample) float64 { return float64(s.heapAlloc) }) &&
+		last.heapAlloc >= first.heapAlloc*heapGrowthFactor {
+		log.Warn().Uint64("from", first.heapAlloc).Uint64("to", last.heapAlloc).Msg("Heap keeps growing, possible leak")
+	}
+	if first.handles >= 0 &&
+		increasing(window, func(s sample) float64 { return float64(s.handles) }) &&
+		last.handles-first.handles >= handleGrowthWarn {
+		log.Warn().Int("from", first.handles).Int("to", last.handles).Msg("Open handle count keeps growing, possible leak")
+	}
+}
+
+func increasing(window []sample, value func(sample) float64) bool {
+	for i := 1; i < len(window); i++ {
+		if value(window[i]) <= value(window[i-1]) {
+			return false
+		}
+	}
+	return true
+}
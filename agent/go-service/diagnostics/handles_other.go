@@ -0,0 +1,15 @@
+//go:build !windows
+
+package diagnostics
+
+import "os"
+
+// openHandles returns the number of open file descriptors
+// Only supported where /proc/self/fd exists (Linux)
+func openHandles() int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	return len(entries)
+}
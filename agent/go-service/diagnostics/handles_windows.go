@@ -0,0 +1,21 @@
+//go:build windows
+
+package diagnostics
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var procGetProcessHandleCount = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetProcessHandleCount")
+
+// openHandles returns the handle count of the current process
+func openHandles() int {
+	var count uint32
+	ret, _, _ := procGetProcessHandleCount.Call(uintptr(windows.CurrentProcess()), uintptr(unsafe.Pointer(&count)))
+	if ret == 0 {
+		return -1
+	}
+	return int(count)
+}
@@ -0,0 +1,70 @@
+package digitstrip
+
+import (
+	"encoding/json"
+	"image"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/ocrutil"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// OCRNode - 读取数字条使用的 OCR 节点，运行时以识别的 roi 覆盖
+const OCRNode = "DigitStripOCR"
+
+// Number - 识别结果中的一个数字，X 为其中心的横坐标
+type Number struct {
+	Value int `json:"value"`
+	X     int `json:"x"`
+}
+
+// Read OCRs the strip within roi and returns its numbers ordered left to right
+func Read(ctx *maa.Context, img image.Image, roi maa.Rect) ([]ocrutil.StripNumber, error) {
+	return ocrutil.Strip(ctx, img, ocrutil.ROIRequest{
+		Pipeline: OCRNode,
+		Override: map[string]any{OCRNode: map[string]any{"roi": roi}},
+	})
+}
+
+// DigitStripRecognition - 一次 OCR 读取横向条带中的多个数字（如一整行价格），数量达到 min_count 即命中
+// custom_recognition_param: {"min_count": 3}
+// Detail 为 [{"value": 数字, "x": 中心横坐标}]，按从左到右排列
+type DigitStripRecognition struct{}
+
+func (r *DigitStripRecognition) Run(ctx *maa.Context, arg *maa.CustomRecognitionArg) (*maa.CustomRecognitionResult, bool) {
+	params := struct {
+		MinCount int `json:"min_count"`
+	}{MinCount: 1}
+	if arg.CustomRecognitionParam != "" {
+		if err := json.Unmarshal([]byte(arg.CustomRecognitionParam), &params); err != nil {
+			log.Error().Err(err).Msg("Failed to parse DigitStripRecognition param")
+			return nil, false
+		}
+	}
+
+	numbers, err := Read(ctx, arg.Img, arg.Roi)
+	if err != nil {
+		log.Error().Err(err).Msg("Digit strip recognition failed")
+		return nil, false
+	}
+	if len(numbers) == 0 || len(numbers) < params.MinCount {
+		log.Debug().Int("count", len(numbers)).Int("min_count", params.MinCount).Msg("Digit strip has too few numbers")
+		return nil, false
+	}
+
+	out := make([]Number, 0, len(numbers))
+	box := numbers[0].Box
+	for _, n := range numbers {
+		out = append(out, Number{Value: n.Value, X: n.CenterX()})
+		box = union(box, n.Box)
+	}
+	detail, _ := json.Marshal(out)
+	return &maa.CustomRecognitionResult{Box: box, Detail: string(detail)}, true
+}
+
+func union(a, b maa.Rect) maa.Rect {
+	x0, y0 := min(a.X(), b.X()), min(a.Y(), b.Y())
+	x1 := max(a.X()+a.Width(), b.X()+b.Width())
+	y1 := max(a.Y()+a.Height(), b.Y()+b.Height())
+	return maa.Rect{x0, y0, x1 - x0, y1 - y0}
+}
@@ -0,0 +1,19 @@
+package digitstrip
+
+import (
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+var (
+	_ maa.CustomRecognitionRunner = &DigitStripRecognition{}
+)
+
+// Components returns the custom components of digitstrip package
+func Components() []registry.Component {
+	return []registry.Component{
+		registry.Recognition("DigitStripRecognition", &DigitStripRecognition{}, "一次 OCR 读取横向条带中的多个数字，数量达到 min_count 即命中",
+			registry.P("min_count", "int", "至少识别到的数字个数，默认 1"),
+		),
+	}
+}
@@ -0,0 +1,166 @@
+// Package dispatch runs MaaEnd tasks on several game instances (emulators or windows) from one agent.
+//
+// Each instance runs in its own child process: task packages keep per-run state in package
+// variables, so two instances in one process would overwrite each other's state.
+package dispatch
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unsafe"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/MaaXYZ/maa-framework-go/v4/controller/adb"
+	"github.com/MaaXYZ/maa-framework-go/v4/controller/win32"
+)
+
+// validName - 实例名会作为目录名，只允许字母、数字、下划线与连字符
+var validName = regexp.MustCompile(`^[0-9A-Za-z_-]+$`)
+
+// Validate checks the configured instances before anything is started
+func Validate(instances []agentconfig.InstanceConfig) error {
+	if len(instances) == 0 {
+		return fmt.Errorf("no instances configured")
+	}
+	seen := map[string]bool{}
+	for i, inst := range instances {
+		if !validName.MatchString(inst.Name) {
+			return fmt.Errorf("instances[%d]: invalid name %q", i, inst.Name)
+		}
+		if seen[inst.Name] {
+			return fmt.Errorf("instances[%d]: duplicate name %q", i, inst.Name)
+		}
+		seen[inst.Name] = true
+		if (inst.Adb == "") == (inst.Window == "") {
+			return fmt.Errorf("instance %s: set exactly one of adb and window", inst.Name)
+		}
+	}
+	return nil
+}
+
+// Find returns the instance with the given name
+func Find(instances []agentconfig.InstanceConfig, name string) (agentconfig.InstanceConfig, bool) {
+	for _, inst := range instances {
+		if inst.Name == name {
+			return inst, true
+		}
+	}
+	return agentconfig.InstanceConfig{}, false
+}
+
+// resourcePaths - 实例加载的资源目录，相对路径基于 base
+func resourcePaths(inst agentconfig.InstanceConfig, base string) []string {
+	paths := inst.Resources
+	if len(paths) == 0 {
+		paths = []string{"resource"}
+		if inst.Adb != "" {
+			paths = append(paths, "resource_adb")
+		}
+	}
+	resolved := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(base, p)
+		}
+		resolved = append(resolved, p)
+	}
+	return resolved
+}
+
+// Connect loads the instance's resources, connects its controller and returns a tasker bound to both
+func Connect(inst agentconfig.InstanceConfig, base string) (*maa.Tasker, error) {
+	res, err := maa.NewResource()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range resourcePaths(inst, base) {
+		if !res.PostBundle(path).Wait().Success() {
+			return nil, fmt.Errorf("load resource %s failed", path)
+		}
+	}
+
+	ctrl, err := newController(inst)
+	if err != nil {
+		return nil, err
+	}
+	if !ctrl.PostConnect().Wait().Success() {
+		return nil, fmt.Errorf("instance %s: connect failed", inst.Name)
+	}
+
+	tasker, err := maa.NewTasker()
+	if err != nil {
+		return nil, err
+	}
+	if err := tasker.BindResource(res); err != nil {
+		return nil, err
+	}
+	if err := tasker.BindController(ctrl); err != nil {
+		return nil, err
+	}
+	return tasker, nil
+}
+
+func newController(inst agentconfig.InstanceConfig) (*maa.Controller, error) {
+	if inst.Adb != "" {
+		return newAdbController(inst)
+	}
+	return newWin32Controller(inst)
+}
+
+// newAdbController - 优先使用自动发现到的同地址设备的截图、输入方式与配置
+func newAdbController(inst agentconfig.InstanceConfig) (*maa.Controller, error) {
+	adbPath := inst.AdbPath
+	screencap, input, config := adb.ScreencapDefault, adb.InputDefault, "{}"
+
+	var devices []*maa.AdbDevice
+	if adbPath != "" {
+		devices, _ = maa.FindAdbDevices(adbPath)
+	} else {
+		devices, _ = maa.FindAdbDevices()
+	}
+	for _, d := range devices {
+		if d.Address != inst.Adb {
+			continue
+		}
+		if adbPath == "" {
+			adbPath = d.AdbPath
+		}
+		screencap, input, config = d.ScreencapMethod, d.InputMethod, d.Config
+		break
+	}
+	if adbPath == "" {
+		adbPath = "adb"
+	}
+	return maa.NewAdbController(adbPath, inst.Adb, screencap, input, config, "")
+}
+
+// newWin32Controller - 按标题查找游戏窗口，与界面中的 Win32 控制器使用相同的输入方式
+func newWin32Controller(inst agentconfig.InstanceConfig) (*maa.Controller, error) {
+	windows, err := maa.FindDesktopWindows()
+	if err != nil {
+		return nil, err
+	}
+	var handle unsafe.Pointer
+	for _, w := range windows {
+		if strings.Contains(w.WindowName, inst.Window) {
+			if handle != nil {
+				return nil, fmt.Errorf("instance %s: more than one window titled %q", inst.Name, inst.Window)
+			}
+			handle = w.Handle
+		}
+	}
+	if handle == nil {
+		return nil, fmt.Errorf("instance %s: no window titled %q", inst.Name, inst.Window)
+	}
+
+	screencap := win32.ScreencapFramePool
+	if inst.Screencap != "" {
+		if screencap, err = win32.ParseScreencapMethod(inst.Screencap); err != nil {
+			return nil, fmt.Errorf("instance %s: %w", inst.Name, err)
+		}
+	}
+	return maa.NewWin32Controller(handle, screencap, win32.InputSendMessageWithCursorPos, win32.InputSendMessageWithCursorPos)
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/dispatch"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/maaend"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+// dispatchTasks - dispatch 可以运行的任务，均使用与界面任务相同的默认选项
+var dispatchTasks = map[string]func(*maaend.Client) (*maaend.Result, error){
+	"resell": func(c *maaend.Client) (*maaend.Result, error) {
+		return c.Resell(maaend.DefaultResellOptions())
+	},
+	"credit-shopping": func(c *maaend.Client) (*maaend.Result, error) {
+		return c.CreditShopping(maaend.DefaultCreditShoppingOptions())
+	},
+}
+
+// runDispatch - go-service dispatch -task resell [-parallel] [-only a,b]
+// 对配置中的每个实例运行任务，每个实例一个子进程（go-service dispatch -task resell -instance a），
+// 数据目录为 data/instances/<name>；默认逐个运行，-parallel 同时运行
+func runDispatch(args []string) error {
+	fs := flag.NewFlagSet("dispatch", flag.ContinueOnError)
+	task := fs.String("task", "", "task to run: resell or credit-shopping")
+	parallel := fs.Bool("parallel", false, "run all instances at the same time")
+	only := fs.String("only", "", "comma separated instance names, defaults to all")
+	instance := fs.String("instance", "", "run a single instance in this process")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if _, ok := dispatchTasks[*task]; !ok {
+		fs.Usage()
+		return fmt.Errorf("-task must be resell or credit-shopping")
+	}
+
+	cfg, err := agentconfig.Load(filepath.Join(getCwd(), "config", "go-service.json"))
+	if err != nil {
+		return err
+	}
+	if err := dispatch.Validate(cfg.Instances); err != nil {
+		return err
+	}
+	if *instance != "" {
+		return runInstance(cfg, *instance, *task)
+	}
+
+	instances := cfg.Instances
+	if *only != "" {
+		instances = nil
+		for _, name := range strings.Split(*only, ",") {
+			inst, ok := dispatch.Find(cfg.Instances, strings.TrimSpace(name))
+			if !ok {
+				return fmt.Errorf("instance %q is not configured", name)
+			}
+			instances = append(instances, inst)
+		}
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	errs := make([]error, len(instances))
+	var wg sync.WaitGroup
+	for i, inst := range instances {
+		cmd := exec.Command(exe, "dispatch", "-task", *task, "-instance", inst.Name)
+		run := func() {
+			errs[i] = runPrefixed(cmd, "["+inst.Name+"] ")
+		}
+		if !*parallel {
+			run()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run()
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for i, inst := range instances {
+		if errs[i] != nil {
+			failed++
+			fmt.Printf("✗ %s: %v\n", inst.Name, errs[i])
+		} else {
+			fmt.Printf("✓ %s\n", inst.Name)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d instances failed", failed, len(instances))
+	}
+	return nil
+}
+
+// runPrefixed runs cmd and copies its output line by line, prefixed with the instance name
+func runPrefixed(cmd *exec.Cmd, prefix string) error {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	cmd.Stderr = cmd.Stdout
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	copyLines(stdout, os.Stdout, prefix)
+	return cmd.Wait()
+}
+
+var stdoutMu sync.Mutex
+
+func copyLines(r io.Reader, w io.Writer, prefix string) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		stdoutMu.Lock()
+		fmt.Fprintln(w, prefix+scanner.Text())
+		stdoutMu.Unlock()
+	}
+}
+
+// runInstance - 在当前进程中连接一个实例并运行任务
+func runInstance(cfg agentconfig.Config, name, task string) error {
+	inst, ok := dispatch.Find(cfg.Instances, name)
+	if !ok {
+		return fmt.Errorf("instance %q is not configured", name)
+	}
+	// 各模块通过 agentconfig.Get() 读取配置，需在创建 client 前装入
+	agentconfig.Set(cfg)
+
+	if err := maa.Init(maa.WithLibDir(filepath.Join(getCwd(), "maafw"))); err != nil {
+		return err
+	}
+	defer maa.Release()
+	if err := maa.ConfigInitOption(getCwd(), "{}"); err != nil {
+		return err
+	}
+	if err := datadir.Init(cfg.DataDir); err != nil {
+		return err
+	}
+	if err := datadir.Isolate("instances", inst.Name); err != nil {
+		return err
+	}
+
+	tasker, err := dispatch.Connect(inst, getCwd())
+	if err != nil {
+		return err
+	}
+	defer tasker.Destroy()
+	client, err := maaend.NewClient(tasker)
+	if err != nil {
+		return err
+	}
+	result, err := dispatchTasks[task](client)
+	if err != nil {
+		return err
+	}
+	if result.Outcome != nil {
+		if data, err := json.Marshal(result.Outcome); err == nil {
+			fmt.Println(string(data))
+		}
+	}
+	if !result.Success {
+		return fmt.Errorf("%s failed", result.Entry)
+	}
+	return nil
+}
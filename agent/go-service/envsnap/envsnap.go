@@ -0,0 +1,202 @@
+// Package envsnap records the environment of each run when its task starts: controller and
+// resolution, agent, framework and resource versions, the detected theme profile and client
+// language, and the config values that change behaviour. Crash reports and safe-mode bundles embed
+// the snapshot, so a report submitted by a user describes the setup it came from.
+package envsnap
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/clientlang"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/jsonc"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/moduleinfo"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/respack"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/seed"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/theme"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// 控制器类型：能执行 shell 的是 ADB，其余为 Win32 或 PlayCover 等桌面控制器
+const (
+	ControllerAdb     = "adb"
+	ControllerDesktop = "desktop"
+)
+
+// Snapshot - 一次运行开始时的环境
+type Snapshot struct {
+	Time  time.Time `json:"time"`
+	Entry string    `json:"entry"`
+	Seed  int64     `json:"seed"`
+
+	Controller string `json:"controller"`
+	// Resolution - 控制器报告的分辨率 [宽, 高]，读不到时为 0
+	Resolution [2]int `json:"resolution"`
+
+	Agent     string `json:"agent"`
+	Framework string `json:"framework"`
+	// Resource - interface.json 中的资源版本；ResourceHash 为已加载资源（含资源包）的哈希
+	Resource     string `json:"resource"`
+	ResourceHash string `json:"resource_hash"`
+	ResourcePack string `json:"resource_pack,omitempty"`
+	// Modules - 各模块的版本
+	Modules map[string]string `json:"modules"`
+
+	// Theme、ClientLanguage - 最近一次识别到的界面主题与客户端语言，模块初始化时才会重新识别，
+	// 所以这里是上一次运行的结果，Agent 启动后的第一次运行为空
+	Theme          string `json:"theme"`
+	ClientLanguage string `json:"client_language"`
+
+	Config Config `json:"config"`
+}
+
+// Config - 影响行为的配置项，不含地址、密钥等敏感配置
+type Config struct {
+	FixedSeed        bool                            `json:"fixed_seed"`
+	ResourcePack     string                          `json:"resource_pack"`
+	Verbosity        string                          `json:"verbosity"`
+	StuckThreshold   int                             `json:"stuck_threshold"`
+	SafeModeAfter    int                             `json:"safe_mode_after_failures"`
+	Foreground       bool                            `json:"foreground"`
+	Resell           agentconfig.ResellConfig        `json:"resell"`
+	OCRAccurateModel string                          `json:"ocr_accurate_model"`
+	OCRChains        map[string]agentconfig.OCRChain `json:"ocr_chains"`
+	Sync             string                          `json:"sync"`
+	Webhooks         int                             `json:"webhooks"`
+	Instances        int                             `json:"instances"`
+}
+
+var (
+	mu      sync.Mutex
+	current *Snapshot
+	// controllers - 按控制器 UUID 缓存的类型，同一控制器只探测一次
+	controllers = map[string]string{}
+)
+
+// Current returns the snapshot of the current run; false before the first task starts
+func Current() (Snapshot, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	if current == nil {
+		return Snapshot{}, false
+	}
+	return *current, true
+}
+
+// Capture records the environment of a run starting at entry
+func Capture(tasker *maa.Tasker, entry string) Snapshot {
+	s := Snapshot{
+		Time:           time.Now(),
+		Entry:          entry,
+		Seed:           seed.Current(),
+		Agent:          moduleinfo.AgentVersion,
+		Framework:      maa.Version(),
+		Resource:       resourceVersion(),
+		Modules:        map[string]string{},
+		Theme:          theme.Current(),
+		ClientLanguage: clientlang.Current(),
+		Config:         configValues(agentconfig.Get()),
+	}
+	for _, m := range moduleinfo.All() {
+		s.Modules[m.Name] = m.Version
+	}
+	if pack, ok := respack.Active(); ok {
+		s.ResourcePack = pack.Label()
+	}
+	if res := tasker.GetResource(); res != nil {
+		s.ResourceHash, _ = res.GetHash()
+	}
+	if controller := tasker.GetController(); controller != nil {
+		if w, h, err := controller.GetResolution(); err == nil {
+			s.Resolution = [2]int{int(w), int(h)}
+		}
+		s.Controller = controllerKind(controller)
+	}
+
+	mu.Lock()
+	current = &s
+	mu.Unlock()
+	return s
+}
+
+func configValues(cfg agentconfig.Config) Config {
+	return Config{
+		FixedSeed:        cfg.Seed != 0,
+		ResourcePack:     cfg.ResourcePack,
+		Verbosity:        cfg.Focus.Verbosity,
+		StuckThreshold:   cfg.StuckCheck.Threshold,
+		SafeModeAfter:    cfg.SafeMode.AfterFailures,
+		Foreground:       cfg.Foreground.Enabled,
+		Resell:           cfg.Resell,
+		OCRAccurateModel: cfg.OCR.AccurateModel,
+		OCRChains:        cfg.OCR.Chains,
+		Sync:             cfg.Sync.Kind,
+		Webhooks:         len(cfg.Webhooks),
+		Instances:        len(cfg.Instances),
+	}
+}
+
+// controllerKind probes whether the controller runs shell commands, as only ADB controllers do
+func controllerKind(controller *maa.Controller) string {
+	uuid, _ := controller.GetUUID()
+	mu.Lock()
+	kind, ok := controllers[uuid]
+	mu.Unlock()
+	if ok {
+		return kind
+	}
+
+	kind = ControllerDesktop
+	if controller.PostShell("echo", 2*time.Second).Wait().Success() {
+		kind = ControllerAdb
+	}
+	if uuid != "" {
+		mu.Lock()
+		controllers[uuid] = kind
+		mu.Unlock()
+	}
+	return kind
+}
+
+// resourceVersion reads the version field of interface.json in the working directory
+func resourceVersion() string {
+	data, err := os.ReadFile(filepath.Join(".", "interface.json"))
+	if err != nil {
+		return ""
+	}
+	var iface struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(jsonc.Strip(data), &iface); err != nil {
+		return ""
+	}
+	return iface.Version
+}
+
+// Recorder captures a snapshot when each task starts
+type Recorder struct{}
+
+// OnTaskerTask handles tasker task events
+func (r *Recorder) OnTaskerTask(tasker *maa.Tasker, event maa.EventStatus, detail maa.TaskerTaskDetail) {
+	if event != maa.EventStatusStarting {
+		return
+	}
+	s := Capture(tasker, detail.Entry)
+	log.Info().
+		Str("entry", s.Entry).
+		Str("controller", s.Controller).
+		Ints("resolution", s.Resolution[:]).
+		Str("agent", s.Agent).
+		Str("framework", s.Framework).
+		Str("resource", s.Resource).
+		Str("resource_pack", s.ResourcePack).
+		Str("theme", s.Theme).
+		Str("client_language", s.ClientLanguage).
+		Interface("config", s.Config).
+		Msg("Run environment")
+}
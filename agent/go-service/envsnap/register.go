@@ -0,0 +1,12 @@
+package envsnap
+
+import "github.com/MaaXYZ/maa-framework-go/v4"
+
+var (
+	_ maa.TaskerEventSink = &Recorder{}
+)
+
+// Register registers the recorder that snapshots the run environment when each task starts
+func Register() {
+	maa.AgentServerAddTaskerSink(&Recorder{})
+}
@@ -4,11 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
-	"regexp"
 	"sort"
-	"strconv"
 	"strings"
 
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/clientlang"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/focus"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/ocrutil"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/overridesnap"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/taskresult"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/theme"
 	maa "github.com/MaaXYZ/maa-framework-go/v4"
 	"github.com/rs/zerolog/log"
 )
@@ -30,6 +34,22 @@ func LogMXUHTML(ctx *maa.Context, htmlText string) bool {
 	return LogMXU(ctx, htmlText)
 }
 
+// LogMXUHTMLAt logs HTML only when the focus verbosity allows the level.
+func LogMXUHTMLAt(ctx *maa.Context, level focus.Level, htmlText string) bool {
+	if !focus.Allowed(level) {
+		return true
+	}
+	return LogMXUHTML(ctx, htmlText)
+}
+
+// LogMXUSimpleHTMLAt logs a simple styled span only when the focus verbosity allows the level.
+func LogMXUSimpleHTMLAt(ctx *maa.Context, level focus.Level, text string) bool {
+	if !focus.Allowed(level) {
+		return true
+	}
+	return LogMXUSimpleHTML(ctx, text)
+}
+
 // LogMXUSimpleHTMLWithColor logs a simple styled span, allowing a custom color.
 func LogMXUSimpleHTMLWithColor(ctx *maa.Context, text string, color string) bool {
 	HTMLTemplate := fmt.Sprintf(`<span style="color: %s; font-weight: 500;">%%s</span>`, color)
@@ -47,6 +67,15 @@ type EssenceFilterInitAction struct{}
 
 func (a *EssenceFilterInitAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
 	log.Info().Msg("<EssenceFilter> ========== Init ==========")
+	overridesnap.Reset(ctx, "EssenceFilter",
+		"OCREssenceInventoryNumber",
+		"EssenceRowDetect",
+		"EssenceDetectFinal",
+		"EssenceFilterSkillDecision",
+		"EssenceFilterRowNextItem",
+	)
+	theme.Apply(ctx)
+	clientlang.Apply(ctx)
 
 	base := getResourceBase()
 	if base == "" {
@@ -59,6 +88,10 @@ func (a *EssenceFilterInitAction) Run(ctx *maa.Context, arg *maa.CustomActionArg
 	matcherConfigPath := filepath.Join(gameDataDir, "matcher_config.json")
 	var params struct {
 		PresetName string `json:"preset_name"`
+		// Skills、ExcludeSkills - 分号分隔的技能名，追加到预设的技能条件中
+		Skills        string `json:"skills"`
+		ExcludeSkills string `json:"exclude_skills"`
+		SkillMatch    string `json:"skill_match"`
 	}
 	if err := json.Unmarshal([]byte(arg.CustomActionParam), &params); err != nil {
 		log.Error().Err(err).Msg("<EssenceFilter> Step1 failed: param parse")
@@ -78,7 +111,7 @@ func (a *EssenceFilterInitAction) Run(ctx *maa.Context, arg *maa.CustomActionArg
 		log.Error().Err(err).Msg("<EssenceFilter> Step3 failed: load DB")
 		return false
 	}
-	LogMXUSimpleHTML(ctx, "武器数据加载完成")
+	LogMXUSimpleHTMLAt(ctx, focus.Step, "武器数据加载完成")
 	logSkillPools()
 
 	// 4. load presets
@@ -101,16 +134,31 @@ func (a *EssenceFilterInitAction) Run(ctx *maa.Context, arg *maa.CustomActionArg
 		return false
 	}
 
-	LogMXUSimpleHTML(ctx, fmt.Sprintf("已选择预设：%s", selectedPreset.Label))
+	LogMXUSimpleHTMLAt(ctx, focus.Step, fmt.Sprintf("已选择预设：%s", selectedPreset.Label))
 	// 6. filter weapons
-	filteredWeapons := FilterWeaponsByConfig(selectedPreset.Filter)
+	filter := selectedPreset.Filter
+	filter.Skills = append(append([]SkillRule(nil), filter.Skills...), ParseSkillNames(params.Skills)...)
+	filter.ExcludeSkills = append(append([]SkillRule(nil), filter.ExcludeSkills...), ParseSkillNames(params.ExcludeSkills)...)
+	if params.SkillMatch != "" {
+		filter.SkillMatch = params.SkillMatch
+	}
+	if len(filter.Skills) > 0 || len(filter.ExcludeSkills) > 0 {
+		log.Info().
+			Interface("skills", filter.Skills).
+			Str("skill_match", filter.SkillMatch).
+			Interface("exclude_skills", filter.ExcludeSkills).
+			Msg("<EssenceFilter> Step6 skill filter")
+	}
+	activeFilter = filter
+	inventory = nil
+	filteredWeapons := FilterWeaponsByConfig(filter)
 	names := make([]string, 0, len(filteredWeapons))
 	for _, w := range filteredWeapons {
 		names = append(names, w.ChineseName)
 	}
 	log.Info().Int("filtered_count", len(filteredWeapons)).Strs("weapons", names).Msg("<EssenceFilter> Step6 ok")
 	buildFilteredSkillStats(filteredWeapons)
-	LogMXUSimpleHTML(ctx, fmt.Sprintf("符合条件的武器数量：%d", len(filteredWeapons)))
+	LogMXUSimpleHTMLAt(ctx, focus.Step, fmt.Sprintf("符合条件的武器数量：%d", len(filteredWeapons)))
 	// Construct weapon list in HTML to show
 	sort.Slice(filteredWeapons, func(i, j int) bool {
 		return filteredWeapons[i].Rarity > filteredWeapons[j].Rarity
@@ -129,7 +177,7 @@ func (a *EssenceFilterInitAction) Run(ctx *maa.Context, arg *maa.CustomActionArg
 		}
 	}
 	builder.WriteString("</table>")
-	LogMXUHTML(ctx, builder.String())
+	LogMXUHTMLAt(ctx, focus.Step, builder.String())
 
 	// 7. extract combos
 	targetSkillCombinations = ExtractSkillCombinations(filteredWeapons)
@@ -164,11 +212,11 @@ func (a *EssenceFilterInitAction) Run(ctx *maa.Context, arg *maa.CustomActionArg
 			uniqueIds[id] = struct{}{}
 		}
 
-		// getPoolBySlot is defined in filter.go, skillNameByID is in loader.go
-		pool := getPoolBySlot(i + 1)
 		skillNames := make([]string, 0, len(uniqueIds))
 		for id := range uniqueIds {
-			skillNames = append(skillNames, skillNameByID(id, pool))
+			if name, ok := SkillNameByID(i+1, id); ok {
+				skillNames = append(skillNames, name)
+			}
 		}
 		sort.Strings(skillNames)
 
@@ -193,7 +241,7 @@ func (a *EssenceFilterInitAction) Run(ctx *maa.Context, arg *maa.CustomActionArg
 		}
 		skillBuilder.WriteString("</table>")
 	}
-	LogMXUHTML(ctx, skillBuilder.String())
+	LogMXUHTMLAt(ctx, focus.Step, skillBuilder.String())
 
 	return true
 }
@@ -203,34 +251,24 @@ type OCREssenceInventoryNumberAction struct{}
 func (a *OCREssenceInventoryNumberAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
 	const maxSinglePage = 45 // 单页可见格子上限：9列×5行，可按需要调整
 
-	if arg.RecognitionDetail == nil || arg.RecognitionDetail.Results == nil || len(arg.RecognitionDetail.Results.Filtered) == 0 {
-		log.Error().Msg("<EssenceFilter> CheckTotal: no OCR detail")
-		return false
-	}
-	ocr, _ := arg.RecognitionDetail.Results.Filtered[0].AsOCR()
+	ocr, ok := ocrutil.Text(arg.RecognitionDetail, 0)
 	text := strings.TrimSpace(ocr.Text)
-	if text == "" {
-		log.Error().Msg("<EssenceFilter> CheckTotal: empty text")
+	if !ok || text == "" {
+		log.Error().Msg("<EssenceFilter> CheckTotal: no OCR text")
 		return false
 	}
 
 	// 提取数字：若是 “cur/total” 取 total，否则取第一个数字
-	re := regexp.MustCompile(`\d+`)
-	nums := re.FindAllString(text, -1)
+	nums := ocrutil.Numbers(text)
 	if len(nums) == 0 {
 		log.Error().Str("text", text).Msg("<EssenceFilter> CheckTotal: no number found")
 		return false
 	}
-	nStr := nums[len(nums)-1] // 优先取 total；若只有一个数字就取它
-	n, err := strconv.Atoi(nStr)
-	if err != nil {
-		log.Error().Err(err).Str("text", text).Msg("<EssenceFilter> CheckTotal: parse fail")
-		return false
-	}
+	n := nums[len(nums)-1] // 优先取 total；若只有一个数字就取它
 
 	log.Info().Int("count", n).Int("max_single_page", maxSinglePage).Str("raw", text).
 		Msg("<EssenceFilter> CheckTotal: parsed")
-	LogMXUSimpleHTML(ctx, fmt.Sprintf("库存中共 <span style=\"color: #ff7000; font-weight: 900;\">%d</span> 个基质", n))
+	LogMXUSimpleHTMLAt(ctx, focus.Step, fmt.Sprintf("库存中共 <span style=\"color: #ff7000; font-weight: 900;\">%d</span> 个基质", n))
 
 	if n <= maxSinglePage {
 		ctx.OverrideNext(arg.CurrentTaskName, []maa.NodeNextItem{
@@ -421,7 +459,7 @@ func (a *EssenceFilterRowNextItemAction) Run(ctx *maa.Context, arg *maa.CustomAc
 				nextSwipe = "EssenceFilterSwipeNext"
 			}
 
-			LogMXUSimpleHTML(ctx, fmt.Sprintf("滑动到第 %d 行", currentRow+1))
+			LogMXUSimpleHTMLAt(ctx, focus.Detail, fmt.Sprintf("滑动到第 %d 行", currentRow+1))
 			currentRow++
 
 			ctx.OverrideNext(arg.CurrentTaskName, []maa.NodeNextItem{
@@ -472,20 +510,40 @@ func (a *EssenceFilterSkillDecisionAction) Run(ctx *maa.Context, arg *maa.Custom
 		MatchedMessageColor = "#064d7c"
 	}
 
-	LogMXUSimpleHTMLWithColor(ctx, fmt.Sprintf("OCR到技能：%s | %s | %s", skills[0], skills[1], skills[2]), MatchedMessageColor)
+	if focus.Allowed(focus.Detail) {
+		LogMXUSimpleHTMLWithColor(ctx, fmt.Sprintf("OCR到技能：%s | %s | %s", skills[0], skills[1], skills[2]), MatchedMessageColor)
+	}
+	if matched {
+		level, breakthrough := 0, -1
+		if NeedsLevelInfo(activeFilter) {
+			level, breakthrough = readLevelInfo(ctx)
+		}
+		if !PassesLevelFilter(activeFilter, level, breakthrough) {
+			log.Info().Str("weapon", combination.Weapon.ChineseName).Int("level", level).Int("breakthrough", breakthrough).Msg("<EssenceFilter> level filter not passed, skip")
+			LogMXUSimpleHTMLAt(ctx, focus.Detail, fmt.Sprintf("%s 等级/突破不满足条件，跳过该物品", combination.Weapon.ChineseName))
+			matched = false
+		} else {
+			weapon := combination.Weapon
+			weapon.Level = level
+			weapon.Breakthrough = breakthrough
+			inventory = append(inventory, InventoryItem{Weapon: weapon, Row: currentRow, Col: currentCol})
+		}
+	}
 	if matched {
 		matchedCount++
 		log.Info().Str("weapon", combination.Weapon.ChineseName).Strs("skills", skills).Ints("skill_ids", combination.SkillIDs).Int("matched_count", matchedCount).Msg("<EssenceFilter> match ok, lock next")
 		weaponcolor := getColorForRarity(combination.Weapon.Rarity)
 		MatchedMessage := fmt.Sprintf(`<div style="color: #064d7c; font-weight: 900;">匹配到武器：<span style="color: %s;">%s</span></div>`, weaponcolor, combination.Weapon.ChineseName)
-		LogMXUHTML(ctx, MatchedMessage)
+		LogMXUHTMLAt(ctx, focus.Step, MatchedMessage)
 
 		ctx.OverrideNext(arg.CurrentTaskName, []maa.NodeNextItem{
 			{Name: "EssenceFilterLockItemLog"},
 		})
 	} else {
-		log.Info().Strs("skills", skills).Msg("<EssenceFilter> not matched, skip to next item")
-		LogMXUSimpleHTML(ctx, "未匹配到目标技能组合，跳过该物品")
+		if combination == nil {
+			log.Info().Strs("skills", skills).Msg("<EssenceFilter> not matched, skip to next item")
+			LogMXUSimpleHTMLAt(ctx, focus.Detail, "未匹配到目标技能组合，跳过该物品")
+		}
 		ctx.OverrideNext(arg.CurrentTaskName, []maa.NodeNextItem{
 			{Name: "EssenceFilterRowNextItem"},
 		})
@@ -504,8 +562,24 @@ func (a *EssenceFilterFinishAction) Run(ctx *maa.Context, arg *maa.CustomActionA
 	log.Info().Int("matched_total", matchedCount).Msg("<EssenceFilter> locked items")
 
 	LogMXUSimpleHTMLWithColor(ctx, fmt.Sprintf("筛选完成！共历遍物品：%d，确认锁定物品：%d", visitedCount, matchedCount), "#11cf00")
+	dismantle := logDismantleCandidates(ctx)
+	if err := SaveInventory(inventoryPath(), inventory); err != nil {
+		log.Warn().Err(err).Msg("<EssenceFilter> save inventory snapshot failed")
+	}
+	taskresult.Emit(ctx, taskresult.Result{
+		Task:   "EssenceFilter",
+		Status: taskresult.StatusSuccess,
+		Metrics: map[string]float64{
+			"visited":   float64(visitedCount),
+			"locked":    float64(matchedCount),
+			"dismantle": float64(len(dismantle)),
+		},
+		Decisions: dismantle,
+	})
 
 	targetSkillCombinations = nil
+	activeFilter = FilterConfig{}
+	inventory = nil
 	matchedCount = 0
 	visitedCount = 0
 	for i := range filteredSkillStats {
@@ -522,6 +596,34 @@ func (a *EssenceFilterFinishAction) Run(ctx *maa.Context, arg *maa.CustomActionA
 	return true
 }
 
+// logDismantleCandidates - 按 KeepDuplicates 列出超出保留数量的物品，返回对应的分解决策
+func logDismantleCandidates(ctx *maa.Context) []taskresult.Decision {
+	limit := KeepLimit(activeFilter)
+	if limit == 0 {
+		return nil
+	}
+	_, dismantle := SplitKeepDuplicates(inventory, limit)
+	if len(dismantle) == 0 {
+		return nil
+	}
+
+	decisions := make([]taskresult.Decision, 0, len(dismantle))
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(`<div style="color: #ff7000;">每种武器保留 %d 个，以下重复物品可分解：</div>`, limit))
+	for _, item := range dismantle {
+		log.Info().Str("weapon", item.Weapon.ChineseName).Int("row", item.Row).Int("col", item.Col).Int("breakthrough", item.Weapon.Breakthrough).Int("level", item.Weapon.Level).Msg("<EssenceFilter> dismantle candidate")
+		builder.WriteString(fmt.Sprintf(`<div style="font-size: 11px;">%s（第%d行第%d列，突破%d，等级%d）</div>`,
+			item.Weapon.ChineseName, item.Row, item.Col, item.Weapon.Breakthrough, item.Weapon.Level))
+		decisions = append(decisions, taskresult.Decision{
+			Action: "dismantle",
+			Target: item.Weapon.ChineseName,
+			Reason: fmt.Sprintf("row %d col %d exceeds keep_duplicates=%d", item.Row, item.Col, limit),
+		})
+	}
+	LogMXUHTML(ctx, builder.String())
+	return decisions
+}
+
 // EssenceFilterTraceAction - log node/step
 type EssenceFilterTraceAction struct{}
 
@@ -569,14 +671,13 @@ func buildFilteredSkillStats(filtered []WeaponData) {
 func logFilteredSkillStats() {
 	for slotIdx, stat := range filteredSkillStats {
 		slot := slotIdx + 1
-		pool := getPoolBySlot(slot)
 		ids := make([]int, 0, len(stat))
 		for id := range stat {
 			ids = append(ids, id)
 		}
 		sort.Ints(ids)
 		for _, id := range ids {
-			name := skillNameByID(id, pool)
+			name, _ := SkillNameByID(slot, id)
 			log.Info().Int("slot", slot).Int("skill_id", id).Str("skill", name).Int("count", stat[id]).Msg("<EssenceFilter> FilteredSkillStats")
 		}
 	}
@@ -1,5 +1,17 @@
 package essencefilter
 
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Values of FilterConfig.SkillMatch
+const (
+	SkillMatchAll = "all"
+	SkillMatchAny = "any"
+)
+
 // FilterWeaponsByConfig - 根据配置过滤武器
 func FilterWeaponsByConfig(config FilterConfig) []WeaponData {
 	result := []WeaponData{}
@@ -27,12 +39,86 @@ func FilterWeaponsByConfig(config FilterConfig) []WeaponData {
 			continue
 		}
 
+		// 技能过滤
+		if !PassesSkillFilter(config, weapon) {
+			continue
+		}
+
 		result = append(result, weapon)
 	}
 
 	return result
 }
 
+// PassesSkillFilter - 武器是否带有要求的技能且不带排除的技能，未设置技能条件时总是满足
+func PassesSkillFilter(config FilterConfig, weapon WeaponData) bool {
+	for _, rule := range config.ExcludeSkills {
+		if !rule.empty() && rule.matches(weapon) {
+			return false
+		}
+	}
+
+	matchAny := strings.EqualFold(config.SkillMatch, SkillMatchAny)
+	checked := false
+	for _, rule := range config.Skills {
+		if rule.empty() {
+			continue
+		}
+		checked = true
+		matched := rule.matches(weapon)
+		if matchAny && matched {
+			return true
+		}
+		if !matchAny && !matched {
+			return false
+		}
+	}
+	return !matchAny || !checked
+}
+
+// empty - 没有 ID 也没有 Name 的条件不参与筛选
+func (r SkillRule) empty() bool {
+	return r.ID <= 0 && strings.TrimSpace(r.Name) == ""
+}
+
+// matches - 武器是否有一个槽位的技能满足该条件
+func (r SkillRule) matches(weapon WeaponData) bool {
+	name := strings.TrimSpace(r.Name)
+	for i := 0; i < 3; i++ {
+		if r.Slot > 0 && r.Slot != i+1 {
+			continue
+		}
+		if r.ID > 0 && (i >= len(weapon.SkillIDs) || weapon.SkillIDs[i] != r.ID) {
+			continue
+		}
+		if name != "" && (i >= len(weapon.SkillsChinese) || !strings.Contains(weapon.SkillsChinese[i], name)) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// ParseSkillNames - 把「暴击率;2:攻击」形式的任务参数拆成技能条件
+// 带「槽位:」前缀的项按 SkillIDByName 换成该槽位的技能 ID，其余按名称子串匹配任一槽位
+func ParseSkillNames(s string) []SkillRule {
+	var rules []SkillRule
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == '；' }) {
+		name := strings.TrimSpace(part)
+		if name == "" {
+			continue
+		}
+		if prefix, rest, ok := strings.Cut(strings.Replace(name, "：", ":", 1), ":"); ok {
+			if slot, err := strconv.Atoi(strings.TrimSpace(prefix)); err == nil && slot >= 1 && slot <= 3 {
+				rules = append(rules, resolveSkillRule(slot, strings.TrimSpace(rest)))
+				continue
+			}
+		}
+		rules = append(rules, SkillRule{Name: name})
+	}
+	return rules
+}
+
 // ExtractSkillCombinations - 提取技能组合
 func ExtractSkillCombinations(weapons []WeaponData) []SkillCombination {
 	combinations := []SkillCombination{}
@@ -47,3 +133,71 @@ func ExtractSkillCombinations(weapons []WeaponData) []SkillCombination {
 
 	return combinations
 }
+
+// PassesLevelFilter - 等级与突破阶段是否满足配置，未识别到的值视为满足
+func PassesLevelFilter(config FilterConfig, level, breakthrough int) bool {
+	if config.MinLevel > 0 && level > 0 && level < config.MinLevel {
+		return false
+	}
+	if config.MinBreakthrough > 0 && breakthrough >= 0 && breakthrough < config.MinBreakthrough {
+		return false
+	}
+	return true
+}
+
+// NeedsLevelInfo - 配置是否依赖等级或突破阶段
+func NeedsLevelInfo(config FilterConfig) bool {
+	return config.MinLevel > 0 || config.MinBreakthrough > 0 || KeepLimit(config) > 0
+}
+
+// KeepLimit - 每个武器保留的物品数量，0 表示不限制
+func KeepLimit(config FilterConfig) int {
+	if config.KeepDuplicates > 0 {
+		return config.KeepDuplicates
+	}
+	if config.KeepMaxBreakthrough {
+		return 1
+	}
+	return 0
+}
+
+// SplitKeepDuplicates - 按武器分组，每组按突破、等级从高到低保留前 n 个，其余作为可分解项返回
+func SplitKeepDuplicates(items []InventoryItem, n int) (keep, dismantle []InventoryItem) {
+	if n <= 0 {
+		return items, nil
+	}
+
+	groups := make(map[string][]int)
+	order := []string{}
+	for i, item := range items {
+		id := item.Weapon.InternalID
+		if _, ok := groups[id]; !ok {
+			order = append(order, id)
+		}
+		groups[id] = append(groups[id], i)
+	}
+
+	kept := make(map[int]bool)
+	for _, id := range order {
+		idx := groups[id]
+		sort.SliceStable(idx, func(a, b int) bool {
+			wa, wb := items[idx[a]].Weapon, items[idx[b]].Weapon
+			if wa.Breakthrough != wb.Breakthrough {
+				return wa.Breakthrough > wb.Breakthrough
+			}
+			return wa.Level > wb.Level
+		})
+		for k := 0; k < n && k < len(idx); k++ {
+			kept[idx[k]] = true
+		}
+	}
+
+	for i, item := range items {
+		if kept[i] {
+			keep = append(keep, item)
+		} else {
+			dismantle = append(dismantle, item)
+		}
+	}
+	return keep, dismantle
+}
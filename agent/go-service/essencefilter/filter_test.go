@@ -0,0 +1,161 @@
+package essencefilter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/ocrutil"
+)
+
+func TestPassesLevelFilter(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       FilterConfig
+		level        int
+		breakthrough int
+		want         bool
+	}{
+		{"no thresholds", FilterConfig{}, 1, 0, true},
+		{"level below min", FilterConfig{MinLevel: 20}, 10, 0, false},
+		{"level at min", FilterConfig{MinLevel: 20}, 20, 0, true},
+		{"level unknown", FilterConfig{MinLevel: 20}, 0, -1, true},
+		{"breakthrough below min", FilterConfig{MinBreakthrough: 2}, 40, 1, false},
+		{"breakthrough at min", FilterConfig{MinBreakthrough: 2}, 40, 2, true},
+		{"breakthrough zero is known", FilterConfig{MinBreakthrough: 1}, 40, 0, false},
+		{"breakthrough unknown", FilterConfig{MinBreakthrough: 2}, 40, -1, true},
+		{"both must pass", FilterConfig{MinLevel: 20, MinBreakthrough: 2}, 30, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PassesLevelFilter(tt.config, tt.level, tt.breakthrough); got != tt.want {
+				t.Errorf("PassesLevelFilter(%+v, %d, %d) = %v, want %v", tt.config, tt.level, tt.breakthrough, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNeedsLevelInfo(t *testing.T) {
+	tests := []struct {
+		name   string
+		config FilterConfig
+		want   bool
+	}{
+		{"rarity only", FilterConfig{MinRarity: 5}, false},
+		{"min level", FilterConfig{MinLevel: 1}, true},
+		{"min breakthrough", FilterConfig{MinBreakthrough: 1}, true},
+		{"keep max breakthrough", FilterConfig{KeepMaxBreakthrough: true}, true},
+		{"keep duplicates", FilterConfig{KeepDuplicates: 2}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NeedsLevelInfo(tt.config); got != tt.want {
+				t.Errorf("NeedsLevelInfo(%+v) = %v, want %v", tt.config, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeepLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		config FilterConfig
+		want   int
+	}{
+		{"unset", FilterConfig{}, 0},
+		{"keep max breakthrough", FilterConfig{KeepMaxBreakthrough: true}, 1},
+		{"keep duplicates", FilterConfig{KeepDuplicates: 3}, 3},
+		{"keep duplicates wins", FilterConfig{KeepMaxBreakthrough: true, KeepDuplicates: 2}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KeepLimit(tt.config); got != tt.want {
+				t.Errorf("KeepLimit(%+v) = %d, want %d", tt.config, got, tt.want)
+			}
+		})
+	}
+}
+
+func item(id string, level, breakthrough, row, col int) InventoryItem {
+	return InventoryItem{
+		Weapon: WeaponData{InternalID: id, Level: level, Breakthrough: breakthrough},
+		Row:    row,
+		Col:    col,
+	}
+}
+
+func TestSplitKeepDuplicates(t *testing.T) {
+	a1 := item("a", 40, 1, 0, 0)
+	a2 := item("a", 20, 3, 0, 1)
+	a3 := item("a", 60, 3, 0, 2)
+	b1 := item("b", 10, 0, 1, 0)
+	c1 := item("c", 30, 2, 1, 1)
+	c2 := item("c", 50, 2, 1, 2)
+
+	tests := []struct {
+		name          string
+		items         []InventoryItem
+		n             int
+		wantKeep      []InventoryItem
+		wantDismantle []InventoryItem
+	}{
+		{
+			name:     "no limit keeps everything",
+			items:    []InventoryItem{a1, a2, b1},
+			n:        0,
+			wantKeep: []InventoryItem{a1, a2, b1},
+		},
+		{
+			name:          "max breakthrough wins, level breaks ties",
+			items:         []InventoryItem{a1, a2, a3, b1, c1, c2},
+			n:             1,
+			wantKeep:      []InventoryItem{a3, b1, c2},
+			wantDismantle: []InventoryItem{a1, a2, c1},
+		},
+		{
+			name:          "keep two per weapon",
+			items:         []InventoryItem{a1, a2, a3, b1},
+			n:             2,
+			wantKeep:      []InventoryItem{a2, a3, b1},
+			wantDismantle: []InventoryItem{a1},
+		},
+		{
+			name:     "single copies are kept",
+			items:    []InventoryItem{b1, c1},
+			n:        1,
+			wantKeep: []InventoryItem{b1, c1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keep, dismantle := SplitKeepDuplicates(tt.items, tt.n)
+			if !reflect.DeepEqual(keep, tt.wantKeep) {
+				t.Errorf("keep = %+v, want %+v", keep, tt.wantKeep)
+			}
+			if !reflect.DeepEqual(dismantle, tt.wantDismantle) {
+				t.Errorf("dismantle = %+v, want %+v", dismantle, tt.wantDismantle)
+			}
+		})
+	}
+}
+
+func TestParseFirstInt(t *testing.T) {
+	tests := []struct {
+		name   string
+		result ocrutil.Result
+		want   int
+		wantOK bool
+	}{
+		{"miss", ocrutil.Result{Text: "Lv.40"}, 0, false},
+		{"level", ocrutil.Result{Hit: true, Text: "Lv.40"}, 40, true},
+		{"first number", ocrutil.Result{Hit: true, Text: "3/4"}, 3, true},
+		{"no digits", ocrutil.Result{Hit: true, Text: "突破"}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseFirstInt(tt.result)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseFirstInt(%+v) = %d, %v, want %d, %v", tt.result, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package essencefilter
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/ocrutil"
+	maa "github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// 等级与突破阶段的 OCR 节点，资源中未定义时跳过相关过滤
+const (
+	levelOCRNode        = "EssenceFilterItemLevel"
+	breakthroughOCRNode = "EssenceFilterItemBreakthrough"
+)
+
+var digitsRe = regexp.MustCompile(`\d+`)
+
+// levelNodesWarned - 缺少节点的提示只输出一次
+var levelNodesWarned bool
+
+// readLevelInfo - 从当前截图识别等级与突破阶段，未识别到时 level 为 0、breakthrough 为 -1
+func readLevelInfo(ctx *maa.Context) (level, breakthrough int) {
+	level, breakthrough = 0, -1
+
+	for _, name := range []string{levelOCRNode, breakthroughOCRNode} {
+		if raw, err := ctx.GetNodeJSON(name); err != nil || raw == "" {
+			if !levelNodesWarned {
+				log.Warn().Str("node", name).Msg("<EssenceFilter> level OCR node not defined, level filters ignored")
+				levelNodesWarned = true
+			}
+			return
+		}
+	}
+
+	img, err := ctx.GetTasker().GetController().CacheImage()
+	if err != nil || img == nil {
+		log.Warn().Err(err).Msg("<EssenceFilter> no cached image for level OCR")
+		return
+	}
+
+	results := ocrutil.BatchExtract(ctx, img, []ocrutil.ROIRequest{
+		{Pipeline: levelOCRNode},
+		{Pipeline: breakthroughOCRNode},
+	})
+	if v, ok := parseFirstInt(results[0]); ok {
+		level = v
+	}
+	if v, ok := parseFirstInt(results[1]); ok {
+		breakthrough = v
+	}
+	log.Info().Int("level", level).Int("breakthrough", breakthrough).Msg("<EssenceFilter> level OCR")
+	return
+}
+
+func parseFirstInt(r ocrutil.Result) (int, bool) {
+	if !r.Hit {
+		return 0, false
+	}
+	m := digitsRe.FindString(r.Text)
+	if m == "" {
+		return 0, false
+	}
+	v, err := strconv.Atoi(m)
+	return v, err == nil
+}
@@ -3,6 +3,9 @@ package essencefilter
 import (
 	"encoding/json"
 	"os"
+	"sync"
+
+	"github.com/rs/zerolog/log"
 )
 
 // LoadWeaponDatabase - 加载武器数据库
@@ -11,7 +14,17 @@ func LoadWeaponDatabase(filepath string) error {
 	if err != nil {
 		return err
 	}
-	return json.Unmarshal(data, &weaponDB)
+	var db WeaponDatabase
+	if err := json.Unmarshal(data, &db); err != nil {
+		return err
+	}
+	if err := ValidateWeaponDatabase(db); err != nil {
+		log.Warn().Err(err).Str("path", filepath).Msg("<EssenceFilter> weapon database has dangling skill ids")
+	}
+	weaponDB = db
+	// 技能池可能已变化，下次匹配时重建索引
+	buildSlotIndicesOnce = sync.Once{}
+	return nil
 }
 
 // LoadPresets - 加载预设配置
@@ -373,16 +373,6 @@ func getPoolBySlot(slot int) []SkillPool {
 	}
 }
 
-// skillNameByID - 按 ID 取技能中文名
-func skillNameByID(id int, pool []SkillPool) string {
-	for _, s := range pool {
-		if s.ID == id {
-			return s.Chinese
-		}
-	}
-	return ""
-}
-
 func firstChar(s string) string {
 	r := []rune(s)
 	if len(r) == 2 {
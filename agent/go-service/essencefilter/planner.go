@@ -0,0 +1,167 @@
+package essencefilter
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	maa "github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// PlanItem - 获取规划中的一项
+type PlanItem struct {
+	Weapon   WeaponData
+	Owned    int
+	Wanted   int
+	Priority int
+}
+
+// Missing returns how many copies are still needed
+func (p PlanItem) Missing() int {
+	if p.Owned >= p.Wanted {
+		return 0
+	}
+	return p.Wanted - p.Owned
+}
+
+// BuildPlan - 对比物品快照与目标清单，返回仍缺少的武器，按优先级、稀有度、缺少数量排序
+// 目标清单中的武器名称经 ResolveWeapons 解析，无法解析的名称单独返回
+func BuildPlan(items []InventoryItem, wanted []agentconfig.WantedWeapon) ([]PlanItem, []string) {
+	owned := make(map[string]int)
+	for _, item := range items {
+		owned[item.Weapon.InternalID]++
+	}
+
+	plan := []PlanItem{}
+	var unresolved []string
+	for _, want := range wanted {
+		r, ok := resolveWeapon(want.Weapon)
+		if !ok {
+			log.Warn().Str("weapon", want.Weapon).Msg("<EssenceFilter> wanted weapon not found in DB")
+			unresolved = append(unresolved, want.Weapon)
+			continue
+		}
+		if r.Ambiguous {
+			log.Warn().Str("weapon", want.Weapon).Strs("candidates", r.Candidates).Str("chosen", r.Weapon.ChineseName).Msg("<EssenceFilter> wanted weapon name is ambiguous")
+		} else if r.Confidence < 1 {
+			log.Info().Str("weapon", want.Weapon).Str("resolved", r.Weapon.ChineseName).Float64("confidence", r.Confidence).Msg("<EssenceFilter> wanted weapon resolved")
+		}
+		weapon := r.Weapon
+		count := want.Count
+		if count <= 0 {
+			count = 1
+		}
+		p := PlanItem{Weapon: weapon, Owned: owned[weapon.InternalID], Wanted: count, Priority: want.Priority}
+		if p.Missing() > 0 {
+			plan = append(plan, p)
+		}
+	}
+
+	sort.SliceStable(plan, func(i, j int) bool {
+		if plan[i].Priority != plan[j].Priority {
+			return plan[i].Priority > plan[j].Priority
+		}
+		if plan[i].Weapon.Rarity != plan[j].Weapon.Rarity {
+			return plan[i].Weapon.Rarity > plan[j].Weapon.Rarity
+		}
+		return plan[i].Missing() > plan[j].Missing()
+	})
+	return plan, unresolved
+}
+
+func sourcesText(w WeaponData) string {
+	if len(w.Sources) == 0 {
+		return "未知"
+	}
+	return strings.Join(w.Sources, "、")
+}
+
+// writePlanReport - 生成获取规划报告，返回报告路径
+func writePlanReport(plan []PlanItem) string {
+	dir := filepath.Join(".", "debug", "essencefilter")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Warn().Err(err).Msg("<EssenceFilter> create report dir failed")
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString(`<html><head><meta charset="utf-8"><title>EssenceFilter Plan</title></head><body>`)
+	builder.WriteString(`<table style="border-collapse: collapse;">`)
+	builder.WriteString(`<tr><th>优先级</th><th>武器</th><th>稀有度</th><th>已有</th><th>缺少</th><th>获取途径</th></tr>`)
+	for _, p := range plan {
+		builder.WriteString(fmt.Sprintf(
+			`<tr><td>%d</td><td style="color: %s;">%s</td><td>%d</td><td>%d</td><td>%d</td><td>%s</td></tr>`,
+			p.Priority, getColorForRarity(p.Weapon.Rarity), html.EscapeString(p.Weapon.ChineseName),
+			p.Weapon.Rarity, p.Owned, p.Missing(), html.EscapeString(sourcesText(p.Weapon)),
+		))
+	}
+	builder.WriteString(`</table></body></html>`)
+
+	path := filepath.Join(dir, "plan.html")
+	if err := os.WriteFile(path, []byte(builder.String()), 0644); err != nil {
+		log.Warn().Err(err).Msg("<EssenceFilter> save plan report failed")
+		return ""
+	}
+	return path
+}
+
+// EssenceFilterPlanAction - 对比最近一次筛选的物品快照与目标清单，输出待获取武器
+type EssenceFilterPlanAction struct{}
+
+func (a *EssenceFilterPlanAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	log.Info().Msg("<EssenceFilter> ========== Plan ==========")
+
+	base := getResourceBase()
+	if base == "" {
+		base = "resource"
+	}
+	if err := LoadWeaponDatabase(filepath.Join(base, "gamedata", "EssenceFilter", "weapons_data.json")); err != nil {
+		log.Error().Err(err).Msg("<EssenceFilter> Plan failed: load DB")
+		return false
+	}
+
+	wanted := agentconfig.Get().Wanted
+	if len(wanted) == 0 {
+		LogMXUSimpleHTML(ctx, "未配置目标武器清单（go-service.json 中的 wanted）")
+		return true
+	}
+
+	items, err := LoadInventory(inventoryPath())
+	if err != nil {
+		log.Error().Err(err).Msg("<EssenceFilter> Plan failed: load inventory snapshot")
+		return false
+	}
+	if items == nil {
+		LogMXUSimpleHTML(ctx, "尚无物品快照，请先运行一次基质筛选")
+	}
+
+	plan, unresolved := BuildPlan(items, wanted)
+	if len(unresolved) > 0 {
+		LogMXUSimpleHTMLWithColor(ctx, fmt.Sprintf("以下武器名称未能识别，请检查 wanted 配置：%s", html.EscapeString(strings.Join(unresolved, "、"))), "#ff7000")
+	}
+	if len(plan) == 0 {
+		if len(unresolved) == 0 {
+			LogMXUSimpleHTMLWithColor(ctx, "目标清单中的武器均已持有", "#11cf00")
+		}
+		return true
+	}
+
+	var builder strings.Builder
+	builder.WriteString(`<div style="font-weight: 900;">待获取武器：</div>`)
+	for i, p := range plan {
+		log.Info().Str("weapon", p.Weapon.ChineseName).Int("owned", p.Owned).Int("missing", p.Missing()).Int("priority", p.Priority).Msg("<EssenceFilter> Plan")
+		builder.WriteString(fmt.Sprintf(`<div style="font-size: 11px;">%d. <span style="color: %s;">%s</span> 缺少 %d（获取途径：%s）</div>`,
+			i+1, getColorForRarity(p.Weapon.Rarity), p.Weapon.ChineseName, p.Missing(), sourcesText(p.Weapon)))
+	}
+	LogMXUHTML(ctx, builder.String())
+
+	if path := writePlanReport(plan); path != "" {
+		log.Info().Str("report", path).Msg("<EssenceFilter> Plan report saved")
+	}
+	return true
+}
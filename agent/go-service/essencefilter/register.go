@@ -1,6 +1,8 @@
 package essencefilter
 
 import (
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/nodecheck"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
 	maa "github.com/MaaXYZ/maa-framework-go/v4"
 )
 
@@ -8,14 +10,45 @@ var (
 	_ maa.ResourceEventSink = &resourcePathSink{}
 )
 
+// Components returns the custom components of essencefilter package
+func Components() []registry.Component {
+	P := registry.P
+	return []registry.Component{
+		registry.Action("EssenceFilterInitAction", &EssenceFilterInitAction{}, "加载武器数据与预设，初始化基质筛选",
+			P("preset_name", "string", "使用的筛选预设"),
+			P("skills", "string", "可选，武器需要带有的技能名，分号分隔，追加到预设的技能条件；写成 槽位:名称 时按该槽位的技能匹配"),
+			P("skill_match", "string", "可选，all 需带有全部技能，any 带有任一即可"),
+			P("exclude_skills", "string", "可选，带有其中任一技能的武器不筛选，分号分隔"),
+		),
+		registry.Action("EssenceFilterCheckItemAction", &EssenceFilterCheckItemAction{}, "OCR 一个技能槽位并匹配",
+			P("slot", "int", "技能槽位，1 到 3"),
+			P("is_last", "bool", "是否为最后一个槽位"),
+		),
+		registry.Action("EssenceFilterRowCollectAction", &EssenceFilterRowCollectAction{}, "收集一行中的基质并点击第一个"),
+		registry.Action("EssenceFilterRowNextItemAction", &EssenceFilterRowNextItemAction{}, "前往下一个基质，或滑动、结束"),
+		registry.Action("EssenceFilterSkillDecisionAction", &EssenceFilterSkillDecisionAction{}, "匹配技能后决定锁定或跳过"),
+		registry.Action("EssenceFilterFinishAction", &EssenceFilterFinishAction{}, "输出筛选结果并重置"),
+		registry.Action("EssenceFilterTraceAction", &EssenceFilterTraceAction{}, "记录当前节点与步骤",
+			P("step", "string", "步骤名，为空时使用节点名"),
+		),
+		registry.Action("OCREssenceInventoryNumberAction", &OCREssenceInventoryNumberAction{}, "读取库存基质总数，一页放得下时不再滑动"),
+		registry.Action("EssenceFilterPlanAction", &EssenceFilterPlanAction{}, "对比物品快照与目标清单，输出待获取武器"),
+	}
+}
+
+// Register adds the resource sink and declares the pipeline nodes essencefilter package references
 func Register() {
 	maa.AgentServerAddResourceSink(&resourcePathSink{})
-	maa.AgentServerRegisterCustomAction("EssenceFilterInitAction", &EssenceFilterInitAction{})
-	maa.AgentServerRegisterCustomAction("EssenceFilterCheckItemAction", &EssenceFilterCheckItemAction{})
-	maa.AgentServerRegisterCustomAction("EssenceFilterRowCollectAction", &EssenceFilterRowCollectAction{})
-	maa.AgentServerRegisterCustomAction("EssenceFilterRowNextItemAction", &EssenceFilterRowNextItemAction{})
-	maa.AgentServerRegisterCustomAction("EssenceFilterSkillDecisionAction", &EssenceFilterSkillDecisionAction{})
-	maa.AgentServerRegisterCustomAction("EssenceFilterFinishAction", &EssenceFilterFinishAction{})
-	maa.AgentServerRegisterCustomAction("EssenceFilterTraceAction", &EssenceFilterTraceAction{})
-	maa.AgentServerRegisterCustomAction("OCREssenceInventoryNumberAction", &OCREssenceInventoryNumberAction{})
+	nodecheck.Require("EssenceFilter",
+		"LogMXU",
+		"NodeClick",
+		"EssenceColorMatch",
+		"EssenceDetectFinal",
+		"EssenceFilterFinish",
+		"EssenceFilterRowNextItem",
+		"EssenceFilterCheckItemSlot1",
+		"EssenceFilterLockItemLog",
+		"EssenceFilterSwipeFirst",
+		"EssenceFilterSwipeNext",
+	)
 }
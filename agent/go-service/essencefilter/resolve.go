@@ -0,0 +1,126 @@
+package essencefilter
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// minWeaponConfidence - 低于该置信度的名称不解析，交给用户确认
+	minWeaponConfidence = 0.6
+	// ambiguousMargin - 次优武器与最优武器的置信度差不超过该值时视为有歧义
+	ambiguousMargin = 0.05
+)
+
+// ResolvedWeapon - 一个名称解析得到的武器
+type ResolvedWeapon struct {
+	Name   string     `json:"name"` // 原始名称
+	Weapon WeaponData `json:"weapon"`
+	// Confidence - 0~1，中文名、英文名或 internal_id 完全一致时为 1
+	Confidence float64 `json:"confidence"`
+	// Ambiguous - 有其他武器的置信度与之接近，Candidates 为这些武器的中文名（含 Weapon 本身）
+	Ambiguous  bool     `json:"ambiguous,omitempty"`
+	Candidates []string `json:"candidates,omitempty"`
+}
+
+// ResolveWeapons - 把 OCR 或用户输入的武器名称解析为武器数据库中的武器
+// 返回的解析结果与输入顺序一致；置信度过低、无法解析的名称单独返回，供用户确认
+func ResolveWeapons(names []string) ([]ResolvedWeapon, []string) {
+	resolved := []ResolvedWeapon{}
+	var unresolved []string
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		r, ok := resolveWeapon(name)
+		if !ok {
+			unresolved = append(unresolved, name)
+			continue
+		}
+		resolved = append(resolved, r)
+	}
+	return resolved, unresolved
+}
+
+type weaponScore struct {
+	weapon WeaponData
+	score  float64
+}
+
+// resolveWeapon - 对每把武器打分，取最高分；与最高分接近的其他武器列为候选
+func resolveWeapon(name string) (ResolvedWeapon, bool) {
+	name = strings.TrimSpace(name)
+	cleaned := cleanWeaponName(name)
+
+	var scores []weaponScore
+	for _, w := range weaponDB.Weapons {
+		if s := scoreWeaponName(name, cleaned, w); s >= minWeaponConfidence {
+			scores = append(scores, weaponScore{weapon: w, score: s})
+		}
+	}
+	if len(scores) == 0 {
+		return ResolvedWeapon{}, false
+	}
+	sort.SliceStable(scores, func(i, j int) bool { return scores[i].score > scores[j].score })
+
+	best := scores[0]
+	r := ResolvedWeapon{Name: name, Weapon: best.weapon, Confidence: best.score}
+	for _, s := range scores[1:] {
+		if best.score-s.score > ambiguousMargin {
+			break
+		}
+		if s.weapon.InternalID == best.weapon.InternalID {
+			continue
+		}
+		if !r.Ambiguous {
+			r.Ambiguous = true
+			r.Candidates = []string{best.weapon.ChineseName}
+		}
+		r.Candidates = append(r.Candidates, s.weapon.ChineseName)
+	}
+	return r, true
+}
+
+// scoreWeaponName - 名称与武器的相似度：完全一致 1，清洗后一致 0.95，
+// 互为子串按长度比例折算，其余按编辑距离折算，误差超过名称长度的三分之一时为 0
+func scoreWeaponName(name, cleaned string, w WeaponData) float64 {
+	if name == w.ChineseName || name == w.InternalID || (w.EnglishName != "" && strings.EqualFold(name, w.EnglishName)) {
+		return 1
+	}
+	if cleaned == "" {
+		return 0
+	}
+
+	best := 0.0
+	for _, target := range []string{cleanWeaponName(w.ChineseName), cleanWeaponName(w.EnglishName)} {
+		if target == "" {
+			continue
+		}
+		if cleaned == target {
+			return 0.95
+		}
+		n, t := utf8.RuneCountInString(cleaned), utf8.RuneCountInString(target)
+		if strings.Contains(target, cleaned) || strings.Contains(cleaned, target) {
+			best = max(best, 0.9*float64(min(n, t))/float64(max(n, t)))
+			continue
+		}
+		maxDist := max(1, max(n, t)/3)
+		if dist := editDistance(cleaned, target, maxDist); dist <= maxDist {
+			best = max(best, 0.9*(1-float64(dist)/float64(max(n, t))))
+		}
+	}
+	return best
+}
+
+// cleanWeaponName - 只保留汉字、字母与数字，字母转为小写
+func cleanWeaponName(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
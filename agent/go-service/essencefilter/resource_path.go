@@ -26,7 +26,7 @@ var (
 type resourcePathSink struct{}
 
 func (c *resourcePathSink) OnResourceLoading(resource *maa.Resource, status maa.EventStatus, detail maa.ResourceLoadingDetail) {
-	fmt.Println("[EssenceFilter] Resource loading event: status=%s, path=%s\n", status, detail.Path)
+	fmt.Printf("[EssenceFilter] Resource loading event: status=%v, path=%s\n", status, detail.Path)
 	if status != maa.EventStatusSucceeded || detail.Path == "" {
 		return
 	}
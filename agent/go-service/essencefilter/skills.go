@@ -0,0 +1,90 @@
+package essencefilter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SkillNameByID - 按槽位与技能池 ID 取技能中文名，ID 不在该槽位的技能池中时返回 false
+func SkillNameByID(slot, id int) (string, bool) {
+	for _, s := range getPoolBySlot(slot) {
+		if s.ID == id {
+			return s.Chinese, true
+		}
+	}
+	return "", false
+}
+
+// SkillIDByName - 按名称取槽位技能池中的 ID，依次尝试中文名、英文名（不区分大小写）、
+// matcher_config.json 中的 skillAliases，最后使用与 OCR 相同的模糊匹配
+// 武器数据中「意志提升·小」「强攻·武装整备」这类带等级或词条后缀的写法按「·」之前的部分匹配
+func SkillIDByName(slot int, name string) (int, bool) {
+	pool := getPoolBySlot(slot)
+	if len(pool) == 0 {
+		return 0, false
+	}
+	name = strings.TrimSpace(name)
+	if base, _, ok := strings.Cut(name, "·"); ok {
+		name = strings.TrimSpace(base)
+	}
+	if name == "" {
+		return 0, false
+	}
+
+	for _, s := range pool {
+		if s.Chinese == name || strings.EqualFold(s.English, name) {
+			return s.ID, true
+		}
+	}
+	if target, ok := matcherConfig.SkillAliases[name]; ok {
+		for _, s := range pool {
+			if s.Chinese == target {
+				return s.ID, true
+			}
+		}
+	}
+
+	buildSlotIndicesOnce.Do(buildSlotIndices)
+	return matchSkillIDEnhanced(slot, name)
+}
+
+// ValidateWeaponDatabase - 检查武器引用的技能 ID 是否都在对应槽位的技能池中
+// 0 表示该槽位没有技能（如三星武器的第二槽位），不视为错误
+func ValidateWeaponDatabase(db WeaponDatabase) error {
+	pools := [3][]SkillPool{db.SkillPools.Slot1, db.SkillPools.Slot2, db.SkillPools.Slot3}
+	var errs []error
+	for _, w := range db.Weapons {
+		if len(w.SkillIDs) > len(pools) {
+			errs = append(errs, fmt.Errorf("%s: %d skill slots", w.ChineseName, len(w.SkillIDs)))
+			continue
+		}
+		for i, id := range w.SkillIDs {
+			if id == 0 {
+				continue
+			}
+			found := false
+			for _, s := range pools[i] {
+				if s.ID == id {
+					found = true
+					break
+				}
+			}
+			if !found {
+				errs = append(errs, fmt.Errorf("%s: slot %d skill id %d not in skill pool", w.ChineseName, i+1, id))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resolveSkillRule - 「槽位:技能名」形式的条件换成技能 ID，换不出时退回按名称子串匹配
+func resolveSkillRule(slot int, name string) SkillRule {
+	if id, ok := SkillIDByName(slot, name); ok {
+		return SkillRule{Slot: slot, ID: id}
+	}
+	log.Warn().Int("slot", slot).Str("skill", name).Msg("<EssenceFilter> skill name not in skill pool, match by name")
+	return SkillRule{Slot: slot, Name: name}
+}
@@ -0,0 +1,42 @@
+package essencefilter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+)
+
+// inventoryPath - 最近一次筛选得到的物品快照
+func inventoryPath() string {
+	return datadir.Path("essencefilter", "inventory.json")
+}
+
+// SaveInventory - 保存物品快照，供获取规划等后续功能使用
+func SaveInventory(path string, items []InventoryItem) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(items, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadInventory - 读取物品快照，文件不存在时返回空快照
+func LoadInventory(path string) ([]InventoryItem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var items []InventoryItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
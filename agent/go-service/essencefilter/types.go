@@ -4,10 +4,16 @@ package essencefilter
 type WeaponData struct {
 	InternalID    string   `json:"internal_id"`
 	ChineseName   string   `json:"chinese_name"`
+	EnglishName   string   `json:"english_name,omitempty"`
 	TypeID        int      `json:"type_id"`
 	Rarity        int      `json:"rarity"`
-	SkillIDs      []int    `json:"skill_ids"`      // [slot1_id, slot2_id, slot3_id]
-	SkillsChinese []string `json:"skills_chinese"` // for logging/matching
+	SkillIDs      []int    `json:"skill_ids"`         // [slot1_id, slot2_id, slot3_id]
+	SkillsChinese []string `json:"skills_chinese"`    // for logging/matching
+	Sources       []string `json:"sources,omitempty"` // optional, acquisition sources
+
+	// Level / Breakthrough - 扫描时 OCR 得到的当前物品等级与突破阶段，数据库中不填
+	Level        int `json:"level,omitempty"`
+	Breakthrough int `json:"breakthrough,omitempty"`
 }
 
 // SkillPool - skill pool entry
@@ -44,6 +50,35 @@ type FilterConfig struct {
 	TypeIDs   []int `json:"type_ids"`   // optional weapon type filter
 	MinRarity int   `json:"min_rarity"` // min rarity
 	MaxRarity int   `json:"max_rarity"` // max rarity
+
+	MinLevel        int `json:"min_level,omitempty"`        // optional, min item level
+	MinBreakthrough int `json:"min_breakthrough,omitempty"` // optional, min breakthrough stage
+	// KeepMaxBreakthrough - 同一武器的重复物品只保留突破最高的一个，等同于 KeepDuplicates = 1
+	KeepMaxBreakthrough bool `json:"keep_max_breakthrough,omitempty"`
+	// KeepDuplicates - 同一武器保留的物品数量，按突破、等级从高到低选取，其余列为可分解
+	KeepDuplicates int `json:"keep_duplicates,omitempty"`
+
+	// Skills - 武器需要带有的技能；SkillMatch 为 any 时满足任一条即可，默认 all 需全部满足
+	Skills     []SkillRule `json:"skills,omitempty"`
+	SkillMatch string      `json:"skill_match,omitempty"`
+	// ExcludeSkills - 带有其中任一技能的武器不筛选
+	ExcludeSkills []SkillRule `json:"exclude_skills,omitempty"`
+}
+
+// SkillRule - 一条技能条件，ID 与 Name 都设置时需同时满足
+// Slot 为 1~3，0 表示任一槽位；ID 为该槽位技能池中的编号，各槽位独立编号，通常需要同时指定 Slot；
+// Name 按子串匹配中文技能名，如「暴击率」
+type SkillRule struct {
+	Slot int    `json:"slot,omitempty"`
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// InventoryItem - 扫描过程中确认匹配的物品
+type InventoryItem struct {
+	Weapon WeaponData `json:"weapon"` // Level / Breakthrough 为该物品的实际值
+	Row    int        `json:"row"`
+	Col    int        `json:"col"`
 }
 
 // SkillCombination - target skill combination
@@ -57,6 +92,8 @@ type SkillCombination struct {
 type MatcherConfig struct {
 	SimilarWordMap  map[string]string `json:"similarWordMap"`
 	SuffixStopwords []string          `json:"suffixStopwords"`
+	// SkillAliases - 技能别名（键为别名，值为技能池中的中文名），供 SkillIDByName 使用
+	SkillAliases map[string]string `json:"skillAliases"`
 }
 
 // Global variables
@@ -83,6 +120,10 @@ var (
 	rowIndex       int
 	weaponDataPath string
 
+	// Active filter of the selected preset, and matched items seen so far
+	activeFilter FilterConfig
+	inventory    []InventoryItem
+
 	// Matcher config - loaded from JSON config file, used for skill name matching
 	matcherConfig MatcherConfig
 )
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/creditshopping"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/resell"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/xlsx"
+)
+
+// runExportXLSX - go-service export-xlsx [-out maaend-data.xlsx]
+// 把数据目录中的倒卖历史与信用点购物历史导出为 Excel 工作簿，每个模块一个工作表，表尾带合计公式
+func runExportXLSX(args []string) error {
+	fs := flag.NewFlagSet("export-xlsx", flag.ContinueOnError)
+	out := fs.String("out", "maaend-data-"+time.Now().Format("20060102-150405")+".xlsx", "workbook to write")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := agentconfig.Load(filepath.Join(getCwd(), "config", "go-service.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v, using default data dir\n", err)
+	}
+	if err := datadir.Init(cfg.DataDir); err != nil {
+		return err
+	}
+
+	var wb xlsx.Workbook
+	exports := []struct {
+		name   string
+		export func(*xlsx.Workbook) (int, error)
+	}{
+		{"resell", resell.ExportSheet},
+		{"creditshopping", creditshopping.ExportSheet},
+	}
+	for _, e := range exports {
+		n, err := e.export(&wb)
+		if err != nil {
+			return fmt.Errorf("%s: %w", e.name, err)
+		}
+		fmt.Printf("%-15s %d rows\n", e.name, n)
+	}
+	if err := wb.Save(*out); err != nil {
+		return err
+	}
+	fmt.Printf("written to %s\n", *out)
+	return nil
+}
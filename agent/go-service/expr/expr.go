@@ -0,0 +1,470 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Expr - a parsed expression over named variables.
+// Only numbers, variables, + - * /, comparisons (< <= > >= == !=), && || !,
+// parentheses and min/max/if are supported, so evaluating user input cannot have side effects.
+// Comparisons and logical operators evaluate to 1 (true) or 0 (false).
+type Expr struct {
+	raw  string
+	root node
+}
+
+type node interface {
+	eval(vars map[string]float64) (float64, error)
+}
+
+type numberNode float64
+
+type varNode string
+
+type unaryNode struct {
+	op      byte
+	operand node
+}
+
+type binaryNode struct {
+	op          byte
+	left, right node
+}
+
+type callNode struct {
+	name string
+	args []node
+}
+
+// compareNode / logicNode - results are 1 or 0
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+type logicNode struct {
+	op          string
+	left, right node
+}
+
+// Parse parses an expression such as "cost*0.1+50"
+func Parse(text string) (*Expr, error) {
+	p := &parser{text: normalize(text)}
+	p.next()
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", p.lit, p.pos)
+	}
+	return &Expr{raw: text, root: root}, nil
+}
+
+// String returns the original expression text
+func (e *Expr) String() string {
+	return e.raw
+}
+
+// Eval evaluates the expression with the given variables
+func (e *Expr) Eval(vars map[string]float64) (float64, error) {
+	return e.root.eval(vars)
+}
+
+// IsCondition reports whether the expression is a condition, i.e. its outermost
+// operation is a comparison, && / || or !, so its value is a decision rather than a score
+func (e *Expr) IsCondition() bool {
+	switch n := e.root.(type) {
+	case compareNode, logicNode:
+		return true
+	case unaryNode:
+		return n.op == '!'
+	}
+	return false
+}
+
+// EvalBool evaluates the expression and reports whether the result is non-zero
+func (e *Expr) EvalBool(vars map[string]float64) (bool, error) {
+	v, err := e.root.eval(vars)
+	if err != nil {
+		return false, err
+	}
+	return v != 0, nil
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (n numberNode) eval(map[string]float64) (float64, error) {
+	return float64(n), nil
+}
+
+func (n varNode) eval(vars map[string]float64) (float64, error) {
+	v, ok := vars[string(n)]
+	if !ok {
+		return 0, fmt.Errorf("unknown variable %q", string(n))
+	}
+	return v, nil
+}
+
+func (n unaryNode) eval(vars map[string]float64) (float64, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	if n.op == '!' {
+		return boolValue(v == 0), nil
+	}
+	return -v, nil
+}
+
+func (n binaryNode) eval(vars map[string]float64) (float64, error) {
+	l, err := n.left.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	r, err := n.right.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	case '/':
+		if r == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	}
+	return 0, fmt.Errorf("unknown operator %q", n.op)
+}
+
+func (n compareNode) eval(vars map[string]float64) (float64, error) {
+	l, err := n.left.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	r, err := n.right.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case "<":
+		return boolValue(l < r), nil
+	case "<=":
+		return boolValue(l <= r), nil
+	case ">":
+		return boolValue(l > r), nil
+	case ">=":
+		return boolValue(l >= r), nil
+	case "==":
+		return boolValue(l == r), nil
+	case "!=":
+		return boolValue(l != r), nil
+	}
+	return 0, fmt.Errorf("unknown operator %q", n.op)
+}
+
+// eval short-circuits, so the right side may reference variables that are only
+// present when the left side allows it
+func (n logicNode) eval(vars map[string]float64) (float64, error) {
+	l, err := n.left.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	if n.op == "&&" && l == 0 {
+		return 0, nil
+	}
+	if n.op == "||" && l != 0 {
+		return 1, nil
+	}
+	r, err := n.right.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	return boolValue(r != 0), nil
+}
+
+func (n callNode) eval(vars map[string]float64) (float64, error) {
+	if n.name == "if" {
+		cond, err := n.args[0].eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		if cond != 0 {
+			return n.args[1].eval(vars)
+		}
+		return n.args[2].eval(vars)
+	}
+	values := make([]float64, 0, len(n.args))
+	for _, arg := range n.args {
+		v, err := arg.eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		values = append(values, v)
+	}
+	result := values[0]
+	for _, v := range values[1:] {
+		if n.name == "min" && v < result || n.name == "max" && v > result {
+			result = v
+		}
+	}
+	return result, nil
+}
+
+type token int
+
+const (
+	tokEOF token = iota
+	tokNumber
+	tokIdent
+	tokOp
+)
+
+type parser struct {
+	text string
+	pos  int
+	tok  token
+	lit  string
+}
+
+// next reads the next token, decoding the text as UTF-8 so non-ASCII names stay whole
+func (p *parser) next() {
+	for p.pos < len(p.text) {
+		c, size := utf8.DecodeRuneInString(p.text[p.pos:])
+		if !unicode.IsSpace(c) {
+			break
+		}
+		p.pos += size
+	}
+	if p.pos >= len(p.text) {
+		p.tok, p.lit = tokEOF, ""
+		return
+	}
+
+	start := p.pos
+	c, size := utf8.DecodeRuneInString(p.text[p.pos:])
+	switch {
+	case isDigit(c) || c == '.':
+		for p.pos < len(p.text) && (isDigit(rune(p.text[p.pos])) || p.text[p.pos] == '.') {
+			p.pos++
+		}
+		p.tok = tokNumber
+	case unicode.IsLetter(c) || c == '_':
+		for p.pos < len(p.text) {
+			c, size := utf8.DecodeRuneInString(p.text[p.pos:])
+			if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' {
+				break
+			}
+			p.pos += size
+		}
+		p.tok = tokIdent
+	default:
+		p.pos += size
+		if p.pos < len(p.text) {
+			switch p.text[start : p.pos+1] {
+			case "<=", ">=", "==", "!=", "&&", "||":
+				p.pos++
+			}
+		}
+		p.tok = tokOp
+	}
+	p.lit = p.text[start:p.pos]
+}
+
+// isDigit - numbers are ASCII only; full-width digits are converted by normalize
+func isDigit(c rune) bool {
+	return c >= '0' && c <= '9'
+}
+
+// normalize converts full-width ASCII (as typed with a Chinese or Japanese IME, e.g. "（cost＋５０）")
+// and the ideographic space to their ASCII forms
+func normalize(text string) string {
+	return strings.Map(func(c rune) rune {
+		switch {
+		case c >= '\uFF01' && c <= '\uFF5E':
+			return c - 0xFEE0
+		case c == '\u3000':
+			return ' '
+		}
+		return c
+	}, text)
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok == tokOp && p.lit == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok == tokOp && p.lit == "&&" {
+		p.next()
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = logicNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseComparison - comparisons do not chain, "a < b < c" is rejected
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok != tokOp {
+		return left, nil
+	}
+	switch op := p.lit; op {
+	case "<", "<=", ">", ">=", "==", "!=":
+		p.next()
+		right, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{op: op, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseSum() (node, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok == tokOp && (p.lit == "+" || p.lit == "-") {
+		op := p.lit[0]
+		p.next()
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseProduct() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok == tokOp && (p.lit == "*" || p.lit == "/") {
+		op := p.lit[0]
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.tok == tokOp && (p.lit == "-" || p.lit == "!") {
+		op := p.lit[0]
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	switch p.tok {
+	case tokNumber:
+		v, err := strconv.ParseFloat(p.lit, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", p.lit)
+		}
+		p.next()
+		return numberNode(v), nil
+	case tokIdent:
+		name := p.lit
+		p.next()
+		if p.tok == tokOp && p.lit == "(" {
+			return p.parseCall(name)
+		}
+		return varNode(name), nil
+	case tokOp:
+		if p.lit == "(" {
+			p.next()
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if p.tok != tokOp || p.lit != ")" {
+				return nil, fmt.Errorf("missing ')' at %d", p.pos)
+			}
+			p.next()
+			return inner, nil
+		}
+	}
+	if p.tok == tokEOF {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", p.lit, p.pos)
+}
+
+func (p *parser) parseCall(name string) (node, error) {
+	fn := strings.ToLower(name)
+	if fn != "min" && fn != "max" && fn != "if" {
+		return nil, fmt.Errorf("unknown function %q", name)
+	}
+	p.next() // consume '('
+	var args []node
+	for {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.tok == tokOp && p.lit == "," {
+			p.next()
+			continue
+		}
+		break
+	}
+	if p.tok != tokOp || p.lit != ")" {
+		return nil, fmt.Errorf("missing ')' at %d", p.pos)
+	}
+	p.next()
+	if fn == "if" && len(args) != 3 {
+		return nil, fmt.Errorf("if takes 3 arguments (condition, then, else), got %d", len(args))
+	}
+	return callNode{name: fn, args: args}, nil
+}
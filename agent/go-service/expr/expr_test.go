@@ -0,0 +1,203 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+)
+
+var testVars = map[string]float64{
+	"cost":      1000,
+	"salePrice": 1500,
+	"profit":    500,
+	"rarity":    4,
+	"库存":        3,
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		text string
+		want float64
+	}{
+		{"42", 42},
+		{"1.5", 1.5},
+		{"cost*0.1+50", 150},
+		{"50+cost*0.1", 150},
+		{"(50+cost)*0.1", 105},
+		{"10-4-3", 3},
+		{"100/10/5", 2},
+		{"-cost+1", -999},
+		{"--5", 5},
+		{"2*-3", -6},
+		{"min(cost, salePrice)", 1000},
+		{"max(1, 7, 3)", 7},
+		{"MAX(profit, 0)", 500},
+		{"min(5)", 5},
+		{"if(rarity >= 4, 100, 50)", 100},
+		{"if(rarity > 4, 100, 50)", 50},
+		{"cost < salePrice", 1},
+		{"cost >= salePrice", 0},
+		{"cost == 1000", 1},
+		{"cost != 1000", 0},
+		{"profit > 100 && rarity >= 4", 1},
+		{"profit > 1000 || rarity >= 4", 1},
+		{"profit > 1000 || rarity > 4", 0},
+		{"!(cost > 2000)", 1},
+		{"!0", 1},
+		{"库存*10", 30},
+		{"（cost＋５０）／１０", 105},
+		{"cost　+\t1", 1001},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.text)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.text, err)
+			continue
+		}
+		got, err := e.Eval(testVars)
+		if err != nil {
+			t.Errorf("Eval(%q): %v", tt.text, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Eval(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"cost/0", "division by zero"},
+		{"cost/(profit-500)", "division by zero"},
+		{"stock*2", "unknown variable"},
+		{"if(stock > 0, 1, 0)", "unknown variable"},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.text)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.text, err)
+			continue
+		}
+		if _, err := e.Eval(testVars); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Eval(%q) error = %v, want %q", tt.text, err, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"cost+",
+		"(cost",
+		"cost)",
+		"cost 50",
+		"1..2",
+		"sqrt(4)",
+		"min(",
+		"min(1,)",
+		"if(1, 2)",
+		"cost $ 2",
+		"cost + 价格。",
+	} {
+		if _, err := Parse(text); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", text)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	e, err := Parse("（cost＋５０）")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := e.String(); got != "（cost＋５０）" {
+		t.Errorf("String() = %q, want the original text", got)
+	}
+}
+
+func TestConditions(t *testing.T) {
+	tests := []struct {
+		text        string
+		isCondition bool
+		want        bool
+	}{
+		{"profit >= 1500 && rarity >= 4", true, false},
+		{"profit >= 500 && rarity >= 4", true, true},
+		{"profit > 1000 || rarity == 4", true, true},
+		{"!(rarity < 3)", true, true},
+		{"cost < 2000", true, true},
+		// && binds tighter than ||
+		{"profit > 1000 && rarity > 5 || cost == 1000", true, true},
+		{"cost == 1000 || profit > 1000 && rarity > 5", true, true},
+		{"(cost == 1000 || profit > 1000) && rarity > 5", true, false},
+		// arithmetic binds tighter than comparison
+		{"cost + 500 == salePrice", true, true},
+		{"profit - cost*0.05", false, true},
+		{"if(rarity >= 4, profit, 0)", false, true},
+		{"(profit > 100)", true, true},
+		{"-(profit > 100)", false, true},
+		{"0", false, false},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.text)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.text, err)
+			continue
+		}
+		if got := e.IsCondition(); got != tt.isCondition {
+			t.Errorf("IsCondition(%q) = %v, want %v", tt.text, got, tt.isCondition)
+		}
+		got, err := e.EvalBool(testVars)
+		if err != nil {
+			t.Errorf("EvalBool(%q): %v", tt.text, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("EvalBool(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestConditionShortCircuit(t *testing.T) {
+	// balance is missing: the right side must only be evaluated when the left side does not decide
+	tests := []struct {
+		text    string
+		want    bool
+		wantErr bool
+	}{
+		{"cost < 2000 || balance > 0", true, false},
+		{"cost > 2000 && balance > 0", false, false},
+		{"cost < 2000 && balance > 0", false, true},
+		{"cost > 2000 || balance > 0", false, true},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.text)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.text, err)
+			continue
+		}
+		got, err := e.EvalBool(testVars)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("EvalBool(%q) = %v, %v, want %v, error %v", tt.text, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestConditionParseErrors(t *testing.T) {
+	for _, text := range []string{
+		"1 < 2 < 3",
+		"cost ==",
+		"&& cost",
+		"cost & 1",
+		"cost | 1",
+		"cost = 1",
+		"cost => 1",
+		"!",
+	} {
+		if _, err := Parse(text); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", text)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package extplugin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// 协议：插件进程常驻，agent 与插件之间通过 stdin/stdout 逐行交换 JSON
+//
+//	agent → 插件  {"id":1,"type":"call","kind":"action",...}
+//	插件 → agent  {"id":1,"type":"op","op":"click","x":100,"y":200}   （可选，仅动作可用，可多次）
+//	agent → 插件  {"id":1,"type":"op_result","success":true}
+//	插件 → agent  {"id":1,"type":"result","success":true}
+//
+// 图片以 base64 编码的 PNG 放在 image 字段中
+const (
+	msgCall     = "call"
+	msgOp       = "op"
+	msgOpResult = "op_result"
+	msgResult   = "result"
+)
+
+// maxLineSize - 单行消息上限，足够放下 base64 编码的整张截图
+const maxLineSize = 64 << 20
+
+var errProcessExited = errors.New("plugin process exited")
+
+// opHandler - 执行插件请求的控制器操作，为 nil 时拒绝所有操作
+type opHandler func(op *response) opResult
+
+// host - 一个常驻的插件进程，同一 exec + args 的组件共用
+// 调用串行进行；超时或进程退出后，下一次调用时重新启动
+type host struct {
+	path string
+	args []string
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	lines  chan []byte
+	nextID int
+}
+
+var (
+	hostsMu sync.Mutex
+	hosts   = map[string]*host{}
+)
+
+// hostFor - 按 exec 路径与参数取得共用的 host，进程在第一次调用时才启动
+func hostFor(path string, args []string) *host {
+	key := path + "\x00" + strings.Join(args, "\x00")
+	hostsMu.Lock()
+	defer hostsMu.Unlock()
+	h, ok := hosts[key]
+	if !ok {
+		h = &host{path: path, args: args}
+		hosts[key] = h
+	}
+	return h
+}
+
+// Shutdown stops every running plugin process; plugins are expected to exit once stdin is closed.
+func Shutdown() {
+	hostsMu.Lock()
+	defer hostsMu.Unlock()
+	for _, h := range hosts {
+		h.mu.Lock()
+		h.stop()
+		h.mu.Unlock()
+	}
+}
+
+func (h *host) start() error {
+	cmd := exec.Command(h.path, h.args...)
+	cmd.Dir = filepath.Dir(h.path)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	lines := make(chan []byte)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(nil, maxLineSize)
+		for scanner.Scan() {
+			lines <- bytes.Clone(scanner.Bytes())
+		}
+	}()
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			log.Debug().Str("plugin", h.path).Str("stderr", scanner.Text()).Msg("Plugin stderr")
+		}
+	}()
+
+	h.cmd, h.stdin, h.lines = cmd, stdin, lines
+	log.Info().Str("plugin", h.path).Int("pid", cmd.Process.Pid).Msg("Plugin process started")
+	return nil
+}
+
+// stop - 关闭 stdin 并结束进程，调用方需持有 h.mu
+func (h *host) stop() {
+	if h.cmd == nil {
+		return
+	}
+	h.stdin.Close()
+	_ = h.cmd.Process.Kill()
+	// 排空输出，让读取协程退出
+	for range h.lines {
+	}
+	_ = h.cmd.Wait()
+	h.cmd, h.stdin, h.lines = nil, nil, nil
+}
+
+// call - 发送一次请求并等待结果，期间处理插件发起的控制器操作
+func (h *host) call(req request, timeout time.Duration, ops opHandler) (*response, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cmd == nil {
+		if err := h.start(); err != nil {
+			return nil, err
+		}
+	}
+	h.nextID++
+	req.ID, req.Type = h.nextID, msgCall
+
+	resp, err := h.exchange(req, timeout, ops)
+	if err != nil {
+		// 进程状态已不可信，下次调用时重启
+		h.stop()
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (h *host) exchange(req request, timeout time.Duration, ops opHandler) (*response, error) {
+	// 超时直接结束进程，这样阻塞中的读写都会返回
+	var timedOut atomic.Bool
+	process := h.cmd.Process
+	timer := time.AfterFunc(timeout, func() {
+		timedOut.Store(true)
+		_ = process.Kill()
+	})
+	defer timer.Stop()
+
+	exited := func(err error) error {
+		if timedOut.Load() {
+			return fmt.Errorf("plugin timed out after %v", timeout)
+		}
+		return err
+	}
+
+	if err := h.send(req); err != nil {
+		return nil, exited(err)
+	}
+	for line := range h.lines {
+		var resp response
+		if err := json.Unmarshal(line, &resp); err != nil {
+			return nil, fmt.Errorf("invalid plugin output: %w", err)
+		}
+		if resp.ID != req.ID {
+			log.Warn().Str("plugin", h.path).Int("id", resp.ID).Int("want", req.ID).Msg("Plugin message for another call ignored")
+			continue
+		}
+
+		switch resp.Type {
+		case msgResult:
+			return &resp, nil
+		case msgOp:
+			result := opResult{Error: "controller ops are only available to actions"}
+			if ops != nil {
+				result = ops(&resp)
+			}
+			result.ID, result.Type = req.ID, msgOpResult
+			if err := h.send(result); err != nil {
+				return nil, exited(err)
+			}
+		default:
+			return nil, fmt.Errorf("unknown plugin message type %q", resp.Type)
+		}
+	}
+	return nil, exited(errProcessExited)
+}
+
+func (h *host) send(msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if _, err := h.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("%w: %v", errProcessExited, err)
+	}
+	return nil
+}
+
+// encodeImage - 将截图编码为 base64 PNG，没有截图时返回空串
+func encodeImage(img image.Image) (string, error) {
+	if img == nil {
+		return "", nil
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
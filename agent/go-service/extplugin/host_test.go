@@ -0,0 +1,132 @@
+package extplugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain - 设置 EXTPLUGIN_TEST_PLUGIN 时，测试程序本身充当插件进程
+func TestMain(m *testing.M) {
+	if os.Getenv("EXTPLUGIN_TEST_PLUGIN") == "1" {
+		fakePlugin()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// fakePlugin - param 为 echo 时返回 pid；为 op 时先请求一次点击再返回结果；为 hang 时不回复
+func fakePlugin() {
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(nil, maxLineSize)
+	writer := bufio.NewWriter(os.Stdout)
+	send := func(msg map[string]any) {
+		data, _ := json.Marshal(msg)
+		writer.Write(append(data, '\n'))
+		writer.Flush()
+	}
+	for scanner.Scan() {
+		var req request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			continue
+		}
+		switch req.Param {
+		case "echo":
+			send(map[string]any{"id": req.ID, "type": msgResult, "success": true, "detail": fmt.Sprintf("%d:%t", os.Getpid(), req.Image != "")})
+		case "op":
+			send(map[string]any{"id": req.ID, "type": msgOp, "op": "click", "x": 1, "y": 2})
+			scanner.Scan()
+			var result opResult
+			_ = json.Unmarshal(scanner.Bytes(), &result)
+			send(map[string]any{"id": req.ID, "type": msgResult, "success": result.Success, "detail": result.Error})
+		case "hang":
+		}
+	}
+}
+
+func testHost(t *testing.T) *host {
+	t.Helper()
+	t.Setenv("EXTPLUGIN_TEST_PLUGIN", "1")
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &host{path: exe, args: []string{"-test.run=^$"}}
+	t.Cleanup(func() {
+		h.mu.Lock()
+		h.stop()
+		h.mu.Unlock()
+	})
+	return h
+}
+
+func TestHostReusesProcess(t *testing.T) {
+	h := testHost(t)
+	first, err := h.call(request{Param: "echo"}, time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := h.call(request{Param: "echo", Image: "x"}, time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstPid, _, _ := strings.Cut(first.Detail, ":")
+	secondPid, hasImage, _ := strings.Cut(second.Detail, ":")
+	if firstPid != secondPid {
+		t.Errorf("calls ran in processes %s and %s, want one process", firstPid, secondPid)
+	}
+	if hasImage != "true" {
+		t.Errorf("plugin did not receive the image")
+	}
+}
+
+func TestHostOps(t *testing.T) {
+	h := testHost(t)
+
+	resp, err := h.call(request{Param: "op"}, time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Success || resp.Detail == "" {
+		t.Errorf("op without handler = %v, %q, want rejected with an error", resp.Success, resp.Detail)
+	}
+
+	var got *response
+	resp, err = h.call(request{Param: "op"}, time.Second, func(op *response) opResult {
+		got = op
+		return opResult{Success: true}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success {
+		t.Errorf("op with handler not reported as successful")
+	}
+	if got == nil || got.Op != "click" || got.X != 1 || got.Y != 2 {
+		t.Errorf("handler got %+v, want click at 1,2", got)
+	}
+}
+
+func TestHostRestartsAfterTimeout(t *testing.T) {
+	h := testHost(t)
+	before, err := h.call(request{Param: "echo"}, time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.call(request{Param: "hang"}, 100*time.Millisecond, nil); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("hanging call error = %v, want timeout", err)
+	}
+	after, err := h.call(request{Param: "echo"}, time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	beforePid, _, _ := strings.Cut(before.Detail, ":")
+	afterPid, _, _ := strings.Cut(after.Detail, ":")
+	if beforePid == afterPid {
+		t.Errorf("process %s reused after timeout, want a restart", beforePid)
+	}
+}
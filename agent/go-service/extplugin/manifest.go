@@ -0,0 +1,83 @@
+package extplugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+	"github.com/rs/zerolog/log"
+)
+
+// manifestFileName - 每个插件目录下的描述文件
+const manifestFileName = "plugin.json"
+
+// Manifest - plugins/<name>/plugin.json
+//
+//	{
+//	    "name": "MyPlugin",
+//	    "actions": [{"name": "MyAction", "exec": "my-plugin.exe", "args": ["action"], "description": "..."}],
+//	    "recognitions": [{"name": "MyReco", "exec": "my-plugin.exe", "args": ["reco"]}]
+//	}
+type Manifest struct {
+	Name         string      `json:"name"`
+	Actions      []Component `json:"actions"`
+	Recognitions []Component `json:"recognitions"`
+
+	// dir - 插件所在目录，exec 的相对路径以此为基准
+	dir string
+}
+
+// Component - 一个由外部进程实现的自定义动作或识别
+type Component struct {
+	Name string   `json:"name"`
+	Exec string   `json:"exec"`
+	Args []string `json:"args"`
+	// TimeoutMs - 单次调用超时，默认 30 秒
+	TimeoutMs int `json:"timeout_ms"`
+	// Description、Params - 可选，随 --list-actions 一起列出
+	Description string           `json:"description"`
+	Params      []registry.Param `json:"params"`
+}
+
+// Discover - 扫描 root 下每个子目录的 plugin.json，无法解析的插件会被跳过
+func Discover(root string) []Manifest {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warn().Err(err).Str("dir", root).Msg("Failed to read plugin directory")
+		}
+		return nil
+	}
+
+	var manifests []Manifest
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		data, err := os.ReadFile(filepath.Join(dir, manifestFileName))
+		if err != nil {
+			continue
+		}
+		var m Manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			log.Warn().Err(err).Str("dir", dir).Msg("Invalid plugin manifest, skipped")
+			continue
+		}
+		if m.Name == "" {
+			m.Name = entry.Name()
+		}
+		m.dir = dir
+		manifests = append(manifests, m)
+	}
+	return manifests
+}
+
+// execPath - exec 为相对路径时相对插件目录解析
+func (m Manifest) execPath(c Component) string {
+	if filepath.IsAbs(c.Exec) {
+		return c.Exec
+	}
+	return filepath.Join(m.dir, c.Exec)
+}
@@ -0,0 +1,170 @@
+package extplugin
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// request - 发给插件进程的一次调用
+type request struct {
+	ID    int      `json:"id"`
+	Type  string   `json:"type"`
+	Kind  string   `json:"kind"`
+	Name  string   `json:"name"`
+	Task  string   `json:"task"`
+	Param string   `json:"param"`
+	Box   maa.Rect `json:"box"`
+	// Image - 当前截图，base64 编码的 PNG，没有截图时为空
+	Image string `json:"image,omitempty"`
+}
+
+// response - 插件进程发来的消息，type 为 result（调用结果）或 op（控制器操作）
+type response struct {
+	ID   int    `json:"id"`
+	Type string `json:"type"`
+
+	Success bool `json:"success"`
+	// Next - 仅动作有效，非空时覆盖当前节点的 next
+	Next []string `json:"next,omitempty"`
+	// Box / Detail - 仅识别有效
+	Box    maa.Rect `json:"box"`
+	Detail string   `json:"detail"`
+
+	// Op - click / swipe / screencap，坐标参数见 runOp
+	Op         string `json:"op"`
+	X          int32  `json:"x"`
+	Y          int32  `json:"y"`
+	X2         int32  `json:"x2"`
+	Y2         int32  `json:"y2"`
+	DurationMs int    `json:"duration_ms"`
+}
+
+// opResult - 控制器操作的结果，screencap 成功时附带新的截图
+type opResult struct {
+	ID      int    `json:"id"`
+	Type    string `json:"type"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+	Image   string `json:"image,omitempty"`
+}
+
+// call - 将请求交给插件的常驻进程处理
+func call(path string, c Component, req request, img image.Image, ops opHandler) (*response, error) {
+	encoded, err := encodeImage(img)
+	if err != nil {
+		return nil, err
+	}
+	req.Image = encoded
+
+	timeout := defaultTimeout
+	if c.TimeoutMs > 0 {
+		timeout = time.Duration(c.TimeoutMs) * time.Millisecond
+	}
+	return hostFor(path, c.Args).call(req, timeout, ops)
+}
+
+// runOp - 在当前控制器上执行插件请求的操作
+//
+//	click      x, y
+//	swipe      x, y → x2, y2，duration_ms 默认 200
+//	screencap  返回新截图
+func runOp(controller *maa.Controller, op *response) opResult {
+	if controller == nil {
+		return opResult{Error: "failed to get controller"}
+	}
+	switch op.Op {
+	case "click":
+		return opResult{Success: controller.PostClick(op.X, op.Y).Wait().Success()}
+	case "swipe":
+		duration := time.Duration(op.DurationMs) * time.Millisecond
+		if duration <= 0 {
+			duration = 200 * time.Millisecond
+		}
+		return opResult{Success: controller.PostSwipe(op.X, op.Y, op.X2, op.Y2, duration).Wait().Success()}
+	case "screencap":
+		if !controller.PostScreencap().Wait().Success() {
+			return opResult{Error: "screencap failed"}
+		}
+		img, err := controller.CacheImage()
+		if err != nil || img == nil {
+			return opResult{Error: "no cached image"}
+		}
+		encoded, err := encodeImage(img)
+		if err != nil {
+			return opResult{Error: err.Error()}
+		}
+		return opResult{Success: true, Image: encoded}
+	default:
+		return opResult{Error: fmt.Sprintf("unknown op %q", op.Op)}
+	}
+}
+
+// ProcessAction - 将自定义动作转发给插件进程
+type ProcessAction struct {
+	path      string
+	component Component
+}
+
+func (a *ProcessAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	var img image.Image
+	controller := ctx.GetTasker().GetController()
+	if controller != nil {
+		img, _ = controller.CacheImage()
+	}
+
+	resp, err := call(a.path, a.component, request{
+		Kind:  "action",
+		Name:  arg.CustomActionName,
+		Task:  arg.CurrentTaskName,
+		Param: arg.CustomActionParam,
+		Box:   arg.Box,
+	}, img, func(op *response) opResult {
+		return runOp(controller, op)
+	})
+	if err != nil {
+		log.Error().Err(err).Str("action", arg.CustomActionName).Msg("Plugin action failed")
+		return false
+	}
+
+	if len(resp.Next) > 0 {
+		next := make([]maa.NodeNextItem, 0, len(resp.Next))
+		for _, name := range resp.Next {
+			next = append(next, maa.NodeNextItem{Name: name})
+		}
+		ctx.OverrideNext(arg.CurrentTaskName, next)
+	}
+	return resp.Success
+}
+
+// ProcessRecognition - 将自定义识别转发给插件进程
+type ProcessRecognition struct {
+	path      string
+	component Component
+}
+
+func (r *ProcessRecognition) Run(ctx *maa.Context, arg *maa.CustomRecognitionArg) (*maa.CustomRecognitionResult, bool) {
+	resp, err := call(r.path, r.component, request{
+		Kind:  "recognition",
+		Name:  arg.CustomRecognitionName,
+		Task:  arg.CurrentTaskName,
+		Param: arg.CustomRecognitionParam,
+		Box:   arg.Roi,
+	}, arg.Img, nil)
+	if err != nil {
+		log.Error().Err(err).Str("recognition", arg.CustomRecognitionName).Msg("Plugin recognition failed")
+		return nil, false
+	}
+	if !resp.Success {
+		return nil, false
+	}
+	return &maa.CustomRecognitionResult{
+		Box:    resp.Box,
+		Detail: resp.Detail,
+	}, true
+}
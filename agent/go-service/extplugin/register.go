@@ -0,0 +1,47 @@
+package extplugin
+
+import (
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	_ maa.CustomActionRunner      = &ProcessAction{}
+	_ maa.CustomRecognitionRunner = &ProcessRecognition{}
+)
+
+// Register discovers plugins under root and adds their components to the registry as plugin:<name>.
+// Component names must not collide with built-in ones, so plugins are added after every package;
+// the first plugin to claim a name wins.
+func Register(root string) {
+	for _, m := range Discover(root) {
+		module := "plugin:" + m.Name
+		for _, c := range m.Actions {
+			if !checkName(m, c) {
+				continue
+			}
+			action := registry.Action(c.Name, &ProcessAction{path: m.execPath(c), component: c}, c.Description, c.Params...)
+			if registry.Add(module, action) {
+				log.Info().Str("plugin", m.Name).Str("action", c.Name).Msg("Plugin action registered")
+			}
+		}
+		for _, c := range m.Recognitions {
+			if !checkName(m, c) {
+				continue
+			}
+			recognition := registry.Recognition(c.Name, &ProcessRecognition{path: m.execPath(c), component: c}, c.Description, c.Params...)
+			if registry.Add(module, recognition) {
+				log.Info().Str("plugin", m.Name).Str("recognition", c.Name).Msg("Plugin recognition registered")
+			}
+		}
+	}
+}
+
+func checkName(m Manifest, c Component) bool {
+	if c.Name == "" || c.Exec == "" {
+		log.Warn().Str("plugin", m.Name).Msg("Plugin component missing name or exec, skipped")
+		return false
+	}
+	return true
+}
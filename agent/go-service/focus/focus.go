@@ -0,0 +1,41 @@
+package focus
+
+import (
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/safemode"
+)
+
+// Level - 提示信息的级别，级别越高越详细
+type Level int
+
+const (
+	// Result - 最终结果、建议与需要用户处理的警告，任何设置下都显示
+	Result Level = iota
+	// Step - 阶段进度，例如数据加载完成、开始扫描某个货架
+	Step
+	// Detail - 逐项细节，例如每个物品的识别结果
+	Detail
+)
+
+// 配置中的取值
+const (
+	Quiet   = "quiet"
+	Normal  = "normal"
+	Verbose = "verbose"
+)
+
+// Allowed reports whether a message of the given level should be shown under the configured verbosity.
+// Unknown values behave like normal. Safe-mode runs show everything.
+func Allowed(level Level) bool {
+	if safemode.Active() {
+		return true
+	}
+	switch agentconfig.Get().Focus.Verbosity {
+	case Quiet:
+		return level <= Result
+	case Verbose:
+		return true
+	default:
+		return level <= Step
+	}
+}
@@ -0,0 +1,90 @@
+package foreground
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// ForegroundGuard brings the game window to the foreground before a task starts and checks
+// that the controller is attached to the intended window or emulator instance.
+// Clicks from Win32 controllers that use the real cursor land on whatever window is on top.
+type ForegroundGuard struct{}
+
+// OnTaskerTask runs the check when a task starts
+func (g *ForegroundGuard) OnTaskerTask(tasker *maa.Tasker, event maa.EventStatus, detail maa.TaskerTaskDetail) {
+	if event != maa.EventStatusStarting {
+		return
+	}
+	cfg := agentconfig.Get().Foreground
+	if !cfg.Enabled {
+		return
+	}
+	controller := tasker.GetController()
+	if controller == nil {
+		return
+	}
+	uuid, err := controller.GetUUID()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to get controller UUID, skipping foreground check")
+		return
+	}
+
+	if err := check(cfg, uuid); err != nil {
+		log.Error().Err(err).Str("entry", detail.Entry).Str("uuid", uuid).Msg("Controller is not attached to the intended window")
+		if cfg.StopOnMismatch {
+			fmt.Printf("⚠️ %v，已停止任务\n", err)
+			tasker.PostStop()
+		} else {
+			fmt.Printf("⚠️ %v\n", err)
+		}
+		return
+	}
+
+	hwnd, ok := parseHandle(uuid)
+	if !ok {
+		// ADB controllers send input to the emulator directly, there is no window to raise
+		return
+	}
+	if err := bringToFront(hwnd); err != nil {
+		log.Warn().Err(err).Str("uuid", uuid).Msg("Failed to bring game window to foreground")
+		return
+	}
+	log.Debug().Str("uuid", uuid).Msg("Game window is in the foreground")
+}
+
+// check compares the controller against the configured instance and window title
+func check(cfg agentconfig.ForegroundConfig, uuid string) error {
+	if cfg.Instance != "" && !strings.Contains(uuid, cfg.Instance) {
+		return fmt.Errorf("当前连接的是 %s，不是配置的实例 %s", uuid, cfg.Instance)
+	}
+	if cfg.WindowTitle == "" {
+		return nil
+	}
+	hwnd, ok := parseHandle(uuid)
+	if !ok {
+		return nil
+	}
+	title, err := windowTitle(hwnd)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(title, cfg.WindowTitle) {
+		return fmt.Errorf("当前连接的窗口是「%s」，标题不包含 %s", title, cfg.WindowTitle)
+	}
+	return nil
+}
+
+// parseHandle reads the window handle ("0x..." or decimal) from a Win32 controller UUID;
+// ADB serials such as "127.0.0.1:16384" or "emulator-5554" are not handles
+func parseHandle(uuid string) (uintptr, bool) {
+	v, err := strconv.ParseUint(strings.TrimSpace(uuid), 0, 64)
+	if err != nil || v == 0 {
+		return 0, false
+	}
+	return uintptr(v), true
+}
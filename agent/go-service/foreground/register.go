@@ -0,0 +1,12 @@
+package foreground
+
+import "github.com/MaaXYZ/maa-framework-go/v4"
+
+var (
+	_ maa.TaskerEventSink = &ForegroundGuard{}
+)
+
+// Register registers the foreground guard as a tasker sink
+func Register() {
+	maa.AgentServerAddTaskerSink(&ForegroundGuard{})
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package foreground
+
+import "errors"
+
+var errUnsupported = errors.New("window management is only supported on Windows")
+
+// windowTitle is only supported on Windows
+func windowTitle(hwnd uintptr) (string, error) {
+	return "", errUnsupported
+}
+
+// bringToFront is only supported on Windows
+func bringToFront(hwnd uintptr) error {
+	return errUnsupported
+}
@@ -0,0 +1,51 @@
+//go:build windows
+
+package foreground
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	user32                  = windows.NewLazySystemDLL("user32.dll")
+	procIsWindow            = user32.NewProc("IsWindow")
+	procIsIconic            = user32.NewProc("IsIconic")
+	procShowWindow          = user32.NewProc("ShowWindow")
+	procSetForegroundWindow = user32.NewProc("SetForegroundWindow")
+	procGetForegroundWindow = user32.NewProc("GetForegroundWindow")
+	procGetWindowTextW      = user32.NewProc("GetWindowTextW")
+)
+
+// SW_RESTORE restores a minimized window to its previous size and position
+const SW_RESTORE = 9
+
+// windowTitle returns the title bar text of hwnd
+func windowTitle(hwnd uintptr) (string, error) {
+	if ret, _, _ := procIsWindow.Call(hwnd); ret == 0 {
+		return "", fmt.Errorf("window 0x%x no longer exists", hwnd)
+	}
+	buf := make([]uint16, 512)
+	procGetWindowTextW.Call(hwnd, uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	return windows.UTF16ToString(buf), nil
+}
+
+// bringToFront restores hwnd if minimized and makes it the foreground window
+func bringToFront(hwnd uintptr) error {
+	if ret, _, _ := procIsWindow.Call(hwnd); ret == 0 {
+		return fmt.Errorf("0x%x is not a window", hwnd)
+	}
+	if fg, _, _ := procGetForegroundWindow.Call(); fg == hwnd {
+		return nil
+	}
+	if iconic, _, _ := procIsIconic.Call(hwnd); iconic != 0 {
+		procShowWindow.Call(hwnd, SW_RESTORE)
+	}
+	// Windows may refuse to switch focus to another process; the window then only flashes in the taskbar
+	if ret, _, err := procSetForegroundWindow.Call(hwnd); ret == 0 {
+		return fmt.Errorf("SetForegroundWindow failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package gameversion
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/ocrutil"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	androidPackageName = "com.hypergryph.endfield"
+	// Optional pipeline node that OCRs the version label on login/settings screen
+	versionOCRPipelineName = "GameVersionOCR"
+	// compatibilityNode - attach.modules 中登记各模块支持的版本
+	compatibilityNode = "GameVersionCompatibility"
+	// refusedNode - 版本不兼容时转到的结束节点
+	refusedNode = "GameVersionGateRefused"
+)
+
+var (
+	versionRe = regexp.MustCompile(`\d+(?:\.\d+)+`)
+
+	// detectedVersion caches the version for the lifetime of the agent
+	detectedVersion string
+	detectMu        sync.Mutex
+)
+
+// Compatibility - 一个模块支持的游戏版本
+type Compatibility struct {
+	MinVersion string `json:"min_version"`
+	MaxVersion string `json:"max_version"`
+	// Incompatible - 范围内但已知不兼容的版本
+	Incompatible []string `json:"incompatible"`
+}
+
+// Check returns why version is not supported, or "" when it is
+func (c Compatibility) Check(version string) string {
+	for _, v := range c.Incompatible {
+		if CompareVersion(version, v) == 0 {
+			return fmt.Sprintf("已知与游戏版本 %s 不兼容", version)
+		}
+	}
+	if c.MinVersion != "" && CompareVersion(version, c.MinVersion) < 0 {
+		return fmt.Sprintf("需要游戏版本 %s 及以上，当前为 %s", c.MinVersion, version)
+	}
+	if c.MaxVersion != "" && CompareVersion(version, c.MaxVersion) > 0 {
+		return fmt.Sprintf("尚未适配游戏版本 %s（已适配到 %s）", version, c.MaxVersion)
+	}
+	return ""
+}
+
+// LoadCompatibility reads the entry of module from GameVersionCompatibility; ok is false when
+// the module is not listed
+func LoadCompatibility(ctx *maa.Context, module string) (Compatibility, bool) {
+	raw, err := ctx.GetNodeJSON(compatibilityNode)
+	if err != nil || raw == "" {
+		return Compatibility{}, false
+	}
+	var data struct {
+		Attach struct {
+			Modules map[string]Compatibility `json:"modules"`
+		} `json:"attach"`
+	}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		log.Warn().Err(err).Str("node", compatibilityNode).Msg("Failed to parse game version compatibility table")
+		return Compatibility{}, false
+	}
+	c, ok := data.Attach.Modules[module]
+	return c, ok
+}
+
+// GameVersionGateAction - stop a module when the game version is outside its supported range
+// custom_action_param: {"module": "Resell"}; the range comes from GameVersionCompatibility,
+// min_version / max_version in the param override it
+type GameVersionGateAction struct{}
+
+func (a *GameVersionGateAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	var params struct {
+		Module     string `json:"module"`
+		MinVersion string `json:"min_version"`
+		MaxVersion string `json:"max_version"`
+	}
+	if err := json.Unmarshal([]byte(arg.CustomActionParam), &params); err != nil {
+		log.Error().Err(err).Msg("Failed to parse CustomActionParam")
+		return false
+	}
+	if params.Module == "" {
+		params.Module = arg.CurrentTaskName
+	}
+	compat, _ := LoadCompatibility(ctx, params.Module)
+	if params.MinVersion != "" {
+		compat.MinVersion = params.MinVersion
+	}
+	if params.MaxVersion != "" {
+		compat.MaxVersion = params.MaxVersion
+	}
+
+	version, ok := DetectVersion(ctx)
+	if !ok {
+		// Unknown version should not block users, only warn
+		log.Warn().Str("module", params.Module).Msg("Game version unknown, skip version gating")
+		return true
+	}
+
+	if reason := compat.Check(version); reason != "" {
+		log.Error().
+			Str("module", params.Module).
+			Str("version", version).
+			Str("min_version", compat.MinVersion).
+			Str("max_version", compat.MaxVersion).
+			Strs("incompatible", compat.Incompatible).
+			Msg("Module is not compatible with current game version")
+		showMessage(ctx, fmt.Sprintf("⚠️ 模块 %s %s，本次不运行；请更新 MaaEnd 后再试", params.Module, reason))
+		ctx.OverrideNext(arg.CurrentTaskName, []maa.NodeNextItem{{Name: refusedNode}})
+		return true
+	}
+
+	log.Info().Str("module", params.Module).Str("version", version).Msg("Game version check passed")
+	return true
+}
+
+// DetectVersion returns the game client version, via ADB package metadata or OCR node
+func DetectVersion(ctx *maa.Context) (string, bool) {
+	detectMu.Lock()
+	defer detectMu.Unlock()
+
+	if detectedVersion != "" {
+		return detectedVersion, true
+	}
+
+	controller := ctx.GetTasker().GetController()
+	if controller == nil {
+		return "", false
+	}
+
+	// 1. ADB: dumpsys package metadata (fails silently on Win32 controller)
+	cmd := fmt.Sprintf("dumpsys package %s | grep versionName", androidPackageName)
+	if controller.PostShell(cmd, 5*time.Second).Wait().Success() {
+		if output, err := controller.GetShellOutput(); err == nil {
+			if v := versionRe.FindString(output); v != "" {
+				detectedVersion = v
+				log.Info().Str("version", v).Str("source", "adb").Msg("Game version detected")
+				return v, true
+			}
+		}
+	}
+
+	// 2. OCR: only if resource defines the version label node
+	if raw, err := ctx.GetNodeJSON(versionOCRPipelineName); err != nil || raw == "" {
+		return "", false
+	}
+	img, err := controller.CacheImage()
+	if err != nil || img == nil {
+		return "", false
+	}
+	detail, err := ctx.RunRecognition(versionOCRPipelineName, img, nil)
+	if err != nil {
+		return "", false
+	}
+	ocr, ok := ocrutil.Text(detail, 0)
+	if !ok {
+		return "", false
+	}
+	if v, ok := ocrutil.Match(ocr.Text, versionRe); ok {
+		detectedVersion = v
+		log.Info().Str("version", v).Str("source", "ocr").Msg("Game version detected")
+		return v, true
+	}
+	return "", false
+}
+
+// CompareVersion compares dotted versions numerically, returns -1, 0 or 1
+func CompareVersion(a, b string) int {
+	pa := strings.Split(a, ".")
+	pb := strings.Split(b, ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var na, nb int
+		if i < len(pa) {
+			na, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			nb, _ = strconv.Atoi(pb[i])
+		}
+		if na < nb {
+			return -1
+		}
+		if na > nb {
+			return 1
+		}
+	}
+	return 0
+}
+
+func showMessage(ctx *maa.Context, text string) {
+	ctx.RunTask("GameVersion_TaskShowMessage", map[string]interface{}{
+		"GameVersion_TaskShowMessage": map[string]interface{}{
+			"recognition": "DirectHit",
+			"action":      "DoNothing",
+			"focus": map[string]interface{}{
+				"Node.Action.Starting": text,
+			},
+		},
+	})
+}
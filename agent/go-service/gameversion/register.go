@@ -0,0 +1,22 @@
+package gameversion
+
+import (
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+var (
+	_ maa.CustomActionRunner = &GameVersionGateAction{}
+)
+
+// Components returns the custom components of gameversion package
+func Components() []registry.Component {
+	P := registry.P
+	return []registry.Component{
+		registry.Action("GameVersionGateAction", &GameVersionGateAction{}, "游戏版本不在模块支持的范围内时提示原因并结束任务",
+			P("module", "string", "模块名，按它查 GameVersionCompatibility 中登记的支持版本"),
+			P("min_version", "string", "可选，覆盖登记的最低版本"),
+			P("max_version", "string", "可选，覆盖登记的最高版本"),
+		),
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"regexp"
 
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/taskresult"
 	"github.com/MaaXYZ/maa-framework-go/v4"
 	"github.com/rs/zerolog/log"
 )
@@ -11,6 +12,9 @@ import (
 // blueprintCodes 蓝图码队列
 var blueprintCodes []string
 
+// blueprintTotal 本次导入的蓝图码总数
+var blueprintTotal int
+
 func parseBlueprintCodes(text string) []string {
 	re := regexp.MustCompile(`EF[a-zA-Z0-9]+`)
 	return re.FindAllString(text, -1)
@@ -38,6 +42,7 @@ func (a *ImportBluePrintsInitTextAction) Run(ctx *maa.Context, arg *maa.CustomAc
 	}
 
 	blueprintCodes = codes
+	blueprintTotal = len(codes)
 	log.Info().Int("count", len(codes)).Strs("codes", codes).Msg("Parsed blueprint codes")
 
 	return true
@@ -48,6 +53,11 @@ type ImportBluePrintsFinishAction struct{}
 func (a *ImportBluePrintsFinishAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
 	if len(blueprintCodes) == 0 {
 		log.Info().Msg("All blueprint codes processed")
+		taskresult.Emit(ctx, taskresult.Result{
+			Task:    "ImportBluePrints",
+			Status:  taskresult.StatusSuccess,
+			Metrics: map[string]float64{"imported": float64(blueprintTotal)},
+		})
 		ctx.GetTasker().PostStop()
 		return true
 	}
@@ -1,6 +1,9 @@
 package importtask
 
-import "github.com/MaaXYZ/maa-framework-go/v4"
+import (
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
 
 var (
 	_ maa.CustomActionRunner = &ImportBluePrintsInitTextAction{}
@@ -8,9 +11,13 @@ var (
 	_ maa.CustomActionRunner = &ImportBluePrintsEnterCodeAction{}
 )
 
-// Register registers all custom action components for importtask package
-func Register() {
-	maa.AgentServerRegisterCustomAction("ImportBluePrintsInitTextAction", &ImportBluePrintsInitTextAction{})
-	maa.AgentServerRegisterCustomAction("ImportBluePrintsFinishAction", &ImportBluePrintsFinishAction{})
-	maa.AgentServerRegisterCustomAction("ImportBluePrintsEnterCodeAction", &ImportBluePrintsEnterCodeAction{})
+// Components returns the custom components of importtask package
+func Components() []registry.Component {
+	return []registry.Component{
+		registry.Action("ImportBluePrintsInitTextAction", &ImportBluePrintsInitTextAction{}, "从输入文本中解析蓝图码",
+			registry.P("text", "string", "包含蓝图码的文本"),
+		),
+		registry.Action("ImportBluePrintsFinishAction", &ImportBluePrintsFinishAction{}, "全部蓝图码处理完后输出结果并停止任务"),
+		registry.Action("ImportBluePrintsEnterCodeAction", &ImportBluePrintsEnterCodeAction{}, "输入下一个蓝图码"),
+	}
 }
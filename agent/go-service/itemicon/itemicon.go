@@ -0,0 +1,117 @@
+package itemicon
+
+import (
+	"encoding/json"
+	"image"
+	"strings"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/assets"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// iconNode - 图标模板库节点，attach.icons 为 物品名 -> 模板路径
+const iconNode = "ItemIconMatch"
+
+const defaultThreshold = 0.8
+
+// assetIcons - 内置资源中的补充图标库，物品名 -> 资源图片路径，同名物品以它为准
+const assetIcons = "itemicon/icons.json"
+
+// assetTemplate - 图标库中指向内置资源图片的模板前缀，匹配前先注册该图片
+const assetTemplate = "asset:"
+
+type database struct {
+	Icons     map[string]string `json:"icons"`
+	Threshold float64           `json:"threshold"`
+}
+
+// load reads the icon database from the node attach and adds the icons of the embedded assets;
+// a missing node yields only the asset icons
+func load(ctx *maa.Context) database {
+	db := database{Threshold: defaultThreshold, Icons: map[string]string{}}
+	if raw, err := ctx.GetNodeJSON(iconNode); err == nil && raw != "" {
+		var node struct {
+			Attach database `json:"attach"`
+		}
+		if err := json.Unmarshal([]byte(raw), &node); err != nil {
+			log.Warn().Err(err).Msg("Failed to parse item icon database")
+		} else {
+			if node.Attach.Threshold > 0 {
+				db.Threshold = node.Attach.Threshold
+			}
+			for item, template := range node.Attach.Icons {
+				db.Icons[item] = template
+			}
+		}
+	}
+
+	data, _, err := assets.ReadFile(assetIcons)
+	if err != nil {
+		return db
+	}
+	var icons map[string]string
+	if err := json.Unmarshal(data, &icons); err != nil {
+		log.Warn().Err(err).Str("asset", assetIcons).Msg("Failed to parse asset icon database")
+		return db
+	}
+	for item, file := range icons {
+		db.Icons[item] = assetTemplate + file
+	}
+	return db
+}
+
+// Known reports whether the database has an icon for item
+func Known(ctx *maa.Context, item string) bool {
+	_, ok := load(ctx).Icons[item]
+	return ok
+}
+
+// Identify matches every icon in the database within roi and returns the best match
+func Identify(ctx *maa.Context, img image.Image, roi maa.Rect) (name string, score float64, ok bool) {
+	db := load(ctx)
+	for item, template := range db.Icons {
+		if s, hit := match(ctx, img, roi, template, db.Threshold); hit && s > score {
+			name, score, ok = item, s, true
+		}
+	}
+	return name, score, ok
+}
+
+// Matches reports whether the icon of item is found within roi
+func Matches(ctx *maa.Context, img image.Image, roi maa.Rect, item string) bool {
+	db := load(ctx)
+	template, exists := db.Icons[item]
+	if !exists {
+		return false
+	}
+	_, hit := match(ctx, img, roi, template, db.Threshold)
+	return hit
+}
+
+func match(ctx *maa.Context, img image.Image, roi maa.Rect, template string, threshold float64) (float64, bool) {
+	if file, ok := strings.CutPrefix(template, assetTemplate); ok {
+		registered, err := assets.Register(ctx, file)
+		if err != nil {
+			log.Warn().Err(err).Str("asset", file).Msg("Failed to register icon asset")
+			return 0, false
+		}
+		template = registered
+	}
+	detail, err := ctx.RunRecognition(iconNode, img, map[string]any{
+		iconNode: map[string]any{
+			"recognition": "TemplateMatch",
+			"template":    template,
+			"roi":         roi,
+			"threshold":   threshold,
+		},
+	})
+	if err != nil || detail == nil || !detail.Hit || detail.Results == nil || len(detail.Results.Best) == 0 {
+		return 0, false
+	}
+	result, ok := detail.Results.Best[0].AsTemplateMatch()
+	if !ok {
+		return 0, false
+	}
+	return result.Score, true
+}
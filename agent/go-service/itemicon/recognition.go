@@ -0,0 +1,34 @@
+package itemicon
+
+import (
+	"encoding/json"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// ItemIconRecognition - 在 roi 内按图标识别物品，命中 expected 中任一物品即成功
+// custom_recognition_param: {"expected": ["嵌晶玉", "武库配额"]}
+// 可作为 And 识别的子项，与名称 OCR 互相校验
+type ItemIconRecognition struct{}
+
+func (r *ItemIconRecognition) Run(ctx *maa.Context, arg *maa.CustomRecognitionArg) (*maa.CustomRecognitionResult, bool) {
+	var params struct {
+		Expected []string `json:"expected"`
+	}
+	if err := json.Unmarshal([]byte(arg.CustomRecognitionParam), &params); err != nil {
+		log.Error().Err(err).Msg("Failed to parse ItemIconRecognition param")
+		return nil, false
+	}
+
+	for _, item := range params.Expected {
+		if Matches(ctx, arg.Img, arg.Roi, item) {
+			detail, _ := json.Marshal(map[string]string{"item": item})
+			return &maa.CustomRecognitionResult{
+				Box:    arg.Roi,
+				Detail: string(detail),
+			}, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,19 @@
+package itemicon
+
+import (
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+var (
+	_ maa.CustomRecognitionRunner = &ItemIconRecognition{}
+)
+
+// Components returns the custom components of itemicon package
+func Components() []registry.Component {
+	return []registry.Component{
+		registry.Recognition("ItemIconRecognition", &ItemIconRecognition{}, "在 roi 内按图标识别物品，命中 expected 中任一物品即成功",
+			registry.P("expected", "array", "期望的物品名"),
+		),
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+)
+
+// runListActions - go-service --list-actions [-module Resell]
+// 以 JSON 输出全部自定义动作与识别，包括 plugins/ 下的插件组件，供前端工具生成参数表单
+func runListActions(args []string) error {
+	fs := flag.NewFlagSet("--list-actions", flag.ContinueOnError)
+	module := fs.String("module", "", "only list components of this module")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	addComponents()
+	list := []registry.Component{}
+	for _, c := range registry.List() {
+		if *module == "" || c.Module == *module {
+			list = append(list, c)
+		}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(list)
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/logi18n"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -55,7 +56,8 @@ func initLogger() (*os.File, error) {
 		With().
 		Timestamp().
 		Caller().
-		Logger()
+		Logger().
+		Hook(logi18n.Hook{})
 
 	zerolog.SetGlobalLevel(zerolog.DebugLevel)
 
@@ -0,0 +1,138 @@
+// Package logi18n tags log events with a language-neutral message key and an English
+// translation, so logs written in Chinese can be read and searched by everyone.
+package logi18n
+
+import (
+	_ "embed"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/rs/zerolog"
+)
+
+// catalog.json lists every Chinese log message with its key and English text.
+// Messages logged with Msgf keep their format verbs so formatted output still matches.
+//
+//go:embed catalog.json
+var catalogJSON []byte
+
+// UntranslatedKey is used for Chinese messages missing from the catalog
+const UntranslatedKey = "untranslated"
+
+type entry struct {
+	Zh  string `json:"zh"`
+	Key string `json:"key"`
+	En  string `json:"en"`
+}
+
+type patternEntry struct {
+	entry
+	re *regexp.Regexp
+}
+
+var (
+	loadOnce sync.Once
+	exact    map[string]entry
+	patterns []patternEntry
+)
+
+// formatVerb matches the fmt verbs used in log messages, such as %d, %s and %.1f
+var formatVerb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func load() {
+	exact = make(map[string]entry)
+	var entries []entry
+	if err := json.Unmarshal(catalogJSON, &entries); err != nil {
+		return
+	}
+	for _, e := range entries {
+		if !formatVerb.MatchString(e.Zh) {
+			exact[e.Zh] = e
+			continue
+		}
+		var b strings.Builder
+		b.WriteString("^")
+		last := 0
+		for _, loc := range formatVerb.FindAllStringIndex(e.Zh, -1) {
+			b.WriteString(regexp.QuoteMeta(e.Zh[last:loc[0]]))
+			b.WriteString("(.*?)")
+			last = loc[1]
+		}
+		b.WriteString(regexp.QuoteMeta(e.Zh[last:]))
+		b.WriteString("$")
+		patterns = append(patterns, patternEntry{entry: e, re: regexp.MustCompile(b.String())})
+	}
+}
+
+// Translate returns the message key and English text of msg.
+// English messages get a key derived from the text and no translation.
+func Translate(msg string) (key, en string) {
+	loadOnce.Do(load)
+	if !hasHan(msg) {
+		return slug(msg), ""
+	}
+	if e, ok := exact[msg]; ok {
+		return e.Key, e.En
+	}
+	for _, p := range patterns {
+		m := p.re.FindStringSubmatch(msg)
+		if m == nil {
+			continue
+		}
+		// Fill the English format verbs with the captured values in order
+		i := 0
+		en := formatVerb.ReplaceAllStringFunc(p.En, func(string) string {
+			i++
+			if i < len(m) {
+				return m[i]
+			}
+			return ""
+		})
+		return p.Key, en
+	}
+	return UntranslatedKey, ""
+}
+
+func hasHan(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// slug turns "Failed to OverridePipeline" into "failed_to_overridepipeline"
+func slug(s string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			underscore = false
+		} else if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+// Hook adds msg_key, and msg_en for Chinese messages, to every event while log.bilingual is on
+type Hook struct{}
+
+// Run implements zerolog.Hook
+func (Hook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if msg == "" || !agentconfig.Get().Log.Bilingual {
+		return
+	}
+	key, en := Translate(msg)
+	e.Str("msg_key", key)
+	if en != "" {
+		e.Str("msg_en", en)
+	}
+}
@@ -0,0 +1,76 @@
+// Package maaend lets other Go programs run MaaEnd automations on their own tasker.
+//
+// The tasker must already be bound to a connected controller and to a resource
+// that has loaded MaaEnd's assets/resource bundle.
+package maaend
+
+import (
+	"fmt"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/creditshopping"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/purchase"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/resell"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/taskresult"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+// Client runs MaaEnd tasks on a tasker
+type Client struct {
+	tasker *maa.Tasker
+}
+
+// Result is the outcome of one task run
+type Result struct {
+	Entry   string
+	Success bool
+	Detail  *maa.TaskDetail
+	// Outcome - 任务输出的结构化结果，任务没有输出时为 nil
+	Outcome *taskresult.Result
+}
+
+// NewClient registers the custom actions MaaEnd tasks need on the tasker's resource
+func NewClient(tasker *maa.Tasker) (*Client, error) {
+	if tasker == nil {
+		return nil, fmt.Errorf("tasker is nil")
+	}
+	if !tasker.Initialized() {
+		return nil, fmt.Errorf("tasker not initialized")
+	}
+	res := tasker.GetResource()
+	if res == nil {
+		return nil, fmt.Errorf("tasker has no resource bound")
+	}
+
+	for _, components := range [][]registry.Component{
+		resell.Components(),
+		creditshopping.Components(),
+		purchase.Components(),
+		taskresult.Components(),
+	} {
+		if err := registry.RegisterTo(res, components...); err != nil {
+			return nil, err
+		}
+	}
+	return &Client{tasker: tasker}, nil
+}
+
+// run posts the entry node with override and waits for it to finish
+// task is the name the task reports its taskresult under
+func (c *Client) run(entry, task string, override map[string]any) (*Result, error) {
+	before, _ := taskresult.Last(task)
+	job := c.tasker.PostTask(entry, override).Wait()
+	detail, err := job.GetDetail()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", entry, err)
+	}
+	result := &Result{
+		Entry:   entry,
+		Success: job.Success(),
+		Detail:  detail,
+	}
+	if outcome, ok := taskresult.Last(task); ok && outcome.FinishedAt.After(before.FinishedAt) {
+		result.Outcome = &outcome
+	}
+	return result, nil
+}
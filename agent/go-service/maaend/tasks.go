@@ -0,0 +1,96 @@
+package maaend
+
+import "strings"
+
+// ResellOptions mirrors the options of the AutoResell task
+type ResellOptions struct {
+	// MinimumProfit - 整数或表达式，如 "3000"、"cost*0.1+50"
+	MinimumProfit string
+	// DecisionPolicy - 选品打分表达式或条件，如 "profit >= 1500 && rarity >= 4"，为空时按利润最高选品
+	DecisionPolicy string
+	// SearchItems - 非空时优先搜索这些商品直接购买
+	SearchItems       []string
+	ScanSpecialOffers bool
+	// DisableChangeRegion - 只在当前地区倒卖，不切换到下一个地区
+	DisableChangeRegion bool
+	// ExcludeFriends - 不参与售价比较的好友名
+	ExcludeFriends []string
+	// ConfirmAbovePrice - 成本价超过该值时跳过购买，0 表示不限制
+	ConfirmAbovePrice int
+}
+
+// DefaultResellOptions returns the same defaults as the AutoResell task
+func DefaultResellOptions() ResellOptions {
+	return ResellOptions{
+		MinimumProfit:  "3000",
+		DecisionPolicy: "profit",
+	}
+}
+
+// Resell runs the resell task from ResellMain
+func (c *Client) Resell(opts ResellOptions) (*Result, error) {
+	enableRegionChange := !opts.DisableChangeRegion
+	return c.run("ResellMain", "Resell", map[string]any{
+		"ResellStart": map[string]any{
+			"action": map[string]any{
+				"param": map[string]any{
+					"custom_action_param": map[string]any{
+						"MinimumProfit":     opts.MinimumProfit,
+						"DecisionPolicy":    opts.DecisionPolicy,
+						"SearchItems":       strings.Join(opts.SearchItems, ";"),
+						"ScanSpecialOffers": opts.ScanSpecialOffers,
+						"ExcludeFriends":    strings.Join(opts.ExcludeFriends, ";"),
+						"ConfirmAbovePrice": opts.ConfirmAbovePrice,
+						"ConfirmMode":       "skip",
+					},
+				},
+			},
+		},
+		"ResellFristAreaInWuLing": map[string]any{"enable": enableRegionChange},
+		"ChangeNextRegion":        map[string]any{"enable": enableRegionChange},
+	})
+}
+
+// CreditShoppingOptions mirrors the options of the CreditShopping task
+type CreditShoppingOptions struct {
+	// BuyFirst - 优先购买的商品名关键字
+	BuyFirst []string
+	// Blacklist - 不购买的商品名关键字
+	Blacklist []string
+	// Force - 信用溢出时无视黑名单
+	Force        bool
+	OnlyDiscount bool
+	// DecisionPolicy - 普通购买的条件，如 "price <= 200"，为空时不限制
+	DecisionPolicy string
+	// Reserve - 信用点低于 300 时停止购买（白名单商品仍会购买）
+	Reserve bool
+}
+
+// DefaultCreditShoppingOptions returns the same defaults as the CreditShopping task
+func DefaultCreditShoppingOptions() CreditShoppingOptions {
+	return CreditShoppingOptions{
+		BuyFirst: []string{"嵌晶玉", "武库配额"},
+	}
+}
+
+// CreditShopping runs the credit shop task from CreditShoppingMain
+func (c *Client) CreditShopping(opts CreditShoppingOptions) (*Result, error) {
+	return c.run("CreditShoppingMain", "CreditShopping", map[string]any{
+		"CreditShoppingShopping": map[string]any{
+			"action": map[string]any{
+				"param": map[string]any{
+					"custom_action_param": map[string]any{
+						"buy_first":       strings.Join(opts.BuyFirst, ";"),
+						"blacklist":       strings.Join(opts.Blacklist, ";"),
+						"decision_policy": opts.DecisionPolicy,
+					},
+				},
+			},
+		},
+		"CreditShoppingBuyBlacklist": map[string]any{"enabled": opts.Force},
+		"CreditShoppingBuyNormal": map[string]any{
+			"attach": map[string]any{"only_buy_discount": opts.OnlyDiscount},
+		},
+		"CreditShoppingReserveCredit": map[string]any{"enabled": opts.Reserve},
+	})
+}
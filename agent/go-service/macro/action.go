@@ -0,0 +1,110 @@
+package macro
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultRecordDuration = 30 * time.Second
+
+// MacroRecordAction - 在指定时间内录制控制器操作并保存为宏
+// custom_action_param: {"name": "daily_mail", "duration_ms": 30000}
+type MacroRecordAction struct{}
+
+func (a *MacroRecordAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	var params struct {
+		Name       string `json:"name"`
+		DurationMs int    `json:"duration_ms"`
+	}
+	if err := json.Unmarshal([]byte(arg.CustomActionParam), &params); err != nil {
+		log.Error().Err(err).Msg("Failed to parse MacroRecordAction param")
+		return false
+	}
+	if _, err := macroPath(params.Name); err != nil {
+		log.Error().Err(err).Msg("Invalid macro name")
+		return false
+	}
+	duration := defaultRecordDuration
+	if params.DurationMs > 0 {
+		duration = time.Duration(params.DurationMs) * time.Millisecond
+	}
+
+	showMessage(ctx, fmt.Sprintf("⏺️ 开始录制宏 %s，持续 %d 秒", params.Name, int(duration.Seconds())))
+	recorder.start()
+	tasker := ctx.GetTasker()
+	deadline := time.Now().Add(duration)
+	for time.Now().Before(deadline) && !tasker.Stopping() {
+		time.Sleep(100 * time.Millisecond)
+	}
+	steps := recorder.stop()
+
+	path, err := Save(&Macro{Name: params.Name, Steps: steps})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to save macro")
+		return false
+	}
+	log.Info().Str("macro", params.Name).Int("steps", len(steps)).Str("path", path).Msg("Macro recorded")
+	showMessage(ctx, fmt.Sprintf("⏹️ 宏 %s 录制完成，共 %d 步", params.Name, len(steps)))
+	return true
+}
+
+// MacroReplayAction - 按录制时的间隔回放宏
+// custom_action_param: {"name": "daily_mail", "speed": 1.0}
+type MacroReplayAction struct{}
+
+func (a *MacroReplayAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	var params struct {
+		Name  string  `json:"name"`
+		Speed float64 `json:"speed"`
+	}
+	if err := json.Unmarshal([]byte(arg.CustomActionParam), &params); err != nil {
+		log.Error().Err(err).Msg("Failed to parse MacroReplayAction param")
+		return false
+	}
+	if params.Speed <= 0 {
+		params.Speed = 1
+	}
+
+	m, err := Load(params.Name)
+	if err != nil {
+		log.Error().Err(err).Str("macro", params.Name).Msg("Failed to load macro")
+		return false
+	}
+	tasker := ctx.GetTasker()
+	controller := tasker.GetController()
+	if controller == nil {
+		log.Error().Msg("Failed to get controller")
+		return false
+	}
+
+	log.Info().Str("macro", m.Name).Int("steps", len(m.Steps)).Msg("Replaying macro")
+	for i, step := range m.Steps {
+		if tasker.Stopping() {
+			return false
+		}
+		time.Sleep(time.Duration(float64(step.DelayMs)/params.Speed) * time.Millisecond)
+		job, err := post(controller, step)
+		if err != nil {
+			log.Error().Err(err).Int("step", i+1).Msg("Failed to replay macro step")
+			return false
+		}
+		job.Wait()
+	}
+	return true
+}
+
+func showMessage(ctx *maa.Context, text string) {
+	ctx.RunTask("Macro_TaskShowMessage", map[string]interface{}{
+		"Macro_TaskShowMessage": map[string]interface{}{
+			"recognition": "DirectHit",
+			"action":      "DoNothing",
+			"focus": map[string]interface{}{
+				"Node.Action.Starting": text,
+			},
+		},
+	})
+}
@@ -0,0 +1,68 @@
+package macro
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+)
+
+// macroDir - 宏文件保存目录，每个宏一个 <name>.json
+func macroDir() string {
+	return datadir.Path("macros")
+}
+
+var nameRe = regexp.MustCompile(`^[0-9A-Za-z_\-]+$`)
+
+// Step - 一次控制器操作，DelayMs 为距上一步的间隔
+type Step struct {
+	Action  string         `json:"action"`
+	Param   map[string]any `json:"param"`
+	DelayMs int64          `json:"delay_ms"`
+}
+
+type Macro struct {
+	Name  string `json:"name"`
+	Steps []Step `json:"steps"`
+}
+
+func macroPath(name string) (string, error) {
+	if !nameRe.MatchString(name) {
+		return "", fmt.Errorf("invalid macro name %q", name)
+	}
+	return filepath.Join(macroDir(), name+".json"), nil
+}
+
+func Load(name string) (*Macro, error) {
+	path, err := macroPath(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Macro
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func Save(m *Macro) (string, error) {
+	path, err := macroPath(m.Name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(macroDir(), 0755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(m, "", "    ")
+	if err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,63 @@
+package macro
+
+import (
+	"sync"
+	"time"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+// replayable - 录制时保留的控制器操作，截图等操作不录制
+var replayable = map[string]bool{
+	"click":      true,
+	"swipe":      true,
+	"click_key":  true,
+	"input_text": true,
+	"scroll":     true,
+}
+
+// Recorder - 通过控制器事件记录操作（包括调试工具等客户端发起的点击）
+type Recorder struct {
+	mu     sync.Mutex
+	active bool
+	last   time.Time
+	steps  []Step
+}
+
+var recorder = &Recorder{}
+
+func (r *Recorder) start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active = true
+	r.last = time.Now()
+	r.steps = nil
+}
+
+func (r *Recorder) stop() []Step {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active = false
+	steps := r.steps
+	r.steps = nil
+	return steps
+}
+
+func (r *Recorder) OnControllerAction(ctrl *maa.Controller, event maa.EventStatus, detail maa.ControllerActionDetail) {
+	if event != maa.EventStatusStarting || !replayable[detail.Action] {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.active {
+		return
+	}
+	now := time.Now()
+	r.steps = append(r.steps, Step{
+		Action:  detail.Action,
+		Param:   detail.Param,
+		DelayMs: now.Sub(r.last).Milliseconds(),
+	})
+	r.last = now
+}
@@ -0,0 +1,32 @@
+package macro
+
+import (
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+var (
+	_ maa.CustomActionRunner  = &MacroRecordAction{}
+	_ maa.CustomActionRunner  = &MacroReplayAction{}
+	_ maa.ControllerEventSink = &Recorder{}
+)
+
+// Components returns the custom components of macro package
+func Components() []registry.Component {
+	P := registry.P
+	return []registry.Component{
+		registry.Action("MacroRecordAction", &MacroRecordAction{}, "在指定时间内录制控制器操作并保存为宏",
+			P("name", "string", "宏名"),
+			P("duration_ms", "int", "录制的毫秒数，默认 30000"),
+		),
+		registry.Action("MacroReplayAction", &MacroReplayAction{}, "按录制时的间隔回放宏",
+			P("name", "string", "宏名"),
+			P("speed", "number", "回放速度倍率，默认 1"),
+		),
+	}
+}
+
+// Register adds the controller sink used for recording
+func Register() {
+	maa.AgentServerAddControllerSink(recorder)
+}
@@ -0,0 +1,59 @@
+package macro
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+// post - 把录制的一步还原为控制器调用
+func post(controller *maa.Controller, step Step) (*maa.Job, error) {
+	p := step.Param
+	switch step.Action {
+	case "click":
+		x, y, ok := point(p, "x", "y", "point")
+		if !ok {
+			break
+		}
+		return controller.PostClick(x, y), nil
+	case "swipe":
+		x1, y1, ok1 := point(p, "x1", "y1", "begin")
+		x2, y2, ok2 := point(p, "x2", "y2", "end")
+		if !ok1 || !ok2 {
+			break
+		}
+		duration, _ := number(p["duration"])
+		return controller.PostSwipe(x1, y1, x2, y2, time.Duration(duration)*time.Millisecond), nil
+	case "click_key":
+		if key, ok := number(p["keycode"]); ok {
+			return controller.PostClickKey(int32(key)), nil
+		}
+	case "input_text":
+		if text, ok := p["text"].(string); ok {
+			return controller.PostInputText(text), nil
+		}
+	case "scroll":
+		dx, _ := number(p["dx"])
+		dy, _ := number(p["dy"])
+		return controller.PostScroll(int32(dx), int32(dy)), nil
+	}
+	return nil, fmt.Errorf("unsupported macro step %q with param %v", step.Action, p)
+}
+
+// point - 坐标既可能是 {"x": 1, "y": 2}，也可能是 {"point": [1, 2]}
+func point(p map[string]any, xKey, yKey, pairKey string) (int32, int32, bool) {
+	if pair, ok := p[pairKey].([]any); ok && len(pair) >= 2 {
+		x, okX := number(pair[0])
+		y, okY := number(pair[1])
+		return int32(x), int32(y), okX && okY
+	}
+	x, okX := number(p[xKey])
+	y, okY := number(p[yKey])
+	return int32(x), int32(y), okX && okY
+}
+
+func number(v any) (float64, bool) {
+	n, ok := v.(float64)
+	return n, ok
+}
@@ -1,14 +1,49 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/cloudsync"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/crashreport"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/diagnostics"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/extplugin"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/moduleinfo"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/takeover"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/webhook"
 	"github.com/MaaXYZ/maa-framework-go/v4"
 	"github.com/rs/zerolog/log"
 )
 
 func main() {
+	// 离线工具：不启动 Agent。roi-overlay 把模块的 roi 画到截图上，backup/restore 导出、导入配置与数据目录，
+	// encrypt-secret 用 MAAEND_SECRET_KEY 或系统钥匙串中的口令加密 token、webhook 地址等敏感配置，resource-packs 列出并校验资源包，
+	// dispatch 对配置中的多个游戏实例逐个或同时运行任务，export-xlsx 把倒卖与购物历史导出为 Excel 工作簿，
+	// --list-actions 以 JSON 列出全部自定义动作与识别及其参数
+	if len(os.Args) > 1 {
+		tools := map[string]func([]string) error{
+			"roi-overlay":    runROIOverlay,
+			"backup":         runBackup,
+			"restore":        runRestore,
+			"encrypt-secret": runEncryptSecret,
+			"resource-packs": runResourcePacks,
+			"dispatch":       runDispatch,
+			"assets":         runAssets,
+			"export-xlsx":    runExportXLSX,
+			"--list-actions": runListActions,
+		}
+		if run, ok := tools[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	logFile, err := initLogger()
 	if err != nil {
 		log.Fatal().
@@ -16,6 +51,7 @@ func main() {
 			Msg("Failed to initialize logger")
 	}
 	defer logFile.Close()
+	defer crashreport.Recover()
 
 	log.Info().
 		Str("version", Version).
@@ -58,7 +94,35 @@ func main() {
 			Msg("Toolkit config option initialized")
 	}
 
+	// Load Go-side config and reload it on change
+	agentconfig.Watch(filepath.Join(getCwd(), "config", "go-service.json"))
+
+	// Crash reports go to debug/crash; a crash from the previous run is reported now,
+	// and uploaded only when crash_report.upload is enabled
+	crashreport.Start(filepath.Join(getCwd(), "debug", "crash"), crashreport.Versions{
+		Agent:     Version,
+		Framework: maa.Version(),
+	})
+
+	// Persisted data lives under one directory; files from older layouts are moved there
+	if err := datadir.Init(agentconfig.Get().DataDir); err != nil {
+		log.Error().Err(err).Msg("Failed to prepare data directory")
+	}
+
+	// Leak diagnostics and pprof, only when enabled in config
+	diagnostics.Start(agentconfig.Get().Diagnostics)
+
+	// Manual takeover API and hotkey, only when enabled in config
+	takeover.Start(agentconfig.Get().Takeover)
+
+	// Task result webhooks; deliveries still queued from the previous run are retried
+	webhook.Start()
+
+	// Data directory sync with WebDAV or S3, only when enabled in config
+	cloudsync.Start(agentconfig.Get().Sync)
+
 	// Register all custom components and sinks
+	moduleinfo.AgentVersion = Version
 	registerAll()
 
 	// Start the agent server
@@ -75,6 +139,7 @@ func main() {
 
 	// Shutdown
 	maa.AgentServerShutDown()
+	extplugin.Shutdown()
 	log.Info().
 		Msg("Agent server shutdown")
 }
@@ -0,0 +1,72 @@
+package moduleinfo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// ModuleInfoAction - 显示 Agent 与各模块的版本，以及最近影响参数的行为变化
+// custom_action_param: {"module": "Resell", "limit": 3}，module 为空时显示全部模块
+type ModuleInfoAction struct{}
+
+func (a *ModuleInfoAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	var params struct {
+		Module string `json:"module"`
+		Limit  int    `json:"limit"`
+	}
+	if arg.CustomActionParam != "" {
+		if err := json.Unmarshal([]byte(arg.CustomActionParam), &params); err != nil {
+			log.Error().Err(err).Msg("Failed to parse ModuleInfoAction param")
+			return false
+		}
+	}
+	if params.Limit <= 0 {
+		params.Limit = 3
+	}
+
+	list := All()
+	if params.Module != "" {
+		m, ok := Find(params.Module)
+		if !ok {
+			log.Error().Str("module", params.Module).Msg("Unknown module")
+			showMessage(ctx, fmt.Sprintf("❌ 未知模块 %s", params.Module))
+			return false
+		}
+		list = []Module{m}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🧩 Agent %s，框架 %s\n", AgentVersion, maa.Version()))
+	for _, m := range list {
+		sb.WriteString(fmt.Sprintf("%s %s\n", m.Name, m.Version))
+		for i, c := range m.Changes {
+			if i >= params.Limit {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("  %s：%s", c.Version, c.Summary))
+			if len(c.Params) > 0 {
+				sb.WriteString(fmt.Sprintf("（%s）", strings.Join(c.Params, "、")))
+			}
+			sb.WriteString("\n")
+		}
+	}
+	log.Info().Str("agent", AgentVersion).Interface("modules", list).Msg("Module info")
+	showMessage(ctx, strings.TrimRight(sb.String(), "\n"))
+	return true
+}
+
+func showMessage(ctx *maa.Context, text string) {
+	ctx.RunTask("ModuleInfo_TaskShowMessage", map[string]interface{}{
+		"ModuleInfo_TaskShowMessage": map[string]interface{}{
+			"recognition": "DirectHit",
+			"action":      "DoNothing",
+			"focus": map[string]interface{}{
+				"Node.Action.Starting": text,
+			},
+		},
+	})
+}
@@ -0,0 +1,55 @@
+package moduleinfo
+
+import (
+	_ "embed"
+	"encoding/json"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+)
+
+// modules.json - 各模块的版本与影响参数的行为变化，修改模块行为时同步更新
+//
+//go:embed modules.json
+var modulesJSON []byte
+
+// Change - 一次影响行为或参数的变化
+type Change struct {
+	Version string   `json:"version"`
+	Summary string   `json:"summary"`
+	Params  []string `json:"params"`
+}
+
+// Module - 一个模块的版本与变化记录，最新的在前
+type Module struct {
+	Name    string   `json:"name"`
+	Version string   `json:"version"`
+	Changes []Change `json:"changes"`
+}
+
+// AgentVersion - Agent 的构建版本，由 main 设置
+var AgentVersion = "dev"
+
+var modules []Module
+
+func init() {
+	if err := json.Unmarshal(modulesJSON, &modules); err != nil {
+		log.Error().Err(err).Msg("Failed to parse embedded module info")
+	}
+	sort.SliceStable(modules, func(i, j int) bool { return modules[i].Name < modules[j].Name })
+}
+
+// All returns every module, sorted by name
+func All() []Module {
+	return append([]Module(nil), modules...)
+}
+
+// Find returns the module with the given name
+func Find(name string) (Module, bool) {
+	for _, m := range modules {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return Module{}, false
+}
@@ -0,0 +1,21 @@
+package moduleinfo
+
+import (
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+var (
+	_ maa.CustomActionRunner = &ModuleInfoAction{}
+)
+
+// Components returns the custom components of moduleinfo package
+func Components() []registry.Component {
+	P := registry.P
+	return []registry.Component{
+		registry.Action("ModuleInfoAction", &ModuleInfoAction{}, "显示 Agent 与各模块的版本，以及最近影响参数的行为变化",
+			P("module", "string", "只显示该模块，为空时显示全部"),
+			P("limit", "int", "每个模块显示的变化条数"),
+		),
+	}
+}
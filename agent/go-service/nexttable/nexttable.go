@@ -0,0 +1,79 @@
+package nexttable
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// Table - 决策结果到后续节点的映射
+// 节点名可以包含 {key} 占位符，由调用方提供取值；空列表表示结束，缺少的结果不修改 next
+type Table map[string][]string
+
+// Load reads attach.next_table of node on top of defaults, so resources can remap destinations
+// without code changes. Entries from attach replace the default entry of the same outcome.
+func Load(ctx *maa.Context, node string, defaults Table) Table {
+	table := Table{}
+	table.Merge(defaults)
+
+	raw, err := ctx.GetNodeJSON(node)
+	if err != nil || raw == "" {
+		return table
+	}
+	var data struct {
+		Attach struct {
+			NextTable Table `json:"next_table"`
+		} `json:"attach"`
+	}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		log.Warn().Err(err).Str("node", node).Msg("Failed to parse attach.next_table, using defaults")
+		return table
+	}
+	table.Merge(data.Attach.NextTable)
+	return table
+}
+
+// Merge copies the entries of other into t, replacing entries of the same outcome
+func (t Table) Merge(other Table) {
+	for outcome, next := range other {
+		t[outcome] = append([]string(nil), next...)
+	}
+}
+
+// Resolve returns the next items for outcome with placeholders expanded; ok is false when
+// the table has no entry for outcome
+func (t Table) Resolve(outcome string, vars map[string]string) ([]maa.NodeNextItem, bool) {
+	names, ok := t[outcome]
+	if !ok {
+		return nil, false
+	}
+	pairs := make([]string, 0, len(vars)*2)
+	for key, value := range vars {
+		pairs = append(pairs, "{"+key+"}", value)
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	items := make([]maa.NodeNextItem, 0, len(names))
+	for _, name := range names {
+		items = append(items, maa.NodeNextItem{Name: replacer.Replace(name)})
+	}
+	return items, true
+}
+
+// Apply overrides the next of node according to outcome; it leaves the node untouched and
+// returns false when the table has no entry for outcome
+func (t Table) Apply(ctx *maa.Context, node, outcome string, vars map[string]string) bool {
+	items, ok := t.Resolve(outcome, vars)
+	if !ok {
+		return false
+	}
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+	log.Debug().Str("node", node).Str("outcome", outcome).Strs("next", names).Msg("Next resolved from table")
+	ctx.OverrideNext(node, items)
+	return true
+}
@@ -0,0 +1,104 @@
+package nodecheck
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/respack"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	requiredMu sync.Mutex
+	// required maps module name to the pipeline nodes its Go code references
+	required = map[string][]string{}
+)
+
+// Require declares pipeline nodes that the Go code of a module references by name.
+// Nodes created at runtime through pipeline override must not be listed here.
+func Require(module string, nodes ...string) {
+	requiredMu.Lock()
+	defer requiredMu.Unlock()
+	required[module] = append(required[module], nodes...)
+}
+
+// NodeChecker verifies referenced nodes exist in the loaded resources before the first task runs
+type NodeChecker struct {
+	// checkedHash is the resource hash already checked, so each resource set is only checked once
+	checkedHash string
+}
+
+// OnTaskerTask handles tasker task events
+func (c *NodeChecker) OnTaskerTask(tasker *maa.Tasker, event maa.EventStatus, detail maa.TaskerTaskDetail) {
+	if event != maa.EventStatusStarting {
+		return
+	}
+
+	res := tasker.GetResource()
+	if res == nil {
+		return
+	}
+	hash, err := res.GetHash()
+	if err != nil || hash == c.checkedHash {
+		return
+	}
+	c.checkedHash = hash
+
+	nodeList, err := res.GetNodeList()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to get node list for reference check")
+		return
+	}
+
+	missing := FindMissing(nodeList)
+	if len(missing) == 0 {
+		if pack, ok := respack.Active(); ok {
+			log.Info().Str("pack", pack.Label()).Msg("Pipeline node reference check passed with resource pack")
+		} else {
+			log.Debug().Msg("Pipeline node reference check passed")
+		}
+		return
+	}
+
+	modules := make([]string, 0, len(missing))
+	for module := range missing {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+
+	var builder strings.Builder
+	builder.WriteString(`<span style="color: #ff0000; font-weight: 900;">🚨 资源缺少以下节点，相关功能运行时将会失败：</span>`)
+	// 使用资源包时缺少节点多半是资源包的问题，一并给出
+	if pack, ok := respack.Active(); ok {
+		builder.WriteString(fmt.Sprintf(`<br/><span style="color: #1890ff;">当前资源包：%s</span>`, pack.Label()))
+	}
+	for _, module := range modules {
+		log.Error().Str("module", module).Strs("missing", missing[module]).Msg("Pipeline nodes referenced by Go code are missing")
+		builder.WriteString(fmt.Sprintf(`<br/><span style="color: #faad14;">%s: %s</span>`, module, strings.Join(missing[module], ", ")))
+	}
+	fmt.Println(builder.String())
+}
+
+// FindMissing returns, per module, the required nodes absent from nodeList
+func FindMissing(nodeList []string) map[string][]string {
+	existing := make(map[string]struct{}, len(nodeList))
+	for _, name := range nodeList {
+		existing[name] = struct{}{}
+	}
+
+	requiredMu.Lock()
+	defer requiredMu.Unlock()
+
+	missing := map[string][]string{}
+	for module, nodes := range required {
+		for _, name := range nodes {
+			if _, ok := existing[name]; !ok {
+				missing[module] = append(missing[module], name)
+			}
+		}
+	}
+	return missing
+}
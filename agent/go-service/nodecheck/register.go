@@ -0,0 +1,12 @@
+package nodecheck
+
+import "github.com/MaaXYZ/maa-framework-go/v4"
+
+var (
+	_ maa.TaskerEventSink = &NodeChecker{}
+)
+
+// Register registers the node reference checker as a tasker sink
+func Register() {
+	maa.AgentServerAddTaskerSink(&NodeChecker{})
+}
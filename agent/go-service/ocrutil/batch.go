@@ -0,0 +1,124 @@
+package ocrutil
+
+import (
+	"image"
+	"sync"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// ROIRequest - 对同一张截图执行的一次 OCR，Pipeline 为预定义的识别节点
+type ROIRequest struct {
+	Pipeline string
+	// Override - 可选的 pipeline override，例如临时修改 roi
+	Override map[string]any
+}
+
+// Result - 单个 ROI 的识别结果
+type Result struct {
+	Pipeline string
+	// Hit - 识别到了非空文本，且符合节点 attach.expected_range 声明的范围
+	Hit  bool
+	Text string
+	// OutOfRange - 识别到了文本，但其中的数字不在声明的范围内
+	OutOfRange bool
+	Box        maa.Rect
+	Score      float64
+	Err        error
+}
+
+// Center returns the center point of the recognized text box
+func (r Result) Center() (int, int) {
+	return r.Box.X() + r.Box.Width()/2, r.Box.Y() + r.Box.Height()/2
+}
+
+type options struct {
+	concurrent bool
+	minScore   float64
+}
+
+// Option configures BatchExtract
+type Option func(*options)
+
+// WithConcurrency runs the recognitions in parallel instead of one by one
+func WithConcurrency() Option {
+	return func(o *options) { o.concurrent = true }
+}
+
+// WithMinScore ignores OCR results scoring below score
+func WithMinScore(score float64) Option {
+	return func(o *options) { o.minScore = score }
+}
+
+// BatchExtract runs every request against the same image and returns results in request order.
+// A node declaring attach.ocr_category falls back along the chain configured for that category.
+func BatchExtract(ctx *maa.Context, img image.Image, reqs []ROIRequest, opts ...Option) []Result {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	results := make([]Result, len(reqs))
+	if !o.concurrent {
+		for i, req := range reqs {
+			results[i] = runChain(ctx, img, req, o.minScore)
+		}
+		return results
+	}
+
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		wg.Add(1)
+		go func(i int, req ROIRequest) {
+			defer wg.Done()
+			results[i] = runChain(ctx, img, req, o.minScore)
+		}(i, req)
+	}
+	wg.Wait()
+	return results
+}
+
+// recognizeText and nodeJSON wrap the framework calls, so tests can run the batch logic without a tasker
+var (
+	recognizeText = func(ctx *maa.Context, img image.Image, req ROIRequest, minScore float64) (Part, bool, error) {
+		var detail *maa.RecognitionDetail
+		var err error
+		if req.Override != nil {
+			detail, err = ctx.RunRecognition(req.Pipeline, img, req.Override)
+		} else {
+			detail, err = ctx.RunRecognition(req.Pipeline, img)
+		}
+		if err != nil {
+			return Part{}, false, err
+		}
+		part, ok := Text(detail, minScore)
+		return part, ok, nil
+	}
+	nodeJSON = func(ctx *maa.Context, node string) (string, error) {
+		return ctx.GetNodeJSON(node)
+	}
+)
+
+func extract(ctx *maa.Context, img image.Image, req ROIRequest, minScore float64) Result {
+	result := Result{Pipeline: req.Pipeline}
+
+	part, ok, err := recognizeText(ctx, img, req, minScore)
+	if err != nil {
+		result.Err = err
+		log.Error().Err(result.Err).Str("pipeline", req.Pipeline).Msg("[OCR] 识别失败")
+		return result
+	}
+	if ok {
+		if !checkRange(ctx, req.Pipeline, part.Text) {
+			result.OutOfRange = true
+			result.Text = part.Text
+			return result
+		}
+		result.Hit = true
+		result.Text = part.Text
+		result.Box = part.Box
+		result.Score = part.Score
+	}
+	return result
+}
@@ -0,0 +1,156 @@
+package ocrutil
+
+import (
+	"encoding/json"
+	"image"
+	"maps"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// Stages of an OCR fallback chain, see agentconfig.OCRChain
+const (
+	StageDefault  = "default"
+	StageAccurate = "accurate"
+	StageDigits   = "digits"
+)
+
+// nodeCategory reads attach.ocr_category of node; empty when the node declares none
+func nodeCategory(ctx *maa.Context, node string) string {
+	raw, err := nodeJSON(ctx, node)
+	if err != nil || raw == "" {
+		return ""
+	}
+	var data struct {
+		Attach struct {
+			OCRCategory string `json:"ocr_category"`
+		} `json:"attach"`
+	}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return ""
+	}
+	return data.Attach.OCRCategory
+}
+
+// chainFor returns the fallback chain of the request's node, or only the default stage
+func chainFor(ctx *maa.Context, req ROIRequest) (agentconfig.OCRChain, string) {
+	category := nodeCategory(ctx, req.Pipeline)
+	if category == "" {
+		return agentconfig.OCRChain{Stages: []string{StageDefault}}, ""
+	}
+	chain, ok := agentconfig.Get().OCR.Chains[category]
+	if !ok || len(chain.Stages) == 0 {
+		return agentconfig.OCRChain{Stages: []string{StageDefault}}, category
+	}
+	return chain, category
+}
+
+// runChain tries each stage of the chain in order and returns the first hit.
+// A stage that cannot run, such as accurate without a configured model, is skipped.
+func runChain(ctx *maa.Context, img image.Image, req ROIRequest, minScore float64) Result {
+	chain, category := chainFor(ctx, req)
+	var result Result
+	for i, stage := range chain.Stages {
+		var ok bool
+		switch stage {
+		case StageDefault:
+			result, ok = runOCR(ctx, img, req, nil, minScore), true
+		case StageAccurate:
+			model := agentconfig.Get().OCR.AccurateModel
+			if model == "" {
+				continue
+			}
+			result, ok = runOCR(ctx, img, req, map[string]any{"model": model}, minScore), true
+		case StageDigits:
+			result, ok = runDigits(ctx, img, req, chain.DigitTemplates)
+		default:
+			log.Warn().Str("category", category).Str("stage", stage).Msg("[OCR] 未知的回退方式，已跳过")
+			continue
+		}
+		if !ok || !result.Hit {
+			continue
+		}
+		if i > 0 {
+			log.Info().Str("pipeline", req.Pipeline).Str("category", category).Str("stage", stage).Str("text", result.Text).Msg("[OCR] 回退后识别成功")
+		}
+		return result
+	}
+	return result
+}
+
+// runOCR runs the request's node, merging extra into its override for this call only
+func runOCR(ctx *maa.Context, img image.Image, req ROIRequest, extra map[string]any, minScore float64) Result {
+	if extra != nil {
+		override := maps.Clone(req.Override)
+		if override == nil {
+			override = map[string]any{}
+		}
+		node := map[string]any{}
+		if existing, ok := override[req.Pipeline].(map[string]any); ok {
+			maps.Copy(node, existing)
+		}
+		maps.Copy(node, extra)
+		override[req.Pipeline] = node
+		req.Override = override
+	}
+	return extract(ctx, img, req, minScore)
+}
+
+// runDigits matches digit templates within the request's roi; ok is false when it cannot run
+func runDigits(ctx *maa.Context, img image.Image, req ROIRequest, dir string) (Result, bool) {
+	result := Result{Pipeline: req.Pipeline}
+	if dir == "" {
+		return result, false
+	}
+	roi, ok := requestROI(ctx, req)
+	if !ok {
+		return result, false
+	}
+	text, box, score, ok := MatchDigits(ctx, img, roi, dir)
+	if !ok {
+		return result, true
+	}
+	if !checkRange(ctx, req.Pipeline, text) {
+		result.OutOfRange = true
+		result.Text = text
+		return result, true
+	}
+	result.Hit = true
+	result.Text = text
+	result.Box = box
+	result.Score = score
+	return result, true
+}
+
+// requestROI returns the roi from the request's override if set, otherwise the node's own roi
+func requestROI(ctx *maa.Context, req ROIRequest) (maa.Rect, bool) {
+	if node, ok := req.Override[req.Pipeline].(map[string]any); ok {
+		if v, ok := node["roi"]; ok {
+			var roi maa.Rect
+			if data, err := json.Marshal(v); err == nil && json.Unmarshal(data, &roi) == nil && roi.Width() > 0 && roi.Height() > 0 {
+				return roi, true
+			}
+		}
+	}
+	raw, err := nodeJSON(ctx, req.Pipeline)
+	if err != nil || raw == "" {
+		return maa.Rect{}, false
+	}
+	var data struct {
+		Recognition struct {
+			Param struct {
+				ROI json.RawMessage `json:"roi"`
+			} `json:"param"`
+		} `json:"recognition"`
+	}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return maa.Rect{}, false
+	}
+	var roi maa.Rect
+	if err := json.Unmarshal(data.Recognition.Param.ROI, &roi); err != nil || roi.Width() <= 0 || roi.Height() <= 0 {
+		return maa.Rect{}, false
+	}
+	return roi, true
+}
@@ -0,0 +1,93 @@
+package ocrutil
+
+import (
+	"fmt"
+	"image"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/assets"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// digitMatchNode - 数字模板匹配使用的节点，内容由 Go 侧按次覆盖
+	digitMatchNode = "OCRDigitTemplateMatch"
+	digitThreshold = 0.8
+)
+
+// digitHit - 一个数字模板的匹配结果
+type digitHit struct {
+	digit int
+	box   maa.Rect
+	score float64
+}
+
+// MatchDigits matches the templates 0.png ~ 9.png under the asset directory dir within roi,
+// drops overlapping matches and joins the digits left to right. box covers every digit.
+func MatchDigits(ctx *maa.Context, img image.Image, roi maa.Rect, dir string) (text string, box maa.Rect, score float64, ok bool) {
+	var hits []digitHit
+	for digit := 0; digit <= 9; digit++ {
+		template, err := assets.Register(ctx, path.Join(dir, fmt.Sprintf("%d.png", digit)))
+		if err != nil {
+			log.Info().Err(err).Str("dir", dir).Msg("[OCR] 缺少数字模板，跳过模板匹配")
+			return "", maa.Rect{}, 0, false
+		}
+		detail, err := ctx.RunRecognition(digitMatchNode, img, map[string]any{
+			digitMatchNode: map[string]any{
+				"recognition": "TemplateMatch",
+				"template":    template,
+				"roi":         roi,
+				"threshold":   digitThreshold,
+			},
+		})
+		if err != nil || detail == nil || !detail.Hit || detail.Results == nil {
+			continue
+		}
+		for _, r := range detail.Results.Filtered {
+			if m, ok := r.AsTemplateMatch(); ok {
+				hits = append(hits, digitHit{digit: digit, box: m.Box, score: m.Score})
+			}
+		}
+	}
+	hits = suppressOverlaps(hits)
+	if len(hits) == 0 {
+		return "", maa.Rect{}, 0, false
+	}
+
+	sort.Slice(hits, func(i, j int) bool { return hits[i].box.X() < hits[j].box.X() })
+	var b strings.Builder
+	left, top, right, bottom := hits[0].box.X(), hits[0].box.Y(), 0, 0
+	score = 1
+	for _, h := range hits {
+		b.WriteString(strconv.Itoa(h.digit))
+		top = min(top, h.box.Y())
+		right = max(right, h.box.X()+h.box.Width())
+		bottom = max(bottom, h.box.Y()+h.box.Height())
+		score = min(score, h.score)
+	}
+	return b.String(), maa.Rect{left, top, right - left, bottom - top}, score, true
+}
+
+// suppressOverlaps - 相似的数字（如 3 和 8）会在同一位置都匹配上，横向重叠超过一半时只保留得分高的
+func suppressOverlaps(hits []digitHit) []digitHit {
+	sort.Slice(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+	var kept []digitHit
+	for _, h := range hits {
+		overlapped := false
+		for _, k := range kept {
+			overlap := min(h.box.X()+h.box.Width(), k.box.X()+k.box.Width()) - max(h.box.X(), k.box.X())
+			if overlap*2 > min(h.box.Width(), k.box.Width()) {
+				overlapped = true
+				break
+			}
+		}
+		if !overlapped {
+			kept = append(kept, h)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,76 @@
+package ocrutil
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+var digitsPattern = regexp.MustCompile(`\d+`)
+
+// Text returns the first non-empty OCR result of detail, trying Best, then Filtered, then All.
+// Results scoring below minScore are skipped; pass 0 to accept any score.
+func Text(detail *maa.RecognitionDetail, minScore float64) (Part, bool) {
+	if detail == nil || detail.Results == nil {
+		return Part{}, false
+	}
+	for _, candidates := range [][]*maa.RecognitionResult{detail.Results.Best, detail.Results.Filtered, detail.Results.All} {
+		if len(candidates) == 0 {
+			continue
+		}
+		ocr, ok := candidates[0].AsOCR()
+		if !ok || ocr.Text == "" || ocr.Score < minScore {
+			continue
+		}
+		return Part{Text: ocr.Text, Box: ocr.Box, Score: ocr.Score}, true
+	}
+	return Part{}, false
+}
+
+// Number joins every digit run in text into one integer, so "1,234" and "1 234" both read as 1234
+func Number(text string) (int, bool) {
+	matches := digitsPattern.FindAllString(text, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.Join(matches, ""))
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// Numbers returns each digit run in text as a separate integer
+func Numbers(text string) []int {
+	var out []int
+	for _, m := range digitsPattern.FindAllString(text, -1) {
+		if n, err := strconv.Atoi(m); err == nil {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// ContainsAny returns the first keyword contained in text
+func ContainsAny(text string, keywords []string) (string, bool) {
+	for _, keyword := range keywords {
+		if keyword != "" && strings.Contains(text, keyword) {
+			return keyword, true
+		}
+	}
+	return "", false
+}
+
+// Match returns the first match of pattern in text, or its first submatch when the pattern has a group
+func Match(text string, pattern *regexp.Regexp) (string, bool) {
+	m := pattern.FindStringSubmatch(text)
+	if m == nil {
+		return "", false
+	}
+	if len(m) > 1 {
+		return m[1], true
+	}
+	return m[0], true
+}
@@ -0,0 +1,214 @@
+package ocrutil
+
+import (
+	"errors"
+	"image"
+	"regexp"
+	"sync/atomic"
+	"testing"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+// fakeNode - 一个节点的 JSON 与识别结果，替代真实的 RunRecognition / GetNodeJSON
+type fakeNode struct {
+	json  string
+	part  Part
+	hit   bool
+	err   error
+	score float64
+}
+
+// useFakeNodes replaces the framework calls with nodes for the duration of the test
+// and returns the number of recognitions run
+func useFakeNodes(t *testing.T, nodes map[string]fakeNode) *atomic.Int32 {
+	t.Helper()
+	var calls atomic.Int32
+	origRecognize, origJSON := recognizeText, nodeJSON
+	t.Cleanup(func() { recognizeText, nodeJSON = origRecognize, origJSON })
+
+	recognizeText = func(_ *maa.Context, _ image.Image, req ROIRequest, minScore float64) (Part, bool, error) {
+		calls.Add(1)
+		n, ok := nodes[req.Pipeline]
+		if !ok {
+			return Part{}, false, errors.New("unknown node")
+		}
+		if n.err != nil || !n.hit || n.part.Score < minScore {
+			return Part{}, false, n.err
+		}
+		return n.part, true, nil
+	}
+	nodeJSON = func(_ *maa.Context, node string) (string, error) {
+		return nodes[node].json, nil
+	}
+	return &calls
+}
+
+func TestBatchExtract(t *testing.T) {
+	nodes := map[string]fakeNode{
+		"Price":   {json: `{"attach":{"expected_range":[1,99999]}}`, hit: true, part: Part{Text: "1,234", Box: maa.Rect{10, 20, 30, 40}, Score: 0.9}},
+		"TooHigh": {json: `{"attach":{"expected_range":[1,7000]}}`, hit: true, part: Part{Text: "12345", Score: 0.9}},
+		"Name":    {hit: true, part: Part{Text: "武器", Score: 0.5}},
+		"Empty":   {},
+		"Broken":  {err: errors.New("boom")},
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want []Result
+	}{
+		{
+			name: "sequential",
+			want: []Result{
+				{Pipeline: "Price", Hit: true, Text: "1,234", Box: maa.Rect{10, 20, 30, 40}, Score: 0.9},
+				{Pipeline: "TooHigh", Text: "12345", OutOfRange: true},
+				{Pipeline: "Name", Hit: true, Text: "武器", Score: 0.5},
+				{Pipeline: "Empty"},
+				{Pipeline: "Broken"},
+			},
+		},
+		{
+			name: "concurrent keeps request order",
+			opts: []Option{WithConcurrency()},
+			want: []Result{
+				{Pipeline: "Price", Hit: true, Text: "1,234", Box: maa.Rect{10, 20, 30, 40}, Score: 0.9},
+				{Pipeline: "TooHigh", Text: "12345", OutOfRange: true},
+				{Pipeline: "Name", Hit: true, Text: "武器", Score: 0.5},
+				{Pipeline: "Empty"},
+				{Pipeline: "Broken"},
+			},
+		},
+		{
+			name: "min score drops weak results",
+			opts: []Option{WithMinScore(0.8)},
+			want: []Result{
+				{Pipeline: "Price", Hit: true, Text: "1,234", Box: maa.Rect{10, 20, 30, 40}, Score: 0.9},
+				{Pipeline: "TooHigh", Text: "12345", OutOfRange: true},
+				{Pipeline: "Name"},
+				{Pipeline: "Empty"},
+				{Pipeline: "Broken"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := useFakeNodes(t, nodes)
+			reqs := make([]ROIRequest, len(tt.want))
+			for i, w := range tt.want {
+				reqs[i] = ROIRequest{Pipeline: w.Pipeline}
+			}
+
+			got := BatchExtract(nil, nil, reqs, tt.opts...)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d results, want %d", len(got), len(tt.want))
+			}
+			for i, w := range tt.want {
+				g := got[i]
+				if (g.Err != nil) != (w.Pipeline == "Broken") {
+					t.Errorf("%s: Err = %v", w.Pipeline, g.Err)
+				}
+				g.Err = nil
+				if g != w {
+					t.Errorf("result %d = %+v, want %+v", i, g, w)
+				}
+			}
+			if int(calls.Load()) != len(reqs) {
+				t.Errorf("ran %d recognitions, want %d", calls.Load(), len(reqs))
+			}
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	if _, ok := Text(nil, 0); ok {
+		t.Error("Text(nil) reported a hit")
+	}
+	if _, ok := Text(&maa.RecognitionDetail{}, 0); ok {
+		t.Error("Text without results reported a hit")
+	}
+}
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   int
+		wantOK bool
+	}{
+		{"1234", 1234, true},
+		{"1,234", 1234, true},
+		{"1 234", 1234, true},
+		{"x 12", 12, true},
+		{"", 0, false},
+		{"价格", 0, false},
+		{"99999999999999999999", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := Number(tt.text)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Number(%q) = %d, %v, want %d, %v", tt.text, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	tests := []struct {
+		text string
+		want []int
+	}{
+		{"12/80", []int{12, 80}},
+		{"1,234", []int{1, 234}},
+		{"none", nil},
+	}
+	for _, tt := range tests {
+		got := Numbers(tt.text)
+		if len(got) != len(tt.want) {
+			t.Errorf("Numbers(%q) = %v, want %v", tt.text, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Numbers(%q) = %v, want %v", tt.text, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestContainsAny(t *testing.T) {
+	tests := []struct {
+		text     string
+		keywords []string
+		want     string
+		wantOK   bool
+	}{
+		{"购买成功", []string{"失败", "成功"}, "成功", true},
+		{"购买成功", []string{"", "失败"}, "", false},
+		{"", []string{"成功"}, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ContainsAny(tt.text, tt.keywords)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ContainsAny(%q, %q) = %q, %v, want %q, %v", tt.text, tt.keywords, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		text    string
+		pattern string
+		want    string
+		wantOK  bool
+	}{
+		{"剩余 12 次", `\d+`, "12", true},
+		{"v1.2.3", `v(\d+\.\d+)`, "1.2", true},
+		{"none", `\d+`, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Match(tt.text, regexp.MustCompile(tt.pattern))
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Match(%q, %q) = %q, %v, want %q, %v", tt.text, tt.pattern, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package ocrutil
+
+import (
+	"fmt"
+	"image"
+	"sort"
+	"strings"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+// Line - 一行文本，由同一行上的若干 OCR 结果按从左到右拼接而成
+type Line struct {
+	Text  string
+	Box   maa.Rect
+	Parts []Part
+}
+
+// Part - 行内的单个 OCR 结果
+type Part struct {
+	Text  string
+	Box   maa.Rect
+	Score float64
+}
+
+// Lines OCRs a larger ROI and groups the results into lines ordered top to bottom.
+// Results whose vertical centers fall within half a text height of each other are
+// treated as one line; parts in a line are ordered left to right and joined by a space.
+func Lines(ctx *maa.Context, img image.Image, req ROIRequest) ([]Line, error) {
+	var detail *maa.RecognitionDetail
+	var err error
+	if req.Override != nil {
+		detail, err = ctx.RunRecognition(req.Pipeline, img, req.Override)
+	} else {
+		detail, err = ctx.RunRecognition(req.Pipeline, img)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", req.Pipeline, err)
+	}
+	if detail == nil || detail.Results == nil {
+		return nil, nil
+	}
+
+	candidates := detail.Results.Filtered
+	if len(candidates) == 0 {
+		candidates = detail.Results.All
+	}
+	parts := make([]Part, 0, len(candidates))
+	for _, c := range candidates {
+		if ocr, ok := c.AsOCR(); ok && strings.TrimSpace(ocr.Text) != "" {
+			parts = append(parts, Part{Text: ocr.Text, Box: ocr.Box, Score: ocr.Score})
+		}
+	}
+	return GroupLines(parts), nil
+}
+
+// GroupLines groups OCR parts into lines ordered top to bottom
+func GroupLines(parts []Part) []Line {
+	sorted := append([]Part(nil), parts...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return centerY(sorted[i].Box) < centerY(sorted[j].Box)
+	})
+
+	var lines []Line
+	for _, part := range sorted {
+		if n := len(lines); n > 0 && sameLine(lines[n-1], part) {
+			lines[n-1].Parts = append(lines[n-1].Parts, part)
+			continue
+		}
+		lines = append(lines, Line{Parts: []Part{part}})
+	}
+
+	for i := range lines {
+		line := &lines[i]
+		sort.SliceStable(line.Parts, func(a, b int) bool {
+			return line.Parts[a].Box.X() < line.Parts[b].Box.X()
+		})
+		texts := make([]string, 0, len(line.Parts))
+		for j, part := range line.Parts {
+			texts = append(texts, part.Text)
+			if j == 0 {
+				line.Box = part.Box
+			} else {
+				line.Box = union(line.Box, part.Box)
+			}
+		}
+		line.Text = strings.Join(texts, " ")
+	}
+	return lines
+}
+
+// sameLine - part 的垂直中心落在当前行的范围内（以半个字高为容差）
+func sameLine(line Line, part Part) bool {
+	last := line.Parts[len(line.Parts)-1]
+	tolerance := min(last.Box.Height(), part.Box.Height()) / 2
+	diff := centerY(part.Box) - centerY(last.Box)
+	return diff <= tolerance
+}
+
+func centerY(r maa.Rect) int {
+	return r.Y() + r.Height()/2
+}
+
+func union(a, b maa.Rect) maa.Rect {
+	x0, y0 := min(a.X(), b.X()), min(a.Y(), b.Y())
+	x1 := max(a.X()+a.Width(), b.X()+b.Width())
+	y1 := max(a.Y()+a.Height(), b.Y()+b.Height())
+	return maa.Rect{x0, y0, x1 - x0, y1 - y0}
+}
@@ -0,0 +1,70 @@
+package ocrutil
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// Range - 节点 attach.expected_range 声明的数值范围（含两端），如价格节点的 [1, 99999]
+// 只适合整段文本就是一个数字的节点，"12/80" 这类文本会被拼成一个数字
+type Range struct {
+	Min int
+	Max int
+}
+
+// Contains reports whether n is within the range
+func (r Range) Contains(n int) bool {
+	return n >= r.Min && n <= r.Max
+}
+
+// ContainsText reports whether text reads as a number within the range
+func (r Range) ContainsText(text string) bool {
+	n, ok := Number(text)
+	return ok && r.Contains(n)
+}
+
+func (r Range) String() string {
+	return fmt.Sprintf("[%d, %d]", r.Min, r.Max)
+}
+
+// NodeRange reads attach.expected_range of node; ok is false when the node declares none
+func NodeRange(ctx *maa.Context, node string) (Range, bool) {
+	raw, err := nodeJSON(ctx, node)
+	if err != nil || raw == "" {
+		return Range{}, false
+	}
+	return parseNodeRange(node, raw)
+}
+
+// parseNodeRange reads attach.expected_range from the node JSON raw
+func parseNodeRange(node, raw string) (Range, bool) {
+	var data struct {
+		Attach struct {
+			ExpectedRange []int `json:"expected_range"`
+		} `json:"attach"`
+	}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil || data.Attach.ExpectedRange == nil {
+		return Range{}, false
+	}
+	if r := data.Attach.ExpectedRange; len(r) != 2 || r[0] > r[1] {
+		log.Warn().Str("pipeline", node).Ints("expected_range", r).Msg("[OCR] expected_range 无效，已忽略")
+		return Range{}, false
+	}
+	return Range{Min: data.Attach.ExpectedRange[0], Max: data.Attach.ExpectedRange[1]}, true
+}
+
+// checkRange - 按节点声明的范围检查读出的数字，没有声明时总是通过
+func checkRange(ctx *maa.Context, node, text string) bool {
+	r, ok := NodeRange(ctx, node)
+	if !ok {
+		return true
+	}
+	if !r.ContainsText(text) {
+		log.Info().Str("pipeline", node).Str("text", text).Str("expected_range", r.String()).Msg("[OCR] 数字不在节点声明的范围内，视为未识别")
+		return false
+	}
+	return true
+}
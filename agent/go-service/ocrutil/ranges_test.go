@@ -0,0 +1,75 @@
+package ocrutil
+
+import "testing"
+
+func TestParseNodeRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		want   Range
+		wantOK bool
+	}{
+		{"declared", `{"attach":{"expected_range":[1,99999]}}`, Range{Min: 1, Max: 99999}, true},
+		{"single value", `{"attach":{"expected_range":[5,5]}}`, Range{Min: 5, Max: 5}, true},
+		{"not declared", `{"attach":{}}`, Range{}, false},
+		{"no attach", `{"recognition":"OCR"}`, Range{}, false},
+		{"reversed", `{"attach":{"expected_range":[10,1]}}`, Range{}, false},
+		{"wrong length", `{"attach":{"expected_range":[1,2,3]}}`, Range{}, false},
+		{"not numbers", `{"attach":{"expected_range":["1","2"]}}`, Range{}, false},
+		{"invalid json", `{`, Range{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseNodeRange("Node", tt.raw)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseNodeRange(%s) = %v, %v, want %v, %v", tt.raw, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRangeContainsText(t *testing.T) {
+	r := Range{Min: 100, Max: 7000}
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"100", true},
+		{"7000", true},
+		{"1,234", true},
+		{"99", false},
+		{"7001", false},
+		{"12345", false},
+		{"价格", false},
+	}
+	for _, tt := range tests {
+		if got := r.ContainsText(tt.text); got != tt.want {
+			t.Errorf("%v.ContainsText(%q) = %v, want %v", r, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCheckRange(t *testing.T) {
+	useFakeNodes(t, map[string]fakeNode{
+		"Price": {json: `{"attach":{"expected_range":[1,99999]}}`},
+		"Name":  {json: `{"recognition":"OCR"}`},
+	})
+
+	tests := []struct {
+		node string
+		text string
+		want bool
+	}{
+		{"Price", "1234", true},
+		{"Price", "0", false},
+		{"Price", "123456", false},
+		{"Price", "无", false},
+		{"Name", "anything", true},
+		{"Missing", "anything", true},
+	}
+	for _, tt := range tests {
+		if got := checkRange(nil, tt.node, tt.text); got != tt.want {
+			t.Errorf("checkRange(%s, %q) = %v, want %v", tt.node, tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package ocrutil
+
+import (
+	"time"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultRetryAttempts = 2
+	defaultRetryDelay    = 300 * time.Millisecond
+)
+
+// Retry - OCR 没有结果时重新截图再识别，用于跳过过场动画等不稳定的画面
+// Attempts 为包含首次在内的总次数，Delay 为两次识别的间隔，<= 0 时都使用默认值
+type Retry struct {
+	Attempts int
+	Delay    time.Duration
+}
+
+// screencap and sleep are replaced in tests
+var (
+	screencap = func(controller *maa.Controller) { controller.PostScreencap().Wait() }
+	sleep     = time.Sleep
+)
+
+// DefaultRetry 识别两次，间隔 300ms，与之前各处手写的“失败就重试一遍”一致
+var DefaultRetry = Retry{Attempts: defaultRetryAttempts, Delay: defaultRetryDelay}
+
+func (r Retry) attempts() int {
+	if r.Attempts <= 0 {
+		return defaultRetryAttempts
+	}
+	return r.Attempts
+}
+
+func (r Retry) delay() time.Duration {
+	if r.Delay <= 0 {
+		return defaultRetryDelay
+	}
+	return r.Delay
+}
+
+// Do takes a fresh screencap before every call to read, and retries until read reports success
+// or the attempts run out. step only names the OCR step in the log.
+func (r Retry) Do(controller *maa.Controller, step string, read func() bool) bool {
+	attempts := r.attempts()
+	for attempt := 1; ; attempt++ {
+		screencap(controller)
+		if read() {
+			if attempt > 1 {
+				log.Info().Str("step", step).Int("attempt", attempt).Msg("[OCR] 重试后识别成功")
+			}
+			return true
+		}
+		if attempt >= attempts {
+			log.Info().Str("step", step).Int("attempts", attempts).Msg("[OCR] 多次识别仍无结果")
+			return false
+		}
+		sleep(r.delay())
+	}
+}
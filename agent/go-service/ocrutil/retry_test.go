@@ -0,0 +1,95 @@
+package ocrutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+func TestRetryDo(t *testing.T) {
+	tests := []struct {
+		name        string
+		retry       Retry
+		succeedOn   int // 第几次读取成功，0 表示一直失败
+		want        bool
+		wantReads   int
+		wantSleeps  []time.Duration
+		wantCapture int
+	}{
+		{
+			name:        "first read succeeds",
+			retry:       DefaultRetry,
+			succeedOn:   1,
+			want:        true,
+			wantReads:   1,
+			wantCapture: 1,
+		},
+		{
+			name:        "succeeds on retry",
+			retry:       Retry{Attempts: 3, Delay: 50 * time.Millisecond},
+			succeedOn:   3,
+			want:        true,
+			wantReads:   3,
+			wantSleeps:  []time.Duration{50 * time.Millisecond, 50 * time.Millisecond},
+			wantCapture: 3,
+		},
+		{
+			name:        "gives up after attempts",
+			retry:       Retry{Attempts: 2, Delay: time.Second},
+			want:        false,
+			wantReads:   2,
+			wantSleeps:  []time.Duration{time.Second},
+			wantCapture: 2,
+		},
+		{
+			name:        "zero value uses defaults",
+			retry:       Retry{},
+			want:        false,
+			wantReads:   defaultRetryAttempts,
+			wantSleeps:  []time.Duration{defaultRetryDelay},
+			wantCapture: defaultRetryAttempts,
+		},
+		{
+			name:        "single attempt never sleeps",
+			retry:       Retry{Attempts: 1, Delay: time.Second},
+			want:        false,
+			wantReads:   1,
+			wantCapture: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sleeps []time.Duration
+			captures := 0
+			origScreencap, origSleep := screencap, sleep
+			t.Cleanup(func() { screencap, sleep = origScreencap, origSleep })
+			screencap = func(*maa.Controller) { captures++ }
+			sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+			reads := 0
+			got := tt.retry.Do(nil, "test", func() bool {
+				reads++
+				return reads == tt.succeedOn
+			})
+			if got != tt.want {
+				t.Errorf("Do() = %v, want %v", got, tt.want)
+			}
+			if reads != tt.wantReads {
+				t.Errorf("read %d times, want %d", reads, tt.wantReads)
+			}
+			if captures != tt.wantCapture {
+				t.Errorf("took %d screencaps, want %d", captures, tt.wantCapture)
+			}
+			if len(sleeps) != len(tt.wantSleeps) {
+				t.Fatalf("slept %v, want %v", sleeps, tt.wantSleeps)
+			}
+			for i := range sleeps {
+				if sleeps[i] != tt.wantSleeps[i] {
+					t.Errorf("slept %v, want %v", sleeps, tt.wantSleeps)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package ocrutil
+
+import (
+	"fmt"
+	"image"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+// StripNumber - 数字条中的一个数字，Box 为其所在的 OCR 框（一个框内有多个数字时按字符位置估算）
+type StripNumber struct {
+	Value int
+	Box   maa.Rect
+	Score float64
+}
+
+// CenterX returns the horizontal center of the number
+func (n StripNumber) CenterX() int {
+	return n.Box.X() + n.Box.Width()/2
+}
+
+// Strip OCRs a wide ROI holding several numbers, such as every price in one shelf row,
+// and returns the numbers ordered left to right. Separators inside a number ("1,234")
+// are dropped; whitespace splits one OCR result into several numbers. Numbers outside the
+// attach.expected_range of the node are left out.
+func Strip(ctx *maa.Context, img image.Image, req ROIRequest) ([]StripNumber, error) {
+	var detail *maa.RecognitionDetail
+	var err error
+	if req.Override != nil {
+		detail, err = ctx.RunRecognition(req.Pipeline, img, req.Override)
+	} else {
+		detail, err = ctx.RunRecognition(req.Pipeline, img)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", req.Pipeline, err)
+	}
+	if detail == nil || detail.Results == nil {
+		return nil, nil
+	}
+
+	candidates := detail.Results.Filtered
+	if len(candidates) == 0 {
+		candidates = detail.Results.All
+	}
+	var numbers []StripNumber
+	for _, c := range candidates {
+		if ocr, ok := c.AsOCR(); ok {
+			numbers = append(numbers, SplitNumbers(Part{Text: ocr.Text, Box: ocr.Box, Score: ocr.Score})...)
+		}
+	}
+	if r, ok := NodeRange(ctx, req.Pipeline); ok {
+		kept := numbers[:0]
+		for _, n := range numbers {
+			if r.Contains(n.Value) {
+				kept = append(kept, n)
+			}
+		}
+		numbers = kept
+	}
+	sort.SliceStable(numbers, func(i, j int) bool { return numbers[i].Box.X() < numbers[j].Box.X() })
+	return numbers, nil
+}
+
+// SplitNumbers splits the whitespace separated numbers of one OCR part, giving each
+// a share of the part box proportional to its position in the text
+func SplitNumbers(part Part) []StripNumber {
+	runes := []rune(part.Text)
+	if len(runes) == 0 {
+		return nil
+	}
+	var numbers []StripNumber
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		if value, ok := Number(string(runes[start:end])); ok {
+			x := part.Box.X() + part.Box.Width()*start/len(runes)
+			w := max(part.Box.Width()*(end-start)/len(runes), 1)
+			numbers = append(numbers, StripNumber{
+				Value: value,
+				Box:   maa.Rect{x, part.Box.Y(), w, part.Box.Height()},
+				Score: part.Score,
+			})
+		}
+		start = -1
+	}
+	for i, r := range runes {
+		if unicode.IsSpace(r) || strings.ContainsRune("|/", r) {
+			flush(i)
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	flush(len(runes))
+	return numbers
+}
@@ -0,0 +1,68 @@
+package overridesnap
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// OverrideDiffAction - 显示最近的 pipeline 覆盖记录，便于排查 attach 改写结果
+// custom_action_param: {"limit": 10}
+type OverrideDiffAction struct{}
+
+func (a *OverrideDiffAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	var params struct {
+		Limit int `json:"limit"`
+	}
+	if arg.CustomActionParam != "" {
+		if err := json.Unmarshal([]byte(arg.CustomActionParam), &params); err != nil {
+			log.Error().Err(err).Msg("Failed to parse OverrideDiffAction param")
+			return false
+		}
+	}
+
+	recent := RecentDiffs(params.Limit)
+	log.Info().Interface("diffs", recent).Msg("Recent pipeline overrides")
+
+	var sb strings.Builder
+	sb.WriteString("🔧 最近的 pipeline 覆盖\n")
+	if len(recent) == 0 {
+		sb.WriteString("（无）")
+	}
+	for _, d := range recent {
+		sb.WriteString(fmt.Sprintf("%s [%s] %s\n", d.Time.Format("15:04:05"), d.Module, d.Node))
+		for _, c := range d.Changes {
+			sb.WriteString(fmt.Sprintf("  %s: %s → %s\n", c.Path, compact(c.Before), compact(c.After)))
+		}
+	}
+
+	ctx.RunTask("OverrideDiff_TaskShowMessage", map[string]interface{}{
+		"OverrideDiff_TaskShowMessage": map[string]interface{}{
+			"recognition": "DirectHit",
+			"action":      "DoNothing",
+			"focus": map[string]interface{}{
+				"Node.Action.Starting": strings.TrimRight(sb.String(), "\n"),
+			},
+		},
+	})
+	return true
+}
+
+// compact - 单行显示字段值，过长时截断
+func compact(v any) string {
+	if v == nil {
+		return "∅"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	s := string(data)
+	if r := []rune(s); len(r) > 80 {
+		s = string(r[:80]) + "…"
+	}
+	return s
+}
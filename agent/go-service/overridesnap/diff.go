@@ -0,0 +1,162 @@
+package overridesnap
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// 保留的最近覆盖记录数
+const maxDiffs = 50
+
+// FieldChange - 一个字段在覆盖前后的值，Path 形如 all_of[2].expected
+type FieldChange struct {
+	Path   string `json:"path"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// Diff - 一次 OverridePipeline 对单个节点的修改
+type Diff struct {
+	Time    time.Time     `json:"time"`
+	Module  string        `json:"module"`
+	Node    string        `json:"node"`
+	Changes []FieldChange `json:"changes"`
+}
+
+var diffs []Diff // 由 mu 保护，最旧的在前
+
+// Apply calls OverridePipeline and logs a field-level diff of every overridden node
+// instead of the whole override map. The diffs are kept for RecentDiffs.
+func Apply(ctx *maa.Context, module string, override map[string]any) error {
+	nodes := make([]string, 0, len(override))
+	for node := range override {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	var pending []Diff
+	for _, node := range nodes {
+		after, err := normalize(override[node])
+		if err != nil {
+			return fmt.Errorf("override %s: %w", node, err)
+		}
+		var before map[string]any
+		if raw, err := ctx.GetNodeJSON(node); err == nil && raw != "" {
+			json.Unmarshal([]byte(raw), &before)
+		}
+
+		var changes []FieldChange
+		if fields, ok := after.(map[string]any); ok {
+			keys := make([]string, 0, len(fields))
+			for key := range fields {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				diffValue(key, nodeField(before, key), fields[key], &changes)
+			}
+		}
+		pending = append(pending, Diff{Time: time.Now(), Module: module, Node: node, Changes: changes})
+	}
+
+	if err := ctx.OverridePipeline(override); err != nil {
+		return err
+	}
+
+	mu.Lock()
+	diffs = append(diffs, pending...)
+	if n := len(diffs); n > maxDiffs {
+		diffs = append([]Diff(nil), diffs[n-maxDiffs:]...)
+	}
+	mu.Unlock()
+
+	for _, d := range pending {
+		if len(d.Changes) == 0 {
+			log.Debug().Str("module", module).Str("node", d.Node).Msg("Pipeline override changed nothing")
+			continue
+		}
+		log.Info().Str("module", module).Str("node", d.Node).Interface("changes", d.Changes).Msg("Pipeline override")
+	}
+	return nil
+}
+
+// RecentDiffs returns up to limit of the latest override diffs, newest first
+func RecentDiffs(limit int) []Diff {
+	mu.Lock()
+	defer mu.Unlock()
+	if limit <= 0 || limit > len(diffs) {
+		limit = len(diffs)
+	}
+	out := make([]Diff, 0, limit)
+	for i := len(diffs) - 1; i >= len(diffs)-limit; i-- {
+		out = append(out, diffs[i])
+	}
+	return out
+}
+
+// normalize round-trips v through JSON so it compares equal to values read from GetNodeJSON
+func normalize(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	err = json.Unmarshal(data, &out)
+	return out, err
+}
+
+// nodeField - GetNodeJSON 返回的是规范化后的节点，识别与动作参数位于 recognition.param / action.param 下
+func nodeField(node map[string]any, key string) any {
+	if v, ok := node[key]; ok {
+		return v
+	}
+	for _, section := range []string{"recognition", "action"} {
+		if s, ok := node[section].(map[string]any); ok {
+			if param, ok := s["param"].(map[string]any); ok {
+				if v, ok := param[key]; ok {
+					return v
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// diffValue - 递归比较对象和等长数组，其余类型整体比较
+func diffValue(path string, before, after any, out *[]FieldChange) {
+	switch a := after.(type) {
+	case map[string]any:
+		if b, ok := before.(map[string]any); ok {
+			keys := make([]string, 0, len(a)+len(b))
+			for key := range a {
+				keys = append(keys, key)
+			}
+			for key := range b {
+				if _, ok := a[key]; !ok {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				diffValue(path+"."+key, b[key], a[key], out)
+			}
+			return
+		}
+	case []any:
+		if b, ok := before.([]any); ok && len(b) == len(a) {
+			for i := range a {
+				diffValue(fmt.Sprintf("%s[%d]", path, i), b[i], a[i], out)
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(before, after) {
+		*out = append(*out, FieldChange{Path: path, Before: before, After: after})
+	}
+}
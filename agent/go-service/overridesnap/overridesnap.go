@@ -0,0 +1,81 @@
+package overridesnap
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	mu        sync.Mutex
+	baselines = map[string]map[string]string{} // module -> node -> node JSON
+)
+
+// Reset rolls the given nodes back to the state recorded at the module's first start.
+// The first call only records the baseline; call it at the start of the module's Init action,
+// before the module applies any override of its own.
+func Reset(ctx *maa.Context, module string, nodes ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	baseline, ok := baselines[module]
+	if !ok {
+		baseline = map[string]string{}
+		baselines[module] = baseline
+	}
+
+	override := map[string]json.RawMessage{}
+	for _, node := range nodes {
+		raw, err := ctx.GetNodeJSON(node)
+		if err != nil || raw == "" {
+			log.Warn().Err(err).Str("module", module).Str("node", node).Msg("Failed to read node for override snapshot")
+			continue
+		}
+		clean, ok := baseline[node]
+		if !ok {
+			baseline[node] = raw
+			continue
+		}
+		if !sameJSON(raw, clean) {
+			override[node] = json.RawMessage(clean)
+		}
+	}
+
+	if len(override) == 0 {
+		return
+	}
+	names := make([]string, 0, len(override))
+	for node := range override {
+		names = append(names, node)
+	}
+	if err := ctx.OverridePipeline(override); err != nil {
+		log.Error().Err(err).Str("module", module).Strs("nodes", names).Msg("Failed to roll back stale overrides")
+		return
+	}
+	log.Info().Str("module", module).Strs("nodes", names).Msg("Rolled back stale overrides")
+}
+
+// resourceSink - 资源重新加载后节点定义可能变化，丢弃所有已记录的快照
+type resourceSink struct{}
+
+func (s *resourceSink) OnResourceLoading(resource *maa.Resource, status maa.EventStatus, detail maa.ResourceLoadingDetail) {
+	if status != maa.EventStatusSucceeded {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	baselines = map[string]map[string]string{}
+}
+
+// sameJSON compares two JSON documents ignoring formatting and key order
+func sameJSON(a, b string) bool {
+	var va, vb any
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return a == b
+	}
+	ca, _ := json.Marshal(va)
+	cb, _ := json.Marshal(vb)
+	return string(ca) == string(cb)
+}
@@ -0,0 +1,25 @@
+package overridesnap
+
+import (
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+var (
+	_ maa.ResourceEventSink  = &resourceSink{}
+	_ maa.CustomActionRunner = &OverrideDiffAction{}
+)
+
+// Components returns the custom components of overridesnap package
+func Components() []registry.Component {
+	return []registry.Component{
+		registry.Action("OverrideDiffAction", &OverrideDiffAction{}, "显示最近的 pipeline 覆盖记录，便于排查 attach 改写结果",
+			registry.P("limit", "int", "显示的记录条数"),
+		),
+	}
+}
+
+// Register registers the resource sink that clears snapshots on resource reload
+func Register() {
+	maa.AgentServerAddResourceSink(&resourceSink{})
+}
@@ -0,0 +1,138 @@
+package pricewatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultMaxAge - 未配置 max_age_hours 时，超过一周的价格不参与比较
+const defaultMaxAge = 7 * 24 * time.Hour
+
+// seenPrice - 某商店最近一次看到的物品价格
+type seenPrice struct {
+	Price int       `json:"price"`
+	Time  time.Time `json:"time"`
+}
+
+// seen - 物品名 -> 商店名 -> 最近价格，首次使用时从数据目录读取；读写时持有 mu
+var seen map[string]map[string]seenPrice
+
+func seenPath() string {
+	return datadir.Path("pricewatch", "last_seen.json")
+}
+
+// loadSeen must be called with mu held
+func loadSeen() {
+	if seen != nil {
+		return
+	}
+	seen = make(map[string]map[string]seenPrice)
+	data, err := os.ReadFile(seenPath())
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, &seen); err != nil {
+		log.Warn().Err(err).Msg("Failed to parse last seen prices, starting fresh")
+		seen = make(map[string]map[string]seenPrice)
+	}
+}
+
+// saveSeen must be called with mu held
+func saveSeen() {
+	data, err := json.MarshalIndent(seen, "", "  ")
+	if err != nil {
+		return
+	}
+	path := seenPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Warn().Err(err).Msg("Failed to create pricewatch data dir")
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Warn().Err(err).Msg("Failed to save last seen prices")
+	}
+}
+
+// cheaper - 另一商店按汇率折算后更便宜的报价
+type cheaper struct {
+	Shop  string
+	Price int
+	// Value, OtherValue - 本次与另一商店折算后的价值
+	Value, OtherValue float64
+}
+
+// cheaperElsewhere - 在 Rates 中设置了汇率、且未过期的其他商店里找折算后最便宜且低于本次的报价
+func cheaperElsewhere(cfg agentconfig.PriceCompareConfig, obs Observation, shops map[string]seenPrice, now time.Time) (cheaper, bool) {
+	rate, ok := cfg.Rates[obs.Shop]
+	if !ok || rate <= 0 {
+		return cheaper{}, false
+	}
+	maxAge := defaultMaxAge
+	if cfg.MaxAgeHours > 0 {
+		maxAge = time.Duration(cfg.MaxAgeHours) * time.Hour
+	}
+
+	value := float64(obs.Price) * rate
+	var best cheaper
+	found := false
+	for shop, p := range shops {
+		otherRate, ok := cfg.Rates[shop]
+		if shop == obs.Shop || !ok || otherRate <= 0 || now.Sub(p.Time) > maxAge {
+			continue
+		}
+		other := float64(p.Price) * otherRate
+		if other < value && (!found || other < best.OtherValue) {
+			best = cheaper{Shop: shop, Price: p.Price, Value: value, OtherValue: other}
+			found = true
+		}
+	}
+	return best, found
+}
+
+// compare records every observation and recommends the other shop when it sells the item cheaper
+func compare(ctx *maa.Context, observations []Observation) {
+	cfg := agentconfig.Get().PriceCompare
+	now := time.Now()
+
+	var hits []string
+	mu.Lock()
+	loadSeen()
+	changed := false
+	for _, obs := range observations {
+		item := strings.TrimSpace(obs.Item)
+		if item == "" || obs.Shop == "" || obs.Price <= 0 {
+			continue
+		}
+		if c, ok := cheaperElsewhere(cfg, obs, seen[item], now); ok {
+			key := fmt.Sprintf("compare|%s|%s", obs.Shop, item)
+			if !notified[key] {
+				notified[key] = true
+				log.Info().Str("item", item).Str("shop", obs.Shop).Int("price", obs.Price).Str("cheaper_shop", c.Shop).Int("cheaper_price", c.Price).Msg("Item is cheaper in another shop")
+				hits = append(hits, fmt.Sprintf("💱 %s在[%s]售价 %d（折合 %.0f），[%s]售价 %d（折合 %.0f）更便宜，建议从[%s]购买",
+					item, obs.Shop, obs.Price, c.Value, c.Shop, c.Price, c.OtherValue, c.Shop))
+			}
+		}
+		if seen[item] == nil {
+			seen[item] = make(map[string]seenPrice)
+		}
+		seen[item][obs.Shop] = seenPrice{Price: obs.Price, Time: now}
+		changed = true
+	}
+	if changed {
+		saveSeen()
+	}
+	mu.Unlock()
+
+	for _, message := range hits {
+		showMessage(ctx, message)
+	}
+}
@@ -0,0 +1,101 @@
+package pricewatch
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// Observation - 一次扫描中看到的物品价格
+type Observation struct {
+	Item string
+	// Shop - 商店名称，用于提示
+	Shop  string
+	Price int
+	// SalePrice - 好友出售价，未知时为 0
+	SalePrice int
+}
+
+var (
+	mu sync.Mutex
+	// notified - 本次任务已经提醒过的规则，避免同一物品重复提醒
+	notified = make(map[string]bool)
+)
+
+// Reset clears the per-task notification history
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	notified = make(map[string]bool)
+}
+
+// Check matches observations against the configured watch rules and notifies on each hit,
+// then compares them with the prices last seen in other shops
+func Check(ctx *maa.Context, observations []Observation) {
+	compare(ctx, observations)
+	rules := agentconfig.Get().PriceWatch
+	if len(rules) == 0 {
+		return
+	}
+
+	for _, obs := range observations {
+		if obs.Item == "" {
+			continue
+		}
+		for _, rule := range rules {
+			if !matches(rule, obs) {
+				continue
+			}
+			key := fmt.Sprintf("%s|%s|%d|%d", obs.Shop, rule.Item, rule.MaxPrice, rule.MinProfit)
+			mu.Lock()
+			seen := notified[key]
+			notified[key] = true
+			mu.Unlock()
+			if seen {
+				continue
+			}
+			notify(ctx, rule, obs)
+		}
+	}
+}
+
+func matches(rule agentconfig.PriceWatchRule, obs Observation) bool {
+	if rule.Item == "" || !strings.Contains(obs.Item, rule.Item) {
+		return false
+	}
+	if rule.MaxPrice > 0 && obs.Price > rule.MaxPrice {
+		return false
+	}
+	if rule.MinProfit > 0 && (obs.SalePrice == 0 || obs.SalePrice-obs.Price < rule.MinProfit) {
+		return false
+	}
+	return true
+}
+
+func notify(ctx *maa.Context, rule agentconfig.PriceWatchRule, obs Observation) {
+	message := fmt.Sprintf("🔔 %s出现价格 %d", obs.Item, obs.Price)
+	if obs.SalePrice > 0 {
+		message += fmt.Sprintf("（好友售价 %d，利润 %d）", obs.SalePrice, obs.SalePrice-obs.Price)
+	}
+	if obs.Shop != "" {
+		message = fmt.Sprintf("[%s] %s", obs.Shop, message)
+	}
+	log.Info().Str("item", obs.Item).Int("price", obs.Price).Interface("rule", rule).Msg("Price watch hit")
+	showMessage(ctx, message)
+}
+
+func showMessage(ctx *maa.Context, message string) {
+	ctx.RunTask("PriceWatch_TaskShowMessage", map[string]interface{}{
+		"PriceWatch_TaskShowMessage": map[string]interface{}{
+			"recognition": "DirectHit",
+			"action":      "DoNothing",
+			"focus": map[string]interface{}{
+				"Node.Action.Starting": message,
+			},
+		},
+	})
+}
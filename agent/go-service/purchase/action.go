@@ -0,0 +1,58 @@
+package purchase
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/safemode"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// PurchaseTransactionAction - 以事务方式执行购买节点
+// custom_action_param: {"steps": ["ResellBuy"], "verify": "ResellReturnToStore", "fail": "", "receipt": "", "max_attempts": 2, "verify_timeout_ms": 5000}
+type PurchaseTransactionAction struct{}
+
+func (a *PurchaseTransactionAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	var params struct {
+		Steps           []string `json:"steps"`
+		Verify          string   `json:"verify"`
+		Fail            string   `json:"fail"`
+		Receipt         string   `json:"receipt"`
+		MaxAttempts     int      `json:"max_attempts"`
+		VerifyTimeoutMs int      `json:"verify_timeout_ms"`
+	}
+	if err := json.Unmarshal([]byte(arg.CustomActionParam), &params); err != nil {
+		log.Error().Err(err).Msg("Failed to parse PurchaseTransactionAction param")
+		return false
+	}
+	if len(params.Steps) == 0 || params.Verify == "" {
+		log.Error().Msg("PurchaseTransactionAction requires steps and verify")
+		return false
+	}
+	if safemode.Active() {
+		safemode.StopBeforePurchase(ctx.GetTasker(), arg.CurrentTaskName)
+		return false
+	}
+
+	err := Transaction{
+		Name:          arg.CurrentTaskName,
+		Steps:         params.Steps,
+		Verify:        params.Verify,
+		Fail:          params.Fail,
+		Receipt:       params.Receipt,
+		MaxAttempts:   params.MaxAttempts,
+		VerifyTimeout: time.Duration(params.VerifyTimeoutMs) * time.Millisecond,
+	}.Run(ctx)
+	if errors.Is(err, ErrPurchaseRejected) {
+		// 交给 pipeline 的 next 处理失败提示
+		log.Info().Str("transaction", arg.CurrentTaskName).Msg("Purchase rejected by game")
+		return true
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Purchase transaction failed")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,212 @@
+package purchase
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/ocrutil"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// Receipt - 购买结果画面上的物品名、数量与总价，零值字段不参与核对
+type Receipt struct {
+	Item     string `json:"item,omitempty"`
+	Quantity int    `json:"quantity,omitempty"`
+	Price    int    `json:"price,omitempty"`
+}
+
+// Mismatch - 购买结果与预期不一致的一次记录
+type Mismatch struct {
+	Time        time.Time `json:"time"`
+	Transaction string    `json:"transaction"`
+	Expected    Receipt   `json:"expected"`
+	Got         Receipt   `json:"got"`
+	Fields      []string  `json:"fields"`
+	Text        string    `json:"text"`
+}
+
+var (
+	receiptMu  sync.Mutex
+	expected   *Receipt
+	mismatches []Mismatch
+)
+
+var (
+	quantityPattern = regexp.MustCompile(`[x×X＊*]\s*(\d+)`)
+	numberPattern   = regexp.MustCompile(`\d+`)
+)
+
+// Expect sets what the next transaction is supposed to buy; the receipt is reconciled against it
+func Expect(r Receipt) {
+	receiptMu.Lock()
+	defer receiptMu.Unlock()
+	expected = &r
+}
+
+// Mismatches returns the receipt mismatches recorded since the agent started
+func Mismatches() []Mismatch {
+	receiptMu.Lock()
+	defer receiptMu.Unlock()
+	return append([]Mismatch(nil), mismatches...)
+}
+
+func takeExpected() *Receipt {
+	receiptMu.Lock()
+	defer receiptMu.Unlock()
+	r := expected
+	expected = nil
+	return r
+}
+
+// parseReceipt - 从购买结果画面的文字中取出物品名、数量和价格
+// 价格优先取与预期一致的数字，其次取最大的数字
+func parseReceipt(text string, want Receipt) Receipt {
+	var got Receipt
+	if want.Item != "" && strings.Contains(text, want.Item) {
+		got.Item = want.Item
+	}
+
+	rest := text
+	if m := quantityPattern.FindStringSubmatch(text); m != nil {
+		got.Quantity, _ = strconv.Atoi(m[1])
+		rest = strings.Replace(text, m[0], " ", 1)
+	}
+	for _, s := range numberPattern.FindAllString(rest, -1) {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			continue
+		}
+		if want.Price > 0 && n == want.Price {
+			got.Price = n
+			break
+		}
+		if n > got.Price {
+			got.Price = n
+		}
+	}
+	return got
+}
+
+// diffReceipt - 列出与预期不一致的字段
+func diffReceipt(want, got Receipt) []string {
+	var fields []string
+	if want.Item != "" && got.Item != want.Item {
+		fields = append(fields, "item")
+	}
+	if want.Quantity > 0 && got.Quantity != want.Quantity {
+		fields = append(fields, "quantity")
+	}
+	if want.Price > 0 && got.Price != want.Price {
+		fields = append(fields, "price")
+	}
+	return fields
+}
+
+// reconcile - 购买确认成功后识别购买结果，与 Expect 设置的预期核对
+func (t Transaction) reconcile(ctx *maa.Context) {
+	want := takeExpected()
+	if t.Receipt == "" || want == nil {
+		return
+	}
+
+	controller := ctx.GetTasker().GetController()
+	if controller == nil {
+		log.Warn().Err(errNoController).Str("transaction", t.Name).Msg("Failed to capture receipt")
+		return
+	}
+	controller.PostScreencap().Wait()
+	img, err := controller.CacheImage()
+	if err != nil || img == nil {
+		log.Warn().Err(err).Str("transaction", t.Name).Msg("Failed to capture receipt")
+		return
+	}
+	lines, err := ocrutil.Lines(ctx, img, ocrutil.ROIRequest{Pipeline: t.Receipt})
+	if err != nil {
+		log.Warn().Err(err).Str("transaction", t.Name).Msg("Failed to read receipt")
+		return
+	}
+	texts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		texts = append(texts, line.Text)
+	}
+	text := strings.Join(texts, "\n")
+
+	got := parseReceipt(text, *want)
+	fields := diffReceipt(*want, got)
+	if len(fields) == 0 {
+		log.Info().Str("transaction", t.Name).Interface("receipt", got).Msg("Receipt matches intended purchase")
+		return
+	}
+
+	m := Mismatch{
+		Time:        time.Now(),
+		Transaction: t.Name,
+		Expected:    *want,
+		Got:         got,
+		Fields:      fields,
+		Text:        text,
+	}
+	receiptMu.Lock()
+	mismatches = append(mismatches, m)
+	receiptMu.Unlock()
+	saveMismatch(m)
+
+	log.Error().
+		Str("transaction", t.Name).
+		Interface("expected", *want).
+		Interface("got", got).
+		Strs("fields", fields).
+		Str("text", text).
+		Msg("Receipt does not match intended purchase")
+	showMessage(ctx, fmt.Sprintf("🚨 购买结果与预期不一致（%s）\n预期：%s\n实际：%s\n可能点到了相邻的商品，请检查背包",
+		strings.Join(fields, "、"), formatReceipt(*want), formatReceipt(got)))
+}
+
+func formatReceipt(r Receipt) string {
+	item := r.Item
+	if item == "" {
+		item = "?"
+	}
+	return fmt.Sprintf("%s ×%d，%d", item, r.Quantity, r.Price)
+}
+
+// saveMismatch - 追加写入数据目录下的 purchase/mismatch.jsonl
+func saveMismatch(m Mismatch) {
+	dir := datadir.Path("purchase")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Warn().Err(err).Msg("Failed to create purchase data dir")
+		return
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return
+	}
+	f, err := os.OpenFile(filepath.Join(dir, "mismatch.jsonl"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to record receipt mismatch")
+		return
+	}
+	defer f.Close()
+	f.Write(append(data, '\n'))
+}
+
+func showMessage(ctx *maa.Context, text string) {
+	ctx.RunTask("Purchase_TaskShowMessage", map[string]interface{}{
+		"Purchase_TaskShowMessage": map[string]interface{}{
+			"recognition": "DirectHit",
+			"action":      "DoNothing",
+			"focus": map[string]interface{}{
+				"Node.Action.Starting": text,
+			},
+		},
+	})
+}
@@ -0,0 +1,33 @@
+package purchase
+
+import (
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+var (
+	_ maa.CustomActionRunner = &PurchaseTransactionAction{}
+	_ maa.CustomActionRunner = &PurchaseSpaceCheckAction{}
+)
+
+// Components returns the custom components of purchase package
+func Components() []registry.Component {
+	P := registry.P
+	return []registry.Component{
+		registry.Action("PurchaseTransactionAction", &PurchaseTransactionAction{}, "以事务方式执行购买节点，直到确认购买成功",
+			P("steps", "array", "依次执行的节点，不跟随其 next"),
+			P("verify", "string", "购买成功后才会出现的识别节点"),
+			P("fail", "string", "可选，购买被拒绝时出现的识别节点"),
+			P("receipt", "string", "可选，购买结果画面的 OCR 节点，用于核对物品与价格"),
+			P("max_attempts", "int", "最多尝试的次数"),
+			P("verify_timeout_ms", "int", "等待 verify 命中的毫秒数"),
+		),
+		registry.Action("PurchaseSpaceCheckAction", &PurchaseSpaceCheckAction{}, "购买前检查背包空间，不足时停止后续购买",
+			P("node", "string", "识别容量的节点"),
+			P("need", "int", "购买所需的格数"),
+			P("reserve", "int", "额外保留的格数"),
+			P("fail", "string", "空间不足时跳转的节点"),
+			P("candidates", "array", "命中数量作为跳过数量下限的节点"),
+		),
+	}
+}
@@ -0,0 +1,120 @@
+package purchase
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"regexp"
+	"strconv"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/ocrutil"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// SpaceNode - 背包容量指示（形如 "123/500"）的 OCR 节点
+const SpaceNode = "Purchase_ROI_InventoryCapacity"
+
+var spacePattern = regexp.MustCompile(`(\d+)\s*/\s*(\d+)`)
+
+// Space - 背包已用格数与容量
+type Space struct {
+	Used     int
+	Capacity int
+}
+
+// Free returns the number of empty slots
+func (s Space) Free() int {
+	if s.Used >= s.Capacity {
+		return 0
+	}
+	return s.Capacity - s.Used
+}
+
+// ReadSpace OCRs the inventory capacity indicator on img
+func ReadSpace(ctx *maa.Context, img image.Image, node string) (Space, error) {
+	if node == "" {
+		node = SpaceNode
+	}
+	lines, err := ocrutil.Lines(ctx, img, ocrutil.ROIRequest{Pipeline: node})
+	if err != nil {
+		return Space{}, err
+	}
+	for _, line := range lines {
+		m := spacePattern.FindStringSubmatch(line.Text)
+		if m == nil {
+			continue
+		}
+		used, _ := strconv.Atoi(m[1])
+		capacity, _ := strconv.Atoi(m[2])
+		if capacity > 0 {
+			return Space{Used: used, Capacity: capacity}, nil
+		}
+	}
+	return Space{}, fmt.Errorf("%s: capacity indicator not found", node)
+}
+
+// PurchaseSpaceCheckAction - 购买前检查背包空间，空间不足时停止后续购买并报告跳过的数量
+// custom_action_param: {"node": "Purchase_ROI_InventoryCapacity", "need": 1, "reserve": 0, "fail": "CreditShoppingNothingToBuy", "candidates": ["CreditShoppingBuyFirst"]}
+// candidates 中的节点在当前画面命中的数量作为跳过数量的下限；无法识别容量时不阻止购买
+type PurchaseSpaceCheckAction struct{}
+
+func (a *PurchaseSpaceCheckAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	var params struct {
+		Node       string   `json:"node"`
+		Need       int      `json:"need"`
+		Reserve    int      `json:"reserve"`
+		Fail       string   `json:"fail"`
+		Candidates []string `json:"candidates"`
+	}
+	if err := json.Unmarshal([]byte(arg.CustomActionParam), &params); err != nil {
+		log.Error().Err(err).Msg("Failed to parse PurchaseSpaceCheckAction param")
+		return false
+	}
+	if params.Need <= 0 {
+		params.Need = 1
+	}
+
+	controller := ctx.GetTasker().GetController()
+	if controller == nil {
+		log.Error().Msg("Failed to get controller")
+		return false
+	}
+	controller.PostScreencap().Wait()
+	img, err := controller.CacheImage()
+	if err != nil || img == nil {
+		log.Warn().Err(err).Msg("Failed to capture screen for inventory space check, continuing")
+		return true
+	}
+	space, err := ReadSpace(ctx, img, params.Node)
+	if err != nil {
+		log.Warn().Err(err).Msg("Inventory space unknown, continuing")
+		return true
+	}
+
+	free := space.Free() - params.Reserve
+	log.Info().Int("used", space.Used).Int("capacity", space.Capacity).Int("reserve", params.Reserve).Int("need", params.Need).Msg("Inventory space checked")
+	if free >= params.Need {
+		return true
+	}
+
+	skipped := 0
+	for _, node := range params.Candidates {
+		if detail, err := ctx.RunRecognition(node, img); err == nil && detail != nil && detail.Hit {
+			skipped++
+		}
+	}
+	if skipped == 0 {
+		skipped = 1
+	}
+	log.Warn().Int("free", free).Int("skipped", skipped).Msg("Not enough inventory space, stopping purchases")
+	showMessage(ctx, fmt.Sprintf("🎒 背包空间不足（%d/%d，预留 %d 格），已停止购买\n至少跳过 %d 次购买，请清理背包后再运行",
+		space.Used, space.Capacity, params.Reserve, skipped))
+
+	next := []maa.NodeNextItem{}
+	if params.Fail != "" {
+		next = append(next, maa.NodeNextItem{Name: params.Fail})
+	}
+	ctx.OverrideNext(arg.CurrentTaskName, next)
+	return true
+}
@@ -0,0 +1,137 @@
+package purchase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultMaxAttempts   = 2
+	defaultVerifyTimeout = 5 * time.Second
+	verifyPollInterval   = 500 * time.Millisecond
+)
+
+// ErrPurchaseRejected - 游戏明确拒绝了购买（售罄、余额不足等），不会重试
+var ErrPurchaseRejected = errors.New("purchase rejected")
+
+var errNoController = errors.New("failed to get controller")
+
+// Transaction - select→confirm→verify 的购买过程
+// 重试前先检查购买是否已经生效，避免界面卡顿导致重复购买
+type Transaction struct {
+	Name string
+	// Steps - 依次执行的节点，每个节点只执行自身，不跟随其 next
+	Steps []string
+	// Verify - 购买成功后才会出现的识别节点
+	Verify string
+	// Fail - 可选，购买被拒绝时出现的识别节点
+	Fail string
+	// Receipt - 可选，购买结果画面的 OCR 节点，用于与 Expect 设置的预期核对
+	Receipt       string
+	MaxAttempts   int
+	VerifyTimeout time.Duration
+}
+
+// Run executes the transaction and returns nil once the purchase is verified
+func (t Transaction) Run(ctx *maa.Context) error {
+	maxAttempts := t.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	// 被拒绝或失败时不应把预期留给下一次购买
+	defer takeExpected()
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			// 上一次结果不明确，重试前确认购买是否已经完成
+			done, err := t.check(ctx)
+			if err != nil {
+				return err
+			}
+			if done {
+				log.Info().Str("transaction", t.Name).Int("attempt", attempt).Msg("Purchase already went through, not retrying")
+				t.reconcile(ctx)
+				return nil
+			}
+		}
+
+		if err := t.runSteps(ctx); err != nil {
+			log.Warn().Err(err).Str("transaction", t.Name).Int("attempt", attempt).Msg("Purchase step failed")
+			continue
+		}
+
+		done, err := t.waitVerified(ctx)
+		if err != nil {
+			return err
+		}
+		if done {
+			log.Info().Str("transaction", t.Name).Int("attempt", attempt).Msg("Purchase verified")
+			t.reconcile(ctx)
+			return nil
+		}
+		log.Warn().Str("transaction", t.Name).Int("attempt", attempt).Msg("Purchase not verified in time")
+	}
+	return fmt.Errorf("transaction %s: not verified after %d attempts", t.Name, maxAttempts)
+}
+
+func (t Transaction) runSteps(ctx *maa.Context) error {
+	for _, step := range t.Steps {
+		detail, err := ctx.RunTask(step, map[string]any{
+			step: map[string]any{"next": []string{}},
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", step, err)
+		}
+		if detail == nil || !detail.Status.Success() {
+			return fmt.Errorf("%s: node did not complete", step)
+		}
+	}
+	return nil
+}
+
+// waitVerified - 在 VerifyTimeout 内轮询 Verify / Fail 节点
+func (t Transaction) waitVerified(ctx *maa.Context) (bool, error) {
+	timeout := t.VerifyTimeout
+	if timeout <= 0 {
+		timeout = defaultVerifyTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		done, err := t.check(ctx)
+		if err != nil || done {
+			return done, err
+		}
+		if time.Now().After(deadline) {
+			return false, nil
+		}
+		time.Sleep(verifyPollInterval)
+	}
+}
+
+// check - 截图并识别一次，Verify 命中返回 true，Fail 命中返回 ErrPurchaseRejected
+func (t Transaction) check(ctx *maa.Context) (bool, error) {
+	controller := ctx.GetTasker().GetController()
+	if controller == nil {
+		return false, errNoController
+	}
+	controller.PostScreencap().Wait()
+	img, err := controller.CacheImage()
+	if err != nil || img == nil {
+		return false, nil
+	}
+
+	if detail, err := ctx.RunRecognition(t.Verify, img); err == nil && detail != nil && detail.Hit {
+		return true, nil
+	}
+	if t.Fail != "" {
+		if detail, err := ctx.RunRecognition(t.Fail, img); err == nil && detail != nil && detail.Hit {
+			return false, ErrPurchaseRejected
+		}
+	}
+	return false, nil
+}
@@ -1,14 +1,19 @@
 package puzzle
 
-import "github.com/MaaXYZ/maa-framework-go/v4"
+import (
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
 
 var (
 	_ maa.CustomRecognitionRunner = &Recognition{}
 	_ maa.CustomActionRunner      = &Action{}
 )
 
-// Register registers all custom recognition and action components for puzzle-solver package
-func Register() {
-	maa.AgentServerRegisterCustomRecognition("PuzzleRecognition", &Recognition{})
-	maa.AgentServerRegisterCustomAction("PuzzleAction", &Action{})
+// Components returns the custom components of puzzle-solver package
+func Components() []registry.Component {
+	return []registry.Component{
+		registry.Recognition("PuzzleRecognition", &Recognition{}, "识别拼图棋盘、可用方块与限制并求解"),
+		registry.Action("PuzzleAction", &Action{}, "按求解结果拖放方块"),
+	}
 }
@@ -1,6 +1,9 @@
 package realtime
 
-import "github.com/MaaXYZ/maa-framework-go/v4"
+import (
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
 
 var (
 	_ maa.CustomRecognitionRunner = &RealTimeAutoFightEntryRecognition{}
@@ -11,12 +14,16 @@ var (
 	_ maa.CustomActionRunner      = &RealTimeAutoFightEndSkillAction{}
 )
 
-// Register registers all custom recognition and action components for realtime package
-func Register() {
-	maa.AgentServerRegisterCustomRecognition("RealTimeAutoFightEntryRecognition", &RealTimeAutoFightEntryRecognition{})
-	maa.AgentServerRegisterCustomRecognition("RealTimeAutoFightExitRecognition", &RealTimeAutoFightExitRecognition{})
-	maa.AgentServerRegisterCustomRecognition("RealTimeAutoFightSkillRecognition", &RealTimeAutoFightSkillRecognition{})
-	maa.AgentServerRegisterCustomAction("RealTimeAutoFightSkillAction", &RealTimeAutoFightSkillAction{})
-	maa.AgentServerRegisterCustomRecognition("RealTimeAutoFightEndSkillRecognition", &RealTimeAutoFightEndSkillRecognition{})
-	maa.AgentServerRegisterCustomAction("RealTimeAutoFightEndSkillAction", &RealTimeAutoFightEndSkillAction{})
+// Components returns the custom components of realtime package
+func Components() []registry.Component {
+	return []registry.Component{
+		registry.Recognition("RealTimeAutoFightEntryRecognition", &RealTimeAutoFightEntryRecognition{}, "识别进入战斗",
+			registry.P("LockTarget", "bool", "进入战斗时按下鼠标中键锁定敌人"),
+		),
+		registry.Recognition("RealTimeAutoFightExitRecognition", &RealTimeAutoFightExitRecognition{}, "识别退出战斗"),
+		registry.Recognition("RealTimeAutoFightSkillRecognition", &RealTimeAutoFightSkillRecognition{}, "技能能量第二格满时命中"),
+		registry.Action("RealTimeAutoFightSkillAction", &RealTimeAutoFightSkillAction{}, "按干员数量轮流按下技能键"),
+		registry.Recognition("RealTimeAutoFightEndSkillRecognition", &RealTimeAutoFightEndSkillRecognition{}, "识别终结技可释放的干员"),
+		registry.Action("RealTimeAutoFightEndSkillAction", &RealTimeAutoFightEndSkillAction{}, "长按对应按键释放终结技"),
+	}
 }
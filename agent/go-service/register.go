@@ -1,32 +1,127 @@
 package main
 
 import (
+	"path/filepath"
+
 	"github.com/MaaXYZ/MaaEnd/agent/go-service/aspectratio"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/chain"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/clicklog"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/crashreport"
 	"github.com/MaaXYZ/MaaEnd/agent/go-service/creditshopping"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/digitstrip"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/envsnap"
 	"github.com/MaaXYZ/MaaEnd/agent/go-service/essencefilter"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/extplugin"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/foreground"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/gameversion"
 	"github.com/MaaXYZ/MaaEnd/agent/go-service/hdrcheck"
 	"github.com/MaaXYZ/MaaEnd/agent/go-service/importtask"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/itemicon"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/macro"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/moduleinfo"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/nodecheck"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/overridesnap"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/purchase"
 	puzzle "github.com/MaaXYZ/MaaEnd/agent/go-service/puzzle-solver"
 	"github.com/MaaXYZ/MaaEnd/agent/go-service/realtime"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
 	"github.com/MaaXYZ/MaaEnd/agent/go-service/resell"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/respack"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/safemode"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/schedule"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/seed"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/shoptab"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/stuckcheck"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/takeover"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/taskresult"
 	"github.com/rs/zerolog/log"
 )
 
+// modules - 各包的自定义动作与识别，name 为 --list-actions 中显示的模块名
+var modules = []struct {
+	name       string
+	components func() []registry.Component
+}{
+	{"RealTime", realtime.Components},
+	{"ImportTask", importtask.Components},
+	{"Resell", resell.Components},
+	{"Puzzle", puzzle.Components},
+	{"EssenceFilter", essencefilter.Components},
+	{"CreditShopping", creditshopping.Components},
+	{"GameVersion", gameversion.Components},
+	{"Macro", macro.Components},
+	{"Purchase", purchase.Components},
+	{"TaskResult", taskresult.Components},
+	{"ItemIcon", itemicon.Components},
+	{"DigitStrip", digitstrip.Components},
+	{"Schedule", schedule.Components},
+	{"OverrideSnap", overridesnap.Components},
+	{"ShopTab", shoptab.Components},
+	{"Chain", chain.Components},
+	{"ModuleInfo", moduleinfo.Components},
+}
+
+// addComponents records the custom components of every package, then those of third-party plugins
+// discovered under plugins/, in the registry. It does not touch the MAA framework, so --list-actions
+// can use it without loading the library.
+func addComponents() {
+	for _, m := range modules {
+		registry.Add(m.name, m.components()...)
+	}
+	extplugin.Register(filepath.Join(getCwd(), "plugins"))
+}
+
 func registerAll() {
-	// Register all custom components from each package
-	realtime.Register()
-	importtask.Register()
+	// Register per-run seeder first so every other sink and component sees the new seed
+	seed.Register()
+
+	// Register all custom components from each package and plugin (plugins run as separate processes)
+	addComponents()
+	registry.RegisterAll()
+
+	// Register the sinks and referenced nodes of packages that also have custom components
 	resell.Register()
-	puzzle.Register()
 	essencefilter.Register()
 	creditshopping.Register()
+	macro.Register()
+	schedule.Register()
+	overridesnap.Register()
 
 	// Register aspect ratio checker (uses TaskerSink, not custom action/recognition)
 	aspectratio.Register()
 
+	// Register foreground guard (uses TaskerSink, raises the game window before clicks start landing on it)
+	foreground.Register()
+
 	// Register HDR checker (uses TaskerSink, warns if HDR is enabled but doesn't stop task)
 	hdrcheck.Register()
 
+	// Register resource pack loader (uses TaskerSink, loads the selected pack before the node check runs)
+	respack.Register()
+
+	// Register run environment recorder (uses TaskerSink, after the resource pack loads so the snapshot names it;
+	// crash reports and safe mode bundles embed the snapshot)
+	envsnap.Register()
+
+	// Register pipeline node reference checker (uses TaskerSink, reports nodes missing from resources)
+	nodecheck.Register()
+
+	// Register stuck detector (uses TaskerSink and ContextSink, stops task if screen never changes)
+	stuckcheck.Register()
+
+	// Register crash tracer (uses TaskerSink and ContextSink, keeps recent node events for crash reports)
+	crashreport.Register()
+
+	// Register safe mode guard (uses TaskerSink and ContextSink, records a diagnostic bundle for tasks that keep failing)
+	safemode.Register()
+
+	// Register takeover pause resetter (uses TaskerSink, clears a pause left over from the previous task)
+	takeover.Register()
+
+	// Register click logger (uses TaskerSink and ContextSink, reports screen regions where clicks have no effect)
+	clicklog.Register()
+
 	log.Info().
+		Int("components", len(registry.List())).
 		Msg("All custom components and sinks registered successfully")
 }
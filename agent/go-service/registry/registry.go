@@ -0,0 +1,132 @@
+// Package registry records every custom action and recognition together with a description and
+// the params it expects. main registers them with the agent server in one place, and
+// `go-service --list-actions` prints them as JSON for frontend tooling.
+package registry
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// Kind - 组件类型
+type Kind string
+
+const (
+	KindAction      Kind = "action"
+	KindRecognition Kind = "recognition"
+)
+
+// Param - custom_action_param / custom_recognition_param 中的一个字段
+type Param struct {
+	Name string `json:"name"`
+	// Type - JSON 类型：string、int、number、bool、array、object，可接受多种类型时以 | 分隔
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// P returns a Param; kept short so each component's params read as one table
+func P(name, typ, description string) Param {
+	return Param{Name: name, Type: typ, Description: description}
+}
+
+// Component - 一个自定义动作或识别，Module 为所属模块，插件的组件为 plugin:<插件名>
+type Component struct {
+	Kind        Kind    `json:"kind"`
+	Name        string  `json:"name"`
+	Module      string  `json:"module"`
+	Description string  `json:"description"`
+	Params      []Param `json:"params,omitempty"`
+
+	action      maa.CustomActionRunner
+	recognition maa.CustomRecognitionRunner
+}
+
+// Action describes a custom action
+func Action(name string, runner maa.CustomActionRunner, description string, params ...Param) Component {
+	return Component{Kind: KindAction, Name: name, Description: description, Params: params, action: runner}
+}
+
+// Recognition describes a custom recognition
+func Recognition(name string, runner maa.CustomRecognitionRunner, description string, params ...Param) Component {
+	return Component{Kind: KindRecognition, Name: name, Description: description, Params: params, recognition: runner}
+}
+
+var (
+	mu         sync.Mutex
+	components []Component
+	// taken - 已登记的组件名，动作与识别共用同一命名空间
+	taken = map[string]string{}
+)
+
+// Add records the components of module. A component whose name is already taken is skipped with a
+// warning, so the first module to claim a name wins; it reports whether every component was recorded.
+func Add(module string, list ...Component) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	all := true
+	for _, c := range list {
+		if owner, ok := taken[c.Name]; ok {
+			log.Warn().Str("module", module).Str("name", c.Name).Str("owner", owner).Msg("Component name already taken, skipped")
+			all = false
+			continue
+		}
+		c.Module = module
+		taken[c.Name] = module
+		components = append(components, c)
+	}
+	return all
+}
+
+// List returns the recorded components ordered by module, kind and name
+func List() []Component {
+	mu.Lock()
+	list := append([]Component(nil), components...)
+	mu.Unlock()
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Module != list[j].Module {
+			return list[i].Module < list[j].Module
+		}
+		if list[i].Kind != list[j].Kind {
+			return list[i].Kind < list[j].Kind
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// RegisterAll registers every recorded component with the agent server
+func RegisterAll() {
+	for _, c := range List() {
+		var err error
+		switch c.Kind {
+		case KindAction:
+			err = maa.AgentServerRegisterCustomAction(c.Name, c.action)
+		case KindRecognition:
+			err = maa.AgentServerRegisterCustomRecognition(c.Name, c.recognition)
+		}
+		if err != nil {
+			log.Error().Err(err).Str("module", c.Module).Str("name", c.Name).Msg("Failed to register component")
+		}
+	}
+}
+
+// RegisterTo registers components on res, for running tasks on a tasker outside the agent server
+func RegisterTo(res *maa.Resource, list ...Component) error {
+	for _, c := range list {
+		var err error
+		switch c.Kind {
+		case KindAction:
+			err = res.RegisterCustomAction(c.Name, c.action)
+		case KindRecognition:
+			err = res.RegisterCustomRecognition(c.Name, c.recognition)
+		}
+		if err != nil {
+			return fmt.Errorf("register %s: %w", c.Name, err)
+		}
+	}
+	return nil
+}
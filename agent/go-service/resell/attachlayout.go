@@ -0,0 +1,163 @@
+package resell
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/overridesnap"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// shelfGrid - attach.layout 中一个货架的格子坐标（720p 基准），未填写的字段沿用节点中的值
+type shelfGrid struct {
+	// RowsY - 每行价格区域的 y，长度须与货架行数一致
+	RowsY []int `json:"rows_y"`
+	// StartX、ColWidth - 第一列价格区域的 x 与相邻两列的间距，需同时填写
+	StartX    *int  `json:"start_x"`
+	ColWidth  int   `json:"col_width"`
+	PriceSize []int `json:"price_size"`
+	// Select 开头的字段对应选择商品节点的点击区域，含义同上
+	SelectRowsY    []int `json:"select_rows_y"`
+	SelectStartX   *int  `json:"select_start_x"`
+	SelectColWidth int   `json:"select_col_width"`
+	SelectSize     []int `json:"select_size"`
+}
+
+// attachLayout - ResellStart 节点 attach.layout 的内容，用于在不改动大量节点的情况下整体调整坐标
+type attachLayout struct {
+	Main    *shelfGrid `json:"main"`
+	Special *shelfGrid `json:"special"`
+	// ROIs - 详情页等单个节点的 roi，键为节点名
+	ROIs map[string][]int `json:"rois"`
+}
+
+// loadAttachLayout - 读取节点的 attach.layout，未配置时返回 false
+func loadAttachLayout(ctx *maa.Context, node string) (attachLayout, bool) {
+	raw, err := ctx.GetNodeJSON(node)
+	if err != nil || raw == "" {
+		return attachLayout{}, false
+	}
+	var data struct {
+		Attach struct {
+			Layout *attachLayout `json:"layout"`
+		} `json:"attach"`
+	}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		log.Warn().Err(err).Str("node", node).Msg("[Resell]解析 attach.layout 失败，沿用节点坐标")
+		return attachLayout{}, false
+	}
+	if data.Attach.Layout == nil {
+		return attachLayout{}, false
+	}
+	return *data.Attach.Layout, true
+}
+
+// applyAttachLayout - 按 attach.layout 覆盖价格区域、点击区域与详情页 roi，需在缩放之前调用
+func applyAttachLayout(ctx *maa.Context, node string) {
+	layout, ok := loadAttachLayout(ctx, node)
+	if !ok {
+		return
+	}
+	override := map[string]any{}
+	for _, s := range []struct {
+		profile shelfProfile
+		grid    *shelfGrid
+	}{
+		{mainShelfProfile, layout.Main},
+		{specialShelfProfile, layout.Special},
+	} {
+		if s.grid == nil {
+			continue
+		}
+		if err := s.grid.addOverrides(ctx, s.profile, override); err != nil {
+			log.Warn().Err(err).Str("shelf", s.profile.Name).Msg("[Resell]attach.layout 货架坐标无效，沿用节点坐标")
+		}
+	}
+	for name, roi := range layout.ROIs {
+		if len(roi) != 4 || roi[2] <= 0 || roi[3] <= 0 {
+			log.Warn().Str("pipeline", name).Ints("roi", roi).Msg("[Resell]attach.layout 中的 roi 无效，已忽略")
+			continue
+		}
+		if raw, err := ctx.GetNodeJSON(name); err != nil || raw == "" {
+			log.Warn().Str("pipeline", name).Msg("[Resell]attach.layout 中的节点不存在，已忽略")
+			continue
+		}
+		setField(override, name, "roi", roi)
+	}
+	if len(override) == 0 {
+		return
+	}
+	if err := overridesnap.Apply(ctx, "Resell", override); err != nil {
+		log.Error().Err(err).Msg("[Resell]应用 attach.layout 失败")
+		return
+	}
+	log.Info().Int("nodes", len(override)).Msg("[Resell]已按 attach.layout 调整节点坐标")
+}
+
+// addOverrides - 计算货架每一格的价格 roi 与点击区域，校验失败时不写入任何内容
+func (g *shelfGrid) addOverrides(ctx *maa.Context, profile shelfProfile, override map[string]any) error {
+	prices, err := gridRects(ctx, profile, profile.PricePipelineFormat, "recognition", "roi", g.RowsY, g.StartX, g.ColWidth, g.PriceSize)
+	if err != nil {
+		return err
+	}
+	targets, err := gridRects(ctx, profile, profile.SelectTaskFormat, "action", "target", g.SelectRowsY, g.SelectStartX, g.SelectColWidth, g.SelectSize)
+	if err != nil {
+		return err
+	}
+	for name, roi := range prices {
+		setField(override, name, "roi", roi)
+	}
+	for name, target := range targets {
+		setField(override, name, "target", target)
+	}
+	return nil
+}
+
+// gridRects - 以节点现有的矩形为底，替换配置了的 y、x 与尺寸；什么都没配置时返回空
+func gridRects(ctx *maa.Context, profile shelfProfile, format, section, key string, rowsY []int, startX *int, colWidth int, size []int) (map[string][]int, error) {
+	if len(rowsY) == 0 && startX == nil && len(size) == 0 {
+		return nil, nil
+	}
+	if len(rowsY) > 0 && len(rowsY) != profile.Rows {
+		return nil, fmt.Errorf("want %d rows, got %d", profile.Rows, len(rowsY))
+	}
+	if startX != nil && colWidth <= 0 {
+		return nil, fmt.Errorf("column width must be positive when start x is set")
+	}
+	if len(size) > 0 && (len(size) != 2 || size[0] <= 0 || size[1] <= 0) {
+		return nil, fmt.Errorf("invalid size %v, want [width, height]", size)
+	}
+
+	rects := map[string][]int{}
+	for row := 1; row <= profile.Rows; row++ {
+		for col := 1; col <= profile.Cols; col++ {
+			name := fmt.Sprintf(format, row, col)
+			rect, ok := nodeRect(ctx, name, section, key)
+			if !ok {
+				// 特惠货架等可选节点未定义时跳过
+				continue
+			}
+			if len(rowsY) > 0 {
+				rect[1] = rowsY[row-1]
+			}
+			if startX != nil {
+				rect[0] = *startX + colWidth*(col-1)
+			}
+			if len(size) > 0 {
+				rect[2], rect[3] = size[0], size[1]
+			}
+			rects[name] = rect[:]
+		}
+	}
+	return rects, nil
+}
+
+func setField(override map[string]any, node, key string, value []int) {
+	fields, ok := override[node].(map[string]any)
+	if !ok {
+		fields = map[string]any{}
+		override[node] = fields
+	}
+	fields[key] = value
+}
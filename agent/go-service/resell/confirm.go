@@ -0,0 +1,120 @@
+package resell
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/nexttable"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/taskresult"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/waitnode"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// 高价确认方式
+const (
+	// confirmModeSkip - 超过阈值直接跳过购买并提醒
+	confirmModeSkip = "skip"
+	// confirmModeWait - 打开购买界面后等待用户手动点击购买
+	confirmModeWait = "wait"
+)
+
+const (
+	defaultConfirmTimeout = 60 * time.Second
+	confirmPollInterval   = 500 * time.Millisecond
+	// 购买成功画面，用户手动购买后出现
+	purchaseSuccessTask = "ResellReturnToStore"
+)
+
+// confirmGate - ConfirmAbovePrice 相关参数
+type confirmGate struct {
+	AbovePrice int
+	Mode       string
+	Timeout    time.Duration
+}
+
+// needed - 该成本价是否需要确认
+func (g confirmGate) needed(cost int) bool {
+	return g.AbovePrice > 0 && cost > g.AbovePrice
+}
+
+// pendingPurchase - 等待用户手动确认的购买
+type pendingPurchase struct {
+	cost           int
+	gate           confirmGate
+	target         string
+	records        []ProfitRecord
+	overflowAmount int
+	// decision - 用户确认后输出的购买决策，由 emitBuy 记下
+	decision taskresult.Decision
+}
+
+// pendingConfirm - 本次购买需要手动确认时由 ResellInitAction 设置，ResellConfirmAbovePriceAction 处理
+var pendingConfirm *pendingPurchase
+
+// gatePurchase - 价格超过阈值时按确认方式处理，返回 false 表示本次不购买
+func gatePurchase(ctx *maa.Context, gate confirmGate, cost int, target string, records []ProfitRecord, overflowAmount int) bool {
+	pendingConfirm = nil
+	if !gate.needed(cost) {
+		return true
+	}
+
+	if gate.Mode == confirmModeWait {
+		log.Info().Int("cost", cost).Int("threshold", gate.AbovePrice).Msg("[Resell]价格超过确认阈值，等待手动确认")
+		pendingConfirm = &pendingPurchase{
+			cost:           cost,
+			gate:           gate,
+			target:         target,
+			records:        records,
+			overflowAmount: overflowAmount,
+		}
+		return true
+	}
+
+	log.Info().Int("cost", cost).Int("threshold", gate.AbovePrice).Msg("[Resell]价格超过确认阈值，跳过购买")
+	ResellShowMessage(ctx, fmt.Sprintf("⚠️ %s 成本价 %d 超过确认阈值 %d，已跳过购买\n如价格无误，请手动购买或调高阈值", target, cost, gate.AbovePrice))
+	emitResult(ctx, taskresult.StatusSkipped, records, overflowAmount, taskresult.Decision{Action: "skip", Target: target, Reason: "above_confirm_price"})
+	return false
+}
+
+// emitBuy - 输出购买结果；等待手动确认时只记下决策，由 ResellConfirmAbovePriceAction 在确认或超时后输出一次
+func emitBuy(ctx *maa.Context, records []ProfitRecord, overflowAmount int, decision taskresult.Decision) {
+	if pendingConfirm != nil {
+		pendingConfirm.decision = decision
+		return
+	}
+	emitResult(ctx, taskresult.StatusSuccess, records, overflowAmount, decision)
+}
+
+// ResellConfirmAbovePriceAction - 购买界面打开后，高价商品等待用户手动点击购买，其余直接进入自动购买
+type ResellConfirmAbovePriceAction struct{}
+
+func (a *ResellConfirmAbovePriceAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	pending := pendingConfirm
+	pendingConfirm = nil
+	if pending == nil {
+		return true
+	}
+	next := nexttable.Load(ctx, arg.CurrentTaskName, confirmNextDefaults)
+
+	timeout := pending.gate.Timeout
+	if timeout <= 0 {
+		timeout = defaultConfirmTimeout
+	}
+	ResellShowMessage(ctx, fmt.Sprintf("⚠️ %s 成本价 %d 超过确认阈值 %d\n请核对价格后在 %d 秒内手动点击购买，超时将取消",
+		pending.target, pending.cost, pending.gate.AbovePrice, int(timeout.Seconds())))
+
+	wait := waitnode.Any(ctx, waitnode.Options{Timeout: timeout, Interval: confirmPollInterval}, waitnode.Condition{Node: purchaseSuccessTask})
+	if wait.Hit() {
+		log.Info().Msg("[Resell]用户已手动确认购买")
+		emitResult(ctx, taskresult.StatusSuccess, pending.records, pending.overflowAmount, pending.decision)
+		next.Apply(ctx, arg.CurrentTaskName, outcomeConfirmed, nil)
+		return true
+	}
+
+	log.Info().Msg("[Resell]等待手动确认超时，取消购买")
+	ResellShowMessage(ctx, "⌛ 未在规定时间内确认购买，已取消")
+	emitResult(ctx, taskresult.StatusSkipped, pending.records, pending.overflowAmount, taskresult.Decision{Action: "skip", Target: pending.target, Reason: "confirm_timeout"})
+	next.Apply(ctx, arg.CurrentTaskName, outcomeConfirmTimeout, nil)
+	return true
+}
@@ -0,0 +1,15 @@
+package resell
+
+import (
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+// readPrice - OCR 没有结果时重新截图再识别。价格节点声明了 attach.ocr_category 为 price，
+// 每次识别失败都会按 ocr.chains.price 依次改用高精度模型、数字模板匹配，低分辨率模拟器上的价格字体常让 OCR 认不出
+func readPrice(ctx *maa.Context, controller *maa.Controller, pipelineName string, override map[string]any) (num int, centerX int, centerY int, success bool) {
+	ocrRetry.Do(controller, pipelineName, func() bool {
+		num, centerX, centerY, success = ocrExtractNumberAt(ctx, controller, pipelineName, override)
+		return success
+	})
+	return num, centerX, centerY, success
+}
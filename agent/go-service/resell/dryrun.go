@@ -0,0 +1,32 @@
+package resell
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/taskresult"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// dryRun - 试运行：照常扫描并计算利润，但不点击购买、不跳转到购买节点，用于调整最低利润
+var dryRun bool
+
+// reportDryRun - 试运行时代替购买，列出将会购买的商品并输出结果
+func reportDryRun(ctx *maa.Context, gate confirmGate, records []ProfitRecord, overflowAmount int, plan []ProfitRecord, reason string) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🧪 试运行，未实际购买\n将会购买%d件商品：", len(plan))
+	for _, r := range plan {
+		fmt.Fprintf(&b, "\n%s", r.Position())
+		if name := r.displayName(); name != "" {
+			fmt.Fprintf(&b, " %s", name)
+		}
+		fmt.Fprintf(&b, "：成本 %d，售价 %d，利润 %d%s%s", r.CostPrice, r.SalePrice, r.Profit, r.rarityNote(), r.friendNote())
+		if gate.needed(r.CostPrice) {
+			b.WriteString("（超过确认阈值，实际运行时需要确认）")
+		}
+		log.Info().Str("位置", r.Position()).Int("成本", r.CostPrice).Int("利润", r.Profit).Str("原因", reason).Msg("[Resell]试运行：将会购买")
+	}
+	ResellShowMessage(ctx, b.String())
+	emitResult(ctx, taskresult.StatusSkipped, records, overflowAmount, taskresult.Decision{Action: "dry_run", Target: planTargets(plan), Reason: reason})
+}
@@ -0,0 +1,176 @@
+package resell
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/configexplain"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/safemode"
+	maa "github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// 价格识别的合理上限，利润不可能超过它
+const maxSanePrice = 7000
+
+// explainConfig - 用白话说明 ResellInitAction 的参数会带来什么行为
+func explainConfig(param string) configexplain.Explanation {
+	var e configexplain.Explanation
+	var params struct {
+		MinimumProfit     interface{} `json:"MinimumProfit"`
+		SearchItems       string      `json:"SearchItems"`
+		ScanSpecialOffers bool        `json:"ScanSpecialOffers"`
+		DecisionPolicy    string      `json:"DecisionPolicy"`
+		ExcludeFriends    string      `json:"ExcludeFriends"`
+		FriendSampleCount int         `json:"FriendSampleCount"`
+		PriceStrategy     string      `json:"PriceStrategy"`
+		ConfirmAbovePrice int         `json:"ConfirmAbovePrice"`
+		ConfirmMode       string      `json:"ConfirmMode"`
+		AutoBuyOnOverflow bool        `json:"AutoBuyOnOverflow"`
+		MaxPurchaseCount  int         `json:"MaxPurchaseCount"`
+		Blacklist         string      `json:"Blacklist"`
+		Whitelist         string      `json:"Whitelist"`
+		DryRun            bool        `json:"DryRun"`
+		MaxPages          int         `json:"MaxPages"`
+		QuotaDeferHours   int         `json:"QuotaDeferHours"`
+		MinimumMargin     int         `json:"MinimumMargin"`
+		DebugReport       bool        `json:"DebugReport"`
+	}
+	if err := json.Unmarshal([]byte(param), &params); err != nil {
+		e.Warnings = append(e.Warnings, fmt.Sprintf("参数无法解析，任务会直接失败：%v", err))
+		return e
+	}
+
+	if items := parseItemList(params.SearchItems); len(items) > 0 {
+		e.Will = append(e.Will, fmt.Sprintf("先搜索 %s，名称一致且利润达标就直接购买；都不满足时再逐格扫描", strings.Join(items, "、")))
+	} else {
+		e.Will = append(e.Will, "逐格扫描货架并比较好友价格")
+	}
+	if params.MaxPages > 1 {
+		e.Will = append(e.Will, fmt.Sprintf("常规货架滑动翻页，最多扫描 %d 页", params.MaxPages))
+	}
+	if params.ScanSpecialOffers {
+		e.Will = append(e.Will, "额外扫描特惠页签")
+	}
+	if friends := parseItemList(params.ExcludeFriends); len(friends) > 0 {
+		e.Wont = append(e.Wont, fmt.Sprintf("不参考 %s 的出售价", strings.Join(friends, "、")))
+	}
+	sample := "全部好友"
+	if params.FriendSampleCount > 0 {
+		sample = fmt.Sprintf("前 %d 位好友", params.FriendSampleCount)
+	}
+	switch strategy, err := parsePriceStrategy(params.PriceStrategy); {
+	case err != nil:
+		e.Warnings = append(e.Warnings, fmt.Sprintf("好友售价策略「%s」无效，任务会直接失败", params.PriceStrategy))
+	case strategy == priceStrategyFirst:
+		e.Will = append(e.Will, "按好友列表第一位的出售价计算利润")
+	case strategy == priceStrategyMedian:
+		e.Will = append(e.Will, fmt.Sprintf("按%s出售价的中位数计算利润", sample))
+	default:
+		e.Will = append(e.Will, fmt.Sprintf("按%s中的最高出售价计算利润", sample))
+	}
+
+	names := parseNameFilter(params.Blacklist, params.Whitelist)
+	if len(names.Whitelist) > 0 {
+		e.Will = append(e.Will, fmt.Sprintf("只购买名称包含 %s 的商品", strings.Join(names.Whitelist, "、")))
+	}
+	if len(names.Blacklist) > 0 {
+		e.Wont = append(e.Wont, fmt.Sprintf("不购买名称包含 %s 的商品，即使利润最高", strings.Join(names.Blacklist, "、")))
+	}
+
+	if policy, err := parseDecisionPolicy(params.DecisionPolicy); err != nil {
+		e.Warnings = append(e.Warnings, fmt.Sprintf("选品策略「%s」无法解析，任务会直接失败：%v", params.DecisionPolicy, err))
+	} else if policy != nil && policy.IsCondition() {
+		e.Will = append(e.Will, fmt.Sprintf("只考虑满足「%s」的商品，从中选出利润最高的商品", policy))
+	} else if p := strings.TrimSpace(params.DecisionPolicy); p != "" && p != "profit" {
+		e.Will = append(e.Will, fmt.Sprintf("按策略「%s」选出得分最高的商品", p))
+	} else {
+		e.Will = append(e.Will, "选出利润最高的商品")
+	}
+
+	var rule profitRule
+	switch v := params.MinimumProfit.(type) {
+	case float64:
+		rule = profitRule{fixed: int(v)}
+	case string:
+		r, err := parseProfitRule(v)
+		if err != nil {
+			e.Warnings = append(e.Warnings, fmt.Sprintf("最低利润「%s」无法解析，任务会直接失败：%v", v, err))
+			return e
+		}
+		rule = r
+	default:
+		e.Warnings = append(e.Warnings, "未设置最低利润，任务会直接失败")
+		return e
+	}
+
+	switch {
+	case rule.expr != nil:
+		e.Will = append(e.Will, fmt.Sprintf("利润达到「%s」（按每件商品计算）时购买", rule))
+	case rule.fixed < 0:
+		e.Will = append(e.Will, fmt.Sprintf("利润达到 %d 时购买", rule.fixed))
+		e.Warnings = append(e.Warnings, "最低利润为负数，可能会亏本购买")
+	case rule.fixed == 0:
+		e.Will = append(e.Will, "只要不亏本就购买")
+	case rule.fixed >= maxSanePrice:
+		e.Warnings = append(e.Warnings, fmt.Sprintf("最低利润 %d 超过了可能的最高利润，永远不会购买", rule.fixed))
+	default:
+		e.Will = append(e.Will, fmt.Sprintf("利润不低于 %d 时购买", rule.fixed))
+	}
+	if params.MinimumMargin > 0 {
+		e.Will = append(e.Will, fmt.Sprintf("同时要求利润率（利润 / 成本价）不低于 %d%%，成本低、利润小的商品不会因最低利润较低而买入", params.MinimumMargin))
+	}
+	if params.MaxPurchaseCount > 1 {
+		e.Will = append(e.Will, fmt.Sprintf("利润达标的商品最多按利润从高到低购买 %d 件", params.MaxPurchaseCount))
+	}
+	e.Wont = append(e.Wont, "利润不达标时不购买，只给出推荐")
+	if params.ConfirmAbovePrice > 0 {
+		if params.ConfirmMode == confirmModeWait {
+			e.Wont = append(e.Wont, fmt.Sprintf("成本价超过 %d 时不会自动点击购买，而是等待你手动确认", params.ConfirmAbovePrice))
+		} else {
+			e.Wont = append(e.Wont, fmt.Sprintf("成本价超过 %d 时不购买，只提醒", params.ConfirmAbovePrice))
+		}
+	}
+	if params.AutoBuyOnOverflow {
+		e.Will = append(e.Will, "配额即将溢出时按利润从高到低依次购买溢出数量的商品")
+	} else {
+		e.Wont = append(e.Wont, "配额即将溢出时不自动购买，只提醒应购买的数量")
+	}
+	if params.QuotaDeferHours > 0 {
+		e.Wont = append(e.Wont, fmt.Sprintf("距下次增加配额超过 %d 小时时，不因配额溢出而购买", params.QuotaDeferHours))
+	}
+
+	if params.DryRun {
+		e.Wont = append(e.Wont, "试运行：只报告将会购买的商品，不会实际购买")
+	} else if safemode.Active() {
+		e.Wont = append(e.Wont, "安全模式：按试运行处理，只报告将会购买的商品，不会实际购买")
+	}
+
+	if params.DebugReport {
+		e.Will = append(e.Will, fmt.Sprintf("保存商品缩略图与报告到 debug/resell，只保留最近 %d 次", keptDebugRuns))
+	}
+
+	if n := len(agentconfig.Get().PriceWatch); n > 0 {
+		e.Will = append(e.Will, fmt.Sprintf("扫描时检查 %d 条价格提醒规则", n))
+	}
+	return e
+}
+
+// ResellExplainConfigAction - 任务开始前说明当前配置会让倒卖做什么、不做什么
+type ResellExplainConfigAction struct{}
+
+func (a *ResellExplainConfigAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	param, err := configexplain.ActionParam(ctx, "ResellStart")
+	if err != nil {
+		log.Warn().Err(err).Msg("[Resell]读取任务参数失败，跳过配置说明")
+		return true
+	}
+	e := explainConfig(param)
+	for _, w := range e.Warnings {
+		log.Warn().Str("warning", w).Msg("[Resell]配置检查")
+	}
+	configexplain.Show(ctx, "📝 本次倒卖将会：", e)
+	return true
+}
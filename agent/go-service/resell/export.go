@@ -0,0 +1,36 @@
+package resell
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/xlsx"
+)
+
+// ExportSheet - 把倒卖历史写成一个工作表：每件扫描到的商品一行，末尾为合计公式，返回商品行数
+func ExportSheet(wb *xlsx.Workbook) (int, error) {
+	runs, err := loadHistory(time.Time{})
+	if err != nil {
+		return 0, err
+	}
+	sheet := wb.AddSheet("倒卖")
+	sheet.AddRow("时间", "位置", "物品", "成本", "售价", "利润", "已购买")
+	for _, run := range runs {
+		for _, r := range run.Records {
+			item := r.Item
+			if item == "" {
+				item = r.Name
+			}
+			sheet.AddRow(run.Time.Local().Format("2006-01-02 15:04:05"), r.position(), item, r.CostPrice, r.SalePrice, r.Profit, r.Purchased)
+		}
+	}
+	n := sheet.Rows() - 1
+	if n > 0 {
+		last := sheet.Rows()
+		sheet.AddRow()
+		sheet.AddRow("购买件数", nil, nil, nil, nil, nil, xlsx.Formula(fmt.Sprintf("COUNTIF(G2:G%d,TRUE)", last)))
+		sheet.AddRow("已购买的利润合计", nil, nil, nil, nil, xlsx.Formula(fmt.Sprintf("SUMIF(G2:G%d,TRUE,F2:F%d)", last, last)))
+		sheet.AddRow("平均利润", nil, nil, nil, nil, xlsx.Formula(fmt.Sprintf("AVERAGE(F2:F%d)", last)))
+	}
+	return n, nil
+}
@@ -0,0 +1,144 @@
+package resell
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/ocrutil"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// 好友价格列表整体识别区域，每行为“好友名 … 价格”
+const friendPriceListTask = "Resell_ROI_FriendPriceList"
+
+var digitsOnlyRe = regexp.MustCompile(`^\D*\d+\D*$`)
+
+// FriendOffer - 好友价格列表中的一行
+type FriendOffer struct {
+	Name  string
+	Price int
+}
+
+// excludedFriends - 不参与售价比较的好友，由任务参数 ExcludeFriends 设置
+var excludedFriends []string
+
+// readFriendOffers - 识别整个好友价格列表，返回每行的好友名与出售价
+// 每行最右侧的纯数字部分视为价格，其余部分拼接为好友名
+func readFriendOffers(ctx *maa.Context, controller *maa.Controller) []FriendOffer {
+	if raw, err := ctx.GetNodeJSON(friendPriceListTask); err != nil || raw == "" {
+		return nil
+	}
+	var lines []ocrutil.Line
+	ocrRetry.Do(controller, friendPriceListTask, func() bool {
+		img, err := controller.CacheImage()
+		if err != nil || img == nil {
+			return false
+		}
+		lines, err = ocrutil.Lines(ctx, img, ocrutil.ROIRequest{Pipeline: friendPriceListTask})
+		if err != nil {
+			log.Warn().Err(err).Msg("[Resell]好友价格列表识别失败")
+			return false
+		}
+		return len(lines) > 0
+	})
+
+	var offers []FriendOffer
+	for _, line := range lines {
+		priceIdx := -1
+		for i := len(line.Parts) - 1; i >= 0; i-- {
+			if digitsOnlyRe.MatchString(line.Parts[i].Text) {
+				priceIdx = i
+				break
+			}
+		}
+		if priceIdx < 0 {
+			continue
+		}
+		price, ok := parsePrice(friendPriceListTask, line.Parts[priceIdx].Text)
+		if !ok {
+			continue
+		}
+		names := make([]string, 0, priceIdx)
+		for _, part := range line.Parts[:priceIdx] {
+			names = append(names, strings.TrimSpace(part.Text))
+		}
+		offers = append(offers, FriendOffer{Name: strings.Join(names, " "), Price: price})
+	}
+	log.Info().Interface("offers", offers).Msg("[Resell]好友价格列表")
+	return offers
+}
+
+// isExcludedFriend - 好友名包含排除列表中的任一关键词
+func isExcludedFriend(name string) bool {
+	for _, keyword := range excludedFriends {
+		if name != "" && strings.Contains(name, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// 好友售价的取值策略，由任务参数 PriceStrategy 设置
+const (
+	// priceStrategyFirst - 列表第一位，列表按售价从高到低排列，通常与 max 相同
+	priceStrategyFirst = "first"
+	// priceStrategyMax - 采样行中的最高价
+	priceStrategyMax = "max"
+	// priceStrategyMedian - 采样行的中位数，避免个别好友的高价拉高利润
+	priceStrategyMedian = "median"
+)
+
+var (
+	priceStrategy = priceStrategyMax
+	// friendSampleCount - 参与取值的好友行数（排除好友之后），0 表示列表中识别到的全部行
+	friendSampleCount int
+)
+
+// parsePriceStrategy - 为空时使用 max
+func parsePriceStrategy(s string) (string, error) {
+	switch strategy := strings.ToLower(strings.TrimSpace(s)); strategy {
+	case "":
+		return priceStrategyMax, nil
+	case priceStrategyFirst, priceStrategyMax, priceStrategyMedian:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown PriceStrategy %q, expected first, max or median", s)
+	}
+}
+
+// pickOffer - 排除指定好友后取前 friendSampleCount 行，按 priceStrategy 选出用于计算利润的一行
+// 中位数在偶数行时取较低的一行，保证结果是某位好友的实际出价
+func pickOffer(offers []FriendOffer) (FriendOffer, bool) {
+	var sampled []FriendOffer
+	for _, offer := range offers {
+		if isExcludedFriend(offer.Name) {
+			log.Info().Str("friend", offer.Name).Int("price", offer.Price).Msg("[Resell]已排除该好友的价格")
+			continue
+		}
+		sampled = append(sampled, offer)
+		if friendSampleCount > 0 && len(sampled) >= friendSampleCount {
+			break
+		}
+	}
+	if len(sampled) == 0 {
+		return FriendOffer{}, false
+	}
+
+	byPrice := append([]FriendOffer(nil), sampled...)
+	sort.SliceStable(byPrice, func(i, j int) bool { return byPrice[i].Price > byPrice[j].Price })
+	highest := byPrice[0]
+	median := byPrice[len(byPrice)/2]
+	log.Info().Int("采样", len(sampled)).Int("最高", highest.Price).Int("中位数", median.Price).Str("策略", priceStrategy).Msg("[Resell]好友售价采样")
+
+	switch priceStrategy {
+	case priceStrategyFirst:
+		return sampled[0], true
+	case priceStrategyMedian:
+		return median, true
+	default:
+		return highest, true
+	}
+}
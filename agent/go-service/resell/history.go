@@ -0,0 +1,241 @@
+package resell
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/taskresult"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// historyRecord - 历史中的一件商品；Purchased 表示本次运行决定购买它，购买失败时也为 true
+type historyRecord struct {
+	Source    string `json:"source,omitempty"`
+	Row       int    `json:"row"`
+	Col       int    `json:"col"`
+	Page      int    `json:"page,omitempty"`
+	Item      string `json:"item,omitempty"`
+	Name      string `json:"name,omitempty"`
+	CostPrice int    `json:"cost"`
+	SalePrice int    `json:"sale"`
+	Profit    int    `json:"profit"`
+	Purchased bool   `json:"purchased"`
+}
+
+// position - 与 ProfitRecord.Position 相同的位置描述
+func (r historyRecord) position() string {
+	return ProfitRecord{Source: r.Source, Row: r.Row, Col: r.Col, Page: r.Page}.Position()
+}
+
+// historyRun - 一次运行扫描到的全部商品，每次运行一行写入 history.jsonl
+type historyRun struct {
+	Time    time.Time       `json:"time"`
+	Records []historyRecord `json:"records"`
+}
+
+// historyRecorded - 本次运行是否已写入历史，高价确认超时会再次输出结果，但不重复记录
+var historyRecorded bool
+
+func historyPath() string {
+	return datadir.Path("resell", "history.jsonl")
+}
+
+// recordHistory - 追加本次运行的扫描结果，决定购买的商品按 decision.Target 中的位置标记
+func recordHistory(records []ProfitRecord, decision taskresult.Decision) {
+	if len(records) == 0 || historyRecorded {
+		return
+	}
+	historyRecorded = true
+	bought := map[string]bool{}
+	if decision.Action == "buy" {
+		for _, position := range strings.Split(decision.Target, "、") {
+			bought[position] = true
+		}
+	}
+	run := historyRun{Time: time.Now()}
+	for _, r := range records {
+		run.Records = append(run.Records, historyRecord{
+			Source:    r.Source,
+			Row:       r.Row,
+			Col:       r.Col,
+			Page:      r.Page,
+			Item:      r.Item,
+			Name:      r.Name,
+			CostPrice: r.CostPrice,
+			SalePrice: r.SalePrice,
+			Profit:    r.Profit,
+			Purchased: bought[r.Position()],
+		})
+	}
+
+	path := historyPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Warn().Err(err).Msg("[Resell]创建历史记录目录失败")
+		return
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Warn().Err(err).Msg("[Resell]写入历史记录失败")
+		return
+	}
+	defer f.Close()
+	f.Write(append(data, '\n'))
+}
+
+// loadHistory - 读取 since 之后的运行记录，无法解析的行跳过
+func loadHistory(since time.Time) ([]historyRun, error) {
+	f, err := os.Open(historyPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var runs []historyRun
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var run historyRun
+		if err := json.Unmarshal(scanner.Bytes(), &run); err != nil {
+			continue
+		}
+		if !run.Time.Before(since) {
+			runs = append(runs, run)
+		}
+	}
+	return runs, scanner.Err()
+}
+
+// historySummary - 一段时间内的倒卖统计，只统计决定购买的商品
+type historySummary struct {
+	Days      int
+	Runs      int
+	Purchases int
+	Profit    int
+	Cost      int
+	// Slots - 按购买次数排序的商品位置
+	Slots []slotCount
+}
+
+type slotCount struct {
+	Position string
+	Count    int
+	Profit   int
+}
+
+// Margin returns the total profit over the total cost of the purchases, in percent
+func (s historySummary) Margin() float64 {
+	if s.Cost <= 0 {
+		return 0
+	}
+	return float64(s.Profit) * 100 / float64(s.Cost)
+}
+
+func summarize(runs []historyRun, days int, now time.Time) historySummary {
+	s := historySummary{Days: days}
+	since := now.AddDate(0, 0, -days)
+	slots := map[string]*slotCount{}
+	for _, run := range runs {
+		if run.Time.Before(since) {
+			continue
+		}
+		s.Runs++
+		for _, r := range run.Records {
+			if !r.Purchased {
+				continue
+			}
+			s.Purchases++
+			s.Profit += r.Profit
+			s.Cost += r.CostPrice
+			position := r.position()
+			slot, ok := slots[position]
+			if !ok {
+				slot = &slotCount{Position: position}
+				slots[position] = slot
+			}
+			slot.Count++
+			slot.Profit += r.Profit
+		}
+	}
+	for _, slot := range slots {
+		s.Slots = append(s.Slots, *slot)
+	}
+	sort.Slice(s.Slots, func(i, j int) bool {
+		if s.Slots[i].Count != s.Slots[j].Count {
+			return s.Slots[i].Count > s.Slots[j].Count
+		}
+		return s.Slots[i].Profit > s.Slots[j].Profit
+	})
+	return s
+}
+
+func (s historySummary) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📅 最近%d天：运行%d次，购买%d件", s.Days, s.Runs, s.Purchases)
+	if s.Purchases == 0 {
+		return b.String()
+	}
+	fmt.Fprintf(&b, "\n累计利润 %d，平均利润率 %.1f%%", s.Profit, s.Margin())
+	for i, slot := range s.Slots {
+		if i >= 3 {
+			break
+		}
+		fmt.Fprintf(&b, "\n%d. %s：购买%d次，利润%d", i+1, slot.Position, slot.Count, slot.Profit)
+	}
+	return b.String()
+}
+
+// ResellReportAction - 汇总历史记录中最近几天的倒卖利润
+// custom_action_param: {"days": [7, 30]}
+type ResellReportAction struct{}
+
+func (a *ResellReportAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	params := struct {
+		Days []int `json:"days"`
+	}{Days: []int{7, 30}}
+	if arg.CustomActionParam != "" {
+		if err := json.Unmarshal([]byte(arg.CustomActionParam), &params); err != nil {
+			log.Error().Err(err).Msg("[Resell]反序列化失败")
+			return false
+		}
+	}
+
+	longest := 0
+	for _, d := range params.Days {
+		longest = max(longest, d)
+	}
+	now := time.Now()
+	runs, err := loadHistory(now.AddDate(0, 0, -longest))
+	if err != nil {
+		log.Error().Err(err).Msg("[Resell]读取历史记录失败")
+		ResellShowMessage(ctx, "⚠️ 读取倒卖历史记录失败")
+		return false
+	}
+	if len(runs) == 0 {
+		ResellShowMessage(ctx, "📊 还没有倒卖历史记录")
+		return true
+	}
+	for _, d := range params.Days {
+		if d <= 0 {
+			continue
+		}
+		summary := summarize(runs, d, now)
+		log.Info().Int("天数", d).Int("运行", summary.Runs).Int("购买", summary.Purchases).Int("利润", summary.Profit).Msg("[Resell]历史统计")
+		ResellShowMessage(ctx, summary.String())
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+package resell
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/overridesnap"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// 实测位置与节点 roi 的偏差不超过该值时沿用节点 roi（720p 基准）
+const columnTolerance = 8
+
+// columnLayout - 从预扫描命中的价格框实测的列间距，格子宽度变化时用于推算其余列的位置
+type columnLayout struct {
+	// Step - 相邻两列价格中心的水平距离，0 表示命中太少无法测量
+	Step int
+	// anchors - 每行最左侧命中的格子，按行号索引
+	anchors map[int]priceAnchor
+}
+
+type priceAnchor struct {
+	col  int
+	x, y int
+}
+
+// measureColumns - 同一行内相邻命中格子的中心距离除以列差，取中位数作为列间距
+func measureColumns(hits map[[2]int]priceHit) columnLayout {
+	layout := columnLayout{anchors: map[int]priceAnchor{}}
+	rows := map[int][]int{}
+	for cell := range hits {
+		rows[cell[0]] = append(rows[cell[0]], cell[1])
+	}
+
+	var steps []int
+	for row, cols := range rows {
+		sort.Ints(cols)
+		first := hits[[2]int{row, cols[0]}]
+		layout.anchors[row] = priceAnchor{col: cols[0], x: first.x, y: first.y}
+		for i := 1; i < len(cols); i++ {
+			prev := hits[[2]int{row, cols[i-1]}]
+			cur := hits[[2]int{row, cols[i]}]
+			if step := (cur.x - prev.x) / (cols[i] - cols[i-1]); step > 0 {
+				steps = append(steps, step)
+			}
+		}
+	}
+	if len(steps) > 0 {
+		sort.Ints(steps)
+		layout.Step = steps[len(steps)/2]
+	}
+	return layout
+}
+
+// center - 推算某一格价格的中心点，该行没有命中或无法测量时返回 false
+func (l columnLayout) center(row, col int) (int, int, bool) {
+	a, ok := l.anchors[row]
+	if !ok || l.Step <= 0 {
+		return 0, 0, false
+	}
+	return a.x + (col-a.col)*l.Step, a.y, true
+}
+
+// priceOverride - 以推算的中心点平移价格节点的 roi，偏差很小或无法推算时返回 nil
+func (l columnLayout) priceOverride(ctx *maa.Context, pipeline string, row, col int) map[string]any {
+	cx, _, ok := l.center(row, col)
+	if !ok {
+		return nil
+	}
+	roi, ok := nodeRect(ctx, pipeline, "recognition", "roi")
+	if !ok {
+		return nil
+	}
+	x := cx - roi[2]/2
+	if abs(x-roi[0]) <= scale.Xi(columnTolerance) {
+		return nil
+	}
+	return map[string]any{pipeline: map[string]any{"roi": []int{x, roi[1], roi[2], roi[3]}}}
+}
+
+// retargetSelect - 把选择商品节点的点击区域移到扫描时实测的价格位置
+func retargetSelect(ctx *maa.Context, record ProfitRecord) {
+	// 节点已在 ResellInitAction 开始时恢复为原始内容（并按分辨率缩放）
+	node := selectTaskName(record)
+	if record.ClickX <= 0 {
+		return
+	}
+	target, ok := nodeRect(ctx, node, "action", "target")
+	if !ok {
+		return
+	}
+	x, y := record.ClickX-target[2]/2, record.ClickY-target[3]/2
+	if abs(x-target[0]) <= scale.Xi(columnTolerance) && abs(y-target[1]) <= scale.Yi(columnTolerance) {
+		return
+	}
+	override := map[string]any{node: map[string]any{"target": []int{x, y, target[2], target[3]}}}
+	if err := overridesnap.Apply(ctx, "Resell", override); err != nil {
+		log.Warn().Err(err).Str("node", node).Msg("[Resell]修改选择商品点击位置失败")
+		return
+	}
+	log.Info().Str("node", node).Int("x", record.ClickX).Int("y", record.ClickY).Msg("[Resell]按实测列位置点击商品")
+}
+
+// nodeRect - 读取规范化节点中 recognition.param.roi 或 action.param.target 的矩形
+func nodeRect(ctx *maa.Context, node, section, key string) ([4]int, bool) {
+	rect, ok := nodeArray(ctx, node, section, key)
+	if !ok || rect[2] <= 0 || rect[3] <= 0 {
+		return [4]int{}, false
+	}
+	return rect, true
+}
+
+// nodeArray - 读取规范化节点参数中的四元数组，引用其他节点等写法返回 false
+func nodeArray(ctx *maa.Context, node, section, key string) ([4]int, bool) {
+	raw, err := ctx.GetNodeJSON(node)
+	if err != nil || raw == "" {
+		return [4]int{}, false
+	}
+	var parsed map[string]struct {
+		Param map[string]json.RawMessage `json:"param"`
+	}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return [4]int{}, false
+	}
+	var arr [4]int
+	if err := json.Unmarshal(parsed[section].Param[key], &arr); err != nil {
+		return [4]int{}, false
+	}
+	return arr, true
+}
+
+func (l columnLayout) String() string {
+	if l.Step <= 0 {
+		return "未知"
+	}
+	return fmt.Sprintf("%dpx", l.Step)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
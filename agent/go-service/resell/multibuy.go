@@ -0,0 +1,95 @@
+package resell
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/nexttable"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/purchase"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// 连续购买多件商品时的节点，购买成功返回商店后由它跳到下一件商品
+const buyNextTask = "ResellBuyNext"
+
+const (
+	outcomeBuyNext = "next"
+	outcomeBuyDone = "done"
+)
+
+// buyNextDefaults - ResellBuyNext 的默认后续节点，done 回到原来的返回商店之后的流程
+var buyNextDefaults = nexttable.Table{
+	outcomeBuyNext: {"{select}"},
+	outcomeBuyDone: {"ResellScrollToTop"},
+}
+
+// buyQueue - 连续购买时尚未购买的商品，由 ResellBuyNextAction 依次取出
+var buyQueue []ProfitRecord
+
+// planBuys - 按加权利润从高到低取前 n 件 accept 接受的商品，accept 为 nil 时不筛选
+// 需要手动确认的高价商品无法连续购买，不在此列
+func planBuys(records []ProfitRecord, weights rarityWeights, gate confirmGate, n int, accept func(ProfitRecord) bool) []ProfitRecord {
+	sorted := append([]ProfitRecord(nil), records...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return weights.weighted(sorted[i]) > weights.weighted(sorted[j])
+	})
+
+	var plan []ProfitRecord
+	for _, record := range sorted {
+		if len(plan) >= n {
+			break
+		}
+		if accept != nil && !accept(record) {
+			continue
+		}
+		if gate.needed(record.CostPrice) {
+			log.Info().Str("位置", record.Position()).Int("成本", record.CostPrice).Msg("[Resell]成本价超过确认阈值，不参与连续购买")
+			continue
+		}
+		plan = append(plan, record)
+	}
+	return plan
+}
+
+// startBuyQueue - 购买第一件商品，其余商品排队，返回商店后由 ResellBuyNext 继续
+func startBuyQueue(ctx *maa.Context, node string, next nexttable.Table, plan []ProfitRecord) {
+	first := plan[0]
+	buyQueue = append([]ProfitRecord(nil), plan[1:]...)
+	ctx.OverrideNext(purchaseSuccessTask, []maa.NodeNextItem{{Name: buyNextTask}})
+
+	purchase.Expect(purchase.Receipt{Item: first.Item, Price: first.CostPrice})
+	selectRecord(ctx, first)
+	next.Apply(ctx, node, outcomeBuy, nextVars(first))
+}
+
+// planTargets - 连续购买的商品位置，用于提示与结果
+func planTargets(plan []ProfitRecord) string {
+	positions := make([]string, 0, len(plan))
+	for _, record := range plan {
+		positions = append(positions, record.Position())
+	}
+	return strings.Join(positions, "、")
+}
+
+// ResellBuyNextAction - 连续购买时，购买完成返回商店后购买队列中的下一件商品
+type ResellBuyNextAction struct{}
+
+func (a *ResellBuyNextAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	next := nexttable.Load(ctx, arg.CurrentTaskName, buyNextDefaults)
+	if len(buyQueue) == 0 {
+		log.Info().Msg("[Resell]连续购买完成")
+		next.Apply(ctx, arg.CurrentTaskName, outcomeBuyDone, nil)
+		return true
+	}
+
+	record := buyQueue[0]
+	buyQueue = buyQueue[1:]
+	log.Info().Str("位置", record.Position()).Int("利润", record.Profit).Int("剩余", len(buyQueue)).Msg("[Resell]连续购买下一件商品")
+	purchase.Expect(purchase.Receipt{Item: record.Item, Price: record.CostPrice})
+	// 返回商店后显示哪个页签不确定，总是重新切换一次
+	currentShelf = ""
+	selectRecord(ctx, record)
+	next.Apply(ctx, arg.CurrentTaskName, outcomeBuyNext, nextVars(record))
+	return true
+}
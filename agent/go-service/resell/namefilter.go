@@ -0,0 +1,93 @@
+package resell
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/ocrutil"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// 商品详情页的物品名区域，资源未定义时不识别名称
+const itemNamePipelineName = "Resell_ROI_DetailItemName"
+
+// nameFilter - 按物品名筛选可购买的商品，关键字写法与 creditshopping 的黑名单相同：分号分隔，名称包含即可
+// 白名单非空时只购买命中白名单的商品；黑名单优先于白名单
+type nameFilter struct {
+	Blacklist []string
+	Whitelist []string
+}
+
+func parseNameFilter(blacklist, whitelist string) nameFilter {
+	return nameFilter{Blacklist: parseItemList(blacklist), Whitelist: parseItemList(whitelist)}
+}
+
+func (f nameFilter) empty() bool {
+	return len(f.Blacklist) == 0 && len(f.Whitelist) == 0
+}
+
+// allows - 商品是否可以购买，不可购买时给出原因
+// 名称未能识别的商品：只配置黑名单时照常购买，配置了白名单时不购买
+func (f nameFilter) allows(record ProfitRecord) (bool, string) {
+	names := record.names()
+	for _, keyword := range f.Blacklist {
+		for _, name := range names {
+			if strings.Contains(name, keyword) {
+				return false, fmt.Sprintf("命中黑名单 %s", keyword)
+			}
+		}
+	}
+	if len(f.Whitelist) == 0 {
+		return true, ""
+	}
+	for _, keyword := range f.Whitelist {
+		for _, name := range names {
+			if strings.Contains(name, keyword) {
+				return true, ""
+			}
+		}
+	}
+	if len(names) == 0 {
+		return false, "未能识别物品名，不在白名单中"
+	}
+	return false, "不在白名单中"
+}
+
+// apply - 去掉不可购买的商品，扫描结果本身仍完整保留在报告与历史中
+func (f nameFilter) apply(records []ProfitRecord) []ProfitRecord {
+	if f.empty() {
+		return records
+	}
+	kept := make([]ProfitRecord, 0, len(records))
+	for _, record := range records {
+		if ok, reason := f.allows(record); !ok {
+			log.Info().Str("位置", record.Position()).Str("物品", record.displayName()).Str("原因", reason).Msg("[Resell]按物品名排除商品")
+			continue
+		}
+		kept = append(kept, record)
+	}
+	return kept
+}
+
+// readItemName - 识别商品详情页上的物品名，未能识别时返回空
+func readItemName(ctx *maa.Context, controller *maa.Controller) string {
+	if raw, err := ctx.GetNodeJSON(itemNamePipelineName); err != nil || raw == "" {
+		return ""
+	}
+	var name string
+	if !ocrRetry.Do(controller, itemNamePipelineName, func() bool {
+		img, err := controller.CacheImage()
+		if err != nil || img == nil {
+			return false
+		}
+		result := ocrutil.BatchExtract(ctx, img, []ocrutil.ROIRequest{{Pipeline: itemNamePipelineName}})[0]
+		name = strings.TrimSpace(result.Text)
+		return result.Hit && name != ""
+	}) {
+		log.Info().Msg("[Resell]未能识别物品名")
+		return ""
+	}
+	log.Info().Str("name", name).Msg("[Resell]识别到物品名")
+	return name
+}
@@ -0,0 +1,38 @@
+package resell
+
+import (
+	"strconv"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/nexttable"
+)
+
+// 决策结果，作为 next_table 的键
+const (
+	outcomeBuy            = "buy"
+	outcomeSearchBuy      = "search_buy"
+	outcomeConfirmed      = "confirmed"
+	outcomeConfirmTimeout = "confirm_timeout"
+)
+
+// startNextDefaults - ResellStart 的默认后续节点，{select} 为货架对应的选择商品节点
+// 也可以用 {source}、{row}、{col} 自行拼接节点名
+var startNextDefaults = nexttable.Table{
+	outcomeBuy:       {"{select}"},
+	outcomeSearchBuy: {"ResellSelectProductConfirm"},
+}
+
+// confirmNextDefaults - ResellConfirmAbovePrice 的默认后续节点
+var confirmNextDefaults = nexttable.Table{
+	outcomeConfirmed:      {purchaseSuccessTask},
+	outcomeConfirmTimeout: {},
+}
+
+// nextVars - 商品位置相关的占位符取值
+func nextVars(record ProfitRecord) map[string]string {
+	return map[string]string{
+		"select": selectTaskName(record),
+		"source": record.Source,
+		"row":    strconv.Itoa(record.Row),
+		"col":    strconv.Itoa(record.Col),
+	}
+}
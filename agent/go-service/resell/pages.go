@@ -0,0 +1,122 @@
+package resell
+
+import (
+	"hash/fnv"
+	"image"
+	"time"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// 货架翻页：一页只显示 8 列，更多商品需要向左滑动
+// 以下坐标为 720p 基准，使用时按 scale 缩放
+const (
+	pageSwipeY        = 470
+	pageSwipeFromX    = 1100
+	pageSwipeToX      = 180
+	pageSwipeDuration = 600 * time.Millisecond
+	// 采样网格，用于判断滑动后货架是否变化
+	pageHashGrid = 24
+)
+
+// shelfArea - 货架商品区域，只比较这一块，避免配额等动态数字影响判断
+var shelfArea = image.Rect(60, 330, 1270, 610)
+
+// pageCount - 本次运行扫描到的常规货架页数，购买前据此滑到商品所在页
+var pageCount = 1
+
+// shelfHash - 货架区域的采样哈希，颜色只取高 4 位以忽略细微的动画与压缩噪声
+func shelfHash(img image.Image) uint64 {
+	area := image.Rect(scale.Xi(shelfArea.Min.X), scale.Yi(shelfArea.Min.Y), scale.Xi(shelfArea.Max.X), scale.Yi(shelfArea.Max.Y)).Intersect(img.Bounds())
+	h := fnv.New64a()
+	buf := make([]byte, 3)
+	for gy := 0; gy < pageHashGrid; gy++ {
+		y := area.Min.Y + gy*area.Dy()/pageHashGrid
+		for gx := 0; gx < pageHashGrid; gx++ {
+			x := area.Min.X + gx*area.Dx()/pageHashGrid
+			r, g, b, _ := img.At(x, y).RGBA()
+			buf[0], buf[1], buf[2] = byte(r>>12), byte(g>>12), byte(b>>12)
+			h.Write(buf)
+		}
+	}
+	return h.Sum64()
+}
+
+// swipePage - forward 为向左滑动到下一页，否则向右滑回上一页
+func swipePage(controller *maa.Controller, forward bool) {
+	fromX, toX := pageSwipeFromX, pageSwipeToX
+	if !forward {
+		fromX, toX = toX, fromX
+	}
+	x1, y := scale.Point(fromX, pageSwipeY)
+	x2, _ := scale.Point(toX, pageSwipeY)
+	controller.PostSwipe(int32(x1), int32(y), int32(x2), int32(y), pageSwipeDuration).Wait()
+}
+
+// nextPage - 滑动到下一页，货架画面没有变化说明已经是最后一页
+func nextPage(ctx *maa.Context, controller *maa.Controller) bool {
+	controller.PostScreencap().Wait()
+	before, err := controller.CacheImage()
+	if err != nil || before == nil {
+		return false
+	}
+	swipePage(controller, true)
+	Resell_delay_freezes_time(ctx, 300)
+	controller.PostScreencap().Wait()
+	after, err := controller.CacheImage()
+	if err != nil || after == nil {
+		return false
+	}
+	if shelfHash(before) == shelfHash(after) {
+		log.Info().Msg("[Resell]滑动后货架没有变化，已是最后一页")
+		return false
+	}
+	return true
+}
+
+// goToPage - 先滑回第一页，再向后翻到 page 页；多滑的次数在边界处不会有影响
+func goToPage(ctx *maa.Context, controller *maa.Controller, page int) {
+	if pageCount <= 1 {
+		return
+	}
+	for i := 0; i < pageCount; i++ {
+		swipePage(controller, false)
+	}
+	for i := 1; i < page; i++ {
+		swipePage(controller, true)
+	}
+	Resell_delay_freezes_time(ctx, 300)
+	log.Info().Int("页", page).Msg("[Resell]已滑到商品所在页")
+}
+
+// scanPages - 扫描常规货架的第 2 页到第 maxPages 页，货架不再变化时停止
+func scanPages(ctx *maa.Context, controller *maa.Controller, maxPages int) []ProfitRecord {
+	var records []ProfitRecord
+	for page := 2; page <= maxPages; page++ {
+		if !nextPage(ctx, controller) {
+			break
+		}
+		pageCount = page
+		log.Info().Int("页", page).Msg("[Resell]扫描下一页货架")
+		records = append(records, scanShelf(ctx, controller, mainShelfProfile, page)...)
+	}
+	return records
+}
+
+// selectRecord - 购买前切到商品所在的页签并滑到所在页，再按实测位置修正点击位置
+func selectRecord(ctx *maa.Context, record ProfitRecord) {
+	source := record.Source
+	if source == "" {
+		source = mainShelfProfile.Name
+	}
+	if !switchShelf(ctx, source) {
+		log.Warn().Str("商品", record.Position()).Msg("[Resell]未能切到商品所在页签，仍按原位置选择")
+	}
+	if source == mainShelfProfile.Name && (record.Page > 1 || pageCount > 1) {
+		if controller := ctx.GetTasker().GetController(); controller != nil {
+			goToPage(ctx, controller, max(record.Page, 1))
+		}
+	}
+	retargetSelect(ctx, record)
+}
@@ -0,0 +1,78 @@
+package resell
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// quotaPlanNode - 配额规划结果写入该节点的 attach.quota，供后续节点或外部流程读取
+const quotaPlanNode = "ResellQuotaPlan"
+
+// quotaPlan - 按当前配额 x/y 与下次增加的配额、时间，拆分本次应买与可推迟的件数
+type quotaPlan struct {
+	Current int `json:"current"`
+	Max     int `json:"max"`
+	// Refill - 下次增加的配额
+	Refill int `json:"refill"`
+	// HoursLater - 距下次增加的小时数，0 表示不足一小时
+	HoursLater int `json:"hours_later"`
+	// BuyNow - 增加前必须买掉的件数，否则超出上限的配额作废
+	BuyNow int `json:"buy_now"`
+	// Deferred - 会超出上限，但增加还早，可以留到之后的运行再买的件数
+	Deferred int `json:"deferred"`
+	// Spare - 买了会占用增加后仍能保留的配额，只在利润达标时购买
+	Spare int `json:"spare"`
+}
+
+// planQuota - deferHours 大于 0 且距下次增加超过它时，溢出的件数推迟购买，否则现在就买
+func planQuota(x, y, hoursLater, b, deferHours int) quotaPlan {
+	p := quotaPlan{Current: x, Max: y, Refill: b, HoursLater: max(hoursLater, 0)}
+	overflow := min(max(x+b-y, 0), x)
+	if deferHours > 0 && p.HoursLater > deferHours {
+		p.Deferred = overflow
+	} else {
+		p.BuyNow = overflow
+	}
+	p.Spare = x - overflow
+	return p
+}
+
+// refillText - 下次增加配额的时间描述
+func (p quotaPlan) refillText() string {
+	if p.HoursLater == 0 {
+		return "1小时内"
+	}
+	return fmt.Sprintf("%d小时后", p.HoursLater)
+}
+
+func (p quotaPlan) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📦 配额 %d/%d，%s增加 %d", p.Current, p.Max, p.refillText(), p.Refill)
+	switch {
+	case p.BuyNow > 0:
+		fmt.Fprintf(&b, "\n现在需要买掉 %d 件，否则超出上限", p.BuyNow)
+	case p.Deferred > 0:
+		fmt.Fprintf(&b, "\n将超出上限 %d 件，距增加还早，推迟到之后的运行再买", p.Deferred)
+	default:
+		b.WriteString("\n增加后不会超出上限")
+	}
+	if p.Spare > 0 {
+		fmt.Fprintf(&b, "\n其余 %d 件只在利润达标时购买", p.Spare)
+	}
+	return b.String()
+}
+
+// publishQuotaPlan - 把规划结果写入 ResellQuotaPlan 节点的 attach，下次运行开始时随 Resell 节点一起恢复
+func publishQuotaPlan(ctx *maa.Context, p quotaPlan) {
+	err := ctx.OverridePipeline(map[string]any{
+		quotaPlanNode: map[string]any{
+			"attach": map[string]any{"quota": p},
+		},
+	})
+	if err != nil {
+		log.Warn().Err(err).Msg("[Resell]写入配额规划失败")
+	}
+}
@@ -0,0 +1,112 @@
+package resell
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/ocrutil"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/overridesnap"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/schedule"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// quotaStatusNode - 配额状态 JSON 输出到该节点的 focus，GUI 据此显示剩余配额
+	quotaStatusNode = "Resell_QuotaStatusJSON"
+	// quotaCheckNode - 进店后识别配额的节点，轻量任务把进店流程的终点改到这里
+	quotaCheckNode = "ResellQuotaCheck"
+	// layoutNode - attach.layout 所在的节点，配额 roi 也可在其中调整
+	layoutNode = "ResellStart"
+)
+
+// quotaStatus - GUI 配额组件使用的数据
+type quotaStatus struct {
+	Type    string `json:"type"`
+	Current int    `json:"current"`
+	Max     int    `json:"max"`
+	Refill  int    `json:"refill"`
+	// HoursLater - 距下次增加的小时数，0 表示不足一小时，-1 表示未识别
+	HoursLater int       `json:"hours_later"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// emitQuotaStatus - 输出配额状态，完整运行与轻量检查都会调用
+func emitQuotaStatus(ctx *maa.Context, x, y, hoursLater, b int) {
+	data, err := json.Marshal(quotaStatus{
+		Type:       "resell_quota",
+		Current:    x,
+		Max:        y,
+		Refill:     max(b, 0),
+		HoursLater: hoursLater,
+		CheckedAt:  time.Now(),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("[Resell]序列化配额状态失败")
+		return
+	}
+	ctx.RunTask(quotaStatusNode, map[string]interface{}{
+		quotaStatusNode: map[string]interface{}{
+			"recognition": "DirectHit",
+			"action":      "DoNothing",
+			"focus": map[string]interface{}{
+				"Node.Action.Succeeded": string(data),
+			},
+		},
+	})
+}
+
+// ResellQuotaNavigateAction - 沿用一键倒卖的进店流程，到弹性需求物资商店后只识别配额，不扫描商品
+type ResellQuotaNavigateAction struct{}
+
+func (a *ResellQuotaNavigateAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	detail, err := ctx.RunTask("ResellMain", map[string]any{
+		"ResellinUnstableStore": map[string]any{
+			"action": "DoNothing",
+			"next":   []string{quotaCheckNode},
+		},
+	})
+	if err != nil || detail == nil || !detail.Status.Success() {
+		log.Error().Err(err).Msg("[Resell]进入商店识别配额失败")
+		return false
+	}
+	return true
+}
+
+// ResellQuotaCheckAction - 只识别配额区域，输出当前/上限与下次增加
+type ResellQuotaCheckAction struct{}
+
+func (a *ResellQuotaCheckAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	controller := ctx.GetTasker().GetController()
+	if controller == nil {
+		log.Error().Msg("[Resell]无法获取控制器")
+		return false
+	}
+	// 与 ResellInitAction 相同：恢复上次的覆盖，再按 attach.layout 与分辨率调整配额 roi
+	overridesnap.Reset(ctx, "Resell", resellNodes(ctx, arg.CurrentTaskName)...)
+	applyAttachLayout(ctx, layoutNode)
+	if s := measureScale(controller); !s.IsIdentity() {
+		applyScale(ctx, []string{"Resell_ROI_Quota_Current", "Resell_ROI_Quota_NextAdd"}, s)
+	}
+
+	var x, y, hoursLater, b int
+	if !ocrutil.DefaultRetry.Do(controller, "quota", func() bool {
+		x, y, hoursLater, b = ocrAndParseQuota(ctx, controller)
+		return x >= 0 && y > 0
+	}) {
+		ResellShowMessage(ctx, "⚠️ 未能识别配额")
+		return false
+	}
+	if hoursLater > 0 {
+		schedule.Observe("倒卖配额刷新", time.Now().Add(time.Duration(hoursLater)*time.Hour), "AutoResell")
+	}
+	emitQuotaStatus(ctx, x, y, hoursLater, b)
+
+	message := fmt.Sprintf("📦 配额 %d/%d", x, y)
+	if b >= 0 {
+		message += fmt.Sprintf("，%s增加 %d", quotaPlan{HoursLater: max(hoursLater, 0)}.refillText(), b)
+	}
+	ResellShowMessage(ctx, message)
+	return true
+}
@@ -0,0 +1,138 @@
+package resell
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// rarityNodes - 稀有度色条的识别节点，从高到低依次识别，第一个命中的即为该商品的稀有度
+var rarityNodes = []struct {
+	Level int
+	Node  string
+}{
+	{6, "Resell_Rarity6"},
+	{5, "Resell_Rarity5"},
+	{4, "Resell_Rarity4"},
+	{3, "Resell_Rarity3"},
+	{2, "Resell_Rarity2"},
+	{1, "Resell_Rarity1"},
+}
+
+// 稀有度色条相对价格中心点的位置（720p 基准），位于价格标签上方
+const (
+	rarityStripAbove  = 30
+	rarityStripHeight = 6
+)
+
+// detectRarity - 识别商品卡片的稀有度，资源未定义节点或均未命中时返回 0
+func detectRarity(ctx *maa.Context, img image.Image, centerX, centerY int) int {
+	if img == nil {
+		return 0
+	}
+	strip := image.Rect(
+		centerX-scale.Xi(thumbnailWidth/2), centerY-scale.Yi(rarityStripAbove),
+		centerX+scale.Xi(thumbnailWidth/2), centerY-scale.Yi(rarityStripAbove-rarityStripHeight),
+	).Intersect(img.Bounds())
+	if strip.Empty() {
+		return 0
+	}
+	roi := []int{strip.Min.X, strip.Min.Y, strip.Dx(), strip.Dy()}
+
+	for _, r := range rarityNodes {
+		// 覆盖未定义的节点会得到默认的 DirectHit，所以只识别资源中定义了的节点
+		if raw, err := ctx.GetNodeJSON(r.Node); err != nil || raw == "" {
+			continue
+		}
+		detail, err := ctx.RunRecognition(r.Node, img, map[string]any{r.Node: map[string]any{"roi": roi}})
+		if err != nil {
+			log.Debug().Err(err).Str("pipeline", r.Node).Msg("[Resell]稀有度识别失败")
+			continue
+		}
+		if detail != nil && detail.Hit {
+			return r.Level
+		}
+	}
+	return 0
+}
+
+// rarityWeights - 按稀有度调整利润的倍率与最低利润，未配置的稀有度保持原样
+type rarityWeights struct {
+	multiplier map[int]float64
+	minProfit  map[int]int
+}
+
+// parseRarityWeights - 格式为 "稀有度:值"，多项用 ; 分隔，如 "6:1.5;5:1.2" 与 "6:0"
+func parseRarityWeights(multipliers, minProfits string) (rarityWeights, error) {
+	w := rarityWeights{multiplier: map[int]float64{}, minProfit: map[int]int{}}
+	err := parseRarityList(multipliers, func(level int, value string) error {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil || v < 0 {
+			return fmt.Errorf("invalid rarity multiplier %q", value)
+		}
+		w.multiplier[level] = v
+		return nil
+	})
+	if err != nil {
+		return rarityWeights{}, err
+	}
+	err = parseRarityList(minProfits, func(level int, value string) error {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid rarity minimum profit %q", value)
+		}
+		w.minProfit[level] = v
+		return nil
+	})
+	if err != nil {
+		return rarityWeights{}, err
+	}
+	return w, nil
+}
+
+func parseRarityList(text string, set func(level int, value string) error) error {
+	for _, item := range parseItemList(strings.ReplaceAll(text, "：", ":")) {
+		key, value, ok := strings.Cut(item, ":")
+		if !ok {
+			return fmt.Errorf("invalid rarity entry %q, want rarity:value", item)
+		}
+		level, err := strconv.Atoi(strings.TrimSpace(key))
+		if err != nil || level < 1 || level > 6 {
+			return fmt.Errorf("invalid rarity %q", key)
+		}
+		if err := set(level, strings.TrimSpace(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// weighted - 乘以稀有度倍率后的利润，稀有度未知或未配置时即原利润
+func (w rarityWeights) weighted(record ProfitRecord) int {
+	m, ok := w.multiplier[record.Rarity]
+	if !ok {
+		return record.Profit
+	}
+	return int(math.Round(float64(record.Profit) * m))
+}
+
+// threshold - 该稀有度单独配置的最低利润，未配置时沿用 base
+func (w rarityWeights) threshold(record ProfitRecord, base int) int {
+	if v, ok := w.minProfit[record.Rarity]; ok {
+		return v
+	}
+	return base
+}
+
+// rarityNote - 推荐信息中附带的稀有度
+func (r ProfitRecord) rarityNote() string {
+	if r.Rarity == 0 {
+		return ""
+	}
+	return fmt.Sprintf("，%d星", r.Rarity)
+}
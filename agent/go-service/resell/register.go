@@ -1,14 +1,70 @@
 package resell
 
-import "github.com/MaaXYZ/maa-framework-go/v4"
+import (
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/nodecheck"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
 
 var (
 	_ maa.CustomActionRunner = &ResellInitAction{}
 	_ maa.CustomActionRunner = &ResellFinishAction{}
+	_ maa.CustomActionRunner = &ResellExplainConfigAction{}
+	_ maa.CustomActionRunner = &ResellConfirmAbovePriceAction{}
+	_ maa.CustomActionRunner = &ResellBuyNextAction{}
+	_ maa.CustomActionRunner = &ResellReportAction{}
+	_ maa.CustomActionRunner = &ResellWhatIfAction{}
+	_ maa.CustomActionRunner = &ResellQuotaNavigateAction{}
+	_ maa.CustomActionRunner = &ResellQuotaCheckAction{}
 )
 
-// Register registers all custom action components for resell package
+// Components returns the custom components of resell package
+func Components() []registry.Component {
+	P := registry.P
+	return []registry.Component{
+		registry.Action("ResellInitAction", &ResellInitAction{}, "扫描商品与好友售价，按利润选出要购买的商品",
+			P("MinimumProfit", "int|string", "最低利润，可为数字或表达式，如 cost*0.2"),
+			P("ScanSpecialOffers", "bool", "额外扫描特惠页签"),
+			P("SearchItems", "string", "先搜索购买的物品名，分号分隔；名称一致且利润达标才购买"),
+			P("DecisionPolicy", "string", "自定义选品策略：打分表达式，或只买满足条件的商品（如 profit >= 1500 && rarity >= 4），为空时按利润最高选品"),
+			P("ExcludeFriends", "string", "不参与售价比较的好友名，分号分隔"),
+			P("FriendSampleCount", "int", "参与售价取值的好友行数，0 表示全部"),
+			P("PriceStrategy", "string", "好友售价取值：first、max、median，默认 max"),
+			P("ConfirmAbovePrice", "int", "成本价超过该值时需要确认，0 表示不限制"),
+			P("ConfirmMode", "string", "skip 跳过并提醒，wait 等待手动点击购买"),
+			P("ConfirmTimeout", "int", "wait 模式等待的秒数"),
+			P("RarityMultiplier", "string", "按稀有度调整利润的倍率，如 6:1.5;5:1.2"),
+			P("RarityMinProfit", "string", "按稀有度单独设置的最低利润，如 6:0"),
+			P("MinimumMargin", "int", "最低利润率（百分比），0 表示不限制"),
+			P("AutoBuyOnOverflow", "bool", "配额溢出时按利润依次购买溢出数量的商品"),
+			P("DebugReport", "bool", "保存商品缩略图与 HTML 报告到 debug/resell，只保留最近 10 次"),
+			P("MaxPurchaseCount", "int", "利润达标时最多购买的件数"),
+			P("MaxPages", "int", "常规货架最多扫描的页数"),
+			P("QuotaDeferHours", "int", "配额将溢出但距下次增加超过该小时数时推迟购买"),
+			P("DryRun", "bool", "只报告将会购买的商品，不实际购买"),
+			P("OCRRetryAttempts", "int", "每一步 OCR 最多识别的次数，0 使用默认值"),
+			P("OCRRetryDelay", "int", "两次识别之间等待的毫秒数，0 使用默认值"),
+			P("Blacklist", "string", "不购买的物品名关键字，分号分隔"),
+			P("Whitelist", "string", "只购买的物品名关键字，分号分隔"),
+			P("next_table", "object", "决策结果到后续节点的映射"),
+		),
+		registry.Action("ResellFinishAction", &ResellFinishAction{}, "结束倒卖，只记录日志，运行结果已在做出决定时输出"),
+		registry.Action("ResellExplainConfigAction", &ResellExplainConfigAction{}, "任务开始前说明当前配置会让倒卖做什么、不做什么"),
+		registry.Action("ResellConfirmAbovePriceAction", &ResellConfirmAbovePriceAction{}, "高价商品等待手动确认购买，其余直接自动购买"),
+		registry.Action("ResellBuyNextAction", &ResellBuyNextAction{}, "连续购买时购买队列中的下一件商品"),
+		registry.Action("ResellReportAction", &ResellReportAction{}, "汇总历史记录中最近几天的倒卖利润",
+			P("days", "array", "统计的天数，默认 [7, 30]"),
+		),
+		registry.Action("ResellWhatIfAction", &ResellWhatIfAction{}, "用历史扫描结果试算不同最低利润下的决定",
+			P("thresholds", "string", "试算的最低利润，分号分隔，可为表达式"),
+			P("days", "int", "使用最近几天的扫描结果，默认 30"),
+		),
+		registry.Action("ResellQuotaNavigateAction", &ResellQuotaNavigateAction{}, "进入弹性需求物资商店后只识别配额，不扫描商品"),
+		registry.Action("ResellQuotaCheckAction", &ResellQuotaCheckAction{}, "识别配额区域，输出当前/上限与下次增加"),
+	}
+}
+
+// Register declares the pipeline nodes resell package references
 func Register() {
-	maa.AgentServerRegisterCustomAction("ResellInitAction", &ResellInitAction{})
-	maa.AgentServerRegisterCustomAction("ResellFinishAction", &ResellFinishAction{})
+	nodecheck.Require("Resell", requiredNodes()...)
 }
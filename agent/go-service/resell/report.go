@@ -0,0 +1,145 @@
+package resell
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// 缩略图相对价格中心点的裁剪范围（720p 基准）
+const (
+	thumbnailWidth = 140
+	thumbnailAbove = 120
+	thumbnailBelow = 20
+)
+
+const (
+	debugRunLayout = "20060102-150405"
+	// keptDebugRuns - 最多保留的调试目录数量（含本次），更早的在创建新目录时删除
+	keptDebugRuns = 10
+)
+
+// runDebugDir - 本次运行的调试目录，保存缩略图和报告；未开启 DebugReport 时为空
+var runDebugDir string
+
+// newRunDebugDir - 创建 debug/resell/<时间戳> 目录，并删除较早的目录，只保留最近 keptDebugRuns 次
+func newRunDebugDir() string {
+	root := filepath.Join(".", "debug", "resell")
+	pruneDebugDirs(root, keptDebugRuns-1)
+	dir := filepath.Join(root, time.Now().Format(debugRunLayout))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Warn().Err(err).Str("dir", dir).Msg("[Resell]创建调试目录失败")
+		return ""
+	}
+	return dir
+}
+
+// pruneDebugDirs - 按时间戳目录名排序，只保留最近 keep 个，其他文件与目录不动
+func pruneDebugDirs(root string, keep int) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return
+	}
+	var runs []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(debugRunLayout, entry.Name()); err == nil {
+			runs = append(runs, entry.Name())
+		}
+	}
+	if len(runs) <= keep {
+		return
+	}
+	sort.Strings(runs)
+	for _, name := range runs[:len(runs)-keep] {
+		if err := os.RemoveAll(filepath.Join(root, name)); err != nil {
+			log.Warn().Err(err).Str("dir", name).Msg("[Resell]删除旧调试目录失败")
+		}
+	}
+}
+
+// cardRect - 以价格中心点为基准推算商品卡片区域
+func cardRect(centerX, centerY int) image.Rectangle {
+	return image.Rect(
+		centerX-scale.Xi(thumbnailWidth/2), centerY-scale.Yi(thumbnailAbove),
+		centerX+scale.Xi(thumbnailWidth/2), centerY+scale.Yi(thumbnailBelow),
+	)
+}
+
+// saveThumbnail - 以价格中心点为基准裁剪商品卡片并保存，返回文件名
+func saveThumbnail(img image.Image, centerX, centerY int, source string, row, col int) string {
+	if runDebugDir == "" || img == nil {
+		return ""
+	}
+	sub, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	})
+	if !ok {
+		return ""
+	}
+
+	rect := cardRect(centerX, centerY).Intersect(img.Bounds())
+	if rect.Empty() {
+		return ""
+	}
+
+	name := fmt.Sprintf("%s_r%dc%d.png", source, row, col)
+	f, err := os.Create(filepath.Join(runDebugDir, name))
+	if err != nil {
+		log.Warn().Err(err).Msg("[Resell]保存缩略图失败")
+		return ""
+	}
+	defer f.Close()
+	if err := png.Encode(f, sub.SubImage(rect)); err != nil {
+		log.Warn().Err(err).Msg("[Resell]保存缩略图失败")
+		return ""
+	}
+	return name
+}
+
+// writeHTMLReport - 生成带缩略图的商品报告，返回报告路径
+func writeHTMLReport(records []ProfitRecord) string {
+	if runDebugDir == "" {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString(`<html><head><meta charset="utf-8"><title>Resell</title></head><body>`)
+	builder.WriteString(`<table style="border-collapse: collapse;">`)
+	builder.WriteString(`<tr><th>商品</th><th>位置</th><th>成本</th><th>售价</th><th>利润</th></tr>`)
+	for _, record := range records {
+		thumb := ""
+		if record.Thumbnail != "" {
+			thumb = fmt.Sprintf(`<img src="%s">`, html.EscapeString(record.Thumbnail))
+		}
+		if name := record.displayName(); name != "" {
+			thumb += "<br>" + html.EscapeString(name)
+		}
+		sale := fmt.Sprintf("%d", record.SalePrice)
+		if record.Friend != "" {
+			sale += "<br>" + html.EscapeString(record.Friend)
+		}
+		builder.WriteString(fmt.Sprintf(
+			`<tr><td>%s</td><td>%s</td><td>%d</td><td>%s</td><td>%d</td></tr>`,
+			thumb, html.EscapeString(record.Position()), record.CostPrice, sale, record.Profit,
+		))
+	}
+	builder.WriteString(`</table></body></html>`)
+
+	path := filepath.Join(runDebugDir, "report.html")
+	if err := os.WriteFile(path, []byte(builder.String()), 0644); err != nil {
+		log.Warn().Err(err).Msg("[Resell]保存报告失败")
+		return ""
+	}
+	return path
+}
@@ -0,0 +1,35 @@
+package resell
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPruneDebugDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"20260101-100000", "20260102-100000", "20260103-100000", "20260104-100000", "notes"} {
+		if err := os.Mkdir(filepath.Join(root, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "20251231-100000"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pruneDebugDirs(root, 2)
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Name())
+	}
+	want := []string{"20251231-100000", "20260103-100000", "20260104-100000", "notes"}
+	if !slices.Equal(got, want) {
+		t.Errorf("after prune = %v, want %v", got, want)
+	}
+}
@@ -5,8 +5,21 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/clientlang"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/focus"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/nexttable"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/ocrutil"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/overridesnap"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/pricewatch"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/purchase"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/roistats"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/safemode"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/schedule"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/taskresult"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/theme"
 	"github.com/MaaXYZ/maa-framework-go/v4"
 	"github.com/rs/zerolog/log"
 )
@@ -18,39 +31,195 @@ type ProfitRecord struct {
 	CostPrice int
 	SalePrice int
 	Profit    int
+	// Source - 商品来源货架，对应 shelfProfile.Name
+	Source string
+	// Thumbnail - 商品卡片缩略图文件名，位于本次运行的调试目录
+	Thumbnail string
+	// Item - 按图标识别出的物品名，图标库中没有时为空
+	Item string
+	// Name - 商品详情页 OCR 识别出的物品名，未能识别时为空
+	Name string
+	// Friend - 给出该售价的好友，未能识别好友列表时为空
+	Friend string
+	// Rarity - 卡片色条识别出的稀有度（1-6），未能识别时为 0
+	Rarity int
+	// ClickX, ClickY - 扫描时识别到的价格中心，购买时按此位置点击商品
+	ClickX, ClickY int
+	// Page - 常规货架的页码，第一页与特惠页签为 0
+	Page int
 }
 
+// friendNote - 推荐信息中附带的出价好友
+func (r ProfitRecord) friendNote() string {
+	if r.Friend == "" {
+		return ""
+	}
+	return fmt.Sprintf("，售给好友 %s", r.Friend)
+}
+
+// names - 可用于按名称筛选的物品名，OCR 与图标识别的结果都算
+func (r ProfitRecord) names() []string {
+	var names []string
+	for _, name := range []string{r.Name, r.Item} {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// displayName - 用于日志与提示的物品名，优先使用 OCR 识别的名称
+func (r ProfitRecord) displayName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Item
+}
+
+// Position - 商品位置描述，特惠页签的商品会带上来源标记
+func (r ProfitRecord) Position() string {
+	if r.Source != "" && r.Source != mainShelfProfile.Name {
+		return fmt.Sprintf("%s第%d行第%d列", shelfLabel(r.Source), r.Row, r.Col)
+	}
+	if r.Page > 1 {
+		return fmt.Sprintf("第%d页第%d行第%d列", r.Page, r.Row, r.Col)
+	}
+	return fmt.Sprintf("第%d行第%d列", r.Row, r.Col)
+}
+
+// ocrRetry - 本次运行各步 OCR 的重试次数与间隔，由 ResellInitAction 的参数设置
+var ocrRetry = ocrutil.DefaultRetry
+
 // ResellInitAction - Initialize Resell task custom action
 type ResellInitAction struct{}
 
 func (a *ResellInitAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
 	log.Info().Msg("[Resell]开始倒卖流程")
+	// 上次运行的覆盖（下一节点、点击位置、分辨率缩放）全部恢复
+	nodes := resellNodes(ctx, arg.CurrentTaskName)
+	overridesnap.Reset(ctx, "Resell", nodes...)
+	theme.Apply(ctx)
+	clientlang.Apply(ctx)
+	pricewatch.Reset()
 	var params struct {
 		MinimumProfit interface{} `json:"MinimumProfit"`
+		// SearchItems - 先搜索购买的物品名，分号分隔，结果名称一致且利润达标才购买
+		SearchItems string `json:"SearchItems"`
+		// ScanSpecialOffers - 额外扫描特惠页签，与常规货架一起参与利润排序
+		ScanSpecialOffers bool `json:"ScanSpecialOffers"`
+		// DecisionPolicy - 自定义选品策略表达式，为空时按利润最高选品
+		DecisionPolicy string `json:"DecisionPolicy"`
+		// ExcludeFriends - 不参与售价比较的好友名，分号分隔
+		ExcludeFriends string `json:"ExcludeFriends"`
+		// FriendSampleCount - 参与售价取值的好友行数，0 表示识别到的全部行
+		FriendSampleCount int `json:"FriendSampleCount"`
+		// PriceStrategy - 好友售价取值：first 第一位、max 最高价、median 中位数，为空时为 max
+		PriceStrategy string `json:"PriceStrategy"`
+		// ConfirmAbovePrice - 成本价超过该值时需要确认，0 表示不限制
+		ConfirmAbovePrice int `json:"ConfirmAbovePrice"`
+		// ConfirmMode - skip：跳过并提醒；wait：等待手动点击购买
+		ConfirmMode string `json:"ConfirmMode"`
+		// ConfirmTimeout - wait 模式等待的秒数
+		ConfirmTimeout int `json:"ConfirmTimeout"`
+		// RarityMultiplier - 按稀有度调整利润的倍率，如 "6:1.5;5:1.2"，参与排序与最低利润判断
+		RarityMultiplier string `json:"RarityMultiplier"`
+		// RarityMinProfit - 按稀有度单独设置的最低利润，如 "6:0"，覆盖 MinimumProfit
+		RarityMinProfit string `json:"RarityMinProfit"`
+		// MinimumMargin - 最低利润率（百分比），与最低利润同时满足才购买，0 表示不限制
+		MinimumMargin int `json:"MinimumMargin"`
+		// AutoBuyOnOverflow - 配额溢出时按利润从高到低依次购买溢出数量的商品，而不是只提醒
+		AutoBuyOnOverflow bool `json:"AutoBuyOnOverflow"`
+		// MaxPurchaseCount - 利润达标时最多购买的商品件数，按利润从高到低依次购买，0 或 1 只买最优的一件
+		MaxPurchaseCount int `json:"MaxPurchaseCount"`
+		// MaxPages - 常规货架最多扫描的页数，超过 8 列的商品需要滑动翻页，0 或 1 只扫描第一页
+		MaxPages int `json:"MaxPages"`
+		// QuotaDeferHours - 配额将溢出但距下次增加超过该小时数时推迟购买，0 表示总是立即购买
+		QuotaDeferHours int `json:"QuotaDeferHours"`
+		// DryRun - 试运行：照常扫描并计算利润，只报告将会购买的商品，不实际购买
+		DryRun bool `json:"DryRun"`
+		// OCRRetryAttempts - 每一步 OCR 无结果时最多识别的次数（含首次），0 使用默认的 2 次
+		OCRRetryAttempts int `json:"OCRRetryAttempts"`
+		// OCRRetryDelay - 两次识别之间等待的毫秒数，0 使用默认的 300ms
+		OCRRetryDelay int `json:"OCRRetryDelay"`
+		// Blacklist - 不购买的物品名关键字，分号分隔，如留作制作材料的商品
+		Blacklist string `json:"Blacklist"`
+		// Whitelist - 只购买的物品名关键字，分号分隔，为空时不限制
+		Whitelist string `json:"Whitelist"`
+		// DebugReport - 保存商品缩略图与 HTML 报告到 debug/resell，默认关闭
+		DebugReport bool `json:"DebugReport"`
+		// NextTable - 决策结果到后续节点的映射，覆盖节点 attach.next_table 中的同名项
+		NextTable nexttable.Table `json:"next_table"`
 	}
 	if err := json.Unmarshal([]byte(arg.CustomActionParam), &params); err != nil {
 		log.Error().Err(err).Msg("[Resell]反序列化失败")
 		return false
 	}
 
-	// Parse MinimumProfit (support both string and int)
-	var MinimumProfit int
+	// Parse MinimumProfit (support int, numeric string and expression string)
+	var MinimumProfit profitRule
 	switch v := params.MinimumProfit.(type) {
 	case float64:
-		MinimumProfit = int(v)
+		MinimumProfit = profitRule{fixed: int(v)}
 	case string:
-		parsed, err := strconv.Atoi(v)
+		rule, err := parseProfitRule(v)
 		if err != nil {
 			log.Error().Err(err).Msgf("Failed to parse MinimumProfit string: %s", v)
 			return false
 		}
-		MinimumProfit = parsed
+		MinimumProfit = rule
 	default:
 		log.Error().Msgf("Invalid MinimumProfit type: %T", v)
 		return false
 	}
 
-	fmt.Printf("MinimumProfit: %d\n", MinimumProfit)
+	fmt.Printf("MinimumProfit: %s\n", MinimumProfit)
+	excludedFriends = parseItemList(params.ExcludeFriends)
+	friendSampleCount = max(params.FriendSampleCount, 0)
+	strategy, err := parsePriceStrategy(params.PriceStrategy)
+	if err != nil {
+		log.Error().Err(err).Msg("[Resell]好友售价策略无效")
+		return false
+	}
+	priceStrategy = strategy
+	names := parseNameFilter(params.Blacklist, params.Whitelist)
+	gate := confirmGate{
+		AbovePrice: params.ConfirmAbovePrice,
+		Mode:       params.ConfirmMode,
+		Timeout:    time.Duration(params.ConfirmTimeout) * time.Second,
+	}
+	ocrRetry = ocrutil.Retry{
+		Attempts: params.OCRRetryAttempts,
+		Delay:    time.Duration(params.OCRRetryDelay) * time.Millisecond,
+	}
+	// 安全模式下只试运行，不购买
+	dryRun = params.DryRun || safemode.Active()
+	pageCount = 1
+	currentShelf = mainShelfProfile.Name
+	pendingConfirm = nil
+	buyQueue = nil
+	historyRecorded = false
+	next := nexttable.Load(ctx, arg.CurrentTaskName, startNextDefaults)
+	next.Merge(params.NextTable)
+
+	policy, err := parseDecisionPolicy(params.DecisionPolicy)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to parse DecisionPolicy: %s", params.DecisionPolicy)
+		return false
+	}
+	weights, err := parseRarityWeights(params.RarityMultiplier, params.RarityMinProfit)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse rarity weights")
+		return false
+	}
+	floor := profitFloor{rule: MinimumProfit, weights: weights, margin: max(params.MinimumMargin, 0), policy: policy}
+
+	// 上次运行时商店已售罄且还没到补货时间，不再进入扫描
+	if until, ok := soldOutUntil(time.Now()); ok {
+		log.Info().Time("补货时间", until).Msg("[Resell]商店售罄尚未补货，跳过本次运行")
+		ResellShowMessage(ctx, fmt.Sprintf("⚠️ 商店已售罄，预计 %s 补货，本次不运行", until.Format("01-02 15:04")))
+		emitResult(ctx, taskresult.StatusSkipped, nil, 0, taskresult.Decision{Action: "none", Reason: "sold_out_cooldown"})
+		return true
+	}
 
 	// Get controller
 	controller := ctx.GetTasker().GetController()
@@ -59,224 +228,218 @@ func (a *ResellInitAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool
 		return false
 	}
 
+	// attach.layout 的坐标是 720p 基准，先覆盖再统一缩放
+	applyAttachLayout(ctx, arg.CurrentTaskName)
+	scale = measureScale(controller)
+	if !scale.IsIdentity() {
+		applyScale(ctx, nodes, scale)
+	}
+
 	overflowAmount := 0
 	log.Info().Msg("Checking quota overflow status...")
 	time.Sleep(500 * time.Millisecond)
-	controller.PostScreencap().Wait()
 
 	// OCR and parse quota from two regions
-	x, y, _, b := ocrAndParseQuota(ctx, controller)
+	var x, y, hoursLater, b int
+	ocrRetry.Do(controller, "quota", func() bool {
+		x, y, hoursLater, b = ocrAndParseQuota(ctx, controller)
+		return x >= 0 && y > 0
+	})
+	if hoursLater > 0 {
+		schedule.Observe("倒卖配额刷新", time.Now().Add(time.Duration(hoursLater)*time.Hour), "AutoResell")
+	}
+	currentStock = -1
+	if x >= 0 && y > 0 {
+		currentStock = x
+	}
+	var quota quotaPlan
 	if x >= 0 && y > 0 && b >= 0 {
 		overflowAmount = x + b - y
+		quota = planQuota(x, y, hoursLater, b, params.QuotaDeferHours)
+		log.Info().Int("现在购买", quota.BuyNow).Int("推迟", quota.Deferred).Int("备用", quota.Spare).Int("小时后增加", quota.HoursLater).Msg("[Resell]配额规划")
+		publishQuotaPlan(ctx, quota)
+		emitQuotaStatus(ctx, x, y, hoursLater, b)
+		ResellShowMessage(ctx, quota.String())
 	} else {
 		log.Info().Msg("Failed to parse quota or no quota found, proceeding with normal flow")
 	}
 
-	// Define product positions using predefined Pipeline names
-	rowNames := []string{"第一行", "第二行", "第三行"}
-	maxCols := 8 // Maximum 8 columns per row
-
-	// Process multiple items by scanning across ROI
-	records := make([]ProfitRecord, 0)
-	maxProfit := 0
-
-	// For each row
-	for rowIdx := 0; rowIdx < 3; rowIdx++ {
-		log.Info().Str("行", rowNames[rowIdx]).Msg("[Resell]当前处理")
-
-		// For each column
-		for col := 1; col <= maxCols; col++ {
-			log.Info().Int("行", rowIdx+1).Int("列", col).Msg("[Resell]商品位置")
-			// Step 1: 识别商品价格
-			log.Info().Msg("[Resell]第一步：识别商品价格")
-			Resell_delay_freezes_time(ctx, 200)
-			controller.PostScreencap().Wait()
-
-			// 构建Pipeline名称
-			pricePipelineName := fmt.Sprintf("Resell_ROI_Product_Row%d_Col%d_Price", rowIdx+1, col)
-			costPrice, clickX, clickY, success := ocrExtractNumberWithCenter(ctx, controller, pricePipelineName)
-			if !success {
-				//失败就重试一遍
-				controller.PostScreencap().Wait()
-				costPrice, clickX, clickY, success = ocrExtractNumberWithCenter(ctx, controller, pricePipelineName)
-				if !success {
-					log.Info().Int("行", rowIdx+1).Int("列", col).Msg("[Resell]位置无数字，说明无商品，下一行")
-					break
-				}
-			}
-
-			// Click on product
-			controller.PostClick(int32(clickX), int32(clickY))
-
-			// Step 2: 识别“查看好友价格”，包含“好友”二字则继续
-			log.Info().Msg("[Resell]第二步：查看好友价格")
-			Resell_delay_freezes_time(ctx, 200)
-			controller.PostScreencap().Wait()
-
-			_, friendBtnX, friendBtnY, success := ocrExtractTextWithCenter(ctx, controller, "Resell_ROI_ViewFriendPrice", "好友")
-			if !success {
-				log.Info().Msg("[Resell]第二步：未找到“好友”字样")
-				continue
-			}
-			//商品详情页右下角识别的成本价格为准
-			controller.PostScreencap().Wait()
-			ConfirmcostPrice, _, _, success := ocrExtractNumberWithCenter(ctx, controller, "Resell_ROI_DetailCostPrice")
-			if success {
-				costPrice = ConfirmcostPrice
-			} else {
-				//失败就重试一遍
-				controller.PostScreencap().Wait()
-				ConfirmcostPrice, _, _, success := ocrExtractNumberWithCenter(ctx, controller, "Resell_ROI_DetailCostPrice")
-				if success {
-					costPrice = ConfirmcostPrice
-				} else {
-					log.Info().Msg("[Resell]第二步：未能识别商品详情页成本价格，继续使用列表页识别的价格")
-				}
-			}
-			log.Info().Int("行", rowIdx+1).Int("列", col).Int("Cost", costPrice).Msg("[Resell]商品售价")
-			// 单击"查看好友价格"按钮
-			controller.PostClick(int32(friendBtnX), int32(friendBtnY))
-
-			// Step 3: 检查好友列表第一位的出售价，即最高价格
-			log.Info().Msg("[Resell]第三步：识别好友出售价")
-			//等加载好友价格
-			Resell_delay_freezes_time(ctx, 600)
-			controller.PostScreencap().Wait()
-
-			salePrice, _, _, success := ocrExtractNumberWithCenter(ctx, controller, "Resell_ROI_FriendSalePrice")
-			if !success {
-				//失败就重试一遍
-				controller.PostScreencap().Wait()
-				salePrice, _, _, success = ocrExtractNumberWithCenter(ctx, controller, "Resell_ROI_FriendSalePrice")
-				if !success {
-					log.Info().Msg("[Resell]第三步：未能识别好友出售价，跳过该商品")
-					continue
-				}
-			}
-			log.Info().Int("Price", salePrice).Msg("[Resell]好友出售价")
-			// 计算利润
-			profit := salePrice - costPrice
-			log.Info().Int("Profit", profit).Msg("[Resell]当前商品利润")
-
-			// Save record with row and column information
-			record := ProfitRecord{
-				Row:       rowIdx + 1,
-				Col:       col,
-				CostPrice: costPrice,
-				SalePrice: salePrice,
-				Profit:    profit,
-			}
-			records = append(records, record)
-
-			if profit > maxProfit {
-				maxProfit = profit
-			}
-
-			// Step 4: 检查页面右上角的“返回”按钮，按ESC返回
-			log.Info().Msg("[Resell]第四步：返回商品详情页")
-			Resell_delay_freezes_time(ctx, 200)
-			controller.PostScreencap().Wait()
-
-			_, _, _, success = ocrExtractTextWithCenter(ctx, controller, "Resell_ROI_ReturnButton", "返回")
-			if success {
-				log.Info().Msg("[Resell]第四步：发现返回按钮，按ESC返回")
+	// 搜索模式：直接搜索目标商品购买，搜索不可用时回退到逐格扫描
+	if searchItems := parseItemList(params.SearchItems); len(searchItems) > 0 && dryRun {
+		log.Info().Msg("[Resell]试运行不使用搜索模式（搜索会直接选中商品），改为逐格扫描")
+	} else if len(searchItems) > 0 {
+		if record, found := searchAndSelect(ctx, controller, searchItems, floor); found {
+			item := record.displayName()
+			log.Info().Str("item", item).Int("Cost", record.CostPrice).Int("Profit", record.Profit).Msg("[Resell]搜索模式：利润达标，购买")
+			if !gatePurchase(ctx, gate, record.CostPrice, item, []ProfitRecord{record}, overflowAmount) {
 				controller.PostClickKey(27)
+				return true
 			}
+			purchase.Expect(purchase.Receipt{Item: record.Item, Price: record.CostPrice})
+			emitBuy(ctx, []ProfitRecord{record}, overflowAmount, taskresult.Decision{Action: "buy", Target: item, Reason: "search"})
+			next.Apply(ctx, arg.CurrentTaskName, outcomeSearchBuy, nil)
+			return true
+		}
+		log.Info().Msg("[Resell]搜索模式不可用或未找到商品，回退到逐格扫描")
+	}
 
-			// Step 5: 识别“查看好友价格”，包含“好友”二字则按ESC关闭页面
-			log.Info().Msg("[Resell]第五步：关闭商品详情页")
-			Resell_delay_freezes_time(ctx, 200)
-			controller.PostScreencap().Wait()
+	runDebugDir = ""
+	if params.DebugReport {
+		runDebugDir = newRunDebugDir()
+	}
 
-			_, _, _, success = ocrExtractTextWithCenter(ctx, controller, "Resell_ROI_ViewFriendPrice", "好友")
-			if success {
-				log.Info().Msg("[Resell]第五步：关闭页面")
-				controller.PostClickKey(27)
-			}
-		}
+	// Scan main shelf, then optionally the special offers tab with its own layout profile
+	records := scanShelf(ctx, controller, mainShelfProfile, 0)
+	if params.MaxPages > 1 {
+		records = append(records, scanPages(ctx, controller, params.MaxPages)...)
+	}
+	if params.ScanSpecialOffers {
+		records = append(records, scanSpecialOffers(ctx, controller)...)
 	}
 
 	// Output results using focus
 	for i, record := range records {
-		log.Info().Int("No.", i+1).Int("列", record.Col).Int("成本", record.CostPrice).Int("售价", record.SalePrice).Int("利润", record.Profit).Msg("[Resell]商品信息")
+		log.Info().Int("No.", i+1).Str("位置", record.Position()).Int("成本", record.CostPrice).Int("售价", record.SalePrice).Int("利润", record.Profit).
+			Str("利润率", fmt.Sprintf("%.1f%%", record.marginPercent())).Str("规则", floor.describe(record)).Bool("达标", floor.meets(record)).Msg("[Resell]商品信息")
+	}
+
+	warnROIDrift(ctx)
+
+	if path := writeHTMLReport(records); path != "" {
+		log.Info().Str("report", path).Msg("[Resell]商品报告已保存")
 	}
 
 	// Check if sold out
 	if len(records) == 0 {
 		log.Info().Msg("库存已售罄，无可购买商品")
 		ResellShowMessage(ctx, "⚠️ 库存已售罄，无可购买商品")
+		// 只有识别到下次增加的小时数时才记录，不足一小时即将补货，无需跳过
+		if hoursLater > 0 {
+			markSoldOut(time.Now().Add(time.Duration(hoursLater) * time.Hour))
+		}
+		emitResult(ctx, taskresult.StatusSkipped, records, overflowAmount, taskresult.Decision{Action: "none", Reason: "sold_out"})
 		return true
 	}
 
-	// Find and output max profit item
-	maxProfitIdx := -1
-	for i, record := range records {
-		if record.Profit == maxProfit {
-			maxProfitIdx = i
-			break
-		}
+	clearSoldOut()
+
+	// 按物品名黑名单/白名单筛选，之后的选品只在可购买的商品中进行
+	candidates := names.apply(records)
+	if len(candidates) == 0 {
+		log.Info().Int("扫描", len(records)).Msg("[Resell]所有商品都被物品名黑名单/白名单排除")
+		ResellShowMessage(ctx, "💡 扫描到的商品都被物品名黑名单/白名单排除，本次不购买")
+		emitResult(ctx, taskresult.StatusSkipped, records, overflowAmount, taskresult.Decision{Action: "none", Reason: "name_filtered"})
+		return true
+	}
+	candidates = filterByPolicy(candidates, policy)
+	if len(candidates) == 0 {
+		log.Info().Str("policy", policy.String()).Msg("[Resell]没有商品满足选品策略")
+		ResellShowMessage(ctx, fmt.Sprintf("💡 没有商品满足选品策略「%s」，本次不购买", policy))
+		emitResult(ctx, taskresult.StatusSkipped, records, overflowAmount, taskresult.Decision{Action: "none", Reason: "policy_rejected"})
+		return true
 	}
 
-	if maxProfitIdx < 0 {
+	// Find and output max profit item (or the best one under the custom policy)
+	maxRecord, ok := bestRecord(candidates, policy, weights)
+	if !ok {
 		log.Error().Msg("未找到最高利润商品")
 		return false
 	}
 
-	maxRecord := records[maxProfitIdx]
-	log.Info().Msgf("最高利润商品: 第%d行第%d列，利润%d", maxRecord.Row, maxRecord.Col, maxRecord.Profit)
+	log.Info().Msgf("最高利润商品: %s，利润%d", maxRecord.Position(), maxRecord.Profit)
 
 	// Check if we should purchase
-	if overflowAmount > 0 {
+	// 溢出但推迟购买时按利润达标的流程处理
+	if quota.BuyNow > 0 {
 		// Quota overflow detected, show reminder and recommend purchase
-		log.Info().Msgf("配额溢出：建议购买%d件商品，推荐第%d行第%d列（利润：%d）",
-			overflowAmount, maxRecord.Row, maxRecord.Col, maxRecord.Profit)
+		log.Info().Msgf("配额溢出：建议购买%d件商品，推荐%s（利润：%d）",
+			quota.BuyNow, maxRecord.Position(), maxRecord.Profit)
+
+		// 背包放不下的部分不再建议购买
+		buyAmount, spaceNote := fitInventory(ctx, controller, quota.BuyNow)
+
+		if params.AutoBuyOnOverflow {
+			if plan := planBuys(candidates, weights, gate, buyAmount, nil); len(plan) > 0 && dryRun {
+				reportDryRun(ctx, gate, records, overflowAmount, plan, "quota_overflow")
+				return true
+			} else if len(plan) > 0 {
+				log.Info().Int("件数", len(plan)).Str("商品", planTargets(plan)).Msg("[Resell]配额溢出，开始连续购买")
+				ResellShowMessage(ctx, fmt.Sprintf("⚠️ 配额溢出，剩余配额明天将超出上限\n依次购买%d件商品: %s%s",
+					len(plan), planTargets(plan), spaceNote))
+				emitResult(ctx, taskresult.StatusSuccess, records, overflowAmount, taskresult.Decision{Action: "buy", Target: planTargets(plan), Reason: "quota_overflow"})
+				startBuyQueue(ctx, arg.CurrentTaskName, next, plan)
+				return true
+			}
+			log.Info().Msg("[Resell]没有可连续购买的商品，改为提醒")
+		}
 
 		// Show message with focus
-		message := fmt.Sprintf("⚠️ 配额溢出提醒\n剩余配额明天将超出上限，建议购买%d件商品\n推荐购买: 第%d行第%d列 (最高利润: %d)",
-			overflowAmount, maxRecord.Row, maxRecord.Col, maxRecord.Profit)
+		message := fmt.Sprintf("⚠️ 配额溢出提醒\n剩余配额明天将超出上限，建议购买%d件商品\n推荐购买: %s (最高利润: %d%s%s)%s",
+			buyAmount, maxRecord.Position(), maxRecord.Profit, maxRecord.rarityNote(), maxRecord.friendNote(), spaceNote)
 		ResellShowMessage(ctx, message)
+		emitResult(ctx, taskresult.StatusSkipped, records, overflowAmount, taskresult.Decision{Action: "recommend", Target: maxRecord.Position(), Reason: "quota_overflow"})
 		return true
-	} else if maxRecord.Profit >= MinimumProfit {
+	} else if floor.meets(maxRecord) {
 		// Normal mode: purchase if meets minimum profit
-		log.Info().Msgf("利润达标，准备购买第%d行第%d列商品（利润：%d）",
-			maxRecord.Row, maxRecord.Col, maxRecord.Profit)
-		taskName := fmt.Sprintf("ResellSelectProductRow%dCol%d", maxRecord.Row, maxRecord.Col)
-		ctx.OverrideNext(arg.CurrentTaskName, []maa.NodeNextItem{
-			{Name: taskName},
-		})
+		if params.MaxPurchaseCount > 1 {
+			count, spaceNote := fitInventory(ctx, controller, params.MaxPurchaseCount)
+			plan := planBuys(candidates, weights, gate, count, floor.meets)
+			if len(plan) > 1 && dryRun {
+				reportDryRun(ctx, gate, records, overflowAmount, plan, "profit_reached")
+				return true
+			}
+			if len(plan) > 1 {
+				log.Info().Int("件数", len(plan)).Str("商品", planTargets(plan)).Msg("[Resell]利润达标，开始连续购买")
+				ResellShowMessage(ctx, fmt.Sprintf("💰 %d件商品利润达标，依次购买: %s%s", len(plan), planTargets(plan), spaceNote))
+				emitResult(ctx, taskresult.StatusSuccess, records, overflowAmount, taskresult.Decision{Action: "buy", Target: planTargets(plan), Reason: "profit_reached"})
+				startBuyQueue(ctx, arg.CurrentTaskName, next, plan)
+				return true
+			}
+			// 只有一件可买时按原流程处理，高价确认也照常生效
+		}
+		log.Info().Msgf("利润达标，准备购买%s商品（利润：%d，按稀有度加权：%d）",
+			maxRecord.Position(), maxRecord.Profit, weights.weighted(maxRecord))
+		if dryRun {
+			reportDryRun(ctx, gate, records, overflowAmount, []ProfitRecord{maxRecord}, "profit_reached")
+			return true
+		}
+		if !gatePurchase(ctx, gate, maxRecord.CostPrice, maxRecord.Position(), records, overflowAmount) {
+			return true
+		}
+		purchase.Expect(purchase.Receipt{Item: maxRecord.Item, Price: maxRecord.CostPrice})
+		emitBuy(ctx, records, overflowAmount, taskresult.Decision{Action: "buy", Target: maxRecord.Position(), Reason: "profit_reached"})
+		selectRecord(ctx, maxRecord)
+		next.Apply(ctx, arg.CurrentTaskName, outcomeBuy, nextVars(maxRecord))
 		return true
 	} else {
 		// No profitable item, show recommendation
-		log.Info().Msgf("没有达到最低利润%d的商品，推荐第%d行第%d列（利润：%d）",
-			MinimumProfit, maxRecord.Row, maxRecord.Col, maxRecord.Profit)
+		log.Info().Msgf("没有达到最低利润%d的商品，推荐%s（利润：%d，按稀有度加权：%d）",
+			floor.minProfit(maxRecord), maxRecord.Position(), maxRecord.Profit, weights.weighted(maxRecord))
 
 		// Show message with focus
-		message := fmt.Sprintf("💡 没有达到最低利润的商品，建议把配额留至明天\n推荐购买: 第%d行第%d列 (利润: %d)",
-			maxRecord.Row, maxRecord.Col, maxRecord.Profit)
+		message := fmt.Sprintf("💡 没有满足%s的商品，建议把配额留至明天\n推荐购买: %s (利润: %d%s%s)",
+			floor.describe(maxRecord), maxRecord.Position(), maxRecord.Profit, maxRecord.rarityNote(), maxRecord.friendNote())
 		ResellShowMessage(ctx, message)
+		emitResult(ctx, taskresult.StatusSkipped, records, overflowAmount, taskresult.Decision{Action: "recommend", Target: maxRecord.Position(), Reason: "below_minimum_profit"})
 		return true
 	}
 }
 
-// extractNumbersFromText - Extract all digits from text and return as integer
-func extractNumbersFromText(text string) (int, bool) {
-	re := regexp.MustCompile(`\d+`)
-	matches := re.FindAllString(text, -1)
-	if len(matches) > 0 {
-		// Concatenate all digit sequences found
-		digitsOnly := ""
-		for _, match := range matches {
-			digitsOnly += match
-		}
-		if num, err := strconv.Atoi(digitsOnly); err == nil {
-			return num, true
-		}
-	}
-	return 0, false
+// ocrExtractNumberWithCenter - OCR region using pipeline name and return number with center coordinates
+func ocrExtractNumberWithCenter(ctx *maa.Context, controller *maa.Controller, pipelineName string) (num int, centerX int, centerY int, success bool) {
+	return ocrExtractNumberAt(ctx, controller, pipelineName, nil)
 }
 
-// ocrExtractNumberWithCenter - OCR region using pipeline name and return number with center coordinates
-func ocrExtractNumberWithCenter(ctx *maa.Context, controller *maa.Controller, pipelineName string) (int, int, int, bool) {
+// ocrExtractNumberAt - 同 ocrExtractNumberWithCenter，可临时覆盖节点（例如按实测列位置平移 roi）
+func ocrExtractNumberAt(ctx *maa.Context, controller *maa.Controller, pipelineName string, override map[string]any) (num int, centerX int, centerY int, success bool) {
+	outcome := roistats.OutcomeEmpty
+	defer func() {
+		if success {
+			outcome = roistats.OutcomeParsed
+		}
+		roistats.RecordOutcome(pipelineName, outcome)
+	}()
+
 	img, err := controller.CacheImage()
 	if err != nil {
 		log.Error().
@@ -290,100 +453,53 @@ func ocrExtractNumberWithCenter(ctx *maa.Context, controller *maa.Controller, pi
 	}
 
 	// 使用 RunRecognition 调用预定义的 pipeline 节点
-	detail, err := ctx.RunRecognition(pipelineName, img, nil)
-	if err != nil {
-		log.Error().
-			Err(err).
-			Msg("[OCR] 识别失败")
-		return 0, 0, 0, false
-	}
-	if detail == nil || detail.Results == nil {
+	result := ocrutil.BatchExtract(ctx, img, []ocrutil.ROIRequest{{Pipeline: pipelineName, Override: override}})[0]
+	if !result.Hit {
+		if result.OutOfRange {
+			outcome = roistats.OutcomeOutOfBounds
+		}
 		log.Info().Str("pipeline", pipelineName).Msg("[OCR] 区域无结果")
 		return 0, 0, 0, false
 	}
-
-	// 优先从 Best 结果中提取，然后是 All
-	for _, results := range [][]*maa.RecognitionResult{detail.Results.Best, detail.Results.All} {
-		if len(results) > 0 {
-			if ocrResult, ok := results[0].AsOCR(); ok {
-				if num, success := extractNumbersFromText(ocrResult.Text); success {
-					// 计算中心坐标
-					centerX := ocrResult.Box.X() + ocrResult.Box.Width()/2
-					centerY := ocrResult.Box.Y() + ocrResult.Box.Height()/2
-					log.Info().Str("pipeline", pipelineName).Str("originText", ocrResult.Text).Int("num", num).Msg("[OCR] 区域找到数字")
-					if num >= 7000 || num <= 100 {
-						//数字不合理，抛弃
-						log.Info().Str("pipeline", pipelineName).Str("originText", ocrResult.Text).Int("num", num).Msg("[OCR] 数字不合理，抛弃")
-						success = false
-						// 如果数字>=10000，则是误识别票券为1，只保留后四位，数据仍然可用
-						if num >= 10000 {
-							adjustedNum := num % 10000
-							log.Info().Str("pipeline", pipelineName).Str("originText", ocrResult.Text).Int("originalNum", num).Int("adjustedNum", adjustedNum).Msg("[OCR] 数字>=10000，已截取后四位")
-							num = adjustedNum
-							success = true
-						}
-					}
-					return num, centerX, centerY, success
-				}
-			}
+	num, success = parsePrice(pipelineName, result.Text)
+	if !success {
+		if _, hasDigits := ocrutil.Number(result.Text); hasDigits {
+			outcome = roistats.OutcomeOutOfBounds
 		}
+		return 0, 0, 0, false
 	}
-
-	return 0, 0, 0, false
+	centerX, centerY = result.Center()
+	return num, centerX, centerY, true
 }
 
-// ocrExtractTextWithCenter - OCR region using pipeline name and check if recognized text contains keyword, return center coordinates
-func ocrExtractTextWithCenter(ctx *maa.Context, controller *maa.Controller, pipelineName string, keyword string) (bool, int, int, bool) {
-	img, err := controller.CacheImage()
-	if err != nil {
-		log.Error().
-			Err(err).
-			Msg("[OCR] 未能获取截图")
-		return false, 0, 0, false
+// parsePrice - 从 OCR 文本中提取价格并检查是否合理
+func parsePrice(pipelineName, text string) (int, bool) {
+	num, ok := ocrutil.Number(text)
+	if !ok {
+		return 0, false
 	}
-	if img == nil {
-		log.Info().Msg("[OCR] 未能获取截图")
-		return false, 0, 0, false
+	log.Info().Str("pipeline", pipelineName).Str("originText", text).Int("num", num).Msg("[OCR] 区域找到数字")
+	if plausiblePrice(num) {
+		return num, true
 	}
-
-	// 使用 RunRecognition 调用预定义的 pipeline 节点
-	detail, err := ctx.RunRecognition(pipelineName, img, nil)
-	if err != nil {
-		log.Error().
-			Err(err).
-			Msg("[OCR] 识别失败")
-		return false, 0, 0, false
-	}
-	if detail == nil || detail.Results == nil {
-		log.Info().Str("pipeline", pipelineName).Str("keyword", keyword).Msg("[OCR] 区域无对应字符")
-		return false, 0, 0, false
-	}
-
-	// 优先从 Filtered 结果中提取，然后是 Best、All
-	for _, results := range [][]*maa.RecognitionResult{detail.Results.Filtered, detail.Results.Best, detail.Results.All} {
-		if len(results) > 0 {
-			if ocrResult, ok := results[0].AsOCR(); ok {
-				if containsKeyword(ocrResult.Text, keyword) {
-					// 计算中心坐标
-					centerX := ocrResult.Box.X() + ocrResult.Box.Width()/2
-					centerY := ocrResult.Box.Y() + ocrResult.Box.Height()/2
-					log.Info().Str("pipeline", pipelineName).Str("originText", ocrResult.Text).Str("keyword", keyword).Msg("[OCR] 区域找到对应字符")
-					return true, centerX, centerY, true
-				}
-			}
-		}
+	// 如果数字>=10000，则是误识别票券为1，只保留后四位，截取后仍在合理范围内时数据可用
+	if num >= 10000 && plausiblePrice(num%10000) {
+		adjustedNum := num % 10000
+		log.Info().Str("pipeline", pipelineName).Str("originText", text).Int("originalNum", num).Int("adjustedNum", adjustedNum).Msg("[OCR] 数字>=10000，已截取后四位")
+		return adjustedNum, true
 	}
-
-	log.Info().Str("pipeline", pipelineName).Str("keyword", keyword).Msg("[OCR] 区域无对应字符")
-	return false, 0, 0, false
+	//数字不合理，抛弃
+	log.Info().Str("pipeline", pipelineName).Str("originText", text).Int("num", num).Msg("[OCR] 数字不合理，抛弃")
+	return num, false
 }
 
-// containsKeyword - Check if text contains keyword
-func containsKeyword(text, keyword string) bool {
-	return regexp.MustCompile(keyword).MatchString(text)
+// plausiblePrice - 物品价格的合理范围
+func plausiblePrice(num int) bool {
+	return num > 100 && num < 7000
 }
 
 // ResellFinishAction - Finish Resell task custom action
+// 本次运行的结果已在做出决定时输出，这里不再输出，避免重复推送 webhook 与写入历史
 type ResellFinishAction struct{}
 
 func (a *ResellFinishAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
@@ -391,21 +507,6 @@ func (a *ResellFinishAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) boo
 	return true
 }
 
-// ExecuteResellTask - Execute Resell main task
-func ExecuteResellTask(tasker *maa.Tasker) error {
-	if tasker == nil {
-		return fmt.Errorf("tasker is nil")
-	}
-
-	if !tasker.Initialized() {
-		return fmt.Errorf("tasker not initialized")
-	}
-
-	tasker.PostTask("ResellMain").Wait()
-
-	return nil
-}
-
 func Resell_delay_freezes_time(ctx *maa.Context, time int) bool {
 	ctx.RunTask("Resell_TaskDelay", map[string]interface{}{
 		"Resell_TaskDelay": map[string]interface{}{
@@ -438,77 +539,76 @@ func ocrAndParseQuota(ctx *maa.Context, controller *maa.Controller) (x int, y in
 		return x, y, hoursLater, b
 	}
 
-	// OCR region 1: 使用预定义的配额当前值Pipeline
-	detail1, err := ctx.RunRecognition("Resell_ROI_Quota_Current", img, nil)
-	if err != nil {
-		log.Error().
-			Err(err).
-			Msg("Failed to run recognition for region 1")
+	// 配额当前值与下次增加量在同一张截图上识别
+	results := ocrutil.BatchExtract(ctx, img, []ocrutil.ROIRequest{
+		{Pipeline: "Resell_ROI_Quota_Current"},
+		{Pipeline: "Resell_ROI_Quota_NextAdd"},
+	})
+	current, nextAdd := results[0], results[1]
+	if current.Err != nil || nextAdd.Err != nil {
 		return x, y, hoursLater, b
 	}
-	if detail1 != nil && detail1.Results != nil {
-		for _, results := range [][]*maa.RecognitionResult{detail1.Results.Best, detail1.Results.All} {
-			if len(results) > 0 {
-				if ocrResult, ok := results[0].AsOCR(); ok && ocrResult.Text != "" {
-					log.Info().Msgf("Quota region 1 OCR: %s", ocrResult.Text)
-					// Parse "x/y" format
-					re := regexp.MustCompile(`(\d+)/(\d+)`)
-					if matches := re.FindStringSubmatch(ocrResult.Text); len(matches) >= 3 {
-						x, _ = strconv.Atoi(matches[1])
-						y, _ = strconv.Atoi(matches[2])
-						log.Info().Msgf("Parsed quota region 1: x=%d, y=%d", x, y)
-					}
-					break
-				}
-			}
+
+	// OCR region 1: 配额当前值
+	if current.Hit {
+		log.Info().Msgf("Quota region 1 OCR: %s", current.Text)
+		// Parse "x/y" format
+		re := regexp.MustCompile(`(\d+)/(\d+)`)
+		if matches := re.FindStringSubmatch(current.Text); len(matches) >= 3 {
+			x, _ = strconv.Atoi(matches[1])
+			y, _ = strconv.Atoi(matches[2])
+			log.Info().Msgf("Parsed quota region 1: x=%d, y=%d", x, y)
 		}
 	}
 
-	// OCR region 2: 使用预定义的配额下次增加Pipeline
-	detail2, err := ctx.RunRecognition("Resell_ROI_Quota_NextAdd", img, nil)
-	if err != nil {
-		log.Error().
-			Err(err).
-			Msg("Failed to run recognition for region 2")
-		return x, y, hoursLater, b
-	}
-	if detail2 != nil && detail2.Results != nil {
-		for _, results := range [][]*maa.RecognitionResult{detail2.Results.Best, detail2.Results.All} {
-			if len(results) > 0 {
-				if ocrResult, ok := results[0].AsOCR(); ok && ocrResult.Text != "" {
-					log.Info().Msgf("Quota region 2 OCR: %s", ocrResult.Text)
-					// Try pattern with hours
-					reHours := regexp.MustCompile(`(\d+)\s*小时.*?[+]\s*(\d+)`)
-					if matches := reHours.FindStringSubmatch(ocrResult.Text); len(matches) >= 3 {
-						hoursLater, _ = strconv.Atoi(matches[1])
-						b, _ = strconv.Atoi(matches[2])
-						log.Info().Msgf("Parsed quota region 2 (hours): hoursLater=%d, b=%d", hoursLater, b)
-						break
-					}
-					// Try pattern with minutes
-					reMinutes := regexp.MustCompile(`(\d+)\s*分钟.*?[+]\s*(\d+)`)
-					if matches := reMinutes.FindStringSubmatch(ocrResult.Text); len(matches) >= 3 {
-						b, _ = strconv.Atoi(matches[2])
-						hoursLater = 0
-						log.Info().Msgf("Parsed quota region 2 (minutes): b=%d", b)
-						break
-					}
-					// Fallback: just find "+b"
-					reFallback := regexp.MustCompile(`[+]\s*(\d+)`)
-					if matches := reFallback.FindStringSubmatch(ocrResult.Text); len(matches) >= 2 {
-						b, _ = strconv.Atoi(matches[1])
-						hoursLater = 0
-						log.Info().Msgf("Parsed quota region 2 (fallback): b=%d", b)
-					}
-					break
-				}
-			}
+	// OCR region 2: 配额下次增加
+	if nextAdd.Hit {
+		text := nextAdd.Text
+		log.Info().Msgf("Quota region 2 OCR: %s", text)
+		// Try pattern with hours
+		reHours := regexp.MustCompile(`(\d+)\s*小时.*?[+]\s*(\d+)`)
+		reMinutes := regexp.MustCompile(`(\d+)\s*分钟.*?[+]\s*(\d+)`)
+		reFallback := regexp.MustCompile(`[+]\s*(\d+)`)
+		if matches := reHours.FindStringSubmatch(text); len(matches) >= 3 {
+			hoursLater, _ = strconv.Atoi(matches[1])
+			b, _ = strconv.Atoi(matches[2])
+			log.Info().Msgf("Parsed quota region 2 (hours): hoursLater=%d, b=%d", hoursLater, b)
+		} else if matches := reMinutes.FindStringSubmatch(text); len(matches) >= 3 {
+			// Try pattern with minutes
+			b, _ = strconv.Atoi(matches[2])
+			hoursLater = 0
+			log.Info().Msgf("Parsed quota region 2 (minutes): b=%d", b)
+		} else if matches := reFallback.FindStringSubmatch(text); len(matches) >= 2 {
+			// Fallback: just find "+b"
+			b, _ = strconv.Atoi(matches[1])
+			hoursLater = 0
+			log.Info().Msgf("Parsed quota region 2 (fallback): b=%d", b)
 		}
 	}
 
 	return x, y, hoursLater, b
 }
 
+// fitInventory - 按背包剩余空间限制购买数量，返回可购买数量与提示，空间未知时不限制
+func fitInventory(ctx *maa.Context, controller *maa.Controller, amount int) (int, string) {
+	controller.PostScreencap().Wait()
+	img, err := controller.CacheImage()
+	if err != nil || img == nil {
+		return amount, ""
+	}
+	space, err := purchase.ReadSpace(ctx, img, "")
+	if err != nil {
+		log.Info().Err(err).Msg("[Resell]未能识别背包容量，不限制购买数量")
+		return amount, ""
+	}
+	if space.Free() >= amount {
+		return amount, ""
+	}
+	skipped := amount - space.Free()
+	log.Warn().Int("free", space.Free()).Int("skipped", skipped).Msg("[Resell]背包空间不足")
+	return space.Free(), fmt.Sprintf("\n🎒 背包剩余 %d 格，因空间不足跳过 %d 件", space.Free(), skipped)
+}
+
 // ResellShowMessage - Show message to user with focus
 func ResellShowMessage(ctx *maa.Context, text string) bool {
 	ctx.RunTask("Resell_TaskShowMessage", map[string]interface{}{
@@ -522,3 +622,48 @@ func ResellShowMessage(ctx *maa.Context, text string) bool {
 	})
 	return true
 }
+
+// ResellShowProgress - 按提示详细程度显示扫描进度，最终结果与建议请用 ResellShowMessage
+func ResellShowProgress(ctx *maa.Context, level focus.Level, text string) bool {
+	if !focus.Allowed(level) {
+		return true
+	}
+	return ResellShowMessage(ctx, text)
+}
+
+// 识别准确率低于此值的区域会在汇总中列出
+const accuracyWarnRate = 0.9
+
+// warnROIDrift - 识别区域命中率相比历史明显下降时提醒用户重新校准，并附上各区域的累计识别准确率
+func warnROIDrift(ctx *maa.Context) {
+	drifts := roistats.Finish()
+
+	var builder strings.Builder
+	if len(drifts) > 0 {
+		builder.WriteString("⚠️ 以下识别区域近期持续识别失败，游戏界面可能已变动，需要重新校准：")
+		for _, d := range drifts {
+			log.Warn().Str("roi", d.ROI).Float64("baseline", d.Baseline).Float64("current", d.Current).Msg("[Resell]识别区域命中率下降")
+			builder.WriteString(fmt.Sprintf("\n%s（历史 %.0f%% → 近期 %.0f%%）", d.ROI, d.Baseline*100, d.Current*100))
+		}
+	}
+
+	accuracyHeader := false
+	for _, acc := range roistats.Report() {
+		log.Info().Str("roi", acc.ROI).Int("parsed", acc.Hits).Int("empty", acc.Empty).Int("out_of_bounds", acc.OutOfBounds).Int("total", acc.Total).Msg("[Resell]识别区域准确率")
+		if acc.Rate() >= accuracyWarnRate {
+			continue
+		}
+		if !accuracyHeader {
+			if builder.Len() > 0 {
+				builder.WriteString("\n")
+			}
+			builder.WriteString("📊 识别准确率偏低的区域（解析成功/无结果/数值越界），可考虑调整 ROI：")
+			accuracyHeader = true
+		}
+		builder.WriteString(fmt.Sprintf("\n%s：%.0f%%（%d/%d/%d）", acc.ROI, acc.Rate()*100, acc.Hits, acc.Empty, acc.OutOfBounds))
+	}
+
+	if builder.Len() > 0 {
+		ResellShowMessage(ctx, builder.String())
+	}
+}
@@ -0,0 +1,85 @@
+package resell
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		want   int
+		wantOK bool
+	}{
+		{"plain", "1234", 1234, true},
+		{"separator", "1,234", 1234, true},
+		{"ticket read as 1", "11234", 1234, true},
+		{"ticket read as 1, five digits", "16999", 6999, true},
+		{"ticket salvage still implausible", "10000", 10000, false},
+		{"ticket salvage above range", "18500", 18500, false},
+		{"too low", "100", 100, false},
+		{"too high", "7000", 7000, false},
+		{"between 7000 and 10000", "8500", 8500, false},
+		{"no digits", "价格", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parsePrice("Test", tt.text)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parsePrice(%q) = %d, %v, want %d, %v", tt.text, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestProfitRuleStock(t *testing.T) {
+	rule, err := parseProfitRule("cost*0.1+stock*10")
+	if err != nil {
+		t.Fatalf("parseProfitRule: %v", err)
+	}
+	t.Cleanup(func() { currentStock = -1 })
+	record := ProfitRecord{CostPrice: 1000, SalePrice: 1500}
+
+	currentStock = 5
+	if got := rule.threshold(record); got != 150 {
+		t.Errorf("threshold with stock 5 = %d, want 150", got)
+	}
+	currentStock = -1
+	if got := rule.threshold(record); got != math.MaxInt32 {
+		t.Errorf("threshold with unknown stock = %d, want %d", got, math.MaxInt32)
+	}
+
+	if _, err := parseProfitRule("cost*0.1+quantity"); err == nil {
+		t.Error("parseProfitRule accepted an unknown variable")
+	}
+}
+
+func TestSameItemName(t *testing.T) {
+	tests := []struct {
+		name   string
+		ocr    string
+		target string
+		want   bool
+	}{
+		{"exact", "嵌晶玉", "嵌晶玉", true},
+		{"whitespace ignored", "嵌 晶 玉", "嵌晶玉", true},
+		{"short exact", "源石", "源石", true},
+		{"one char fragment", "玉", "嵌晶玉", false},
+		{"two char fragment", "嵌晶", "嵌晶玉", false},
+		{"fragment of long name", "晶玉", "高级嵌晶玉原石", false},
+		{"near miss on short name", "嵌晶王", "嵌晶玉", false},
+		{"different item sharing a prefix", "嵌晶玉原石", "嵌晶玉", false},
+		{"extra trailing char", "嵌晶玉。", "嵌晶玉", true},
+		{"one misread in long name", "高级嵌晶王原石", "高级嵌晶玉原石", true},
+		{"two misreads in long name", "高级嵌品王原石", "高级嵌晶玉原石", false},
+		{"empty ocr", "", "嵌晶玉", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameItemName(tt.ocr, tt.target); got != tt.want {
+				t.Errorf("sameItemName(%q, %q) = %v, want %v", tt.ocr, tt.target, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package resell
+
+import (
+	"encoding/json"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/taskresult"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// resultFocusNode - 输出 JSON 结果的节点，GUI 按节点名识别，只读取 Node.Action.Succeeded 的 focus，不当作提示文字显示
+const resultFocusNode = "Resell_TaskResultJSON"
+
+// resultRecord - JSON 结果中的一件商品，字段名保持稳定供 GUI 渲染表格
+type resultRecord struct {
+	Position  string `json:"position"`
+	Source    string `json:"source,omitempty"`
+	Page      int    `json:"page,omitempty"`
+	Row       int    `json:"row"`
+	Col       int    `json:"col"`
+	Item      string `json:"item,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Friend    string `json:"friend,omitempty"`
+	Rarity    int    `json:"rarity,omitempty"`
+	CostPrice int    `json:"costPrice"`
+	SalePrice int    `json:"salePrice"`
+	Profit    int    `json:"profit"`
+}
+
+// resultPayload - 通过 focus 输出的机器可读结果
+type resultPayload struct {
+	Type           string              `json:"type"`
+	Status         taskresult.Status   `json:"status"`
+	Records        []resultRecord      `json:"records"`
+	MaxProfit      int                 `json:"maxProfit"`
+	OverflowAmount int                 `json:"overflowAmount"`
+	Decision       taskresult.Decision `json:"decision"`
+}
+
+// emitResult - 输出本次倒卖的结构化结果，并把扫描结果写入历史记录
+func emitResult(ctx *maa.Context, status taskresult.Status, records []ProfitRecord, overflowAmount int, decision taskresult.Decision) {
+	metrics := map[string]float64{
+		"scanned":        float64(len(records)),
+		"quota_overflow": float64(overflowAmount),
+	}
+	if best, ok := bestRecord(records, nil, rarityWeights{}); ok {
+		metrics["max_profit"] = float64(best.Profit)
+	}
+	taskresult.Emit(ctx, taskresult.Result{
+		Task:      "Resell",
+		Status:    status,
+		Metrics:   metrics,
+		Decisions: []taskresult.Decision{decision},
+	})
+	emitResultJSON(ctx, status, records, overflowAmount, decision)
+	recordHistory(records, decision)
+}
+
+// emitResultJSON - 在 Resell_TaskResultJSON 节点的 focus 中输出 JSON 结果，供 GUI 展示商品表格
+func emitResultJSON(ctx *maa.Context, status taskresult.Status, records []ProfitRecord, overflowAmount int, decision taskresult.Decision) {
+	payload := resultPayload{
+		Type:           "resell_result",
+		Status:         status,
+		Records:        make([]resultRecord, 0, len(records)),
+		OverflowAmount: overflowAmount,
+		Decision:       decision,
+	}
+	for _, r := range records {
+		payload.Records = append(payload.Records, resultRecord{
+			Position:  r.Position(),
+			Source:    r.Source,
+			Page:      r.Page,
+			Row:       r.Row,
+			Col:       r.Col,
+			Item:      r.Item,
+			Name:      r.Name,
+			Friend:    r.Friend,
+			Rarity:    r.Rarity,
+			CostPrice: r.CostPrice,
+			SalePrice: r.SalePrice,
+			Profit:    r.Profit,
+		})
+	}
+	if best, ok := bestRecord(records, nil, rarityWeights{}); ok {
+		payload.MaxProfit = best.Profit
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Error().Err(err).Msg("[Resell]序列化 JSON 结果失败")
+		return
+	}
+	ctx.RunTask(resultFocusNode, map[string]interface{}{
+		resultFocusNode: map[string]interface{}{
+			"recognition": "DirectHit",
+			"action":      "DoNothing",
+			"focus": map[string]interface{}{
+				"Node.Action.Succeeded": string(data),
+			},
+		},
+	})
+}
@@ -0,0 +1,196 @@
+package resell
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/expr"
+	"github.com/rs/zerolog/log"
+)
+
+// profitRule - 最低利润规则，可以是固定值，也可以是按商品计算的表达式
+// 表达式可用变量：cost（成本价）、salePrice（好友出售价）、rarity（稀有度，未识别为 0）、
+// stock（当前配额，即还能买入的数量；未识别配额时引用它的表达式计算失败），例如 "cost*0.1+50"
+type profitRule struct {
+	fixed int
+	expr  *expr.Expr
+}
+
+func parseProfitRule(text string) (profitRule, error) {
+	text = strings.TrimSpace(text)
+	if n, err := strconv.Atoi(text); err == nil {
+		return profitRule{fixed: n}, nil
+	}
+	e, err := expr.Parse(text)
+	if err != nil {
+		return profitRule{}, err
+	}
+	if e.IsCondition() {
+		return profitRule{}, fmt.Errorf("最低利润应为数值表达式，条件请填写在选品策略中")
+	}
+	// 用示例值试算一次，尽早暴露未知变量
+	if _, err := e.Eval(sampleVars()); err != nil {
+		return profitRule{}, err
+	}
+	return profitRule{expr: e}, nil
+}
+
+func (r profitRule) String() string {
+	if r.expr != nil {
+		return r.expr.String()
+	}
+	return strconv.Itoa(r.fixed)
+}
+
+// threshold - 该商品需要达到的最低利润
+func (r profitRule) threshold(record ProfitRecord) int {
+	if r.expr == nil {
+		return r.fixed
+	}
+	v, err := r.expr.Eval(recordVars(record))
+	if err != nil {
+		log.Error().Err(err).Str("expr", r.expr.String()).Msg("[Resell]最低利润表达式计算失败，视为不达标")
+		return math.MaxInt32
+	}
+	return int(math.Ceil(v))
+}
+
+// currentStock - 本次运行识别到的当前配额，未识别时为 -1
+var currentStock = -1
+
+func recordVars(record ProfitRecord) map[string]float64 {
+	vars := map[string]float64{
+		"cost":      float64(record.CostPrice),
+		"salePrice": float64(record.SalePrice),
+		"profit":    float64(record.SalePrice - record.CostPrice),
+		"rarity":    float64(record.Rarity),
+	}
+	if currentStock >= 0 {
+		vars["stock"] = float64(currentStock)
+	}
+	return vars
+}
+
+// sampleVars - 解析时试算用的示例值，包含全部变量
+func sampleVars() map[string]float64 {
+	vars := recordVars(ProfitRecord{CostPrice: 1000, SalePrice: 1000})
+	vars["stock"] = 10
+	return vars
+}
+
+// profitFloor - 购买前需同时满足的最低利润与最低利润率
+type profitFloor struct {
+	rule    profitRule
+	weights rarityWeights
+	// margin - 最低利润率（利润 / 成本价，百分比），0 表示不限制，避免低价商品凭很小的利润达标
+	margin int
+	// policy - 选品策略，为条件时也要成立
+	policy *expr.Expr
+}
+
+// minProfit - 该商品生效的最低利润，稀有度单独配置时覆盖 rule
+func (f profitFloor) minProfit(record ProfitRecord) int {
+	return f.weights.threshold(record, f.rule.threshold(record))
+}
+
+// meets - 按稀有度加权后的利润与利润率都达标
+func (f profitFloor) meets(record ProfitRecord) bool {
+	if f.weights.weighted(record) < f.minProfit(record) {
+		return false
+	}
+	if f.margin > 0 && record.marginPercent() < float64(f.margin) {
+		return false
+	}
+	return policyAllows(f.policy, record)
+}
+
+// describe - 该商品生效的规则，如 "利润≥100 且利润率≥10%"
+func (f profitFloor) describe(record ProfitRecord) string {
+	text := fmt.Sprintf("利润≥%d", f.minProfit(record))
+	if f.margin > 0 {
+		text += fmt.Sprintf(" 且利润率≥%d%%", f.margin)
+	}
+	if f.policy != nil && f.policy.IsCondition() {
+		text += fmt.Sprintf(" 且满足「%s」", f.policy)
+	}
+	return text
+}
+
+// marginPercent - 利润占成本价的百分比，成本价未识别时为 0
+func (r ProfitRecord) marginPercent() float64 {
+	if r.CostPrice <= 0 {
+		return 0
+	}
+	return float64(r.Profit) * 100 / float64(r.CostPrice)
+}
+
+// parseDecisionPolicy - 自定义选品策略，可用变量同最低利润表达式，另加 profit（利润）
+// 条件（最外层为比较或 && || !），如 "profit >= 1500 && rarity >= 4"：条件不成立的商品不买，其余按利润选品
+// 其余表达式为打分，如 "profit - cost*0.05"：对每件商品求值，分数最高者作为候选
+func parseDecisionPolicy(text string) (*expr.Expr, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, nil
+	}
+	e, err := expr.Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := e.Eval(sampleVars()); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// policyAllows - 选品策略为条件时，条件成立的商品才可购买；打分策略或未设置策略时总是允许
+func policyAllows(policy *expr.Expr, record ProfitRecord) bool {
+	if policy == nil || !policy.IsCondition() {
+		return true
+	}
+	ok, err := policy.EvalBool(recordVars(record))
+	if err != nil {
+		log.Error().Err(err).Str("expr", policy.String()).Str("位置", record.Position()).Msg("[Resell]选品策略计算失败，视为不满足")
+		return false
+	}
+	return ok
+}
+
+// filterByPolicy - 去掉选品策略条件不成立的商品
+func filterByPolicy(records []ProfitRecord, policy *expr.Expr) []ProfitRecord {
+	if policy == nil || !policy.IsCondition() {
+		return records
+	}
+	var allowed []ProfitRecord
+	for _, record := range records {
+		if policyAllows(policy, record) {
+			allowed = append(allowed, record)
+		}
+	}
+	return allowed
+}
+
+// bestRecord - 选出候选商品；未设置策略或策略为条件时取按稀有度加权后利润最高者，同分取靠前的一件
+func bestRecord(records []ProfitRecord, policy *expr.Expr, weights rarityWeights) (ProfitRecord, bool) {
+	best := -1
+	bestScore := math.Inf(-1)
+	for i, record := range records {
+		score := float64(weights.weighted(record))
+		if policy != nil && !policy.IsCondition() {
+			v, err := policy.Eval(recordVars(record))
+			if err != nil {
+				log.Error().Err(err).Str("expr", policy.String()).Str("位置", record.Position()).Msg("[Resell]选品策略计算失败，跳过该商品")
+				continue
+			}
+			score = v
+		}
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return ProfitRecord{}, false
+	}
+	return records[best], true
+}
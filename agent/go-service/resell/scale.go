@@ -0,0 +1,71 @@
+package resell
+
+import (
+	"strings"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/coord"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/overridesnap"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// scale - 本次运行的截图相对 1280x720 基准的缩放，ResellInitAction 中测得
+var scale = coord.Identity
+
+// resellNodes - 资源中所有 Resell 开头的节点，每次开始时恢复、必要时缩放的范围
+func resellNodes(ctx *maa.Context, extra ...string) []string {
+	nodes := append([]string(nil), extra...)
+	list, err := ctx.GetTasker().GetResource().GetNodeList()
+	if err != nil {
+		log.Warn().Err(err).Msg("[Resell]获取节点列表失败")
+		return nodes
+	}
+	for _, name := range list {
+		if strings.HasPrefix(name, "Resell") {
+			nodes = append(nodes, name)
+		}
+	}
+	return nodes
+}
+
+// measureScale - 按当前截图的尺寸测量缩放比例，截图失败时按基准处理
+func measureScale(controller *maa.Controller) coord.Scale {
+	controller.PostScreencap().Wait()
+	img, err := controller.CacheImage()
+	if err != nil || img == nil {
+		return coord.Identity
+	}
+	return coord.FromImage(img)
+}
+
+// applyScale - 截图不是基准分辨率时，按比例缩放节点中固定的 roi、点击区域与偏移
+// 引用其他节点的 roi 会跟随被引用节点，无需处理；模板图片仍是 720p 的，不在此列
+func applyScale(ctx *maa.Context, nodes []string, s coord.Scale) {
+	override := map[string]any{}
+	for _, node := range nodes {
+		fields := map[string]any{}
+		for _, f := range []struct{ section, key string }{
+			{"recognition", "roi"},
+			{"recognition", "roi_offset"},
+			{"action", "target"},
+			{"action", "target_offset"},
+		} {
+			r, ok := nodeArray(ctx, node, f.section, f.key)
+			if !ok || r == [4]int{} {
+				continue
+			}
+			fields[f.key] = s.Rect(r)
+		}
+		if len(fields) > 0 {
+			override[node] = fields
+		}
+	}
+	if len(override) == 0 {
+		return
+	}
+	if err := overridesnap.Apply(ctx, "Resell", override); err != nil {
+		log.Error().Err(err).Msg("[Resell]按分辨率缩放节点失败")
+		return
+	}
+	log.Info().Float64("x", s.X).Float64("y", s.Y).Int("nodes", len(override)).Msg("[Resell]截图不是 720p，已按比例缩放节点坐标")
+}
@@ -0,0 +1,390 @@
+package resell
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/focus"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/itemicon"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/ocrutil"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/pricewatch"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/roistats"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/waitnode"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// shelfProfile describes the grid layout of one shop tab
+type shelfProfile struct {
+	// Name - 货架标识，写入 ProfitRecord.Source
+	Name string
+	// Label - 货架名称，用于提示信息
+	Label string
+	// PricePipelineFormat - 商品价格区域节点名，参数为行、列
+	PricePipelineFormat string
+	// SelectTaskFormat - 选择商品的节点名，参数为行、列
+	SelectTaskFormat string
+	Rows             int
+	Cols             int
+}
+
+var (
+	mainShelfProfile = shelfProfile{
+		Name:                "main",
+		Label:               "常规",
+		PricePipelineFormat: "Resell_ROI_Product_Row%d_Col%d_Price",
+		SelectTaskFormat:    "ResellSelectProductRow%dCol%d",
+		Rows:                3,
+		Cols:                8,
+	}
+	specialShelfProfile = shelfProfile{
+		Name:                "special",
+		Label:               "特惠",
+		PricePipelineFormat: "Resell_ROI_Special_Row%d_Col%d_Price",
+		SelectTaskFormat:    "ResellSelectSpecialRow%dCol%d",
+		Rows:                1,
+		Cols:                8,
+	}
+)
+
+// 未配置 step_timeout 时等待界面出现的最长时间
+const defaultStepTimeout = 3 * time.Second
+
+// 切换页签的节点
+const (
+	switchSpecialTabTask = "ResellSwitchSpecialTab"
+	switchMainTabTask    = "ResellSwitchMainTab"
+)
+
+// currentShelf - 当前显示的货架，购买前据此切换到商品所在的页签
+var currentShelf = mainShelfProfile.Name
+
+// requiredNodes - Go 代码引用的节点，搜索相关节点为可选，不在此列
+func requiredNodes() []string {
+	nodes := []string{
+		"ResellMain",
+		"ResellSelectProductConfirm",
+		"Resell_ROI_ViewFriendPrice",
+		"Resell_ROI_DetailCostPrice",
+		"Resell_ROI_FriendSalePrice",
+		"Resell_ROI_ReturnButton",
+		"Resell_ROI_Quota_Current",
+		"Resell_ROI_Quota_NextAdd",
+	}
+	nodes = append(nodes, switchSpecialTabTask, switchMainTabTask)
+	for _, profile := range []shelfProfile{mainShelfProfile, specialShelfProfile} {
+		for row := 1; row <= profile.Rows; row++ {
+			for col := 1; col <= profile.Cols; col++ {
+				nodes = append(nodes,
+					fmt.Sprintf(profile.PricePipelineFormat, row, col),
+					fmt.Sprintf(profile.SelectTaskFormat, row, col),
+				)
+			}
+		}
+	}
+	return nodes
+}
+
+func shelfProfileByName(name string) shelfProfile {
+	if name == specialShelfProfile.Name {
+		return specialShelfProfile
+	}
+	return mainShelfProfile
+}
+
+func shelfLabel(name string) string {
+	return shelfProfileByName(name).Label
+}
+
+// selectTaskName - 购买该商品需要跳转的节点名
+func selectTaskName(record ProfitRecord) string {
+	profile := shelfProfileByName(record.Source)
+	return fmt.Sprintf(profile.SelectTaskFormat, record.Row, record.Col)
+}
+
+// scanSpecialOffers - 切换到特惠页签扫描后切回常规货架，页签不可用时返回空
+func scanSpecialOffers(ctx *maa.Context, controller *maa.Controller) []ProfitRecord {
+	for _, name := range []string{switchSpecialTabTask, switchMainTabTask} {
+		if raw, err := ctx.GetNodeJSON(name); err != nil || raw == "" {
+			log.Info().Str("pipeline", name).Msg("[Resell]资源中未定义特惠页签节点，跳过特惠扫描")
+			return nil
+		}
+	}
+
+	if !switchShelf(ctx, specialShelfProfile.Name) {
+		return nil
+	}
+	records := scanShelf(ctx, controller, specialShelfProfile, 0)
+	switchShelf(ctx, mainShelfProfile.Name)
+	return records
+}
+
+// switchShelf - 切换到 name 对应的页签，已在该页签时不操作
+func switchShelf(ctx *maa.Context, name string) bool {
+	if currentShelf == name {
+		return true
+	}
+	task := switchMainTabTask
+	if name == specialShelfProfile.Name {
+		task = switchSpecialTabTask
+	}
+	log.Info().Str("货架", shelfLabel(name)).Msg("[Resell]切换页签")
+	if detail, err := ctx.RunTask(task); err != nil || detail == nil || !detail.Status.Success() {
+		log.Error().Err(err).Str("货架", shelfLabel(name)).Msg("[Resell]切换页签失败")
+		return false
+	}
+	currentShelf = name
+	return true
+}
+
+// priceHit - 预扫描识别到的商品价格与点击位置
+type priceHit struct {
+	price int
+	x, y  int
+}
+
+// prescanPrices - 在同一张截图上批量识别整个货架的价格，识别成功的格子不再单独截图
+// 首轮按节点 roi 识别，然后从命中的价格框测量列间距，按实测位置重试未命中的格子
+func prescanPrices(ctx *maa.Context, controller *maa.Controller, profile shelfProfile) (image.Image, map[[2]int]priceHit, columnLayout) {
+	hits := make(map[[2]int]priceHit)
+	controller.PostScreencap().Wait()
+	img, err := controller.CacheImage()
+	if err != nil || img == nil {
+		log.Warn().Err(err).Msg("[Resell]预扫描截图失败，改为逐格识别")
+		return nil, hits, columnLayout{}
+	}
+
+	var reqs []ocrutil.ROIRequest
+	var cells [][2]int
+	for row := 1; row <= profile.Rows; row++ {
+		for col := 1; col <= profile.Cols; col++ {
+			reqs = append(reqs, ocrutil.ROIRequest{Pipeline: fmt.Sprintf(profile.PricePipelineFormat, row, col)})
+			cells = append(cells, [2]int{row, col})
+		}
+	}
+
+	collectHits(ctx, img, reqs, cells, hits)
+
+	layout := measureColumns(hits)
+	var retryReqs []ocrutil.ROIRequest
+	var retryCells [][2]int
+	for i, cell := range cells {
+		if _, ok := hits[cell]; ok {
+			continue
+		}
+		if override := layout.priceOverride(ctx, reqs[i].Pipeline, cell[0], cell[1]); override != nil {
+			retryReqs = append(retryReqs, ocrutil.ROIRequest{Pipeline: reqs[i].Pipeline, Override: override})
+			retryCells = append(retryCells, cell)
+		}
+	}
+	if len(retryReqs) > 0 {
+		log.Info().Str("货架", profile.Label).Str("列间距", layout.String()).Int("格子", len(retryReqs)).Msg("[Resell]列位置与节点不符，按实测列间距重试")
+		collectHits(ctx, img, retryReqs, retryCells, hits)
+	}
+	checkPriceStrips(ctx, img, profile, hits)
+
+	log.Info().Str("货架", profile.Label).Int("命中", len(hits)).Int("总数", len(reqs)).Str("列间距", layout.String()).Msg("[Resell]价格预扫描完成")
+	return img, hits, measureColumns(hits)
+}
+
+func collectHits(ctx *maa.Context, img image.Image, reqs []ocrutil.ROIRequest, cells [][2]int, hits map[[2]int]priceHit) {
+	for i, result := range ocrutil.BatchExtract(ctx, img, reqs) {
+		if !result.Hit {
+			continue
+		}
+		price, ok := parsePrice(result.Pipeline, result.Text)
+		if !ok {
+			continue
+		}
+		// 未命中的格子会在逐格识别时再记录一次，这里只记录命中
+		roistats.Record(result.Pipeline, true)
+		x, y := result.Center()
+		hits[cells[i]] = priceHit{price: price, x: x, y: y}
+	}
+}
+
+// waitStep - 等待某一步的界面出现，代替固定延时后只识别一次
+func waitStep(ctx *maa.Context, cfg agentconfig.ResellConfig, node string) waitnode.Result {
+	timeout := time.Duration(cfg.StepTimeout) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultStepTimeout
+	}
+	result := waitnode.Any(ctx, waitnode.Options{Timeout: timeout}, waitnode.Condition{Node: node})
+	roistats.Record(node, result.Hit())
+	return result
+}
+
+// identifyItem - 按图标识别商品卡片上的物品
+func identifyItem(ctx *maa.Context, img image.Image, centerX, centerY int) string {
+	if img == nil {
+		return ""
+	}
+	rect := cardRect(centerX, centerY).Intersect(img.Bounds())
+	name, score, ok := itemicon.Identify(ctx, img, maa.Rect{rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy()})
+	if !ok {
+		return ""
+	}
+	log.Info().Str("item", name).Float64("score", score).Msg("[Resell]图标识别商品")
+	return name
+}
+
+// scanShelf - 逐格识别货架上每件商品的成本价与好友出售价，page 为常规货架翻页后的页码，不翻页时为 0
+func scanShelf(ctx *maa.Context, controller *maa.Controller, profile shelfProfile, page int) []ProfitRecord {
+	records := make([]ProfitRecord, 0)
+	cfg := agentconfig.Get().Resell
+
+	ResellShowProgress(ctx, focus.Step, fmt.Sprintf("🔍 正在扫描%s货架", profile.Label))
+	Resell_delay_freezes_time(ctx, cfg.ScanDelay)
+	prescanImg, prescan, layout := prescanPrices(ctx, controller, profile)
+
+	// For each row
+	for rowIdx := 0; rowIdx < profile.Rows; rowIdx++ {
+		log.Info().Str("货架", profile.Label).Int("行", rowIdx+1).Msg("[Resell]当前处理")
+
+		// For each column
+		for col := 1; col <= profile.Cols; col++ {
+			log.Info().Int("行", rowIdx+1).Int("列", col).Msg("[Resell]商品位置")
+			// 暂停期间可能被用户改动过画面，以下检查点发生过暂停时重新扫描当前格子
+			position := ProfitRecord{Row: rowIdx + 1, Col: col, Source: profile.Name, Page: page}
+			if _, stopped := checkpoint(ctx, position); stopped {
+				return records
+			}
+			// Step 1: 识别商品价格
+			log.Info().Msg("[Resell]第一步：识别商品价格")
+			Resell_delay_freezes_time(ctx, cfg.ScanDelay)
+
+			var costPrice, clickX, clickY int
+			var img image.Image
+			if hit, ok := prescan[[2]int{rowIdx + 1, col}]; ok {
+				costPrice, clickX, clickY = hit.price, hit.x, hit.y
+				img = prescanImg
+			} else {
+				// 构建Pipeline名称
+				pricePipelineName := fmt.Sprintf(profile.PricePipelineFormat, rowIdx+1, col)
+				override := layout.priceOverride(ctx, pricePipelineName, rowIdx+1, col)
+				var success bool
+				if costPrice, clickX, clickY, success = readPrice(ctx, controller, pricePipelineName, override); !success {
+					log.Info().Int("行", rowIdx+1).Int("列", col).Msg("[Resell]位置无数字，说明无商品，下一行")
+					break
+				}
+				img, _ = controller.CacheImage()
+			}
+
+			// 保存商品卡片缩略图，便于核对报告中的位置
+			thumbSource := profile.Name
+			if page > 1 {
+				thumbSource = fmt.Sprintf("%s_p%d", profile.Name, page)
+			}
+			thumbnail := saveThumbnail(img, clickX, clickY, thumbSource, rowIdx+1, col)
+			item := identifyItem(ctx, img, clickX, clickY)
+			rarity := detectRarity(ctx, img, clickX, clickY)
+
+			if paused, stopped := checkpoint(ctx, position); stopped {
+				return records
+			} else if paused {
+				col--
+				continue
+			}
+			// Click on product
+			controller.PostClick(int32(clickX), int32(clickY))
+
+			// Step 2: 等待商品详情页出现“查看好友价格”
+			log.Info().Msg("[Resell]第二步：查看好友价格")
+			wait := waitStep(ctx, cfg, "Resell_ROI_ViewFriendPrice")
+			if !wait.Hit() {
+				log.Info().Dur("等待", wait.Elapsed).Msg("[Resell]第二步：未找到“好友”字样")
+				continue
+			}
+			friendBtnX, friendBtnY := wait.Center()
+			//商品详情页右下角识别的成本价格为准
+			if confirmCostPrice, _, _, success := readPrice(ctx, controller, "Resell_ROI_DetailCostPrice", nil); success {
+				costPrice = confirmCostPrice
+			} else {
+				log.Info().Msg("[Resell]第二步：未能识别商品详情页成本价格，继续使用列表页识别的价格")
+			}
+			log.Info().Int("行", rowIdx+1).Int("列", col).Int("Cost", costPrice).Msg("[Resell]商品售价")
+			name := readItemName(ctx, controller)
+			if paused, stopped := checkpoint(ctx, position); stopped {
+				return records
+			} else if paused {
+				col--
+				continue
+			}
+			// 单击"查看好友价格"按钮
+			controller.PostClick(int32(friendBtnX), int32(friendBtnY))
+
+			// Step 3: 检查好友列表第一位的出售价，即最高价格
+			log.Info().Msg("[Resell]第三步：识别好友出售价")
+			//等加载好友价格
+			Resell_delay_freezes_time(ctx, cfg.FriendPriceDelay)
+
+			// 优先识别整个列表以便排除好友、记录出价好友，列表识别不到时只读第一位
+			var salePrice int
+			var friend string
+			if offers := readFriendOffers(ctx, controller); len(offers) > 0 {
+				best, ok := pickOffer(offers)
+				if !ok {
+					log.Info().Msg("[Resell]第三步：好友价格均已排除，跳过该商品")
+					continue
+				}
+				salePrice, friend = best.Price, best.Name
+			} else if price, _, _, success := readPrice(ctx, controller, "Resell_ROI_FriendSalePrice", nil); success {
+				salePrice = price
+			} else {
+				log.Info().Msg("[Resell]第三步：未能识别好友出售价，跳过该商品")
+				continue
+			}
+			log.Info().Int("Price", salePrice).Str("friend", friend).Msg("[Resell]好友出售价")
+			// 计算利润
+			profit := salePrice - costPrice
+			log.Info().Int("Profit", profit).Msg("[Resell]当前商品利润")
+
+			// Save record with row and column information
+			record := ProfitRecord{
+				Row:       rowIdx + 1,
+				Col:       col,
+				CostPrice: costPrice,
+				SalePrice: salePrice,
+				Profit:    profit,
+				Source:    profile.Name,
+				Thumbnail: thumbnail,
+				Item:      item,
+				Name:      name,
+				Friend:    friend,
+				Rarity:    rarity,
+				ClickX:    clickX,
+				ClickY:    clickY,
+				Page:      page,
+			}
+			records = append(records, record)
+			ResellShowProgress(ctx, focus.Detail, fmt.Sprintf("%s：成本 %d，售价 %d，利润 %d%s%s",
+				record.Position(), costPrice, salePrice, profit, record.rarityNote(), record.friendNote()))
+			pricewatch.Check(ctx, []pricewatch.Observation{{Item: item, Shop: "倒卖", Price: costPrice, SalePrice: salePrice}})
+
+			// 该格已记录，暂停后由用户关闭详情页，恢复时直接扫描下一格
+			if paused, stopped := checkpoint(ctx, position); stopped {
+				return records
+			} else if paused {
+				continue
+			}
+
+			// Step 4: 等待页面右上角的“返回”按钮，按ESC返回
+			log.Info().Msg("[Resell]第四步：返回商品详情页")
+			if waitStep(ctx, cfg, "Resell_ROI_ReturnButton").Hit() {
+				log.Info().Msg("[Resell]第四步：发现返回按钮，按ESC返回")
+				controller.PostClickKey(27)
+			}
+
+			// Step 5: 等待“查看好友价格”重新出现，按ESC关闭页面
+			log.Info().Msg("[Resell]第五步：关闭商品详情页")
+			if waitStep(ctx, cfg, "Resell_ROI_ViewFriendPrice").Hit() {
+				log.Info().Msg("[Resell]第五步：关闭页面")
+				controller.PostClickKey(27)
+			}
+		}
+	}
+
+	return records
+}
@@ -0,0 +1,236 @@
+package resell
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/focus"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/itemicon"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/ocrutil"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/pricewatch"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// 搜索模式使用的节点
+const (
+	searchBoxPipelineName        = "Resell_ROI_SearchBox"
+	searchResultPipelineName     = "Resell_ROI_SearchResultPrice"
+	searchResultNamePipelineName = "Resell_ROI_SearchResultName"
+)
+
+// parseItemList - Convert "A;B" -> ["A", "B"]
+func parseItemList(text string) []string {
+	var items []string
+	for _, part := range strings.Split(text, ";") {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+	return items
+}
+
+// searchModeAvailable - Check whether resources define the search box nodes
+func searchModeAvailable(ctx *maa.Context) bool {
+	for _, name := range []string{searchBoxPipelineName, searchResultPipelineName, searchResultNamePipelineName} {
+		raw, err := ctx.GetNodeJSON(name)
+		if err != nil || raw == "" {
+			log.Info().Str("pipeline", name).Msg("[Resell]资源中未定义搜索节点")
+			return false
+		}
+	}
+	return true
+}
+
+// recognizeCenter - Run recognition on cached image and return center of hit box
+func recognizeCenter(ctx *maa.Context, controller *maa.Controller, pipelineName string) (int, int, bool) {
+	img, err := controller.CacheImage()
+	if err != nil || img == nil {
+		log.Error().Err(err).Msg("[Resell]未能获取截图")
+		return 0, 0, false
+	}
+	detail, err := ctx.RunRecognition(pipelineName, img, nil)
+	if err != nil {
+		log.Error().Err(err).Str("pipeline", pipelineName).Msg("[Resell]识别失败")
+		return 0, 0, false
+	}
+	if detail == nil || !detail.Hit {
+		return 0, 0, false
+	}
+	return detail.Box.X() + detail.Box.Width()/2, detail.Box.Y() + detail.Box.Height()/2, true
+}
+
+// sameItemName - OCR 名称与目标名称去掉空白后相同，或编辑距离不超过较长一方的 1/4 即视为同一物品
+// 任一方少于 3 个字时只接受完全相同，避免 1-2 个字的 OCR 片段被当作目标物品
+func sameItemName(ocr, target string) bool {
+	a := []rune(strings.Join(strings.Fields(ocr), ""))
+	b := []rune(strings.Join(strings.Fields(target), ""))
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+	if string(a) == string(b) {
+		return true
+	}
+	if min(len(a), len(b)) < 3 {
+		return false
+	}
+	return editDistance(a, b)*4 <= max(len(a), len(b))
+}
+
+// editDistance - 按字计算的 Levenshtein 距离
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// readSearchResultName - 识别搜索结果卡片上的物品名
+func readSearchResultName(ctx *maa.Context, controller *maa.Controller) string {
+	var name string
+	ocrRetry.Do(controller, searchResultNamePipelineName, func() bool {
+		img, err := controller.CacheImage()
+		if err != nil || img == nil {
+			return false
+		}
+		result := ocrutil.BatchExtract(ctx, img, []ocrutil.ROIRequest{{Pipeline: searchResultNamePipelineName}})[0]
+		name = strings.TrimSpace(result.Text)
+		return result.Hit && name != ""
+	})
+	return name
+}
+
+// appraiseSearchResult - 在已打开的商品详情页读取成本价与好友出售价，读完后回到详情页
+func appraiseSearchResult(ctx *maa.Context, controller *maa.Controller, item string, costPrice int) (ProfitRecord, bool) {
+	cfg := agentconfig.Get().Resell
+	wait := waitStep(ctx, cfg, "Resell_ROI_ViewFriendPrice")
+	if !wait.Hit() {
+		log.Info().Str("item", item).Msg("[Resell]搜索结果未打开商品详情页")
+		return ProfitRecord{}, false
+	}
+	if confirmCostPrice, _, _, success := readPrice(ctx, controller, "Resell_ROI_DetailCostPrice", nil); success {
+		costPrice = confirmCostPrice
+	}
+	name := readItemName(ctx, controller)
+	if name != "" && !sameItemName(name, item) {
+		log.Info().Str("item", item).Str("name", name).Msg("[Resell]详情页物品名与搜索目标不符")
+		return ProfitRecord{}, false
+	}
+
+	friendBtnX, friendBtnY := wait.Center()
+	controller.PostClick(int32(friendBtnX), int32(friendBtnY))
+	Resell_delay_freezes_time(ctx, cfg.FriendPriceDelay)
+
+	var salePrice int
+	var friend string
+	if offers := readFriendOffers(ctx, controller); len(offers) > 0 {
+		best, ok := pickOffer(offers)
+		if !ok {
+			return ProfitRecord{}, false
+		}
+		salePrice, friend = best.Price, best.Name
+	} else if price, _, _, success := readPrice(ctx, controller, "Resell_ROI_FriendSalePrice", nil); success {
+		salePrice = price
+	} else {
+		log.Info().Str("item", item).Msg("[Resell]未能识别搜索商品的好友出售价")
+		return ProfitRecord{}, false
+	}
+
+	// 从好友价格页返回商品详情页，购买从详情页继续
+	if waitStep(ctx, cfg, "Resell_ROI_ReturnButton").Hit() {
+		controller.PostClickKey(27)
+	}
+	waitStep(ctx, cfg, "Resell_ROI_ViewFriendPrice")
+
+	return ProfitRecord{
+		Row:       1,
+		Col:       1,
+		CostPrice: costPrice,
+		SalePrice: salePrice,
+		Profit:    salePrice - costPrice,
+		Item:      item,
+		Name:      name,
+		Friend:    friend,
+	}, true
+}
+
+// searchAndSelect - 在搜索框中依次输入目标商品名，核对结果名称后打开详情页读取好友出售价，
+// 利润达标时停在详情页等待购买，否则关闭详情页搜索下一件
+// Returns false when search is unavailable or nothing qualified, so caller can fall back to grid scan
+func searchAndSelect(ctx *maa.Context, controller *maa.Controller, items []string, floor profitFloor) (ProfitRecord, bool) {
+	if !searchModeAvailable(ctx) {
+		return ProfitRecord{}, false
+	}
+
+	for _, item := range items {
+		Resell_delay_freezes_time(ctx, 200)
+		controller.PostScreencap().Wait()
+
+		boxX, boxY, ok := recognizeCenter(ctx, controller, searchBoxPipelineName)
+		if !ok {
+			log.Info().Msg("[Resell]未找到搜索框，回退到逐格扫描")
+			return ProfitRecord{}, false
+		}
+
+		log.Info().Str("item", item).Msg("[Resell]搜索商品")
+		controller.PostClick(int32(boxX), int32(boxY)).Wait()
+		controller.PostInputText(item).Wait()
+		controller.PostClickKey(13).Wait()
+
+		Resell_delay_freezes_time(ctx, 300)
+		controller.PostScreencap().Wait()
+
+		costPrice, clickX, clickY, success := ocrExtractNumberWithCenter(ctx, controller, searchResultPipelineName)
+		if !success {
+			log.Info().Str("item", item).Msg("[Resell]搜索无结果")
+			continue
+		}
+
+		// 结果名称必须与目标一致，避免名称相近的物品被误买
+		if name := readSearchResultName(ctx, controller); !sameItemName(name, item) {
+			log.Info().Str("item", item).Str("name", name).Msg("[Resell]搜索结果名称与目标不符，跳过")
+			continue
+		}
+		// 图标库中有该物品时，再用图标校验一次
+		if itemicon.Known(ctx, item) {
+			img, _ := controller.CacheImage()
+			rect := cardRect(clickX, clickY)
+			if img == nil || !itemicon.Matches(ctx, img, maa.Rect{rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy()}, item) {
+				log.Info().Str("item", item).Msg("[Resell]搜索结果图标与目标不符，跳过")
+				continue
+			}
+		}
+
+		controller.PostClick(int32(clickX), int32(clickY))
+		record, ok := appraiseSearchResult(ctx, controller, item, costPrice)
+		if ok {
+			pricewatch.Check(ctx, []pricewatch.Observation{{Item: item, Shop: "倒卖", Price: record.CostPrice, SalePrice: record.SalePrice}})
+		}
+		if ok && floor.meets(record) {
+			log.Info().Str("item", item).Int("Cost", record.CostPrice).Int("Price", record.SalePrice).Int("Profit", record.Profit).Msg("[Resell]搜索命中商品，利润达标")
+			return record, true
+		}
+		if ok {
+			log.Info().Str("item", item).Int("Profit", record.Profit).Str("规则", floor.describe(record)).Msg("[Resell]搜索商品利润不达标，跳过")
+			ResellShowProgress(ctx, focus.Detail, fmt.Sprintf("%s：利润 %d，不满足%s", item, record.Profit, floor.describe(record)))
+		}
+		// 关闭详情页，回到搜索结果
+		controller.PostClickKey(27).Wait()
+	}
+
+	return ProfitRecord{}, false
+}
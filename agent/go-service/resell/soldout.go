@@ -0,0 +1,56 @@
+package resell
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+	"github.com/rs/zerolog/log"
+)
+
+// soldOutState - 商店售罄后预计补货的时间，持久化后重复运行可以直接跳过
+type soldOutState struct {
+	Until time.Time `json:"until"`
+}
+
+func soldOutPath() string {
+	return datadir.Path("resell", "sold_out.json")
+}
+
+// markSoldOut - 记录售罄，until 取配额区域显示的下次增加时间
+func markSoldOut(until time.Time) {
+	data, err := json.Marshal(soldOutState{Until: until})
+	if err != nil {
+		return
+	}
+	path := soldOutPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Warn().Err(err).Msg("[Resell]创建售罄记录目录失败")
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Warn().Err(err).Msg("[Resell]写入售罄记录失败")
+	}
+}
+
+// clearSoldOut - 扫描到商品后删除售罄记录
+func clearSoldOut() {
+	if err := os.Remove(soldOutPath()); err != nil && !os.IsNotExist(err) {
+		log.Warn().Err(err).Msg("[Resell]删除售罄记录失败")
+	}
+}
+
+// soldOutUntil - 仍在售罄记录的补货时间之前时返回该时间
+func soldOutUntil(now time.Time) (time.Time, bool) {
+	data, err := os.ReadFile(soldOutPath())
+	if err != nil {
+		return time.Time{}, false
+	}
+	var state soldOutState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return time.Time{}, false
+	}
+	return state.Until, now.Before(state.Until)
+}
@@ -0,0 +1,88 @@
+package resell
+
+import (
+	"fmt"
+	"image"
+	"sort"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/digitstrip"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/ocrutil"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// checkPriceStrips - 每行用一次数字条 OCR 复核预扫描的价格，与逐格结果不一致的格子丢弃、改为逐格识别
+// 同时比较整行数字与节点 roi 的水平偏差，偏差超过容差时提示布局可能已变化
+func checkPriceStrips(ctx *maa.Context, img image.Image, profile shelfProfile, hits map[[2]int]priceHit) {
+	if img == nil || len(hits) == 0 {
+		return
+	}
+	if raw, err := ctx.GetNodeJSON(digitstrip.OCRNode); err != nil || raw == "" {
+		return
+	}
+
+	for row := 1; row <= profile.Rows; row++ {
+		first, okFirst := nodeRect(ctx, fmt.Sprintf(profile.PricePipelineFormat, row, 1), "recognition", "roi")
+		last, okLast := nodeRect(ctx, fmt.Sprintf(profile.PricePipelineFormat, row, profile.Cols), "recognition", "roi")
+		if !okFirst || !okLast {
+			continue
+		}
+		strip := maa.Rect{first[0], first[1], last[0] + last[2] - first[0], max(first[3], last[3])}
+		numbers, err := digitstrip.Read(ctx, img, strip)
+		if err != nil {
+			log.Debug().Err(err).Int("行", row).Msg("[Resell]数字条识别失败")
+			continue
+		}
+		if len(numbers) == 0 {
+			continue
+		}
+
+		var offsets []int
+		for col := 1; col <= profile.Cols; col++ {
+			cell := [2]int{row, col}
+			roi, ok := nodeRect(ctx, fmt.Sprintf(profile.PricePipelineFormat, row, col), "recognition", "roi")
+			if !ok {
+				continue
+			}
+			expectedX := roi[0] + roi[2]/2
+			n, found := nearestNumber(numbers, expectedX, roi[2]/2)
+			if !found {
+				continue
+			}
+			offsets = append(offsets, n.CenterX()-expectedX)
+
+			hit, ok := hits[cell]
+			if !ok || n.Value == hit.price {
+				continue
+			}
+			log.Warn().Str("货架", profile.Label).Int("行", row).Int("列", col).
+				Int("逐格", hit.price).Int("数字条", n.Value).
+				Msg("[Resell]价格与数字条不一致，改为逐格重新识别")
+			delete(hits, cell)
+		}
+
+		if len(offsets) >= 2 {
+			sort.Ints(offsets)
+			if drift := offsets[len(offsets)/2]; abs(drift) > scale.Xi(columnTolerance) {
+				log.Warn().Str("货架", profile.Label).Int("行", row).Int("偏移", drift).
+					Msg("[Resell]整行价格位置与节点 roi 有偏差，界面布局可能已变化")
+			}
+		}
+	}
+}
+
+// nearestNumber - 中心离 x 最近且不超过 within 的数字
+func nearestNumber(numbers []ocrutil.StripNumber, x, within int) (ocrutil.StripNumber, bool) {
+	var best ocrutil.StripNumber
+	found := false
+	for _, n := range numbers {
+		d := abs(n.CenterX() - x)
+		if d > within {
+			continue
+		}
+		if !found || d < abs(best.CenterX()-x) {
+			best, found = n, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,25 @@
+package resell
+
+import (
+	"fmt"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/takeover"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// checkpoint - 扫描每一步之前的检查点，用户请求接管时在此停止操作并等待恢复
+// paused 为 true 表示发生过暂停，画面可能已被用户改动；stopped 为 true 表示暂停期间任务被停止
+func checkpoint(ctx *maa.Context, position ProfitRecord) (paused, stopped bool) {
+	if !takeover.Paused() {
+		return false, false
+	}
+	log.Info().Str("位置", position.Position()).Msg("[Resell]已暂停，等待用户恢复")
+	ResellShowMessage(ctx, fmt.Sprintf("⏸️ 已在%s暂停，请手动回到货架页面后恢复", position.Position()))
+	if !takeover.Wait(ctx) {
+		return true, true
+	}
+	log.Info().Str("位置", position.Position()).Msg("[Resell]已恢复扫描")
+	ResellShowMessage(ctx, fmt.Sprintf("▶️ 已恢复，从%s继续扫描", position.Position()))
+	return true, false
+}
@@ -0,0 +1,132 @@
+package resell
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// whatIfOutcome - 某个假设的最低利润下，历史扫描会得到的决定
+type whatIfOutcome struct {
+	Rule string
+	// Latest - 最近一次扫描会购买的商品，不购买时为 nil
+	Latest *historyRecord
+	// LatestQualified - 最近一次扫描中达标的商品件数
+	LatestQualified int
+	// Buys, Profit - 统计期内会购买的次数与这些购买的利润合计，每次运行只买利润最高的一件
+	Buys   int
+	Profit int
+}
+
+// simulate - 按最低利润规则重新评估每次运行，只比较利润，不考虑稀有度倍率与自定义选品策略
+func simulate(runs []historyRun, rule profitRule) whatIfOutcome {
+	outcome := whatIfOutcome{Rule: rule.String()}
+	for i, run := range runs {
+		var best *historyRecord
+		qualified := 0
+		for j := range run.Records {
+			r := &run.Records[j]
+			record := ProfitRecord{CostPrice: r.CostPrice, SalePrice: r.SalePrice, Profit: r.Profit}
+			if r.Profit < rule.threshold(record) {
+				continue
+			}
+			qualified++
+			if best == nil || r.Profit > best.Profit {
+				best = r
+			}
+		}
+		if best != nil {
+			outcome.Buys++
+			outcome.Profit += best.Profit
+		}
+		if i == len(runs)-1 {
+			outcome.Latest, outcome.LatestQualified = best, qualified
+		}
+	}
+	return outcome
+}
+
+// actualPurchases - 统计期内实际购买的次数与利润，用于和假设对比
+func actualPurchases(runs []historyRun) (buys, profit int) {
+	for _, run := range runs {
+		for _, r := range run.Records {
+			if r.Purchased {
+				buys++
+				profit += r.Profit
+			}
+		}
+	}
+	return buys, profit
+}
+
+func (o whatIfOutcome) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "最低利润 %s：", o.Rule)
+	if o.Latest == nil {
+		b.WriteString("最近一次不购买")
+	} else {
+		fmt.Fprintf(&b, "最近一次%d件达标，购买%s（利润 %d）", o.LatestQualified, o.Latest.position(), o.Latest.Profit)
+	}
+	fmt.Fprintf(&b, "；统计期内购买%d次，利润%d", o.Buys, o.Profit)
+	return b.String()
+}
+
+// ResellWhatIfAction - 用历史扫描结果试算不同的最低利润会做出什么决定，帮助选择合适的阈值
+// custom_action_param: {"thresholds": "1000;2000;cost*0.2", "days": 30}
+type ResellWhatIfAction struct{}
+
+func (a *ResellWhatIfAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	params := struct {
+		Thresholds string `json:"thresholds"`
+		Days       int    `json:"days"`
+	}{Thresholds: "1000;2000;3000;4000", Days: 30}
+	if arg.CustomActionParam != "" {
+		if err := json.Unmarshal([]byte(arg.CustomActionParam), &params); err != nil {
+			log.Error().Err(err).Msg("[Resell]反序列化失败")
+			return false
+		}
+	}
+
+	var rules []profitRule
+	for _, text := range parseItemList(params.Thresholds) {
+		rule, err := parseProfitRule(text)
+		if err != nil {
+			log.Error().Err(err).Str("threshold", text).Msg("[Resell]假设的最低利润无法解析")
+			ResellShowMessage(ctx, fmt.Sprintf("⚠️ 最低利润「%s」无法解析：%v", text, err))
+			return false
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		ResellShowMessage(ctx, "⚠️ 没有填写要试算的最低利润")
+		return false
+	}
+
+	runs, err := loadHistory(time.Now().AddDate(0, 0, -max(params.Days, 1)))
+	if err != nil {
+		log.Error().Err(err).Msg("[Resell]读取历史记录失败")
+		ResellShowMessage(ctx, "⚠️ 读取倒卖历史记录失败")
+		return false
+	}
+	if len(runs) == 0 {
+		ResellShowMessage(ctx, "📊 还没有倒卖历史记录，先运行几次一键倒卖再试算")
+		return true
+	}
+
+	buys, profit := actualPurchases(runs)
+	var b strings.Builder
+	fmt.Fprintf(&b, "🧮 按最近%d天的%d次扫描试算（实际购买%d次，利润%d）", params.Days, len(runs), buys, profit)
+	for _, rule := range rules {
+		outcome := simulate(runs, rule)
+		log.Info().Str("rule", outcome.Rule).Int("buys", outcome.Buys).Int("profit", outcome.Profit).Int("latest_qualified", outcome.LatestQualified).Msg("[Resell]最低利润试算")
+		b.WriteString("\n")
+		b.WriteString(outcome.String())
+	}
+	b.WriteString("\n只比较利润，不计稀有度倍率与选品策略")
+	ResellShowMessage(ctx, b.String())
+	return true
+}
@@ -0,0 +1,98 @@
+package respack
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// Root - 资源包所在目录，每个子目录是一个资源包
+var Root = filepath.Join(".", "resource_packs")
+
+var (
+	mu     sync.Mutex
+	active *Pack
+)
+
+// Active returns the pack loaded on top of the current resources, if any
+func Active() (Pack, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	if active == nil {
+		return Pack{}, false
+	}
+	return *active, true
+}
+
+func setActive(p *Pack) {
+	mu.Lock()
+	defer mu.Unlock()
+	active = p
+}
+
+// Loader loads the resource pack selected in config before the first task of each resource set
+type Loader struct {
+	// loadedHash - 已处理过的资源哈希（加载资源包之后的），客户端重新加载资源后会变化
+	loadedHash string
+}
+
+// OnTaskerTask handles tasker task events
+func (l *Loader) OnTaskerTask(tasker *maa.Tasker, event maa.EventStatus, detail maa.TaskerTaskDetail) {
+	if event != maa.EventStatusStarting {
+		return
+	}
+	res := tasker.GetResource()
+	if res == nil {
+		return
+	}
+	hash, err := res.GetHash()
+	if err != nil || hash == l.loadedHash {
+		return
+	}
+	l.loadedHash = hash
+	setActive(nil)
+
+	name := agentconfig.Get().ResourcePack
+	if name == "" {
+		return
+	}
+	packs, err := Discover(Root)
+	if err != nil {
+		log.Error().Err(err).Str("root", Root).Msg("Failed to list resource packs")
+		return
+	}
+	pack, ok := Find(packs, name)
+	if !ok {
+		log.Error().Str("pack", name).Str("root", Root).Msg("Selected resource pack not found")
+		printWarning(fmt.Sprintf("未找到资源包 %s，已使用默认资源", name))
+		return
+	}
+
+	report := Validate(pack)
+	if !report.OK() {
+		log.Error().Str("pack", name).Strs("problems", report.Problems).Msg("Resource pack is invalid, not loaded")
+		printWarning(fmt.Sprintf("资源包 %s 校验未通过，已使用默认资源：%s", pack.Label(), strings.Join(report.Problems, "；")))
+		return
+	}
+
+	if !res.PostBundle(pack.Path).Wait().Success() {
+		log.Error().Str("pack", name).Str("path", pack.Path).Msg("Failed to load resource pack")
+		printWarning(fmt.Sprintf("资源包 %s 加载失败", pack.Label()))
+		return
+	}
+	if hash, err := res.GetHash(); err == nil {
+		l.loadedHash = hash
+	}
+	setActive(&pack)
+	log.Info().Str("pack", name).Str("channel", pack.Channel).Str("version", pack.Version).Int("nodes", len(report.Nodes)).Msg("Resource pack loaded")
+	fmt.Printf("<span style=\"color: #1890ff;\">📦 已加载资源包 %s，覆盖 %d 个节点</span>\n", pack.Label(), len(report.Nodes))
+}
+
+func printWarning(text string) {
+	fmt.Printf("<span style=\"color: #faad14; font-weight: bold;\">⚠️ %s</span>\n", text)
+}
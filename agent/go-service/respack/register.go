@@ -0,0 +1,12 @@
+package respack
+
+import "github.com/MaaXYZ/maa-framework-go/v4"
+
+var (
+	_ maa.TaskerEventSink = &Loader{}
+)
+
+// Register registers the resource pack loader as a tasker sink
+func Register() {
+	maa.AgentServerAddTaskerSink(&Loader{})
+}
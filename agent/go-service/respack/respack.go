@@ -0,0 +1,140 @@
+package respack
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/jsonc"
+)
+
+// manifestFile - 资源包目录下可选的说明文件
+const manifestFile = "pack.json"
+
+// Pack - 资源包目录下的一个子目录，结构与 resource 相同（pipeline、image 等），加载时叠加在客户端资源之上
+type Pack struct {
+	Name string `json:"name"`
+	// Channel - stable、beta 或 custom，仅用于展示
+	Channel     string `json:"channel"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Path        string `json:"-"`
+}
+
+// Label returns the pack name with its channel and version, for messages
+func (p Pack) Label() string {
+	var extra []string
+	for _, s := range []string{p.Channel, p.Version} {
+		if s != "" {
+			extra = append(extra, s)
+		}
+	}
+	if len(extra) == 0 {
+		return p.Name
+	}
+	return fmt.Sprintf("%s（%s）", p.Name, strings.Join(extra, "，"))
+}
+
+// Report - 资源包的校验结果
+type Report struct {
+	// Nodes - 资源包定义的节点，新增或覆盖客户端资源中的同名节点
+	Nodes    []string
+	Problems []string
+}
+
+// OK reports whether the pack can be loaded
+func (r Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Discover lists the packs under root, sorted by name; a missing root means no packs
+func Discover(root string) ([]Pack, error) {
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var packs []Pack
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		pack := Pack{Name: entry.Name(), Channel: "custom"}
+		path := filepath.Join(root, entry.Name())
+		if data, err := os.ReadFile(filepath.Join(path, manifestFile)); err == nil {
+			if err := json.Unmarshal(data, &pack); err != nil {
+				return nil, fmt.Errorf("%s: %w", filepath.Join(path, manifestFile), err)
+			}
+		}
+		// 目录名是配置中引用资源包的名字，pack.json 中的 name 只影响展示
+		if pack.Name == "" {
+			pack.Name = entry.Name()
+		}
+		pack.Path = path
+		packs = append(packs, pack)
+	}
+	sort.Slice(packs, func(i, j int) bool { return filepath.Base(packs[i].Path) < filepath.Base(packs[j].Path) })
+	return packs, nil
+}
+
+// Find returns the pack whose directory is name
+func Find(packs []Pack, name string) (Pack, bool) {
+	for _, p := range packs {
+		if filepath.Base(p.Path) == name {
+			return p, true
+		}
+	}
+	return Pack{}, false
+}
+
+// Validate checks that the pack has a pipeline directory whose files all parse
+func Validate(pack Pack) Report {
+	var report Report
+	pipelineDir := filepath.Join(pack.Path, "pipeline")
+	if info, err := os.Stat(pipelineDir); err != nil || !info.IsDir() {
+		report.Problems = append(report.Problems, "缺少 pipeline 目录")
+		return report
+	}
+
+	seen := map[string]string{}
+	filepath.WalkDir(pipelineDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			report.Problems = append(report.Problems, err.Error())
+			return nil
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		rel, _ := filepath.Rel(pack.Path, path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			report.Problems = append(report.Problems, fmt.Sprintf("%s: %v", rel, err))
+			return nil
+		}
+		var nodes map[string]json.RawMessage
+		if err := json.Unmarshal(jsonc.Strip(data), &nodes); err != nil {
+			report.Problems = append(report.Problems, fmt.Sprintf("%s: %v", rel, err))
+			return nil
+		}
+		for name := range nodes {
+			if prev, ok := seen[name]; ok {
+				report.Problems = append(report.Problems, fmt.Sprintf("%s: 节点 %s 与 %s 重复", rel, name, prev))
+				continue
+			}
+			seen[name] = rel
+			report.Nodes = append(report.Nodes, name)
+		}
+		return nil
+	})
+	if report.OK() && len(report.Nodes) == 0 {
+		report.Problems = append(report.Problems, "pipeline 目录中没有节点")
+	}
+	sort.Strings(report.Nodes)
+	return report
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/respack"
+)
+
+// runResourcePacks - go-service resource-packs [-root resource_packs]
+// 列出资源包并逐个校验，标出配置中选中的资源包
+func runResourcePacks(args []string) error {
+	fs := flag.NewFlagSet("resource-packs", flag.ContinueOnError)
+	root := fs.String("root", respack.Root, "directory containing resource packs")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	packs, err := respack.Discover(*root)
+	if err != nil {
+		return err
+	}
+	cfg, _ := agentconfig.Load(filepath.Join(getCwd(), "config", "go-service.json"))
+	if len(packs) == 0 {
+		fmt.Printf("no resource packs in %s\n", *root)
+	}
+
+	invalid := 0
+	for _, pack := range packs {
+		dir := filepath.Base(pack.Path)
+		mark := " "
+		if dir == cfg.ResourcePack {
+			mark = "*"
+		}
+		report := respack.Validate(pack)
+		fmt.Printf("%s %s  %s  %d nodes\n", mark, dir, pack.Label(), len(report.Nodes))
+		for _, problem := range report.Problems {
+			fmt.Printf("    ! %s\n", problem)
+		}
+		if !report.OK() {
+			invalid++
+		}
+	}
+	if cfg.ResourcePack != "" {
+		if _, ok := respack.Find(packs, cfg.ResourcePack); !ok {
+			return fmt.Errorf("selected resource pack %q not found in %s", cfg.ResourcePack, *root)
+		}
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d resource pack(s) failed validation", invalid)
+	}
+	return nil
+}
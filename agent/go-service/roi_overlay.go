@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/roioverlay"
+)
+
+// runROIOverlay - go-service roi-overlay -module Resell -screenshot shot.png [-out overlay.png] [-pipeline dir]
+// 把模块所有固定 roi 画到截图上，并在标准输出打印编号对应的节点
+func runROIOverlay(args []string) error {
+	fs := flag.NewFlagSet("roi-overlay", flag.ContinueOnError)
+	module := fs.String("module", "", "pipeline module name, e.g. Resell")
+	screenshot := fs.String("screenshot", "", "screenshot to draw on (png or jpeg)")
+	out := fs.String("out", "", "output png, defaults to <screenshot>_roi.png")
+	pipelineDir := fs.String("pipeline", filepath.Join(getCwd(), "resource", "pipeline"), "pipeline directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *module == "" || *screenshot == "" {
+		fs.Usage()
+		return fmt.Errorf("-module and -screenshot are required")
+	}
+	if *out == "" {
+		ext := filepath.Ext(*screenshot)
+		*out = (*screenshot)[:len(*screenshot)-len(ext)] + "_roi.png"
+	}
+
+	regions, err := roioverlay.Load(*pipelineDir, *module)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(*screenshot)
+	if err != nil {
+		return err
+	}
+	img, _, err := image.Decode(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("decode %s: %w", *screenshot, err)
+	}
+
+	composite := roioverlay.Render(img, regions)
+	w, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	if err := png.Encode(w, composite); err != nil {
+		return err
+	}
+
+	for i, r := range regions {
+		fmt.Printf("%3d  %-40s %-16s [%d, %d, %d, %d]\n", i+1, r.Node, r.Field,
+			r.Rect.Min.X, r.Rect.Min.Y, r.Rect.Dx(), r.Rect.Dy())
+	}
+	fmt.Printf("%d regions written to %s\n", len(regions), *out)
+	return nil
+}
@@ -0,0 +1,116 @@
+package roioverlay
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/jsonc"
+)
+
+// Region - 一个节点上配置的固定 roi（720p 基准坐标）
+type Region struct {
+	Node string
+	// Field - roi 来源字段，如 roi、target、all_of[2].roi
+	Field string
+	Rect  image.Rectangle
+}
+
+// Load collects the fixed ROIs of every node in the module's pipeline files.
+// A module matches <module>.json and every file under <module>/ in pipelineDir;
+// ROIs that reference another node by name are skipped.
+func Load(pipelineDir, module string) ([]Region, error) {
+	var files []string
+	if f := filepath.Join(pipelineDir, module+".json"); fileExists(f) {
+		files = append(files, f)
+	}
+	_ = filepath.WalkDir(filepath.Join(pipelineDir, module), func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && strings.HasSuffix(path, ".json") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no pipeline files for module %s in %s", module, pipelineDir)
+	}
+
+	var regions []Region
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var nodes map[string]map[string]any
+		if err := json.Unmarshal(jsonc.Strip(data), &nodes); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		for name, node := range nodes {
+			regions = append(regions, nodeRegions(name, node)...)
+		}
+	}
+	sort.SliceStable(regions, func(i, j int) bool {
+		if regions[i].Node != regions[j].Node {
+			return regions[i].Node < regions[j].Node
+		}
+		return regions[i].Field < regions[j].Field
+	})
+	return regions, nil
+}
+
+// nodeRegions - 同时支持扁平写法与 recognition/action 嵌套 param 写法，以及 And/Or 的子识别
+func nodeRegions(name string, node map[string]any) []Region {
+	var regions []Region
+	add := func(field string, v any) {
+		if r, ok := toRect(v); ok {
+			regions = append(regions, Region{Node: name, Field: field, Rect: r})
+		}
+	}
+
+	add("roi", node["roi"])
+	add("target", node["target"])
+	for _, key := range []string{"recognition", "action"} {
+		if m, ok := node[key].(map[string]any); ok {
+			if param, ok := m["param"].(map[string]any); ok {
+				add(key+".roi", param["roi"])
+				add(key+".target", param["target"])
+			}
+		}
+	}
+	for _, key := range []string{"all_of", "any_of"} {
+		items, _ := node[key].([]any)
+		for i, item := range items {
+			if sub, ok := item.(map[string]any); ok {
+				add(fmt.Sprintf("%s[%d].roi", key, i), sub["roi"])
+			}
+		}
+	}
+	return regions
+}
+
+func toRect(v any) (image.Rectangle, bool) {
+	arr, ok := v.([]any)
+	if !ok || len(arr) != 4 {
+		return image.Rectangle{}, false
+	}
+	var n [4]int
+	for i, x := range arr {
+		f, ok := x.(float64)
+		if !ok {
+			return image.Rectangle{}, false
+		}
+		n[i] = int(f)
+	}
+	if n[2] <= 0 || n[3] <= 0 {
+		return image.Rectangle{}, false
+	}
+	return image.Rect(n[0], n[1], n[0]+n[2], n[1]+n[3]), true
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
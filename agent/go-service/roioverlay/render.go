@@ -0,0 +1,105 @@
+package roioverlay
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// 720p 基准分辨率，所有 roi 均以此为坐标系
+const (
+	baseWidth  = 1280
+	baseHeight = 720
+)
+
+var palette = []color.RGBA{
+	{255, 64, 64, 255},
+	{64, 200, 64, 255},
+	{64, 128, 255, 255},
+	{255, 192, 0, 255},
+	{200, 64, 255, 255},
+	{0, 200, 200, 255},
+}
+
+// Render draws every region onto a copy of the screenshot, scaled from 720p to the screenshot size.
+// Each region gets a translucent fill, so overlapping regions show up darker, an outline,
+// and its index in regions drawn at the top-left corner.
+func Render(screenshot image.Image, regions []Region) *image.RGBA {
+	bounds := screenshot.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(out, out.Bounds(), screenshot, bounds.Min, draw.Src)
+
+	sx := float64(bounds.Dx()) / baseWidth
+	sy := float64(bounds.Dy()) / baseHeight
+	for i, region := range regions {
+		r := image.Rect(
+			int(float64(region.Rect.Min.X)*sx), int(float64(region.Rect.Min.Y)*sy),
+			int(float64(region.Rect.Max.X)*sx), int(float64(region.Rect.Max.Y)*sy),
+		).Intersect(out.Bounds())
+		if r.Empty() {
+			continue
+		}
+		c := palette[i%len(palette)]
+		fill := color.RGBA{c.R / 4, c.G / 4, c.B / 4, 64}
+		draw.Draw(out, r, &image.Uniform{fill}, image.Point{}, draw.Over)
+		outline(out, r, c)
+		drawNumber(out, r.Min.X+2, r.Min.Y+2, i+1, c)
+	}
+	return out
+}
+
+func outline(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	for x := r.Min.X; x < r.Max.X; x++ {
+		img.SetRGBA(x, r.Min.Y, c)
+		img.SetRGBA(x, r.Max.Y-1, c)
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		img.SetRGBA(r.Min.X, y, c)
+		img.SetRGBA(r.Max.X-1, y, c)
+	}
+}
+
+// 3x5 点阵数字
+var digitGlyphs = [10][5]string{
+	{"###", "#.#", "#.#", "#.#", "###"},
+	{".#.", "##.", ".#.", ".#.", "###"},
+	{"###", "..#", "###", "#..", "###"},
+	{"###", "..#", "###", "..#", "###"},
+	{"#.#", "#.#", "###", "..#", "..#"},
+	{"###", "#..", "###", "..#", "###"},
+	{"###", "#..", "###", "#.#", "###"},
+	{"###", "..#", "..#", "..#", "..#"},
+	{"###", "#.#", "###", "#.#", "###"},
+	{"###", "#.#", "###", "..#", "###"},
+}
+
+// drawNumber - 以 2 倍大小绘制数字
+func drawNumber(img *image.RGBA, x, y, n int, c color.RGBA) {
+	const scale = 2
+	digits := []int{}
+	for {
+		digits = append([]int{n % 10}, digits...)
+		n /= 10
+		if n == 0 {
+			break
+		}
+	}
+	for _, d := range digits {
+		for row, line := range digitGlyphs[d] {
+			for col, ch := range line {
+				if ch != '#' {
+					continue
+				}
+				for dy := 0; dy < scale; dy++ {
+					for dx := 0; dx < scale; dx++ {
+						px, py := x+col*scale+dx, y+row*scale+dy
+						if image.Pt(px, py).In(img.Bounds()) {
+							img.SetRGBA(px, py, c)
+						}
+					}
+				}
+			}
+		}
+		x += 4 * scale
+	}
+}
@@ -0,0 +1,222 @@
+package roistats
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// Number of runs kept per ROI
+	historyLimit = 20
+	// Minimum attempts in a run for it to count
+	minAttempts = 3
+	// Minimum number of earlier runs required to form a baseline
+	minBaselineRuns = 3
+	// Number of latest runs that must all be failing to report drift
+	driftRuns = 2
+	// Baseline hit rate above which a region is considered reliable
+	reliableRate = 0.8
+	// Hit rate below which a run is considered failing
+	failingRate = 0.3
+)
+
+// Outcome - result of one recognition attempt
+type Outcome int
+
+const (
+	// OutcomeParsed - a usable value was read
+	OutcomeParsed Outcome = iota
+	// OutcomeEmpty - nothing was recognized, or the text held no value
+	OutcomeEmpty
+	// OutcomeOutOfBounds - a value was read but failed the sanity check
+	OutcomeOutOfBounds
+)
+
+// RunStat - recognition outcome counts of one ROI in one run
+// Hits counts parsed values; misses are split into Empty and OutOfBounds
+type RunStat struct {
+	Hits        int `json:"hits"`
+	Total       int `json:"total"`
+	Empty       int `json:"empty,omitempty"`
+	OutOfBounds int `json:"out_of_bounds,omitempty"`
+}
+
+// Rate returns the hit rate of the run
+func (s RunStat) Rate() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Total)
+}
+
+// Drift - a ROI whose recent hit rate dropped well below its baseline
+type Drift struct {
+	ROI      string
+	Baseline float64
+	Current  float64
+}
+
+var (
+	mu      sync.Mutex
+	current = map[string]*RunStat{}
+)
+
+// statsPath - 各区域历次运行的识别统计
+func statsPath() string {
+	return datadir.Path("roistats", "roi_stats.json")
+}
+
+// Accuracy - outcome totals of one ROI across all recorded runs
+type Accuracy struct {
+	ROI string
+	RunStat
+}
+
+// Record records one recognition attempt for the named ROI in the current run
+func Record(roi string, hit bool) {
+	if hit {
+		RecordOutcome(roi, OutcomeParsed)
+	} else {
+		RecordOutcome(roi, OutcomeEmpty)
+	}
+}
+
+// RecordOutcome records one recognition attempt with its detailed outcome
+func RecordOutcome(roi string, outcome Outcome) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	stat, ok := current[roi]
+	if !ok {
+		stat = &RunStat{}
+		current[roi] = stat
+	}
+	stat.Total++
+	switch outcome {
+	case OutcomeParsed:
+		stat.Hits++
+	case OutcomeEmpty:
+		stat.Empty++
+	case OutcomeOutOfBounds:
+		stat.OutOfBounds++
+	}
+}
+
+// Report returns the cross-run outcome totals of every ROI, least accurate first
+func Report() []Accuracy {
+	mu.Lock()
+	defer mu.Unlock()
+
+	report := make([]Accuracy, 0)
+	for roi, runs := range load() {
+		acc := Accuracy{ROI: roi}
+		for _, r := range runs {
+			acc.Hits += r.Hits
+			acc.Total += r.Total
+			acc.Empty += r.Empty
+			acc.OutOfBounds += r.OutOfBounds
+		}
+		if acc.Total > 0 {
+			report = append(report, acc)
+		}
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Rate() != report[j].Rate() {
+			return report[i].Rate() < report[j].Rate()
+		}
+		return report[i].ROI < report[j].ROI
+	})
+	return report
+}
+
+// Finish appends the current run to the persisted history, resets the run
+// and returns ROIs that show a drift pattern
+func Finish() []Drift {
+	mu.Lock()
+	defer mu.Unlock()
+
+	history := load()
+	for roi, stat := range current {
+		if stat.Total < minAttempts {
+			continue
+		}
+		runs := append(history[roi], *stat)
+		if len(runs) > historyLimit {
+			runs = runs[len(runs)-historyLimit:]
+		}
+		history[roi] = runs
+	}
+	current = map[string]*RunStat{}
+
+	if err := save(history); err != nil {
+		log.Warn().Err(err).Str("path", statsPath()).Msg("Failed to save ROI stats")
+	}
+
+	return detectDrift(history)
+}
+
+func detectDrift(history map[string][]RunStat) []Drift {
+	drifts := make([]Drift, 0)
+	for roi, runs := range history {
+		if len(runs) < minBaselineRuns+driftRuns {
+			continue
+		}
+		recent := runs[len(runs)-driftRuns:]
+		failing := true
+		var recentStat RunStat
+		for _, r := range recent {
+			if r.Rate() >= failingRate {
+				failing = false
+				break
+			}
+			recentStat.Hits += r.Hits
+			recentStat.Total += r.Total
+		}
+		if !failing {
+			continue
+		}
+
+		var baseStat RunStat
+		for _, r := range runs[:len(runs)-driftRuns] {
+			baseStat.Hits += r.Hits
+			baseStat.Total += r.Total
+		}
+		if baseStat.Rate() < reliableRate {
+			continue
+		}
+
+		drifts = append(drifts, Drift{ROI: roi, Baseline: baseStat.Rate(), Current: recentStat.Rate()})
+	}
+	sort.Slice(drifts, func(i, j int) bool { return drifts[i].ROI < drifts[j].ROI })
+	return drifts
+}
+
+func load() map[string][]RunStat {
+	history := map[string][]RunStat{}
+	data, err := os.ReadFile(statsPath())
+	if err != nil {
+		return history
+	}
+	if err := json.Unmarshal(data, &history); err != nil {
+		log.Warn().Err(err).Str("path", statsPath()).Msg("Failed to parse ROI stats, starting over")
+		return map[string][]RunStat{}
+	}
+	return history
+}
+
+func save(history map[string][]RunStat) error {
+	if err := os.MkdirAll(filepath.Dir(statsPath()), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(statsPath(), data, 0644)
+}
@@ -0,0 +1,172 @@
+package safemode
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/envsnap"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+const (
+	// logTailBytes - 诊断包附带的 go-service 日志末尾长度
+	logTailBytes = 2 << 20
+	// screenshotLimit - 单次运行最多保存的截图数，避免长时间运行占满磁盘
+	screenshotLimit = 500
+)
+
+// bundle - 一次安全模式运行的诊断包，写在 debug/safemode/<入口>-<时间> 下，结束时打包为同名 zip
+type bundle struct {
+	mu       sync.Mutex
+	dir      string
+	entry    string
+	failures int
+	started  time.Time
+	traceOut *os.File
+	shots    int
+	// stoppedForPurchase - 运行因到达购买而被安全模式停止，不计入连续失败
+	stoppedForPurchase bool
+	// environment - 运行开始时的环境
+	environment *envsnap.Snapshot
+}
+
+func openBundle(entry string, failures int) (*bundle, error) {
+	started := time.Now()
+	dir := filepath.Join(".", "debug", "safemode", entry+"-"+started.Format("20060102-150405"))
+	if err := os.MkdirAll(filepath.Join(dir, "frames"), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(filepath.Join(dir, "trace.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	b := &bundle{dir: dir, entry: entry, failures: failures, started: started, traceOut: f}
+	if s, ok := envsnap.Current(); ok {
+		b.environment = &s
+	}
+	return b, nil
+}
+
+func (b *bundle) trace(kind, name string, status maa.EventStatus) {
+	data, err := json.Marshal(traceEvent{Time: time.Now(), Kind: kind, Name: name, Event: eventName(status)})
+	if err != nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.traceOut != nil {
+		b.traceOut.Write(append(data, '\n'))
+	}
+}
+
+// screenshot - 按顺序编号保存，文件名带上节点名便于对照 trace.jsonl
+func (b *bundle) screenshot(node string, img image.Image) {
+	b.mu.Lock()
+	if b.shots >= screenshotLimit {
+		b.mu.Unlock()
+		return
+	}
+	b.shots++
+	path := filepath.Join(b.dir, "frames", fmt.Sprintf("%04d-%s.png", b.shots, node))
+	b.mu.Unlock()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	png.Encode(f, img)
+}
+
+// close - 写入运行信息与日志末尾，打包为 zip 并返回其路径
+func (b *bundle) close(succeeded bool) (string, error) {
+	b.mu.Lock()
+	if b.traceOut != nil {
+		b.traceOut.Close()
+		b.traceOut = nil
+	}
+	info, err := json.MarshalIndent(map[string]any{
+		"entry":                b.entry,
+		"consecutive_failures": b.failures,
+		"started":              b.started,
+		"finished":             time.Now(),
+		"succeeded":            succeeded,
+		"stopped_for_purchase": b.stoppedForPurchase,
+		"screenshots":          b.shots,
+		"environment":          b.environment,
+	}, "", "  ")
+	b.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(b.dir, "info.json"), info, 0644); err != nil {
+		return "", err
+	}
+	copyLogTail(filepath.Join(".", "debug", "go-service.log"), filepath.Join(b.dir, "go-service.log"))
+
+	path := b.dir + ".zip"
+	if err := zipDir(b.dir, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func copyLogTail(src, dst string) {
+	in, err := os.Open(src)
+	if err != nil {
+		return
+	}
+	defer in.Close()
+	if info, err := in.Stat(); err == nil && info.Size() > logTailBytes {
+		in.Seek(-logTailBytes, io.SeekEnd)
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return
+	}
+	defer out.Close()
+	io.Copy(out, in)
+}
+
+func zipDir(dir, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	err = filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		dst, err := w.Create(strings.ReplaceAll(rel, string(filepath.Separator), "/"))
+		if err != nil {
+			return err
+		}
+		src, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(dst, src)
+		return err
+	})
+	if err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
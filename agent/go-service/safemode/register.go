@@ -0,0 +1,14 @@
+package safemode
+
+import "github.com/MaaXYZ/maa-framework-go/v4"
+
+var (
+	_ maa.TaskerEventSink  = &Guard{}
+	_ maa.ContextEventSink = &Recorder{}
+)
+
+// Register registers the failure guard as tasker sink and the bundle recorder as context sink
+func Register() {
+	maa.AgentServerAddTaskerSink(&Guard{})
+	maa.AgentServerAddContextSink(&Recorder{})
+}
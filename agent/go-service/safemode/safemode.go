@@ -0,0 +1,219 @@
+// Package safemode switches a task entry into safe mode after it has failed several runs in a
+// row. A safe-mode run buys nothing, shows every focus message, and records a screenshot and a
+// trace line for every node into a diagnostic bundle that can be attached to an issue as is.
+package safemode
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	mu sync.Mutex
+	// failures - 各任务入口连续失败的次数，首次使用时从数据目录读取
+	failures map[string]int
+	// current - 当前处于安全模式的运行，nil 表示正常运行
+	current *bundle
+)
+
+func failuresPath() string {
+	return datadir.Path("safemode", "failures.json")
+}
+
+// loadFailures must be called with mu held
+func loadFailures() {
+	if failures != nil {
+		return
+	}
+	failures = make(map[string]int)
+	data, err := os.ReadFile(failuresPath())
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, &failures); err != nil {
+		log.Warn().Err(err).Msg("Failed to parse consecutive failures, starting fresh")
+		failures = make(map[string]int)
+	}
+}
+
+// saveFailures must be called with mu held
+func saveFailures() {
+	data, err := json.MarshalIndent(failures, "", "  ")
+	if err != nil {
+		return
+	}
+	path := failuresPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Warn().Err(err).Msg("Failed to create safe mode data dir")
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Warn().Err(err).Msg("Failed to save consecutive failures")
+	}
+}
+
+// Active reports whether the current run is in safe mode
+func Active() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return current != nil
+}
+
+// StopBeforePurchase stops a safe-mode run that reached a purchase. The stop does not count as a failure.
+func StopBeforePurchase(tasker *maa.Tasker, node string) {
+	mu.Lock()
+	if current != nil {
+		current.stoppedForPurchase = true
+	}
+	mu.Unlock()
+
+	log.Warn().Str("node", node).Msg("Safe mode run reached a purchase, stopping")
+	fmt.Printf("<span style=\"color: #ff8c00; font-weight: bold;\">🛡️ 安全模式不会购买，已在 %s 处停止本次运行</span>\n", node)
+	tasker.PostStop()
+}
+
+// Guard counts consecutive failures of each task entry and runs the next task in safe mode once
+// safe_mode.after_failures is reached; a successful run clears the count
+type Guard struct{}
+
+// OnTaskerTask handles tasker task events
+func (g *Guard) OnTaskerTask(tasker *maa.Tasker, event maa.EventStatus, detail maa.TaskerTaskDetail) {
+	threshold := agentconfig.Get().SafeMode.AfterFailures
+
+	mu.Lock()
+	defer mu.Unlock()
+	loadFailures()
+
+	switch event {
+	case maa.EventStatusStarting:
+		if threshold <= 0 || failures[detail.Entry] < threshold {
+			return
+		}
+		b, err := openBundle(detail.Entry, failures[detail.Entry])
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to create safe mode bundle")
+			return
+		}
+		current = b
+		log.Warn().Str("entry", detail.Entry).Int("failures", failures[detail.Entry]).Str("bundle", b.dir).Msg("Running in safe mode")
+		fmt.Printf("<span style=\"color: #ff8c00; font-weight: bold;\">🛡️ 任务 %s 已连续失败 %d 次，本次以安全模式运行：不购买任何物品，显示全部提示并记录每一步的截图</span>\n",
+			detail.Entry, failures[detail.Entry])
+
+	case maa.EventStatusSucceeded, maa.EventStatusFailed:
+		succeeded := event == maa.EventStatusSucceeded
+		if current != nil {
+			b := current
+			current = nil
+			path, err := b.close(succeeded)
+			if err != nil {
+				log.Warn().Err(err).Msg("Failed to pack safe mode bundle")
+				path = b.dir
+			}
+			log.Info().Str("bundle", path).Msg("Safe mode diagnostic bundle written")
+			fmt.Printf("<span style=\"color: #ff8c00; font-weight: bold;\">🛡️ 安全模式诊断包已保存到 %s，反馈问题时请附上</span>\n", path)
+			if b.stoppedForPurchase {
+				return
+			}
+		}
+
+		if succeeded {
+			if failures[detail.Entry] > 0 {
+				delete(failures, detail.Entry)
+				saveFailures()
+			}
+			return
+		}
+		failures[detail.Entry]++
+		saveFailures()
+		if threshold > 0 && failures[detail.Entry] == threshold {
+			log.Warn().Str("entry", detail.Entry).Int("failures", threshold).Msg("Consecutive failures reached, next run uses safe mode")
+			fmt.Printf("<span style=\"color: #ff4500; font-weight: bold;\">⚠️ 任务 %s 已连续失败 %d 次，下次运行将进入安全模式并生成诊断包</span>\n",
+				detail.Entry, threshold)
+		}
+	}
+}
+
+// Recorder writes the frame and a trace line of every node of a safe-mode run into its bundle
+type Recorder struct{}
+
+func (r *Recorder) OnNodePipelineNode(ctx *maa.Context, event maa.EventStatus, detail maa.NodePipelineNodeDetail) {
+	b := active()
+	if b == nil {
+		return
+	}
+	b.trace("node", detail.Name, event)
+	// Starting 时缓存的截图就是命中该节点的画面
+	if event != maa.EventStatusStarting {
+		return
+	}
+	if tasker := ctx.GetTasker(); tasker != nil {
+		if controller := tasker.GetController(); controller != nil {
+			if img, err := controller.CacheImage(); err == nil && img != nil {
+				b.screenshot(detail.Name, img)
+			}
+		}
+	}
+}
+
+func (r *Recorder) OnNodeRecognitionNode(ctx *maa.Context, event maa.EventStatus, detail maa.NodeRecognitionNodeDetail) {
+	if b := active(); b != nil {
+		b.trace("recognition_node", detail.Name, event)
+	}
+}
+
+func (r *Recorder) OnNodeActionNode(ctx *maa.Context, event maa.EventStatus, detail maa.NodeActionNodeDetail) {
+	if b := active(); b != nil {
+		b.trace("action_node", detail.Name, event)
+	}
+}
+
+func (r *Recorder) OnNodeNextList(ctx *maa.Context, event maa.EventStatus, detail maa.NodeNextListDetail) {
+}
+
+func (r *Recorder) OnNodeRecognition(ctx *maa.Context, event maa.EventStatus, detail maa.NodeRecognitionDetail) {
+	if b := active(); b != nil {
+		b.trace("recognition", detail.Name, event)
+	}
+}
+
+func (r *Recorder) OnNodeAction(ctx *maa.Context, event maa.EventStatus, detail maa.NodeActionDetail) {
+	if b := active(); b != nil {
+		b.trace("action", detail.Name, event)
+	}
+}
+
+func active() *bundle {
+	mu.Lock()
+	defer mu.Unlock()
+	return current
+}
+
+// eventName - 与崩溃报告中的事件名一致
+func eventName(status maa.EventStatus) string {
+	switch status {
+	case maa.EventStatusStarting:
+		return "starting"
+	case maa.EventStatusSucceeded:
+		return "succeeded"
+	case maa.EventStatusFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// traceEvent - trace.jsonl 中的一行
+type traceEvent struct {
+	Time  time.Time `json:"time"`
+	Kind  string    `json:"kind"`
+	Name  string    `json:"name"`
+	Event string    `json:"event"`
+}
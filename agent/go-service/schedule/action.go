@@ -0,0 +1,26 @@
+package schedule
+
+import (
+	"time"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// SchedulePreviewAction - 显示接下来的刷新时间与建议运行时间
+type SchedulePreviewAction struct{}
+
+func (a *SchedulePreviewAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	preview := Preview(time.Now())
+	log.Info().Str("preview", preview).Msg("Schedule preview")
+	ctx.RunTask("Schedule_TaskShowMessage", map[string]interface{}{
+		"Schedule_TaskShowMessage": map[string]interface{}{
+			"recognition": "DirectHit",
+			"action":      "DoNothing",
+			"focus": map[string]interface{}{
+				"Node.Action.Starting": preview,
+			},
+		},
+	})
+	return true
+}
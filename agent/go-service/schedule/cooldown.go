@@ -0,0 +1,130 @@
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// cooldownRefusedNode - 冷却中拒绝运行时转到的结束节点
+const cooldownRefusedNode = "ScheduleCooldownRefused"
+
+var (
+	completedMu sync.Mutex
+	// completed - 各任务入口最近一次成功完成的时间，首次使用时从数据目录读取
+	completed map[string]time.Time
+)
+
+func completedPath() string {
+	return datadir.Path("schedule", "last_completed.json")
+}
+
+// loadCompleted must be called with completedMu held
+func loadCompleted() {
+	if completed != nil {
+		return
+	}
+	completed = make(map[string]time.Time)
+	data, err := os.ReadFile(completedPath())
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, &completed); err != nil {
+		log.Warn().Err(err).Msg("Failed to parse last completion times, starting fresh")
+		completed = make(map[string]time.Time)
+	}
+}
+
+// markCompleted records that entry finished successfully at t and persists all completion times
+func markCompleted(entry string, t time.Time) {
+	completedMu.Lock()
+	defer completedMu.Unlock()
+	loadCompleted()
+	completed[entry] = t
+
+	data, err := json.MarshalIndent(completed, "", "  ")
+	if err != nil {
+		return
+	}
+	path := completedPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Warn().Err(err).Msg("Failed to create schedule data dir")
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Warn().Err(err).Msg("Failed to save last completion times")
+	}
+}
+
+// CooldownUntil reports when entry may run again, if it is still cooling down at t
+func CooldownUntil(entry string, t time.Time) (time.Time, bool) {
+	minutes := agentconfig.Get().Schedule.Cooldown.Minutes[entry]
+	if minutes <= 0 {
+		return time.Time{}, false
+	}
+
+	completedMu.Lock()
+	defer completedMu.Unlock()
+	loadCompleted()
+	last, ok := completed[entry]
+	if !ok {
+		return time.Time{}, false
+	}
+	until := last.Add(time.Duration(minutes) * time.Minute)
+	return until, t.Before(until)
+}
+
+// CooldownGuard records the completion time of every task that succeeds
+type CooldownGuard struct{}
+
+// OnTaskerTask handles tasker task events
+func (g *CooldownGuard) OnTaskerTask(tasker *maa.Tasker, event maa.EventStatus, detail maa.TaskerTaskDetail) {
+	if event == maa.EventStatusSucceeded {
+		markCompleted(detail.Entry, time.Now())
+	}
+}
+
+// CooldownGateAction - stop the task when its entry starts again too soon after its last completion
+// custom_action_param: {"force": false}; the task option sets force to run once regardless
+type CooldownGateAction struct{}
+
+func (a *CooldownGateAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	var params struct {
+		Force bool `json:"force"`
+	}
+	if arg.CustomActionParam != "" {
+		if err := json.Unmarshal([]byte(arg.CustomActionParam), &params); err != nil {
+			log.Error().Err(err).Msg("Failed to parse CustomActionParam")
+			return false
+		}
+	}
+	if arg.TaskDetail == nil {
+		return true
+	}
+	entry := arg.TaskDetail.Entry
+
+	until, cooling := CooldownUntil(entry, time.Now())
+	if !cooling {
+		return true
+	}
+	if params.Force {
+		log.Info().Str("entry", entry).Time("until", until).Msg("Cooldown ignored by task option")
+		return true
+	}
+	log.Error().
+		Str("entry", entry).
+		Time("until", until).
+		Msg("Task refused during cooldown")
+	fmt.Printf("<span style=\"color: #ff4500; font-weight: bold;\">⏳ 任务 %s 刚运行完成，已停止本次重复运行，请在 %s 之后再运行；确需立即运行可在任务选项中打开「忽略冷却」</span>\n",
+		entry, until.Format("01-02 15:04"))
+	ctx.OverrideNext(arg.CurrentTaskName, []maa.NodeNextItem{{Name: cooldownRefusedNode}})
+	return true
+}
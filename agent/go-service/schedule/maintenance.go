@@ -0,0 +1,138 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// Window - 一个具体的维护时段
+type Window struct {
+	Name  string
+	Start time.Time
+	End   time.Time
+}
+
+// InMaintenance reports the configured maintenance window that contains t, if any
+func InMaintenance(t time.Time) (Window, bool) {
+	for _, cfg := range agentconfig.Get().Schedule.Maintenance {
+		if w, ok := resolveWindow(cfg, t); ok {
+			return w, true
+		}
+	}
+	return Window{}, false
+}
+
+// resolveWindow returns the occurrence of cfg that contains t
+func resolveWindow(cfg agentconfig.MaintenanceWindow, t time.Time) (Window, bool) {
+	name := cfg.Name
+	if name == "" {
+		name = "维护"
+	}
+
+	if cfg.From != "" || cfg.To != "" {
+		from, err1 := time.ParseInLocation("2006-01-02 15:04", cfg.From, t.Location())
+		to, err2 := time.ParseInLocation("2006-01-02 15:04", cfg.To, t.Location())
+		if err1 != nil || err2 != nil {
+			log.Warn().Str("from", cfg.From).Str("to", cfg.To).Msg("Invalid maintenance window, skipped")
+			return Window{}, false
+		}
+		if !t.Before(from) && t.Before(to) {
+			return Window{Name: name, Start: from, End: to}, true
+		}
+		return Window{}, false
+	}
+
+	start, err1 := parseClock(cfg.Start)
+	end, err2 := parseClock(cfg.End)
+	if err1 != nil || err2 != nil {
+		log.Warn().Str("start", cfg.Start).Str("end", cfg.End).Msg("Invalid maintenance window, skipped")
+		return Window{}, false
+	}
+
+	// 跨天时段可能从前一天开始，所以同时检查昨天和今天
+	for _, offset := range []int{-1, 0} {
+		day := time.Date(t.Year(), t.Month(), t.Day()+offset, 0, 0, 0, 0, t.Location())
+		if !onWeekday(cfg.Weekdays, day.Weekday()) {
+			continue
+		}
+		from := day.Add(start)
+		to := day.Add(end)
+		if end <= start {
+			to = to.AddDate(0, 0, 1)
+		}
+		if !t.Before(from) && t.Before(to) {
+			return Window{Name: name, Start: from, End: to}, true
+		}
+	}
+	return Window{}, false
+}
+
+func parseClock(s string) (time.Duration, error) {
+	clock, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute, nil
+}
+
+func onWeekday(weekdays []int, day time.Weekday) bool {
+	if len(weekdays) == 0 {
+		return true
+	}
+	for _, d := range weekdays {
+		if time.Weekday(d) == day {
+			return true
+		}
+	}
+	return false
+}
+
+// afterMaintenance moves t past any maintenance window it falls into; windows may chain
+func afterMaintenance(t time.Time) (time.Time, []string) {
+	var names []string
+	for i := 0; i < 8; i++ {
+		w, ok := InMaintenance(t)
+		if !ok {
+			break
+		}
+		names = append(names, w.Name)
+		t = w.End
+	}
+	return t, names
+}
+
+// exemptEntries - 维护期间仍允许运行的任务，查看运行建议、模块版本与倒卖统计、试算不会进入游戏
+var exemptEntries = map[string]bool{
+	"SchedulePreview": true,
+	"ModuleInfo":      true,
+	"ResellReport":    true,
+	"ResellWhatIf":    true,
+}
+
+// MaintenanceGuard stops any task that starts inside a maintenance window
+type MaintenanceGuard struct{}
+
+// OnTaskerTask handles tasker task events
+func (g *MaintenanceGuard) OnTaskerTask(tasker *maa.Tasker, event maa.EventStatus, detail maa.TaskerTaskDetail) {
+	if event != maa.EventStatusStarting || exemptEntries[detail.Entry] {
+		return
+	}
+
+	w, ok := InMaintenance(time.Now())
+	if !ok {
+		return
+	}
+
+	log.Error().
+		Str("entry", detail.Entry).
+		Str("window", w.Name).
+		Time("end", w.End).
+		Msg("Task refused during maintenance window")
+	fmt.Printf("<span style=\"color: #ff4500; font-weight: bold;\">🛠️ 当前处于维护时段「%s」，任务 %s 已停止，请在 %s 之后再运行</span>\n",
+		w.Name, detail.Entry, w.End.Format("01-02 15:04"))
+	tasker.PostStop()
+}
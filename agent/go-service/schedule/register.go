@@ -0,0 +1,28 @@
+package schedule
+
+import (
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+var (
+	_ maa.CustomActionRunner = &SchedulePreviewAction{}
+	_ maa.CustomActionRunner = &CooldownGateAction{}
+	_ maa.TaskerEventSink    = &MaintenanceGuard{}
+	_ maa.TaskerEventSink    = &CooldownGuard{}
+)
+
+// Components returns the custom components of schedule package
+func Components() []registry.Component {
+	return []registry.Component{
+		registry.Action("SchedulePreviewAction", &SchedulePreviewAction{}, "显示接下来的刷新时间与建议运行时间"),
+		registry.Action("CooldownGateAction", &CooldownGateAction{}, "任务完成后冷却时间内再次运行时提示并结束",
+			registry.P("force", "bool", "忽略冷却，本次照常运行")),
+	}
+}
+
+// Register adds the maintenance guard and the completion recorder as tasker sinks
+func Register() {
+	maa.AgentServerAddTaskerSink(&MaintenanceGuard{})
+	maa.AgentServerAddTaskerSink(&CooldownGuard{})
+}
@@ -0,0 +1,118 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+)
+
+// Event - 一次会影响任务收益的刷新
+type Event struct {
+	Name  string
+	At    time.Time
+	Tasks []string
+}
+
+// Run - 建议的一次运行，可能合并了多个刷新
+type Run struct {
+	At     time.Time
+	Tasks  []string
+	Causes []string
+	// Shifted - 因维护时段顺延时记录时段名称
+	Shifted []string
+}
+
+var (
+	mu       sync.Mutex
+	observed = make(map[string]Event)
+)
+
+// Observe records a refresh time seen during a task, e.g. the resell quota refresh
+func Observe(name string, at time.Time, tasks ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	observed[name] = Event{Name: name, At: at, Tasks: tasks}
+}
+
+// Upcoming returns the refresh events after now, earliest first
+func Upcoming(now time.Time) []Event {
+	cfg := agentconfig.Get().Schedule
+	reset := time.Date(now.Year(), now.Month(), now.Day(), cfg.DailyResetHour, 0, 0, 0, now.Location())
+	if !reset.After(now) {
+		reset = reset.AddDate(0, 0, 1)
+	}
+	events := []Event{{Name: "商店每日重置", At: reset, Tasks: []string{"CreditShopping", "AutoResell"}}}
+
+	mu.Lock()
+	for _, e := range observed {
+		if e.At.After(now) {
+			events = append(events, e)
+		}
+	}
+	mu.Unlock()
+
+	sort.Slice(events, func(i, j int) bool { return events[i].At.Before(events[j].At) })
+	return events
+}
+
+// Plan queues a run shortly after each event and merges events that are close together,
+// so the same task is not started twice in a row. Runs landing in a maintenance window
+// are shifted to the end of the window
+func Plan(events []Event) []Run {
+	cfg := agentconfig.Get().Schedule
+	delay := time.Duration(cfg.DelayMinutes) * time.Minute
+	merge := time.Duration(cfg.MergeMinutes) * time.Minute
+
+	var runs []Run
+	for _, e := range events {
+		at, shifted := afterMaintenance(e.At.Add(delay))
+		if n := len(runs); n > 0 && at.Sub(runs[n-1].At) <= merge {
+			// 与上一次运行冲突，推迟上一次运行到本次刷新之后
+			last := &runs[n-1]
+			last.At = at
+			last.Tasks = union(last.Tasks, e.Tasks)
+			last.Causes = append(last.Causes, e.Name)
+			last.Shifted = union(last.Shifted, shifted)
+			continue
+		}
+		runs = append(runs, Run{At: at, Tasks: append([]string(nil), e.Tasks...), Causes: []string{e.Name}, Shifted: shifted})
+	}
+	return runs
+}
+
+// Preview formats the planned runs for display
+func Preview(now time.Time) string {
+	runs := Plan(Upcoming(now))
+	var sb strings.Builder
+	sb.WriteString("📅 运行建议\n")
+	if w, ok := InMaintenance(now); ok {
+		sb.WriteString(fmt.Sprintf("🛠️ 维护中：%s，%s 结束\n", w.Name, w.End.Format("01-02 15:04")))
+	}
+	for _, run := range runs {
+		sb.WriteString(fmt.Sprintf("%s  %s（%s）",
+			run.At.Format("01-02 15:04"), strings.Join(run.Tasks, "、"), strings.Join(run.Causes, "、")))
+		if len(run.Shifted) > 0 {
+			sb.WriteString(fmt.Sprintf(" ⏩ 因%s顺延", strings.Join(run.Shifted, "、")))
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func union(a, b []string) []string {
+	seen := make(map[string]bool, len(a))
+	for _, s := range a {
+		seen[s] = true
+	}
+	for _, s := range b {
+		if !seen[s] {
+			a = append(a, s)
+			seen[s] = true
+		}
+	}
+	return a
+}
@@ -0,0 +1,25 @@
+//go:build darwin
+
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keychainPassphrase reads the generic password KeychainService/KeychainAccount from the
+// macOS keychain; a missing item returns an empty string
+func keychainPassphrase() (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", KeychainService, "-a", KeychainAccount, "-w").Output()
+	if err != nil {
+		// security 找不到条目时以 44 退出
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+			return "", nil
+		}
+		return "", fmt.Errorf("secret: read keychain: %w", err)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
@@ -0,0 +1,28 @@
+//go:build !windows && !darwin
+
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keychainPassphrase reads KeychainService/KeychainAccount from the Secret Service (GNOME Keyring,
+// KWallet) through secret-tool; a missing item or a missing secret-tool returns an empty string
+func keychainPassphrase() (string, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return "", nil
+	}
+	out, err := exec.Command("secret-tool", "lookup", "service", KeychainService, "account", KeychainAccount).Output()
+	if err != nil {
+		// secret-tool 找不到条目时以 1 退出且没有输出
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && len(exitErr.Stderr) == 0 {
+			return "", nil
+		}
+		return "", fmt.Errorf("secret: read secret service: %w", err)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
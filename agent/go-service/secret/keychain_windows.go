@@ -0,0 +1,66 @@
+//go:build windows
+
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf16"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	advapi32     = windows.NewLazySystemDLL("advapi32.dll")
+	procCredRead = advapi32.NewProc("CredReadW")
+	procCredFree = advapi32.NewProc("CredFree")
+)
+
+// CRED_TYPE_GENERIC - 普通凭据，即 cmdkey /generic 创建的类型
+const CRED_TYPE_GENERIC = 1
+
+// credential - CREDENTIALW 结构
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// keychainPassphrase reads the generic credential KeychainService/KeychainAccount from the
+// Windows Credential Manager; a missing credential returns an empty string
+func keychainPassphrase() (string, error) {
+	target, err := windows.UTF16PtrFromString(KeychainService + "/" + KeychainAccount)
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	ret, _, callErr := procCredRead.Call(uintptr(unsafe.Pointer(target)), CRED_TYPE_GENERIC, 0, uintptr(unsafe.Pointer(&cred)))
+	if ret == 0 {
+		if errors.Is(callErr, windows.ERROR_NOT_FOUND) {
+			return "", nil
+		}
+		return "", fmt.Errorf("secret: read credential manager: %w", callErr)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	blob := unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)
+	// cmdkey 与凭据管理器界面保存的是 UTF-16LE 口令
+	if len(blob)%2 == 0 {
+		chars := make([]uint16, len(blob)/2)
+		for i := range chars {
+			chars[i] = uint16(blob[2*i]) | uint16(blob[2*i+1])<<8
+		}
+		return string(utf16.Decode(chars)), nil
+	}
+	return string(blob), nil
+}
@@ -0,0 +1,122 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// KeyEnv - 加密密钥所在的环境变量，可以是任意口令，实际密钥取其 SHA-256
+const KeyEnv = "MAAEND_SECRET_KEY"
+
+// KeychainService, KeychainAccount - 未设置 KeyEnv 时，从系统钥匙串读取口令的条目：
+// Windows 凭据管理器中名为 MaaEnd/secret-key 的普通凭据，macOS 钥匙串中的通用密码，
+// 其他系统通过 secret-tool 读取 Secret Service
+const (
+	KeychainService = "MaaEnd"
+	KeychainAccount = "secret-key"
+)
+
+// prefix - 加密值的前缀，不带前缀的值按明文处理
+const prefix = "enc:v1:"
+
+// ErrNoKey - 环境变量与系统钥匙串中都没有密钥
+var ErrNoKey = errors.New("secret: no key: set " + KeyEnv + " or store the key in the OS keychain (service " +
+	KeychainService + ", account " + KeychainAccount + ")")
+
+var (
+	keychainMu  sync.Mutex
+	keychainKey string
+)
+
+// IsEncrypted reports whether value was produced by Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Encrypt seals plain with AES-256-GCM under the key from KeyEnv or the OS keychain
+func Encrypt(plain string) (string, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plain), nil)
+	return prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt; values without the prefix are returned unchanged,
+// so plain settings keep working
+func Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, prefix))
+	if err != nil {
+		return "", fmt.Errorf("secret: %w", err)
+	}
+	gcm, err := newGCM()
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("secret: value too short")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("secret: wrong key or corrupted value")
+	}
+	return string(plain), nil
+}
+
+// Mask hides a setting for logs and debug output; encrypted values are safe to show as is
+func Mask(value string) string {
+	if value == "" || IsEncrypted(value) {
+		return value
+	}
+	return "***"
+}
+
+// passphrase - 优先取 KeyEnv，否则读系统钥匙串；钥匙串只在读到口令后缓存，之后补存的口令也能生效
+func passphrase() (string, error) {
+	if value := os.Getenv(KeyEnv); value != "" {
+		return value, nil
+	}
+	keychainMu.Lock()
+	defer keychainMu.Unlock()
+	if keychainKey != "" {
+		return keychainKey, nil
+	}
+	value, err := keychainPassphrase()
+	if err != nil {
+		return "", err
+	}
+	if value == "" {
+		return "", ErrNoKey
+	}
+	keychainKey = value
+	return value, nil
+}
+
+func newGCM() (cipher.AEAD, error) {
+	phrase, err := passphrase()
+	if err != nil {
+		return nil, err
+	}
+	key := sha256.Sum256([]byte(phrase))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
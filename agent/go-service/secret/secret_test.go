@@ -0,0 +1,33 @@
+package secret
+
+import "testing"
+
+func TestRoundTrip(t *testing.T) {
+	t.Setenv(KeyEnv, "correct horse")
+	sealed, err := Encrypt("https://example.com/hook?token=abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(sealed) {
+		t.Fatalf("Encrypt() = %q, want the %q prefix", sealed, prefix)
+	}
+	got, err := Decrypt(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "https://example.com/hook?token=abc" {
+		t.Errorf("Decrypt() = %q, want the original value", got)
+	}
+
+	t.Setenv(KeyEnv, "wrong")
+	if _, err := Decrypt(sealed); err == nil {
+		t.Errorf("Decrypt() with the wrong key succeeded")
+	}
+}
+
+func TestDecryptPlain(t *testing.T) {
+	got, err := Decrypt("plain-token")
+	if err != nil || got != "plain-token" {
+		t.Errorf("Decrypt(plain) = %q, %v, want the value unchanged", got, err)
+	}
+}
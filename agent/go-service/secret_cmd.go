@@ -0,0 +1,25 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/secret"
+)
+
+// runEncryptSecret - go-service encrypt-secret，从标准输入读取一行明文，输出可直接写入 go-service.json 的加密值
+// 明文不经命令行参数传入，避免留在 shell 历史中
+func runEncryptSecret(args []string) error {
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return fmt.Errorf("read secret from stdin: %w", err)
+	}
+	sealed, err := secret.Encrypt(strings.TrimRight(line, "\r\n"))
+	if err != nil {
+		return err
+	}
+	fmt.Println(sealed)
+	return nil
+}
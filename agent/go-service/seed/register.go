@@ -0,0 +1,22 @@
+package seed
+
+import "github.com/MaaXYZ/maa-framework-go/v4"
+
+var (
+	_ maa.TaskerEventSink = &runSeeder{}
+)
+
+// runSeeder picks the seed of each run when a task starts
+type runSeeder struct{}
+
+// OnTaskerTask handles tasker task events
+func (s *runSeeder) OnTaskerTask(tasker *maa.Tasker, event maa.EventStatus, detail maa.TaskerTaskDetail) {
+	if event == maa.EventStatusStarting {
+		Reset()
+	}
+}
+
+// Register registers the per-run seeder as a tasker sink
+func Register() {
+	maa.AgentServerAddTaskerSink(&runSeeder{})
+}
@@ -0,0 +1,77 @@
+package seed
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/rs/zerolog/log"
+)
+
+// 所有随机行为（延时抖动、点击偏移等）都应通过本包取随机数，
+// 这样配置固定的 seed 后，配合录制的节点轨迹即可复现用户的一次运行
+
+var (
+	mu      sync.Mutex
+	current int64
+	rng     = rand.New(rand.NewPCG(0, 0))
+)
+
+// Reset starts a new run: the seed comes from the seed config field, or from the clock when it is 0
+func Reset() int64 {
+	s := agentconfig.Get().Seed
+	fixed := s != 0
+	if !fixed {
+		s = time.Now().UnixNano()
+	}
+
+	mu.Lock()
+	current = s
+	rng = rand.New(rand.NewPCG(uint64(s), 0))
+	mu.Unlock()
+
+	log.Info().Int64("seed", s).Bool("fixed", fixed).Msg("Run seed")
+	return s
+}
+
+// Current returns the seed of the current run
+func Current() int64 {
+	mu.Lock()
+	defer mu.Unlock()
+	return current
+}
+
+// IntN returns a random int in [0, n); n <= 0 returns 0
+func IntN(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return rng.IntN(n)
+}
+
+// Float64 returns a random float in [0, 1)
+func Float64() float64 {
+	mu.Lock()
+	defer mu.Unlock()
+	return rng.Float64()
+}
+
+// Jitter returns d shifted by a random amount of up to fraction*d either way
+func Jitter(d time.Duration, fraction float64) time.Duration {
+	if d <= 0 || fraction <= 0 {
+		return d
+	}
+	delta := float64(d) * fraction
+	return d + time.Duration((Float64()*2-1)*delta)
+}
+
+// Offset returns a random offset in [-max, max], e.g. to spread clicks inside a target
+func Offset(max int) int {
+	if max <= 0 {
+		return 0
+	}
+	return IntN(2*max+1) - max
+}
@@ -0,0 +1,22 @@
+package shoptab
+
+import (
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+var (
+	_ maa.CustomActionRunner = &ShopTabNavigateAction{}
+)
+
+// Components returns the custom components of shoptab package
+func Components() []registry.Component {
+	P := registry.P
+	return []registry.Component{
+		registry.Action("ShopTabNavigateAction", &ShopTabNavigateAction{}, "依次切换商店分类标签页，在每个标签页下应用各自的购买规则后扫描",
+			P("tabs", "array", "标签页：name、template、roi、threshold、rules、skip"),
+			P("rules", "string", "接收各标签页购买规则的节点"),
+			P("scan", "string", "每个标签页执行的扫描节点"),
+		),
+	}
+}
@@ -0,0 +1,161 @@
+package shoptab
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const switchNode = "ShopTabSwitch"
+
+// Tab - 商店中的一个分类标签页
+type Tab struct {
+	Name string `json:"name"`
+	// Template - 标签文字的模板图，为空时沿用 ShopTabSwitch 节点中的模板
+	Template  string  `json:"template"`
+	ROI       []int   `json:"roi"`
+	Threshold float64 `json:"threshold"`
+	// Rules - 该标签页的购买规则，逐项覆盖 rules 节点当前的 custom_action_param（即用户在任务选项中的设置）
+	// 未写出或为空字符串的项保留用户的设置
+	Rules map[string]any `json:"rules"`
+	// Skip - 只切换不扫描，常用于临时关闭某个标签页
+	Skip bool `json:"skip"`
+}
+
+// ShopTabNavigateAction - 依次切换商店分类标签页，并在每个标签页下应用各自的购买规则后执行扫描
+// custom_action_param: {"tabs": [...], "rules": "CreditShoppingShopping", "scan": "CreditShoppingScanItem"}
+type ShopTabNavigateAction struct{}
+
+func (a *ShopTabNavigateAction) Run(ctx *maa.Context, arg *maa.CustomActionArg) bool {
+	var params struct {
+		Tabs  []Tab  `json:"tabs"`
+		Rules string `json:"rules"`
+		Scan  string `json:"scan"`
+	}
+	if err := json.Unmarshal([]byte(arg.CustomActionParam), &params); err != nil {
+		log.Error().Err(err).Msg("Failed to parse ShopTabNavigateAction param")
+		return false
+	}
+	if len(params.Tabs) == 0 || params.Scan == "" {
+		log.Error().Msg("ShopTabNavigateAction requires tabs and scan")
+		return false
+	}
+
+	scanned := 0
+	for _, tab := range params.Tabs {
+		if ctx.GetTasker().Stopping() {
+			return false
+		}
+		if tab.Skip {
+			log.Info().Str("tab", tab.Name).Msg("Shop tab skipped")
+			continue
+		}
+		if err := switchTab(ctx, tab); err != nil {
+			log.Warn().Err(err).Str("tab", tab.Name).Msg("Failed to switch shop tab, skipping")
+			continue
+		}
+		if params.Rules != "" && tab.Rules != nil {
+			if err := applyRules(ctx, params.Rules, tab.Rules); err != nil {
+				log.Warn().Err(err).Str("tab", tab.Name).Msg("Failed to apply tab rules, skipping")
+				continue
+			}
+		}
+		if _, err := ctx.RunTask(params.Scan); err != nil {
+			log.Warn().Err(err).Str("tab", tab.Name).Msg("Shop tab scan failed")
+			continue
+		}
+		scanned++
+		log.Info().Str("tab", tab.Name).Msg("Shop tab scanned")
+	}
+
+	log.Info().Int("scanned", scanned).Int("tabs", len(params.Tabs)).Msg("Shop tab navigation finished")
+	return scanned > 0
+}
+
+// switchTab - 识别标签文字并点击，覆盖 ShopTabSwitch 的模板与 roi
+func switchTab(ctx *maa.Context, tab Tab) error {
+	override := map[string]any{"next": []string{}}
+	if tab.Template != "" {
+		override["template"] = tab.Template
+	}
+	if len(tab.ROI) == 4 {
+		override["roi"] = tab.ROI
+	}
+	if tab.Threshold > 0 {
+		override["threshold"] = tab.Threshold
+	}
+
+	detail, err := ctx.RunTask(switchNode, map[string]any{switchNode: override})
+	if err != nil {
+		return err
+	}
+	if detail == nil || !detail.Status.Success() {
+		return fmt.Errorf("tab label %q not found", tab.Name)
+	}
+	return nil
+}
+
+// applyRules - 以用户设置合并该标签页规则后的参数运行参数解析节点，只执行节点自身
+func applyRules(ctx *maa.Context, node string, rules map[string]any) error {
+	detail, err := ctx.RunTask(node, map[string]any{
+		node: map[string]any{
+			"recognition":         "DirectHit",
+			"custom_action_param": mergeRules(currentParam(ctx, node), rules),
+			"next":                []string{},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if detail == nil || !detail.Status.Success() {
+		return fmt.Errorf("%s did not complete", node)
+	}
+	return nil
+}
+
+// currentParam - 节点当前的 custom_action_param，已包含任务选项的覆盖；读取失败时为空
+func currentParam(ctx *maa.Context, node string) map[string]any {
+	raw, err := ctx.GetNodeJSON(node)
+	if err != nil || raw == "" {
+		log.Warn().Err(err).Str("node", node).Msg("Failed to read rules node, using tab rules only")
+		return nil
+	}
+	var data struct {
+		Action struct {
+			Param struct {
+				CustomActionParam any `json:"custom_action_param"`
+			} `json:"param"`
+		} `json:"action"`
+	}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		log.Warn().Err(err).Str("node", node).Msg("Failed to parse rules node, using tab rules only")
+		return nil
+	}
+	switch param := data.Action.Param.CustomActionParam.(type) {
+	case map[string]any:
+		return param
+	case string:
+		var parsed map[string]any
+		if err := json.Unmarshal([]byte(param), &parsed); err == nil {
+			return parsed
+		}
+	}
+	return nil
+}
+
+// mergeRules - 在用户设置上逐项覆盖标签页规则，标签页中为空的项不覆盖
+func mergeRules(base, rules map[string]any) map[string]any {
+	merged := make(map[string]any, len(base)+len(rules))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range rules {
+		if value == nil || value == "" {
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
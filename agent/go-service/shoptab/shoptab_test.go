@@ -0,0 +1,42 @@
+package shoptab
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeRules(t *testing.T) {
+	user := map[string]any{"buy_first": "嵌晶玉", "blacklist": "A;B", "reserve_credit": "300"}
+	tests := []struct {
+		name  string
+		rules map[string]any
+		want  map[string]any
+	}{
+		{"no tab rules", nil, user},
+		{
+			"empty values keep user settings",
+			map[string]any{"buy_first": "", "blacklist": nil},
+			user,
+		},
+		{
+			"tab value overrides",
+			map[string]any{"buy_first": "武库配额"},
+			map[string]any{"buy_first": "武库配额", "blacklist": "A;B", "reserve_credit": "300"},
+		},
+		{
+			"tab adds a key",
+			map[string]any{"max_price": "嵌晶玉:200"},
+			map[string]any{"buy_first": "嵌晶玉", "blacklist": "A;B", "reserve_credit": "300", "max_price": "嵌晶玉:200"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeRules(user, tt.rules); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeRules() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if user["buy_first"] != "嵌晶玉" {
+		t.Errorf("mergeRules modified the user settings")
+	}
+}
@@ -0,0 +1,186 @@
+package stuckcheck
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// Number of recent node names kept for the diagnostic bundle
+	recentNodeLimit = 20
+	// Sampling grid used to hash frames
+	hashGrid = 32
+)
+
+// screenActions are the action types that interact with the screen; DoNothing, waits and custom actions are not counted
+var screenActions = map[maa.NodeActionType]bool{
+	maa.NodeActionTypeClick:        true,
+	maa.NodeActionTypeLongPress:    true,
+	maa.NodeActionTypeSwipe:        true,
+	maa.NodeActionTypeMultiSwipe:   true,
+	maa.NodeActionTypeScroll:       true,
+	maa.NodeActionTypeClickKey:     true,
+	maa.NodeActionTypeLongPressKey: true,
+	maa.NodeActionTypeInputText:    true,
+}
+
+//go:embed warning_message.html
+var stuckWarningHTML string
+
+// StuckDetector aborts the task when the screen stays identical while clicks, swipes, keys or input keep being issued
+type StuckDetector struct {
+	mu          sync.Mutex
+	lastHash    uint64
+	sameCount   int
+	recentNodes []string
+}
+
+// OnTaskerTask resets state when a new task starts
+func (d *StuckDetector) OnTaskerTask(tasker *maa.Tasker, event maa.EventStatus, detail maa.TaskerTaskDetail) {
+	if event != maa.EventStatusStarting {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lastHash = 0
+	d.sameCount = 0
+	d.recentNodes = nil
+}
+
+// OnNodeAction hashes the latest frame after each screen-interacting action and checks for a stuck screen
+func (d *StuckDetector) OnNodeAction(ctx *maa.Context, event maa.EventStatus, detail maa.NodeActionDetail) {
+	if event != maa.EventStatusSucceeded || !isScreenAction(ctx, detail.Name) {
+		return
+	}
+
+	tasker := ctx.GetTasker()
+	if tasker == nil {
+		return
+	}
+	controller := tasker.GetController()
+	if controller == nil {
+		return
+	}
+	img, err := controller.CacheImage()
+	if err != nil || img == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.recentNodes = append(d.recentNodes, detail.Name)
+	if len(d.recentNodes) > recentNodeLimit {
+		d.recentNodes = d.recentNodes[len(d.recentNodes)-recentNodeLimit:]
+	}
+
+	hash := frameHash(img)
+	if hash != d.lastHash {
+		d.lastHash = hash
+		d.sameCount = 0
+		return
+	}
+
+	d.sameCount++
+	if d.sameCount < agentconfig.Get().StuckCheck.Threshold {
+		return
+	}
+
+	log.Error().
+		Int("same_count", d.sameCount).
+		Strs("recent_nodes", d.recentNodes).
+		Msg("Screen unchanged for too many actions, task seems stuck and will be stopped")
+
+	if dir, err := saveDiagnostics(img, d.recentNodes, d.sameCount); err != nil {
+		log.Warn().Err(err).Msg("Failed to save stuck diagnostics")
+	} else {
+		log.Info().Str("dir", dir).Msg("Stuck diagnostics saved")
+	}
+	fmt.Println(stuckWarningHTML)
+
+	d.sameCount = 0
+	d.recentNodes = nil
+	tasker.PostStop()
+}
+
+// isScreenAction reports whether the node's action interacts with the screen
+func isScreenAction(ctx *maa.Context, name string) bool {
+	node, err := ctx.GetNode(name)
+	if err != nil || node == nil || node.Action == nil {
+		return false
+	}
+	return screenActions[node.Action.Type]
+}
+
+func (d *StuckDetector) OnNodePipelineNode(ctx *maa.Context, event maa.EventStatus, detail maa.NodePipelineNodeDetail) {
+}
+
+func (d *StuckDetector) OnNodeRecognitionNode(ctx *maa.Context, event maa.EventStatus, detail maa.NodeRecognitionNodeDetail) {
+}
+
+func (d *StuckDetector) OnNodeActionNode(ctx *maa.Context, event maa.EventStatus, detail maa.NodeActionNodeDetail) {
+}
+
+func (d *StuckDetector) OnNodeNextList(ctx *maa.Context, event maa.EventStatus, detail maa.NodeNextListDetail) {
+}
+
+func (d *StuckDetector) OnNodeRecognition(ctx *maa.Context, event maa.EventStatus, detail maa.NodeRecognitionDetail) {
+}
+
+// frameHash hashes a coarse grid of pixels so that identical frames produce identical hashes
+func frameHash(img image.Image) uint64 {
+	bounds := img.Bounds()
+	h := fnv.New64a()
+	buf := make([]byte, 3)
+	for gy := 0; gy < hashGrid; gy++ {
+		y := bounds.Min.Y + gy*bounds.Dy()/hashGrid
+		for gx := 0; gx < hashGrid; gx++ {
+			x := bounds.Min.X + gx*bounds.Dx()/hashGrid
+			r, g, b, _ := img.At(x, y).RGBA()
+			buf[0], buf[1], buf[2] = byte(r>>8), byte(g>>8), byte(b>>8)
+			h.Write(buf)
+		}
+	}
+	return h.Sum64()
+}
+
+// saveDiagnostics writes the stuck frame and recent node trace to debug/stuck/<timestamp>
+func saveDiagnostics(img image.Image, recentNodes []string, sameCount int) (string, error) {
+	dir := filepath.Join(".", "debug", "stuck", time.Now().Format("20060102-150405"))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	f, err := os.Create(filepath.Join(dir, "frame.png"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		return "", err
+	}
+
+	info, err := json.MarshalIndent(map[string]any{
+		"same_count":   sameCount,
+		"recent_nodes": recentNodes,
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "trace.json"), info, 0644); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
@@ -0,0 +1,15 @@
+package stuckcheck
+
+import "github.com/MaaXYZ/maa-framework-go/v4"
+
+var (
+	_ maa.TaskerEventSink  = &StuckDetector{}
+	_ maa.ContextEventSink = &StuckDetector{}
+)
+
+// Register registers the stuck detector as tasker and context sink
+func Register() {
+	detector := &StuckDetector{}
+	maa.AgentServerAddTaskerSink(detector)
+	maa.AgentServerAddContextSink(detector)
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package takeover
+
+import "errors"
+
+// listenHotkey - 全局热键只在 Windows 上支持，其他平台请使用 HTTP 接口
+func listenHotkey(name string) error {
+	return errors.New("global hotkey is only supported on Windows")
+}
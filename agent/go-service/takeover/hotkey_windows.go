@@ -0,0 +1,55 @@
+//go:build windows
+
+package takeover
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// hotkeyPollInterval - 轮询按键状态的间隔，足以捕捉一次普通的按下
+const hotkeyPollInterval = 50 * time.Millisecond
+
+var procGetAsyncKeyState = windows.NewLazySystemDLL("user32.dll").NewProc("GetAsyncKeyState")
+
+// virtualKey - 支持 F1-F12、Pause 与 ScrollLock，避免占用游戏中会用到的按键
+func virtualKey(name string) (uintptr, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	switch name {
+	case "PAUSE":
+		return 0x13, nil
+	case "SCROLLLOCK":
+		return 0x91, nil
+	}
+	if n, err := strconv.Atoi(strings.TrimPrefix(name, "F")); err == nil && strings.HasPrefix(name, "F") && n >= 1 && n <= 12 {
+		return uintptr(0x70 + n - 1), nil
+	}
+	return 0, fmt.Errorf("unsupported hotkey %q, use F1-F12, Pause or ScrollLock", name)
+}
+
+// listenHotkey - 在后台轮询按键，每次按下切换暂停与恢复
+func listenHotkey(name string) error {
+	vk, err := virtualKey(name)
+	if err != nil {
+		return err
+	}
+	if err := procGetAsyncKeyState.Find(); err != nil {
+		return err
+	}
+	go func() {
+		down := false
+		for range time.Tick(hotkeyPollInterval) {
+			state, _, _ := procGetAsyncKeyState.Call(vk)
+			pressed := state&0x8000 != 0
+			if pressed && !down {
+				Toggle()
+			}
+			down = pressed
+		}
+	}()
+	return nil
+}
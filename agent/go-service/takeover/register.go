@@ -0,0 +1,20 @@
+package takeover
+
+import "github.com/MaaXYZ/maa-framework-go/v4"
+
+var (
+	_ maa.TaskerEventSink = &pauseResetter{}
+)
+
+// pauseResetter clears a leftover pause whenever a task starts or ends
+type pauseResetter struct{}
+
+// OnTaskerTask handles tasker task events
+func (r *pauseResetter) OnTaskerTask(tasker *maa.Tasker, event maa.EventStatus, detail maa.TaskerTaskDetail) {
+	reset()
+}
+
+// Register registers the pause resetter as a tasker sink
+func Register() {
+	maa.AgentServerAddTaskerSink(&pauseResetter{})
+}
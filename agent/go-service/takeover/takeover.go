@@ -0,0 +1,169 @@
+// Package takeover lets the user pause a running scan, fix the game by hand, and resume it.
+// Pausing is requested through a local HTTP API or a global hotkey; scans check for it between
+// steps and stop sending input until the user resumes.
+package takeover
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/secret"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// pollInterval - 暂停期间检查恢复与任务停止的间隔
+const pollInterval = 200 * time.Millisecond
+
+var (
+	mu     sync.Mutex
+	paused bool
+	// since - 本次暂停开始的时间
+	since time.Time
+)
+
+// Start serves the pause/resume API and listens for the hotkey, each only when configured.
+// The API refuses to listen beyond loopback unless a token is configured.
+func Start(cfg agentconfig.TakeoverConfig) {
+	if cfg.Addr != "" {
+		token, err := secret.Decrypt(cfg.Token)
+		if err == nil && token == "" && !isLoopback(cfg.Addr) {
+			err = errors.New("non-loopback address requires takeover.token")
+		}
+		if err != nil {
+			log.Error().Err(err).Str("addr", cfg.Addr).Msg("Takeover server not started")
+		} else {
+			go serve(cfg.Addr, token)
+		}
+	}
+	if cfg.Hotkey != "" {
+		if err := listenHotkey(cfg.Hotkey); err != nil {
+			log.Warn().Err(err).Str("hotkey", cfg.Hotkey).Msg("Takeover hotkey not available")
+		}
+	}
+	if cfg.Addr != "" || cfg.Hotkey != "" {
+		log.Info().Str("addr", cfg.Addr).Str("hotkey", cfg.Hotkey).Msg("Manual takeover enabled")
+	}
+}
+
+// Pause asks the running scan to stop sending input at its next checkpoint
+func Pause() {
+	mu.Lock()
+	defer mu.Unlock()
+	if !paused {
+		paused, since = true, time.Now()
+		log.Info().Msg("Takeover requested, pausing at the next checkpoint")
+	}
+}
+
+// Resume lets a paused scan continue
+func Resume() {
+	mu.Lock()
+	defer mu.Unlock()
+	if paused {
+		paused = false
+		log.Info().Dur("paused", time.Since(since)).Msg("Takeover ended, resuming")
+	}
+}
+
+// reset - 任务开始或结束时清除暂停，避免上一次任务留下的暂停卡住下一次任务
+func reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	if paused {
+		paused = false
+		log.Info().Dur("paused", time.Since(since)).Msg("Takeover pause cleared with the task")
+	}
+}
+
+// Toggle pauses a running scan or resumes a paused one
+func Toggle() {
+	if Paused() {
+		Resume()
+	} else {
+		Pause()
+	}
+}
+
+// Paused reports whether a pause has been requested and not yet resumed
+func Paused() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return paused
+}
+
+// Wait blocks while paused. It returns false when the task is stopped during the pause.
+func Wait(ctx *maa.Context) bool {
+	tasker := ctx.GetTasker()
+	for Paused() {
+		if tasker != nil && tasker.Stopping() {
+			return false
+		}
+		time.Sleep(pollInterval)
+	}
+	return true
+}
+
+// serve - POST /pause、POST /resume、GET /status；配置了 token 时需带 Authorization: Bearer <token>
+func serve(addr, token string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /pause", func(w http.ResponseWriter, r *http.Request) {
+		Pause()
+		writeStatus(w)
+	})
+	mux.HandleFunc("POST /resume", func(w http.ResponseWriter, r *http.Request) {
+		Resume()
+		writeStatus(w)
+	})
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		writeStatus(w)
+	})
+	if err := http.ListenAndServe(addr, requireToken(token, mux)); err != nil {
+		log.Error().Err(err).Str("addr", addr).Msg("Takeover server stopped")
+	}
+}
+
+// requireToken - token 为空时不校验
+func requireToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopback - addr 的主机部分是否只在本机可达；主机为空（如 :6061）会监听所有网卡，不算本机
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || host == "" {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func writeStatus(w http.ResponseWriter) {
+	mu.Lock()
+	status := map[string]any{"paused": paused}
+	if paused {
+		status["since"] = since
+	}
+	mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,17 @@
+package taskresult
+
+import (
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/registry"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+)
+
+var (
+	_ maa.CustomRecognitionRunner = &TaskResultRecognition{}
+)
+
+// Components returns the custom components of taskresult package
+func Components() []registry.Component {
+	return []registry.Component{
+		registry.Recognition("TaskResultRecognition", &TaskResultRecognition{}, "原样返回参数作为识别详情，使任务结果出现在识别回调中"),
+	}
+}
@@ -0,0 +1,87 @@
+package taskresult
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/webhook"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// resultNode - 承载结果的节点名，客户端可通过该节点的识别详情读取 JSON
+const resultNode = "TaskResult"
+
+type Status string
+
+const (
+	StatusSuccess Status = "success"
+	StatusFailed  Status = "failed"
+	// StatusSkipped - 任务正常结束但没有执行任何操作，例如无商品可买
+	StatusSkipped Status = "skipped"
+)
+
+// Decision - 任务做出的一次决定
+type Decision struct {
+	Action string `json:"action"`
+	Target string `json:"target,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Result - 任务结束时输出的机器可读结果
+type Result struct {
+	Task       string             `json:"task"`
+	Status     Status             `json:"status"`
+	Metrics    map[string]float64 `json:"metrics,omitempty"`
+	Decisions  []Decision         `json:"decisions,omitempty"`
+	FinishedAt time.Time          `json:"finished_at"`
+}
+
+var (
+	mu   sync.Mutex
+	last = make(map[string]Result)
+)
+
+// Emit records the result and publishes it as the recognition detail of the TaskResult node
+func Emit(ctx *maa.Context, r Result) {
+	r.FinishedAt = time.Now()
+	mu.Lock()
+	last[r.Task] = r
+	mu.Unlock()
+
+	payload, err := json.Marshal(r)
+	if err != nil {
+		log.Error().Err(err).Str("task", r.Task).Msg("Failed to marshal task result")
+		return
+	}
+	log.Info().RawJSON("result", payload).Msg("Task result")
+	webhook.Enqueue(r.Task, payload)
+
+	ctx.RunTask(resultNode, map[string]any{
+		resultNode: map[string]any{
+			"recognition":              "Custom",
+			"custom_recognition":       "TaskResultRecognition",
+			"custom_recognition_param": r,
+			"action":                   "DoNothing",
+		},
+	})
+}
+
+// Last returns the latest result emitted by task in this process
+func Last(task string) (Result, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	r, ok := last[task]
+	return r, ok
+}
+
+// TaskResultRecognition - 原样返回参数作为识别详情，使结果出现在 agent 协议的识别回调中
+type TaskResultRecognition struct{}
+
+func (r *TaskResultRecognition) Run(ctx *maa.Context, arg *maa.CustomRecognitionArg) (*maa.CustomRecognitionResult, bool) {
+	return &maa.CustomRecognitionResult{
+		Box:    arg.Roi,
+		Detail: arg.CustomRecognitionParam,
+	}, true
+}
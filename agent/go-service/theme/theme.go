@@ -0,0 +1,157 @@
+package theme
+
+import (
+	"encoding/json"
+	"image"
+	"sort"
+	"sync"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/overridesnap"
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// profilesNode - 在该节点的 attach.profiles 中定义各主题的取样点和覆盖
+const profilesNode = "ThemeProfiles"
+
+// 取样坐标以 1280x720 为基准
+const (
+	baseWidth  = 1280
+	baseHeight = 720
+)
+
+// 未指定时的颜色容差（各通道）
+const defaultTolerance = 24
+
+// Sample - 一个取样像素及其在该主题下的颜色
+type Sample struct {
+	X         int    `json:"x"`
+	Y         int    `json:"y"`
+	Color     [3]int `json:"color"`
+	Tolerance int    `json:"tolerance"`
+}
+
+// Profile - 一套界面主题的识别配置
+type Profile struct {
+	Name    string   `json:"name"`
+	Samples []Sample `json:"samples"`
+	// Override - 命中该主题时应用的 pipeline 覆盖，例如替换模板、调整阈值
+	Override map[string]any `json:"override"`
+}
+
+var (
+	mu      sync.Mutex
+	current string // 最近一次识别到的主题，空表示默认主题
+)
+
+// Current returns the theme detected by the last Apply, empty for the default theme
+func Current() string {
+	mu.Lock()
+	defer mu.Unlock()
+	return current
+}
+
+// Apply samples the current screen, picks the profile whose samples all match and applies its override.
+// Nodes touched by any profile are first rolled back, so switching themes between runs does not
+// leave the previous profile's templates in place. Call it at the start of a module's Init action.
+func Apply(ctx *maa.Context) string {
+	profiles := loadProfiles(ctx)
+	if len(profiles) == 0 {
+		return ""
+	}
+
+	var nodes []string
+	seen := map[string]bool{}
+	for _, p := range profiles {
+		for node := range p.Override {
+			if !seen[node] {
+				seen[node] = true
+				nodes = append(nodes, node)
+			}
+		}
+	}
+	sort.Strings(nodes)
+	overridesnap.Reset(ctx, "Theme", nodes...)
+
+	controller := ctx.GetTasker().GetController()
+	controller.PostScreencap().Wait()
+	img, err := controller.CacheImage()
+	if err != nil || img == nil {
+		log.Warn().Err(err).Msg("Failed to capture screen for theme detection, using default theme")
+		return ""
+	}
+
+	name := ""
+	for _, p := range profiles {
+		if matches(img, p.Samples) {
+			name = p.Name
+			if len(p.Override) > 0 {
+				if err := overridesnap.Apply(ctx, "Theme", p.Override); err != nil {
+					log.Error().Err(err).Str("theme", p.Name).Msg("Failed to apply theme override")
+				}
+			}
+			break
+		}
+	}
+
+	mu.Lock()
+	current = name
+	mu.Unlock()
+	if name == "" {
+		log.Info().Msg("No theme profile matched, using default theme")
+	} else {
+		log.Info().Str("theme", name).Msg("Theme detected")
+	}
+	return name
+}
+
+func loadProfiles(ctx *maa.Context) []Profile {
+	raw, err := ctx.GetNodeJSON(profilesNode)
+	if err != nil || raw == "" {
+		return nil
+	}
+	var node struct {
+		Attach struct {
+			Profiles []Profile `json:"profiles"`
+		} `json:"attach"`
+	}
+	if err := json.Unmarshal([]byte(raw), &node); err != nil {
+		log.Error().Err(err).Str("node", profilesNode).Msg("Failed to parse theme profiles")
+		return nil
+	}
+	return node.Attach.Profiles
+}
+
+// matches - 所有取样点都在容差内才算命中，没有取样点的配置视为不可识别
+func matches(img image.Image, samples []Sample) bool {
+	if len(samples) == 0 {
+		return false
+	}
+	bounds := img.Bounds()
+	for _, s := range samples {
+		x := bounds.Min.X + s.X*bounds.Dx()/baseWidth
+		y := bounds.Min.Y + s.Y*bounds.Dy()/baseHeight
+		if !(image.Point{X: x, Y: y}).In(bounds) {
+			return false
+		}
+		r, g, b, _ := img.At(x, y).RGBA()
+		tolerance := s.Tolerance
+		if tolerance <= 0 {
+			tolerance = defaultTolerance
+		}
+		got := [3]int{int(r >> 8), int(g >> 8), int(b >> 8)}
+		for i := range got {
+			if abs(got[i]-s.Color[i]) > tolerance {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
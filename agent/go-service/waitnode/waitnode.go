@@ -0,0 +1,119 @@
+package waitnode
+
+import (
+	"image"
+	"time"
+
+	"github.com/MaaXYZ/maa-framework-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultInterval - 未指定时两次截图识别之间的间隔
+const DefaultInterval = 300 * time.Millisecond
+
+// Condition - 一个等待条件，Node 命中即视为满足
+type Condition struct {
+	// Name - 满足时报告的名称，为空时使用 Node
+	Name string
+	Node string
+	// Override - 可选的 pipeline override，例如临时修改 roi 或 expected
+	Override map[string]any
+}
+
+// Options - 等待的超时与轮询间隔
+type Options struct {
+	Timeout  time.Duration
+	Interval time.Duration
+}
+
+// Result - 等待结果；超时或任务停止时 Fired 为空、Index 为 -1
+type Result struct {
+	Fired  string
+	Index  int
+	Detail *maa.RecognitionDetail
+	// Image - 满足条件时的截图
+	Image   image.Image
+	Stopped bool
+	Elapsed time.Duration
+}
+
+// Hit reports whether one of the conditions was met
+func (r Result) Hit() bool {
+	return r.Index >= 0
+}
+
+// TimedOut reports whether the wait ran out of time without any condition being met
+func (r Result) TimedOut() bool {
+	return r.Index < 0 && !r.Stopped
+}
+
+// Center returns the center of the box that satisfied the condition
+func (r Result) Center() (int, int) {
+	if r.Detail == nil {
+		return 0, 0
+	}
+	box := r.Detail.Box
+	return box.X() + box.Width()/2, box.Y() + box.Height()/2
+}
+
+// Nodes builds one condition per node name
+func Nodes(names ...string) []Condition {
+	conds := make([]Condition, 0, len(names))
+	for _, name := range names {
+		conds = append(conds, Condition{Node: name})
+	}
+	return conds
+}
+
+// Any takes screenshots until one of the conditions is met, the timeout passes or the task is stopped.
+// Conditions are checked in order on each screenshot, so earlier ones win when several match.
+func Any(ctx *maa.Context, opts Options, conds ...Condition) Result {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	tasker := ctx.GetTasker()
+	controller := tasker.GetController()
+	start := time.Now()
+	deadline := start.Add(opts.Timeout)
+
+	for {
+		if tasker.Stopping() {
+			return Result{Index: -1, Stopped: true, Elapsed: time.Since(start)}
+		}
+		controller.PostScreencap().Wait()
+		img, err := controller.CacheImage()
+		if err == nil && img != nil {
+			for i, cond := range conds {
+				if detail, ok := recognize(ctx, img, cond); ok {
+					name := cond.Name
+					if name == "" {
+						name = cond.Node
+					}
+					return Result{Fired: name, Index: i, Detail: detail, Image: img, Elapsed: time.Since(start)}
+				}
+			}
+		}
+		if !time.Now().Add(opts.Interval).Before(deadline) {
+			break
+		}
+		time.Sleep(opts.Interval)
+	}
+
+	log.Debug().Int("conditions", len(conds)).Dur("timeout", opts.Timeout).Msg("Wait for node timed out")
+	return Result{Index: -1, Elapsed: time.Since(start)}
+}
+
+func recognize(ctx *maa.Context, img image.Image, cond Condition) (*maa.RecognitionDetail, bool) {
+	var detail *maa.RecognitionDetail
+	var err error
+	if cond.Override != nil {
+		detail, err = ctx.RunRecognition(cond.Node, img, cond.Override)
+	} else {
+		detail, err = ctx.RunRecognition(cond.Node, img)
+	}
+	if err != nil {
+		log.Debug().Err(err).Str("node", cond.Node).Msg("Wait condition recognition failed")
+		return nil, false
+	}
+	return detail, detail != nil && detail.Hit
+}
@@ -0,0 +1,239 @@
+// Package webhook posts task results to the webhooks configured by the user. Deliveries are
+// queued on disk and retried with exponential backoff, so a result is not lost when the
+// endpoint is unreachable for a while during an overnight run.
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/agentconfig"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/datadir"
+	"github.com/MaaXYZ/MaaEnd/agent/go-service/secret"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	postTimeout = 15 * time.Second
+	// baseBackoff, maxBackoff - 第 n 次失败后等待 baseBackoff * 2^(n-1)，最长 maxBackoff
+	baseBackoff = 30 * time.Second
+	maxBackoff  = 30 * time.Minute
+	// defaultMaxAttempts - 未配置 max_attempts 时的投递次数，按上面的间隔约覆盖一晚
+	defaultMaxAttempts = 12
+)
+
+// delivery - 一条待投递的结果
+type delivery struct {
+	ID string `json:"id"`
+	// Webhook - 目标 webhook 的名称，投递时按名称读取当前配置
+	Webhook   string          `json:"webhook"`
+	Payload   json.RawMessage `json:"payload"`
+	Attempts  int             `json:"attempts"`
+	NextAt    time.Time       `json:"next_at"`
+	CreatedAt time.Time       `json:"created_at"`
+	LastError string          `json:"last_error,omitempty"`
+}
+
+var (
+	mu    sync.Mutex
+	queue []delivery
+	// wake - 有新的投递时唤醒后台协程
+	wake   = make(chan struct{}, 1)
+	seq    int
+	client = &http.Client{Timeout: postTimeout}
+)
+
+func queuePath() string {
+	return datadir.Path("webhook", "queue.json")
+}
+
+// saveQueue must be called with mu held
+func saveQueue() {
+	data, err := json.MarshalIndent(queue, "", "  ")
+	if err != nil {
+		return
+	}
+	path := queuePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Warn().Err(err).Msg("Failed to create webhook data dir")
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Warn().Err(err).Msg("Failed to save webhook queue")
+	}
+}
+
+// Start loads the deliveries left by the previous run and delivers queued results in the background
+func Start() {
+	mu.Lock()
+	if data, err := os.ReadFile(queuePath()); err == nil {
+		if err := json.Unmarshal(data, &queue); err != nil {
+			log.Warn().Err(err).Msg("Failed to parse webhook queue, starting empty")
+			queue = nil
+		}
+	}
+	if len(queue) > 0 {
+		log.Info().Int("pending", len(queue)).Msg("Resuming webhook deliveries from the previous run")
+	}
+	mu.Unlock()
+	go run()
+}
+
+// Enqueue queues payload for every webhook that subscribes to task
+func Enqueue(task string, payload []byte) {
+	hooks := agentconfig.Get().Webhooks
+	if len(hooks) == 0 {
+		return
+	}
+	now := time.Now()
+	mu.Lock()
+	for _, hook := range hooks {
+		if hook.URL == "" || (len(hook.Tasks) > 0 && !slices.Contains(hook.Tasks, task)) {
+			continue
+		}
+		seq++
+		queue = append(queue, delivery{
+			ID:        fmt.Sprintf("%d-%d", now.UnixNano(), seq),
+			Webhook:   hook.Name,
+			Payload:   append(json.RawMessage(nil), payload...),
+			NextAt:    now,
+			CreatedAt: now,
+		})
+	}
+	saveQueue()
+	mu.Unlock()
+
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+// backoff returns how long to wait after the given number of failed attempts
+func backoff(attempts int) time.Duration {
+	d := baseBackoff
+	for i := 1; i < attempts && d < maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, maxBackoff)
+}
+
+// run delivers due items one at a time and sleeps until the next one is due or a new one arrives
+func run() {
+	for {
+		d, ok, wait := nextDue(time.Now())
+		if !ok {
+			timer := time.NewTimer(wait)
+			select {
+			case <-wake:
+			case <-timer.C:
+			}
+			timer.Stop()
+			continue
+		}
+		finish(d, deliver(d))
+	}
+}
+
+// nextDue returns the earliest due delivery, or how long until one is due
+func nextDue(now time.Time) (delivery, bool, time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	wait := time.Hour
+	for _, d := range queue {
+		if !d.NextAt.After(now) {
+			return d, true, 0
+		}
+		wait = min(wait, d.NextAt.Sub(now))
+	}
+	return delivery{}, false, wait
+}
+
+// find returns the webhook config named name
+func find(name string) (agentconfig.WebhookConfig, bool) {
+	for _, hook := range agentconfig.Get().Webhooks {
+		if hook.Name == name {
+			return hook, true
+		}
+	}
+	return agentconfig.WebhookConfig{}, false
+}
+
+func deliver(d delivery) error {
+	hook, ok := find(d.Webhook)
+	if !ok {
+		return errRemoved
+	}
+	endpoint, err := secret.Decrypt(hook.URL)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(d.Payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+// errRemoved - 投递前 webhook 已从配置中删除
+var errRemoved = fmt.Errorf("webhook no longer configured")
+
+// finish removes a delivered or exhausted item, or schedules the next attempt
+func finish(d delivery, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	idx := slices.IndexFunc(queue, func(q delivery) bool { return q.ID == d.ID })
+	if idx < 0 {
+		return
+	}
+	if err == nil {
+		log.Info().Str("webhook", d.Webhook).Int("attempts", d.Attempts+1).Msg("Webhook delivered")
+		queue = slices.Delete(queue, idx, idx+1)
+		saveQueue()
+		return
+	}
+
+	d.Attempts++
+	d.LastError = err.Error()
+	maxAttempts := defaultMaxAttempts
+	if hook, ok := find(d.Webhook); ok && hook.MaxAttempts > 0 {
+		maxAttempts = hook.MaxAttempts
+	}
+	if err == errRemoved || d.Attempts >= maxAttempts {
+		log.Warn().Err(err).Str("webhook", d.Webhook).Int("attempts", d.Attempts).Msg("Webhook delivery given up")
+		queue = slices.Delete(queue, idx, idx+1)
+		saveQueue()
+		appendDead(d)
+		return
+	}
+	d.NextAt = time.Now().Add(backoff(d.Attempts))
+	log.Warn().Err(err).Str("webhook", d.Webhook).Int("attempts", d.Attempts).Time("next_at", d.NextAt).Msg("Webhook delivery failed, will retry")
+	queue[idx] = d
+	saveQueue()
+}
+
+// appendDead - 放弃的投递追加写入 webhook/dead.jsonl，便于手动补发
+func appendDead(d delivery) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return
+	}
+	f, err := os.OpenFile(datadir.Path("webhook", "dead.jsonl"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to record dropped webhook delivery")
+		return
+	}
+	defer f.Close()
+	f.Write(append(data, '\n'))
+}
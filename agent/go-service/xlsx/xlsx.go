@@ -0,0 +1,185 @@
+// Package xlsx writes minimal Excel workbooks with archive/zip: string, number, bool and
+// formula cells, one worksheet per Sheet, no styles.
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Formula - 以公式写入的单元格，不含开头的 "="，如 "SUM(C2:C10)"
+type Formula string
+
+// Sheet - 一个工作表，按行追加单元格
+type Sheet struct {
+	Name string
+	rows [][]any
+}
+
+// AddRow appends a row. Cells may be string, int, int64, float64, bool, Formula or nil for an empty cell;
+// anything else is written with fmt.Sprint.
+func (s *Sheet) AddRow(cells ...any) {
+	s.rows = append(s.rows, cells)
+}
+
+// Rows returns the number of rows added so far, so totals can reference the rows above them
+func (s *Sheet) Rows() int {
+	return len(s.rows)
+}
+
+// Workbook - 工作簿，工作表按添加顺序排列
+type Workbook struct {
+	sheets []*Sheet
+}
+
+// AddSheet appends a sheet; Excel limits names to 31 characters without []:*?/\
+func (w *Workbook) AddSheet(name string) *Sheet {
+	s := &Sheet{Name: name}
+	w.sheets = append(w.sheets, s)
+	return s
+}
+
+// Column returns the column letters of a 1-based index, 1 -> "A", 28 -> "AB"
+func Column(n int) string {
+	var b []byte
+	for n > 0 {
+		n--
+		b = append([]byte{byte('A' + n%26)}, b...)
+		n /= 26
+	}
+	return string(b)
+}
+
+// Save writes the workbook to path
+func (w *Workbook) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := w.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Write writes the workbook as an .xlsx archive
+func (w *Workbook) Write(out io.Writer) error {
+	if len(w.sheets) == 0 {
+		return fmt.Errorf("workbook has no sheets")
+	}
+	z := zip.NewWriter(out)
+	files := []part{
+		{"[Content_Types].xml", w.contentTypes()},
+		{"_rels/.rels", rootRels},
+		{"xl/workbook.xml", w.workbook()},
+		{"xl/_rels/workbook.xml.rels", w.workbookRels()},
+	}
+	for i, s := range w.sheets {
+		files = append(files, part{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), s.xml()})
+	}
+	for _, file := range files {
+		fw, err := z.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, file.body); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+// part - 压缩包中的一个文件
+type part struct {
+	name string
+	body string
+}
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+const rootRels = xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+func (w *Workbook) contentTypes() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	for i := range w.sheets {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+func (w *Workbook) workbook() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, s := range w.sheets {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(s.Name), i+1, i+1)
+	}
+	// 打开时重新计算公式，写入的文件不带缓存值
+	b.WriteString(`</sheets><calcPr fullCalcOnLoad="1"/></workbook>`)
+	return b.String()
+}
+
+func (w *Workbook) workbookRels() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range w.sheets {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+func (s *Sheet) xml() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, row := range s.rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, v := range row {
+			writeCell(&b, fmt.Sprintf("%s%d", Column(c+1), r+1), v)
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+func writeCell(b *strings.Builder, ref string, v any) {
+	switch v := v.(type) {
+	case nil:
+	case Formula:
+		fmt.Fprintf(b, `<c r="%s"><f>%s</f></c>`, ref, escape(string(v)))
+	case int, int64, float64:
+		fmt.Fprintf(b, `<c r="%s"><v>%v</v></c>`, ref, v)
+	case bool:
+		n := 0
+		if v {
+			n = 1
+		}
+		fmt.Fprintf(b, `<c r="%s" t="b"><v>%d</v></c>`, ref, n)
+	case string:
+		fmt.Fprintf(b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, escape(v))
+	default:
+		writeCell(b, ref, fmt.Sprint(v))
+	}
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}